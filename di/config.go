@@ -0,0 +1,217 @@
+package di
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+)
+
+// StorageDriver selects the persistence backend used by the container
+type StorageDriver string
+
+const (
+	StorageMemory StorageDriver = "memory" // In-memory maps, wiped on every restart
+	StorageSQLite StorageDriver = "sqlite" // Embedded SQLite file
+)
+
+// Config holds the settings used to build the container
+type Config struct {
+	StorageDriver StorageDriver
+	SQLitePath    string // Database file used when StorageDriver is StorageSQLite
+
+	ReservationTTL           time.Duration // How long stock stays reserved for an unpaid order
+	ReservationSweepInterval time.Duration // How often expired reservations are released
+
+	IdempotencyKeyTTL           time.Duration // How long a response is replayed for a repeated Idempotency-Key
+	IdempotencyKeySweepInterval time.Duration // How often expired idempotency keys are deleted
+
+	TaxStandardRate      int                     // Consumption tax in percent
+	TaxReducedRate       int                     // Reduced consumption tax in percent
+	TaxReducedCategories []string                // Product categories taxed at the reduced rate
+	TaxRounding          entity.TaxRounding      // How fractional yen of tax are rounded
+	TaxRoundingScope     entity.TaxRoundingScope // Whether tax is rounded per line or per rate on the invoice
+
+	AllocationStrategy string   // Which warehouses orders draw stock from: "priority", "nearest" or "fewest_shipments"
+	AllocationPriority []string // Warehouse IDs the priority strategy draws from first; others follow by ID
+
+	StockAlertNotifier      string        // Where low-stock alerts go: "log" or "file"
+	StockAlertFile          string        // File alerts are appended to when StockAlertNotifier is "file"
+	StockAlertSweepInterval time.Duration // How often all stock is checked against its reorder points
+}
+
+const (
+	StockAlertNotifierLog  = "log"  // Alerts are written to the standard logger
+	StockAlertNotifierFile = "file" // Alerts are appended to StockAlertFile as JSON lines
+)
+
+// DefaultConfig returns the configuration used when nothing is overridden
+func DefaultConfig() Config {
+	return Config{
+		StorageDriver:            StorageMemory,
+		SQLitePath:               "ec_site.db",
+		ReservationTTL:           15 * time.Minute,
+		ReservationSweepInterval: time.Minute,
+
+		IdempotencyKeyTTL:           24 * time.Hour,
+		IdempotencyKeySweepInterval: time.Hour,
+
+		TaxStandardRate:      10,
+		TaxReducedRate:       8,
+		TaxReducedCategories: []string{"Food"},
+		TaxRounding:          entity.TaxRoundingFloor,
+		TaxRoundingScope:     entity.TaxRoundPerInvoice,
+
+		AllocationStrategy: service.AllocationStrategyPriority,
+
+		StockAlertNotifier:      StockAlertNotifierLog,
+		StockAlertFile:          "stock_alerts.log",
+		StockAlertSweepInterval: 5 * time.Minute,
+	}
+}
+
+// TaxPolicy builds the tax policy described by the configuration
+func (cfg Config) TaxPolicy() (*entity.RateTaxPolicy, error) {
+	categoryClasses := make(map[string]entity.TaxClass)
+	for _, category := range cfg.TaxReducedCategories {
+		categoryClasses[category] = entity.TaxClassReduced
+	}
+	rates := map[entity.TaxClass]int{
+		entity.TaxClassStandard: cfg.TaxStandardRate,
+		entity.TaxClassReduced:  cfg.TaxReducedRate,
+	}
+	return entity.NewRateTaxPolicy(rates, categoryClasses, cfg.TaxRounding, cfg.TaxRoundingScope)
+}
+
+// StockAllocationStrategy builds the allocation strategy described by the configuration
+func (cfg Config) StockAllocationStrategy() (service.AllocationStrategy, error) {
+	return service.NewAllocationStrategy(cfg.AllocationStrategy, cfg.AllocationPriority)
+}
+
+// LoadConfigFromEnv builds a Config from environment variables, falling back to DefaultConfig
+//
+//	STORAGE_DRIVER:             "memory" (default) or "sqlite"
+//	SQLITE_PATH:                path of the SQLite database file (default "ec_site.db")
+//	RESERVATION_TTL:            Go duration such as "15m" (default 15m)
+//	RESERVATION_SWEEP_INTERVAL: Go duration such as "1m" (default 1m)
+//	IDEMPOTENCY_KEY_TTL:        Go duration such as "24h" (default 24h)
+//	IDEMPOTENCY_SWEEP_INTERVAL: Go duration such as "1h" (default 1h)
+//	TAX_STANDARD_RATE:          consumption tax in percent (default 10)
+//	TAX_REDUCED_RATE:           reduced consumption tax in percent (default 8)
+//	TAX_REDUCED_CATEGORIES:     comma-separated categories at the reduced rate (default "Food")
+//	TAX_ROUNDING:               "floor" (default), "round" or "ceil"
+//	TAX_ROUNDING_SCOPE:         "invoice" (default) or "line"
+//	ALLOCATION_STRATEGY:        "priority" (default), "nearest" or "fewest_shipments"
+//	ALLOCATION_PRIORITY:        comma-separated warehouse IDs drawn from first by "priority"
+//	STOCK_ALERT_NOTIFIER:       "log" (default) or "file"
+//	STOCK_ALERT_FILE:           file the "file" notifier appends to (default "stock_alerts.log")
+//	STOCK_ALERT_SWEEP_INTERVAL: Go duration such as "5m" (default 5m)
+func LoadConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+
+	if driver := os.Getenv("STORAGE_DRIVER"); driver != "" {
+		cfg.StorageDriver = StorageDriver(driver)
+	}
+	if path := os.Getenv("SQLITE_PATH"); path != "" {
+		cfg.SQLitePath = path
+	}
+	if err := durationFromEnv("RESERVATION_TTL", &cfg.ReservationTTL); err != nil {
+		return cfg, err
+	}
+	if err := durationFromEnv("RESERVATION_SWEEP_INTERVAL", &cfg.ReservationSweepInterval); err != nil {
+		return cfg, err
+	}
+	if err := durationFromEnv("IDEMPOTENCY_KEY_TTL", &cfg.IdempotencyKeyTTL); err != nil {
+		return cfg, err
+	}
+	if err := durationFromEnv("IDEMPOTENCY_SWEEP_INTERVAL", &cfg.IdempotencyKeySweepInterval); err != nil {
+		return cfg, err
+	}
+	if err := intFromEnv("TAX_STANDARD_RATE", &cfg.TaxStandardRate); err != nil {
+		return cfg, err
+	}
+	if err := intFromEnv("TAX_REDUCED_RATE", &cfg.TaxReducedRate); err != nil {
+		return cfg, err
+	}
+	listFromEnv("TAX_REDUCED_CATEGORIES", &cfg.TaxReducedCategories)
+	if rounding := os.Getenv("TAX_ROUNDING"); rounding != "" {
+		cfg.TaxRounding = entity.TaxRounding(rounding)
+	}
+	if scope := os.Getenv("TAX_ROUNDING_SCOPE"); scope != "" {
+		cfg.TaxRoundingScope = entity.TaxRoundingScope(scope)
+	}
+	if _, err := cfg.TaxPolicy(); err != nil {
+		return cfg, fmt.Errorf("invalid tax configuration: %w", err)
+	}
+	if strategy := os.Getenv("ALLOCATION_STRATEGY"); strategy != "" {
+		cfg.AllocationStrategy = strategy
+	}
+	listFromEnv("ALLOCATION_PRIORITY", &cfg.AllocationPriority)
+	if _, err := cfg.StockAllocationStrategy(); err != nil {
+		return cfg, fmt.Errorf("invalid ALLOCATION_STRATEGY: %w", err)
+	}
+	if notifier := os.Getenv("STOCK_ALERT_NOTIFIER"); notifier != "" {
+		cfg.StockAlertNotifier = notifier
+	}
+	if cfg.StockAlertNotifier != StockAlertNotifierLog && cfg.StockAlertNotifier != StockAlertNotifierFile {
+		return cfg, fmt.Errorf("invalid STOCK_ALERT_NOTIFIER: %s", cfg.StockAlertNotifier)
+	}
+	if path := os.Getenv("STOCK_ALERT_FILE"); path != "" {
+		cfg.StockAlertFile = path
+	}
+	if err := durationFromEnv("STOCK_ALERT_SWEEP_INTERVAL", &cfg.StockAlertSweepInterval); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+// durationFromEnv overwrites *target with the positive duration in the named variable, if set
+func durationFromEnv(name string, target *time.Duration) error {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if d <= 0 {
+		return fmt.Errorf("invalid %s: must be positive", name)
+	}
+	*target = d
+	return nil
+}
+
+// intFromEnv overwrites *target with the integer in the named variable, if set
+func intFromEnv(name string, target *int) error {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	*target = n
+	return nil
+}
+
+// listFromEnv overwrites *target with the non-empty items of the comma-separated named variable,
+// if set; an empty value clears the list
+func listFromEnv(name string, target *[]string) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return
+	}
+	*target = nil
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*target = append(*target, item)
+		}
+	}
+}
@@ -2,15 +2,20 @@ package di
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
 	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
 	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
 	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/auth"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/notification"
 	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/payment"
 	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/persistence"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/persistence/sqlite"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/search"
 	"github.com/gal1996/vibe_coding_with_architecture/interface/handler"
 	"github.com/gal1996/vibe_coding_with_architecture/interface/middleware"
 	"github.com/gal1996/vibe_coding_with_architecture/usecase/interactor"
@@ -20,22 +25,38 @@ import (
 // Container holds all dependencies
 type Container struct {
 	// Repositories
-	ProductRepository   repository.ProductRepository
-	UserRepository      repository.UserRepository
-	OrderRepository     repository.OrderRepository
-	StockRepository     repository.StockRepository
-	WarehouseRepository repository.WarehouseRepository
-	CouponRepository    repository.CouponRepository
-	WishlistRepository  repository.WishlistRepository
+	ProductRepository      repository.ProductRepository
+	UserRepository         repository.UserRepository
+	OrderRepository        repository.OrderRepository
+	StockRepository        repository.StockRepository
+	ReservationRepository  repository.StockReservationRepository
+	WarehouseRepository    repository.WarehouseRepository
+	CouponRepository       repository.CouponRepository
+	WishlistRepository     repository.WishlistRepository
+	CartRepository         repository.CartRepository
+	ShippingRuleRepository repository.ShippingRuleRepository
+	AddressRepository      repository.AddressRepository
+	ReorderPointRepository repository.ReorderPointRepository
+	UnitOfWork             repository.UnitOfWork
+	IdempotencyStore       port.IdempotencyStore
 
 	// Services
-	AuthService      port.AuthService
-	PaymentService   port.PaymentService
-	OrderService     *service.OrderService
-	StockService     *service.StockService
-	CouponService    *service.CouponService
-	AnalyticsService *service.AnalyticsService
-	WishlistService  *service.WishlistService
+	AuthService        port.AuthService
+	PaymentService     port.PaymentService
+	OrderService       *service.OrderService
+	StockService       *service.StockService
+	CouponService      *service.CouponService
+	AnalyticsService   *service.AnalyticsService
+	WishlistService    *service.WishlistService
+	CartService        *service.CartService
+	ShippingCalculator service.ShippingCalculator
+	AddressService     *service.AddressService
+	WarehouseService   *service.WarehouseService
+	TransferService    *service.StockTransferService
+	ReorderService     *service.ReorderService
+	PricingService     *service.PricingService
+	SearchService      *service.ProductSearchService
+	StockAlertNotifier port.StockAlertNotifier
 
 	// Use Cases
 	ProductUseCase   *interactor.ProductUseCase
@@ -43,44 +64,185 @@ type Container struct {
 	OrderUseCase     *interactor.OrderUseCase
 	AnalyticsUseCase *interactor.AnalyticsUseCase
 	WishlistUseCase  *interactor.WishlistUseCase
+	CartUseCase      *interactor.CartUseCase
+	ShippingUseCase  *interactor.ShippingUseCase
+	AddressUseCase   *interactor.AddressUseCase
+	WarehouseUseCase *interactor.WarehouseUseCase
+	TransferUseCase  *interactor.StockTransferUseCase
+	AlertUseCase     *interactor.StockAlertUseCase
+	CatalogUseCase   *interactor.CatalogUseCase
 
 	// Handlers
-	ProductHandler *handler.ProductHandler
-	UserHandler    *handler.UserHandler
-	OrderHandler   *handler.OrderHandler
-	AdminHandler   *handler.AdminHandler
-	WishlistHandler *handler.WishlistHandler
+	ProductHandler       *handler.ProductHandler
+	UserHandler          *handler.UserHandler
+	OrderHandler         *handler.OrderHandler
+	AdminHandler         *handler.AdminHandler
+	WishlistHandler      *handler.WishlistHandler
+	CartHandler          *handler.CartHandler
+	ShippingHandler      *handler.ShippingHandler
+	AddressHandler       *handler.AddressHandler
+	WarehouseHandler     *handler.WarehouseHandler
+	StockTransferHandler *handler.StockTransferHandler
+	StockAlertHandler    *handler.StockAlertHandler
+	CatalogHandler       *handler.CatalogHandler
 
 	// Middleware
-	AuthMiddleware *middleware.AuthMiddleware
+	AuthMiddleware        *middleware.AuthMiddleware
+	IdempotencyMiddleware *middleware.IdempotencyMiddleware
+
+	// db is the open database handle when a SQL storage driver is used
+	db *sql.DB
+
+	reservationSweepInterval    time.Duration
+	idempotencyKeySweepInterval time.Duration
+	stockAlertSweepInterval     time.Duration
 }
 
 // NewContainer creates a new dependency injection container
-func NewContainer() *Container {
-	// Initialize repositories
-	productRepo := persistence.NewMemoryProductRepository()
-	userRepo := persistence.NewMemoryUserRepository()
-	orderRepo := persistence.NewMemoryOrderRepository()
-	stockRepo := persistence.NewMemoryStockRepository()
-	warehouseRepo := persistence.NewMemoryWarehouseRepository()
-	couponRepo := persistence.NewMemoryCouponRepository()
-	wishlistRepo := persistence.NewMemoryWishlistRepository()
+func NewContainer(cfg Config) (*Container, error) {
+	// Initialize repositories for the configured storage driver
+	var (
+		db               *sql.DB
+		productRepo      repository.ProductRepository
+		userRepo         repository.UserRepository
+		orderRepo        repository.OrderRepository
+		stockRepo        repository.StockRepository
+		movementRepo     repository.StockMovementRepository
+		transferRepo     repository.StockTransferRepository
+		reservationRepo  repository.StockReservationRepository
+		warehouseRepo    repository.WarehouseRepository
+		couponRepo       repository.CouponRepository
+		wishlistRepo     repository.WishlistRepository
+		cartRepo         repository.CartRepository
+		shippingRuleRepo repository.ShippingRuleRepository
+		addressRepo      repository.AddressRepository
+		reorderRepo      repository.ReorderPointRepository
+		priceHistoryRepo repository.PriceHistoryRepository
+		saleRepo         repository.SaleRepository
+		unitOfWork       repository.UnitOfWork
+		idempotency      port.IdempotencyStore
+	)
+
+	switch cfg.StorageDriver {
+	case StorageMemory, "":
+		memoryOrderRepo := persistence.NewMemoryOrderRepository()
+		memoryStockRepo := persistence.NewMemoryStockRepository()
+		memoryCouponRepo := persistence.NewMemoryCouponRepository()
+		memoryReservationRepo := persistence.NewMemoryStockReservationRepository()
+		productRepo = persistence.NewMemoryProductRepository()
+		userRepo = persistence.NewMemoryUserRepository()
+		orderRepo = memoryOrderRepo
+		stockRepo = memoryStockRepo
+		movementRepo = memoryStockRepo.Ledger()
+		transferRepo = memoryStockRepo.Transfers()
+		reservationRepo = memoryReservationRepo
+		warehouseRepo = persistence.NewMemoryWarehouseRepository()
+		couponRepo = memoryCouponRepo
+		wishlistRepo = persistence.NewMemoryWishlistRepository()
+		cartRepo = persistence.NewMemoryCartRepository()
+		shippingRuleRepo = persistence.NewMemoryShippingRuleRepository()
+		addressRepo = persistence.NewMemoryAddressRepository()
+		reorderRepo = persistence.NewMemoryReorderPointRepository()
+		priceHistoryRepo = persistence.NewMemoryPriceHistoryRepository()
+		saleRepo = persistence.NewMemorySaleRepository()
+		unitOfWork = persistence.NewMemoryUnitOfWork(memoryStockRepo, memoryReservationRepo, memoryCouponRepo, memoryOrderRepo)
+		idempotency = persistence.NewMemoryIdempotencyStore()
+	case StorageSQLite:
+		var err error
+		db, err = sqlite.Open(context.Background(), cfg.SQLitePath)
+		if err != nil {
+			return nil, err
+		}
+		productRepo = sqlite.NewProductRepository(db)
+		userRepo = sqlite.NewUserRepository(db)
+		orderRepo = sqlite.NewOrderRepository(db)
+		stockRepo = sqlite.NewStockRepository(db)
+		movementRepo = sqlite.NewStockMovementRepository(db)
+		transferRepo = sqlite.NewStockTransferRepository(db)
+		reservationRepo = sqlite.NewStockReservationRepository(db)
+		warehouseRepo = sqlite.NewWarehouseRepository(db)
+		couponRepo = sqlite.NewCouponRepository(db)
+		wishlistRepo = sqlite.NewWishlistRepository(db)
+		cartRepo = sqlite.NewCartRepository(db)
+		shippingRuleRepo = sqlite.NewShippingRuleRepository(db)
+		addressRepo = sqlite.NewAddressRepository(db)
+		reorderRepo = sqlite.NewReorderPointRepository(db)
+		priceHistoryRepo = sqlite.NewPriceHistoryRepository(db)
+		saleRepo = sqlite.NewSaleRepository(db)
+		unitOfWork = sqlite.NewUnitOfWork(db)
+		idempotency = sqlite.NewIdempotencyStore(db)
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", cfg.StorageDriver)
+	}
+
+	// Keep an in-memory search index in step with every product write
+	productIndex := search.NewProductIndex()
+	productRepo = search.NewIndexedProductRepository(productRepo, productIndex)
 
 	// Initialize services
 	authService := auth.NewJWTAuthService(userRepo)
 	paymentService := payment.NewSimulatedPaymentService()
-	stockService := service.NewStockService(stockRepo, warehouseRepo)
+	allocationStrategy, err := cfg.StockAllocationStrategy()
+	if err != nil {
+		return nil, fmt.Errorf("invalid allocation strategy: %w", err)
+	}
+	stockService := service.NewStockService(stockRepo, warehouseRepo, movementRepo, allocationStrategy)
 	couponService := service.NewCouponService(couponRepo)
-	orderService := service.NewOrderService(productRepo, orderRepo, stockService, couponService)
+	taxPolicy, err := cfg.TaxPolicy()
+	if err != nil {
+		return nil, fmt.Errorf("invalid tax configuration: %w", err)
+	}
+	shippingCalculator := service.NewRuleShippingCalculator(shippingRuleRepo)
+	pricingService := service.NewPricingService(productRepo, priceHistoryRepo, saleRepo)
+	orderService := service.NewOrderService(productRepo, orderRepo, stockService, pricingService, couponService, unitOfWork, taxPolicy, shippingCalculator, cfg.ReservationTTL)
+	// Hand stock to backordered orders as soon as it becomes available
+	stockService.OnStockChanged(func(productIDs []string) {
+		if _, err := orderService.FillBackorders(context.Background(), productIDs); err != nil {
+			log.Printf("Failed to fill backorders: %v", err)
+		}
+	})
+	// Fill the search index from storage and keep its stock figures current
+	searchService := service.NewProductSearchService(productIndex, productRepo, stockService, pricingService)
+	if err := searchService.Reindex(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to build search index: %w", err)
+	}
+	stockService.OnStockMoved(searchService.RecordMovements)
+	stockService.OnStockChanged(func(productIDs []string) {
+		if err := searchService.RefreshStock(context.Background(), productIDs); err != nil {
+			log.Printf("Failed to refresh search index: %v", err)
+		}
+	})
 	analyticsService := service.NewAnalyticsService(orderRepo, productRepo, stockRepo, warehouseRepo)
 	wishlistService := service.NewWishlistService(wishlistRepo, productRepo, userRepo)
+	addressService := service.NewAddressService(addressRepo)
+	cartService := service.NewCartService(cartRepo, productRepo, orderService, couponService, addressService)
+	warehouseService := service.NewWarehouseService(warehouseRepo, stockRepo, transferRepo)
+	transferService := service.NewStockTransferService(stockService, warehouseRepo, transferRepo)
+	reorderService := service.NewReorderService(reorderRepo, stockRepo, warehouseRepo)
+	productService := service.NewProductService(productRepo, wishlistRepo, warehouseRepo, stockService)
+	var stockAlertNotifier port.StockAlertNotifier
+	switch cfg.StockAlertNotifier {
+	case StockAlertNotifierLog, "":
+		stockAlertNotifier = notification.NewLogStockAlertNotifier()
+	case StockAlertNotifierFile:
+		stockAlertNotifier = notification.NewFileStockAlertNotifier(cfg.StockAlertFile)
+	default:
+		return nil, fmt.Errorf("unknown stock alert notifier: %s", cfg.StockAlertNotifier)
+	}
 
 	// Initialize use cases
-	productUseCase := interactor.NewProductUseCase(productRepo, userRepo, authService, stockService, wishlistService)
+	productUseCase := interactor.NewProductUseCase(productRepo, userRepo, authService, stockService, wishlistService, productService, pricingService, searchService)
 	userUseCase := interactor.NewUserUseCase(userRepo, authService)
-	orderUseCase := interactor.NewOrderUseCase(orderRepo, productRepo, orderService, authService, paymentService)
+	orderUseCase := interactor.NewOrderUseCase(orderRepo, productRepo, orderService, addressService, authService, paymentService)
 	analyticsUseCase := interactor.NewAnalyticsUseCase(analyticsService, authService)
 	wishlistUseCase := interactor.NewWishlistUseCase(wishlistService, authService)
+	cartUseCase := interactor.NewCartUseCase(cartService, orderUseCase, authService)
+	shippingUseCase := interactor.NewShippingUseCase(shippingRuleRepo, authService)
+	addressUseCase := interactor.NewAddressUseCase(addressService, authService)
+	warehouseUseCase := interactor.NewWarehouseUseCase(warehouseService, stockService, transferService, productRepo, authService)
+	transferUseCase := interactor.NewStockTransferUseCase(transferService, productRepo, authService)
+	alertUseCase := interactor.NewStockAlertUseCase(reorderService, productRepo, authService, stockAlertNotifier)
+	catalogUseCase := interactor.NewCatalogUseCase(productRepo, stockService, warehouseService, pricingService, authService)
 
 	// Initialize handlers
 	productHandler := handler.NewProductHandler(productUseCase)
@@ -88,28 +250,52 @@ func NewContainer() *Container {
 	orderHandler := handler.NewOrderHandler(orderUseCase)
 	adminHandler := handler.NewAdminHandler(analyticsUseCase)
 	wishlistHandler := handler.NewWishlistHandler(wishlistUseCase)
+	cartHandler := handler.NewCartHandler(cartUseCase)
+	shippingHandler := handler.NewShippingHandler(shippingUseCase)
+	addressHandler := handler.NewAddressHandler(addressUseCase)
+	warehouseHandler := handler.NewWarehouseHandler(warehouseUseCase)
+	stockTransferHandler := handler.NewStockTransferHandler(transferUseCase)
+	stockAlertHandler := handler.NewStockAlertHandler(alertUseCase)
+	catalogHandler := handler.NewCatalogHandler(catalogUseCase)
 
 	// Initialize middleware
 	authMiddleware := middleware.NewAuthMiddleware(authService, userRepo)
+	idempotencyMiddleware := middleware.NewIdempotencyMiddleware(idempotency, authService, cfg.IdempotencyKeyTTL)
 
 	return &Container{
 		// Repositories
-		ProductRepository:   productRepo,
-		UserRepository:      userRepo,
-		OrderRepository:     orderRepo,
-		StockRepository:     stockRepo,
-		WarehouseRepository: warehouseRepo,
-		CouponRepository:    couponRepo,
-		WishlistRepository:  wishlistRepo,
+		ProductRepository:      productRepo,
+		UserRepository:         userRepo,
+		OrderRepository:        orderRepo,
+		StockRepository:        stockRepo,
+		ReservationRepository:  reservationRepo,
+		WarehouseRepository:    warehouseRepo,
+		CouponRepository:       couponRepo,
+		WishlistRepository:     wishlistRepo,
+		CartRepository:         cartRepo,
+		ShippingRuleRepository: shippingRuleRepo,
+		AddressRepository:      addressRepo,
+		ReorderPointRepository: reorderRepo,
+		UnitOfWork:             unitOfWork,
+		IdempotencyStore:       idempotency,
 
 		// Services
-		AuthService:      authService,
-		PaymentService:   paymentService,
-		OrderService:     orderService,
-		StockService:     stockService,
-		CouponService:    couponService,
-		AnalyticsService: analyticsService,
-		WishlistService:  wishlistService,
+		AuthService:        authService,
+		PaymentService:     paymentService,
+		OrderService:       orderService,
+		StockService:       stockService,
+		CouponService:      couponService,
+		AnalyticsService:   analyticsService,
+		WishlistService:    wishlistService,
+		CartService:        cartService,
+		ShippingCalculator: shippingCalculator,
+		AddressService:     addressService,
+		WarehouseService:   warehouseService,
+		TransferService:    transferService,
+		ReorderService:     reorderService,
+		PricingService:     pricingService,
+		SearchService:      searchService,
+		StockAlertNotifier: stockAlertNotifier,
 
 		// Use Cases
 		ProductUseCase:   productUseCase,
@@ -117,30 +303,135 @@ func NewContainer() *Container {
 		OrderUseCase:     orderUseCase,
 		AnalyticsUseCase: analyticsUseCase,
 		WishlistUseCase:  wishlistUseCase,
+		CartUseCase:      cartUseCase,
+		ShippingUseCase:  shippingUseCase,
+		AddressUseCase:   addressUseCase,
+		WarehouseUseCase: warehouseUseCase,
+		TransferUseCase:  transferUseCase,
+		AlertUseCase:     alertUseCase,
+		CatalogUseCase:   catalogUseCase,
 
 		// Handlers
-		ProductHandler: productHandler,
-		UserHandler:    userHandler,
-		OrderHandler:   orderHandler,
-		AdminHandler:   adminHandler,
-		WishlistHandler: wishlistHandler,
+		ProductHandler:       productHandler,
+		UserHandler:          userHandler,
+		OrderHandler:         orderHandler,
+		AdminHandler:         adminHandler,
+		WishlistHandler:      wishlistHandler,
+		CartHandler:          cartHandler,
+		ShippingHandler:      shippingHandler,
+		AddressHandler:       addressHandler,
+		WarehouseHandler:     warehouseHandler,
+		StockTransferHandler: stockTransferHandler,
+		StockAlertHandler:    stockAlertHandler,
+		CatalogHandler:       catalogHandler,
 
 		// Middleware
-		AuthMiddleware: authMiddleware,
+		AuthMiddleware:        authMiddleware,
+		IdempotencyMiddleware: idempotencyMiddleware,
+
+		db: db,
+
+		reservationSweepInterval:    cfg.ReservationSweepInterval,
+		idempotencyKeySweepInterval: cfg.IdempotencyKeySweepInterval,
+		stockAlertSweepInterval:     cfg.StockAlertSweepInterval,
+	}, nil
+}
+
+// StartReservationSweeper releases expired stock reservations in the background until ctx is cancelled
+func (c *Container) StartReservationSweeper(ctx context.Context) {
+	runEvery(ctx, c.reservationSweepInterval, func(now time.Time) {
+		released, err := c.OrderService.ReleaseExpiredReservations(ctx, now)
+		if err != nil {
+			log.Printf("Failed to release expired reservations: %v", err)
+		} else if released > 0 {
+			log.Printf("Released %d expired stock reservations", released)
+		}
+	})
+}
+
+// StartIdempotencyKeySweeper deletes expired idempotency keys in the background until ctx is cancelled
+func (c *Container) StartIdempotencyKeySweeper(ctx context.Context) {
+	runEvery(ctx, c.idempotencyKeySweepInterval, func(now time.Time) {
+		deleted, err := c.IdempotencyStore.DeleteExpired(ctx, now)
+		if err != nil {
+			log.Printf("Failed to delete expired idempotency keys: %v", err)
+		} else if deleted > 0 {
+			log.Printf("Deleted %d expired idempotency keys", deleted)
+		}
+	})
+}
+
+// StartStockAlertChecker checks stock against its reorder points in the background until ctx is
+// cancelled: the products of every stock change are checked as it commits, and all stock is
+// checked at every sweep interval to catch changes made elsewhere
+func (c *Container) StartStockAlertChecker(ctx context.Context) {
+	changed := make(chan []string, 64)
+	c.StockService.OnStockChanged(func(productIDs []string) {
+		select {
+		case changed <- productIDs:
+		default:
+			// The checker is behind; the next sweep picks the change up
+		}
+	})
+	check := func(productIDs []string) {
+		if err := c.AlertUseCase.CheckStock(ctx, productIDs); err != nil {
+			log.Printf("Failed to check stock alerts: %v", err)
+		}
 	}
+
+	go func() {
+		check(nil)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case productIDs := <-changed:
+				check(productIDs)
+			}
+		}
+	}()
+	runEvery(ctx, c.stockAlertSweepInterval, func(now time.Time) {
+		check(nil)
+	})
+}
+
+// runEvery calls fn on a background goroutine at every tick of interval until ctx is cancelled
+func runEvery(ctx context.Context, interval time.Duration, fn func(now time.Time)) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				fn(now)
+			}
+		}
+	}()
+}
+
+// Close releases resources held by the container, such as the database handle
+func (c *Container) Close() error {
+	if c.db != nil {
+		return c.db.Close()
+	}
+	return nil
 }
 
 // SeedTestData seeds the container with test data
 func (c *Container) SeedTestData() error {
+	ctx := context.Background()
+
 	// Try to create admin user or get existing one
-	adminUser, err := c.UserUseCase.Register(nil, interactor.RegisterInput{
+	adminUser, err := c.UserUseCase.Register(ctx, interactor.RegisterInput{
 		Username: "admin",
 		Password: "admin123",
 		IsAdmin:  true,
 	})
 	if err != nil {
 		// If user already exists, try to login to get the user
-		loginOutput, loginErr := c.UserUseCase.Login(nil, interactor.LoginInput{
+		loginOutput, loginErr := c.UserUseCase.Login(ctx, interactor.LoginInput{
 			Username: "admin",
 			Password: "admin123",
 		})
@@ -151,13 +442,44 @@ func (c *Container) SeedTestData() error {
 	}
 
 	// Try to create regular user (ignore if exists)
-	_, err = c.UserUseCase.Register(nil, interactor.RegisterInput{
+	regularUser, err := c.UserUseCase.Register(ctx, interactor.RegisterInput{
 		Username: "user",
 		Password: "user123",
 		IsAdmin:  false,
 	})
 	// Ignore error if user already exists
 
+	// Give the sample users a default address so they can place orders right away
+	if err == nil {
+		err = c.seedAddress(ctx, regularUser.ID, entity.AddressFields{
+			Recipient:  "テスト ユーザー",
+			PostalCode: "530-0001",
+			Prefecture: "大阪府",
+			City:       "大阪市北区",
+			Line1:      "梅田1-1-1",
+			Phone:      "06-1234-5678",
+		})
+		if err != nil {
+			return err
+		}
+	}
+	err = c.seedAddress(ctx, adminUser.ID, entity.AddressFields{
+		Recipient:  "管理者",
+		PostalCode: "105-0011",
+		Prefecture: "東京都",
+		City:       "港区",
+		Line1:      "芝公園4-2-8",
+		Phone:      "03-1234-5678",
+	})
+	if err != nil {
+		return err
+	}
+
+	// Persistent storage keeps the catalog across restarts, so only seed it once
+	if _, err := c.WarehouseRepository.FindByID(ctx, "WH-001"); err == nil {
+		return nil
+	}
+
 	// Create warehouses
 	warehouse1, err := entity.NewWarehouse("WH-001", "東京倉庫", "東京都港区")
 	if err != nil {
@@ -173,66 +495,62 @@ func (c *Container) SeedTestData() error {
 	}
 
 	// Save warehouses
-	err = c.WarehouseRepository.Create(nil, warehouse1)
+	err = c.WarehouseRepository.Create(ctx, warehouse1)
 	if err != nil {
 		return err
 	}
-	err = c.WarehouseRepository.Create(nil, warehouse2)
+	err = c.WarehouseRepository.Create(ctx, warehouse2)
 	if err != nil {
 		return err
 	}
-	err = c.WarehouseRepository.Create(nil, warehouse3)
+	err = c.WarehouseRepository.Create(ctx, warehouse3)
 	if err != nil {
 		return err
 	}
 
 	// Create some products (using admin context)
-	ctx := auth.SetUserInContext(context.Background(), adminUser)
+	adminCtx := auth.SetUserInContext(ctx, adminUser)
 
 	products := []struct {
-		input interactor.CreateProductInput
+		input  interactor.CreateProductInput
 		stocks map[string]int // warehouseID -> quantity
 	}{
 		{
-			input: interactor.CreateProductInput{Name: "Laptop", Price: 1200, Category: "Electronics"},
+			input:  interactor.CreateProductInput{Name: "Laptop", Price: 1200, Category: "Electronics"},
 			stocks: map[string]int{"WH-001": 5, "WH-002": 3, "WH-003": 2},
 		},
 		{
-			input: interactor.CreateProductInput{Name: "Mouse", Price: 25, Category: "Electronics"},
+			input:  interactor.CreateProductInput{Name: "Mouse", Price: 25, Category: "Electronics"},
 			stocks: map[string]int{"WH-001": 20, "WH-002": 15, "WH-003": 15},
 		},
 		{
-			input: interactor.CreateProductInput{Name: "Keyboard", Price: 75, Category: "Electronics"},
+			input:  interactor.CreateProductInput{Name: "Keyboard", Price: 75, Category: "Electronics"},
 			stocks: map[string]int{"WH-001": 10, "WH-002": 10, "WH-003": 10},
 		},
 		{
-			input: interactor.CreateProductInput{Name: "Desk", Price: 300, Category: "Furniture"},
+			input:  interactor.CreateProductInput{Name: "Desk", Price: 300, Category: "Furniture"},
 			stocks: map[string]int{"WH-001": 2, "WH-002": 2, "WH-003": 1},
 		},
 		{
-			input: interactor.CreateProductInput{Name: "Chair", Price: 150, Category: "Furniture"},
+			input:  interactor.CreateProductInput{Name: "Chair", Price: 150, Category: "Furniture"},
 			stocks: map[string]int{"WH-001": 5, "WH-002": 5, "WH-003": 5},
 		},
 		{
-			input: interactor.CreateProductInput{Name: "Coffee", Price: 10, Category: "Food"},
+			input:  interactor.CreateProductInput{Name: "Coffee", Price: 10, Category: "Food"},
 			stocks: map[string]int{"WH-001": 40, "WH-002": 30, "WH-003": 30},
 		},
 	}
 
 	for _, p := range products {
-		product, err := c.ProductUseCase.CreateProduct(ctx, p.input)
+		product, err := c.ProductUseCase.CreateProduct(adminCtx, p.input)
 		if err != nil {
 			return err
 		}
 
-		// Create stock entries for each warehouse
+		// Receive the initial stock in each warehouse so the ledger accounts for it
 		for warehouseID, quantity := range p.stocks {
-			stockID := fmt.Sprintf("STK-%s-%s", product.ID, warehouseID)
-			stock, err := entity.NewStock(stockID, product.ID, warehouseID, quantity)
-			if err != nil {
-				return err
-			}
-			err = c.StockRepository.Create(nil, stock)
+			ref := service.MovementRef{Type: entity.StockMovementRestock, Note: "initial stock"}
+			_, err := c.StockService.AdjustStockLevel(ctx, product.ID, warehouseID, quantity, ref)
 			if err != nil {
 				return err
 			}
@@ -306,7 +624,7 @@ func (c *Container) SeedTestData() error {
 		coupon.ValidUntil = time.Now().AddDate(1, 0, 0)
 		coupon.IsActive = true
 
-		err = c.CouponRepository.Create(nil, coupon)
+		err = c.CouponRepository.Create(ctx, coupon)
 		if err != nil {
 			// Ignore error if coupon already exists
 			fmt.Printf("Coupon %s might already exist, skipping: %v\n", cp.code, err)
@@ -314,4 +632,14 @@ func (c *Container) SeedTestData() error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// seedAddress adds an address for a user whose address book is still empty
+func (c *Container) seedAddress(ctx context.Context, userID string, fields entity.AddressFields) error {
+	addresses, err := c.AddressService.ListAddresses(ctx, userID)
+	if err != nil || len(addresses) > 0 {
+		return err
+	}
+	_, err = c.AddressService.AddAddress(ctx, userID, fields)
+	return err
+}
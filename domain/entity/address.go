@@ -0,0 +1,142 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	postalCodePattern = regexp.MustCompile(`^(\d{3})-?(\d{4})$`)
+	phonePattern      = regexp.MustCompile(`^0\d{9,10}$`)
+)
+
+// Address is an entry in a user's address book
+type Address struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	Recipient  string    `json:"recipient"`
+	PostalCode string    `json:"postal_code"` // Always stored as 123-4567
+	Prefecture string    `json:"prefecture"`
+	City       string    `json:"city"`
+	Line1      string    `json:"line1"`           // Town and block number
+	Line2      string    `json:"line2,omitempty"` // Building and room
+	Phone      string    `json:"phone"`           // Digits only
+	IsDefault  bool      `json:"is_default"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// AddressFields are the parts of an address a user fills in
+type AddressFields struct {
+	Recipient  string
+	PostalCode string
+	Prefecture string
+	City       string
+	Line1      string
+	Line2      string
+	Phone      string
+}
+
+// NewAddress creates a new address entity
+func NewAddress(id, userID string, fields AddressFields) (*Address, error) {
+	if userID == "" {
+		return nil, errors.New("user ID cannot be empty")
+	}
+
+	now := time.Now()
+	address := &Address{
+		ID:        id,
+		UserID:    userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := address.Update(fields); err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// Update validates fields and replaces the address with them
+func (a *Address) Update(fields AddressFields) error {
+	recipient := strings.TrimSpace(fields.Recipient)
+	if recipient == "" {
+		return errors.New("recipient cannot be empty")
+	}
+	postalCode, err := NormalizePostalCode(fields.PostalCode)
+	if err != nil {
+		return err
+	}
+	if !IsPrefecture(fields.Prefecture) {
+		return fmt.Errorf("unknown prefecture: %s", fields.Prefecture)
+	}
+	city := strings.TrimSpace(fields.City)
+	if city == "" {
+		return errors.New("city cannot be empty")
+	}
+	line1 := strings.TrimSpace(fields.Line1)
+	if line1 == "" {
+		return errors.New("address line 1 cannot be empty")
+	}
+	phone, err := normalizePhone(fields.Phone)
+	if err != nil {
+		return err
+	}
+
+	a.Recipient = recipient
+	a.PostalCode = postalCode
+	a.Prefecture = fields.Prefecture
+	a.City = city
+	a.Line1 = line1
+	a.Line2 = strings.TrimSpace(fields.Line2)
+	a.Phone = phone
+	a.UpdatedAt = time.Now()
+	return nil
+}
+
+// Snapshot returns a copy of the address to keep on an order
+func (a *Address) Snapshot() ShippingAddress {
+	return ShippingAddress{
+		AddressID:  a.ID,
+		Recipient:  a.Recipient,
+		PostalCode: a.PostalCode,
+		Prefecture: a.Prefecture,
+		City:       a.City,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		Phone:      a.Phone,
+	}
+}
+
+// ShippingAddress is the address an order ships to, copied from the address book at checkout.
+// Later edits to the address book do not change it.
+type ShippingAddress struct {
+	AddressID  string `json:"address_id"` // Address book entry it was copied from
+	Recipient  string `json:"recipient"`
+	PostalCode string `json:"postal_code"`
+	Prefecture string `json:"prefecture"`
+	City       string `json:"city"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	Phone      string `json:"phone"`
+}
+
+// NormalizePostalCode checks a Japanese postal code and formats it as 123-4567
+func NormalizePostalCode(postalCode string) (string, error) {
+	match := postalCodePattern.FindStringSubmatch(strings.TrimSpace(postalCode))
+	if match == nil {
+		return "", fmt.Errorf("invalid postal code: %s", postalCode)
+	}
+	return match[1] + "-" + match[2], nil
+}
+
+// normalizePhone checks a Japanese phone number and strips its hyphens
+func normalizePhone(phone string) (string, error) {
+	digits := strings.ReplaceAll(strings.TrimSpace(phone), "-", "")
+	if !phonePattern.MatchString(digits) {
+		return "", fmt.Errorf("invalid phone number: %s", phone)
+	}
+	return digits, nil
+}
@@ -0,0 +1,86 @@
+package entity
+
+import "testing"
+
+func TestNewAddress_Validation(t *testing.T) {
+	valid := AddressFields{
+		Recipient:  "山田 太郎",
+		PostalCode: "1000001",
+		Prefecture: "東京都",
+		City:       "千代田区",
+		Line1:      "千代田1-1",
+		Phone:      "03-1234-5678",
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(f *AddressFields)
+		wantErr bool
+	}{
+		{"valid address", func(f *AddressFields) {}, false},
+		{"hyphenated postal code", func(f *AddressFields) { f.PostalCode = "100-0001" }, false},
+		{"short postal code", func(f *AddressFields) { f.PostalCode = "100-001" }, true},
+		{"postal code with letters", func(f *AddressFields) { f.PostalCode = "ABC-0001" }, true},
+		{"unknown prefecture", func(f *AddressFields) { f.Prefecture = "東京" }, true},
+		{"missing recipient", func(f *AddressFields) { f.Recipient = " " }, true},
+		{"missing city", func(f *AddressFields) { f.City = "" }, true},
+		{"missing line 1", func(f *AddressFields) { f.Line1 = "" }, true},
+		{"mobile phone", func(f *AddressFields) { f.Phone = "090-1234-5678" }, false},
+		{"phone too short", func(f *AddressFields) { f.Phone = "03-123-456" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := valid
+			tt.modify(&fields)
+			_, err := NewAddress("ADDR-1", "USER-001", fields)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	address, _ := NewAddress("ADDR-1", "USER-001", valid)
+	if address.PostalCode != "100-0001" || address.Phone != "0312345678" {
+		t.Errorf("Expected normalized postal code and phone, got %s and %s", address.PostalCode, address.Phone)
+	}
+	if len(Prefectures) != 47 {
+		t.Errorf("Expected 47 prefectures, got %d", len(Prefectures))
+	}
+}
+
+func TestOrder_ShippingAddressIsFixed(t *testing.T) {
+	order, _ := NewOrder("ORD-1", "USER-001")
+	if err := order.SetShippingAddress(ShippingAddress{AddressID: "ADDR-1", Prefecture: "東京都"}); err != nil {
+		t.Fatalf("SetShippingAddress failed: %v", err)
+	}
+	if err := order.SetShippingAddress(ShippingAddress{AddressID: "ADDR-2", Prefecture: "大阪府"}); err == nil {
+		t.Error("Expected changing the shipping address to fail")
+	}
+	if order.ShippingAddress.AddressID != "ADDR-1" {
+		t.Errorf("Expected address ADDR-1 to be kept, got %s", order.ShippingAddress.AddressID)
+	}
+}
+
+func TestPrefectureDistance(t *testing.T) {
+	if got := PrefectureOf("福岡県福岡市"); got != "福岡県" {
+		t.Errorf("Expected 福岡県, got %q", got)
+	}
+	if got := PrefectureOf("Tokyo"); got != "" {
+		t.Errorf("Expected no prefecture for an unknown location, got %q", got)
+	}
+
+	tokyoOsaka, _ := PrefectureDistance("東京都", "大阪府")
+	tokyoFukuoka, _ := PrefectureDistance("東京都", "福岡県")
+	if tokyoOsaka < 350 || tokyoOsaka > 450 || tokyoFukuoka <= tokyoOsaka {
+		t.Errorf("Expected Osaka about 400km from Tokyo and Fukuoka further, got %.0f and %.0f", tokyoOsaka, tokyoFukuoka)
+	}
+	if _, ok := PrefectureDistance("東京都", ""); ok {
+		t.Error("Expected no distance to an unknown prefecture")
+	}
+	for _, prefecture := range Prefectures {
+		if _, ok := PrefectureDistance(prefecture, prefecture); !ok {
+			t.Errorf("Expected a location for %s", prefecture)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrCartItemNotFound is returned when a product is not in the cart
+var ErrCartItemNotFound = errors.New("product not in cart")
+
+// Cart is a user's shopping cart. Each user has at most one cart, and the cart holds
+// quantities only: prices and stock are looked up again whenever the cart is priced.
+type Cart struct {
+	UserID     string     `json:"user_id"`
+	Items      []CartItem `json:"items"`
+	CouponCode string     `json:"coupon_code,omitempty"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// CartItem is one product line in a cart
+type CartItem struct {
+	ProductID string    `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+	AddedAt   time.Time `json:"added_at"`
+}
+
+// NewCart creates an empty cart for a user
+func NewCart(userID string) (*Cart, error) {
+	if userID == "" {
+		return nil, errors.New("user ID cannot be empty")
+	}
+
+	return &Cart{
+		UserID:    userID,
+		Items:     []CartItem{},
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+// AddItem adds quantity units of a product, merging with an existing line for the same product
+func (c *Cart) AddItem(productID string, quantity int) error {
+	if quantity <= 0 {
+		return errors.New("quantity must be positive")
+	}
+
+	now := time.Now()
+	if i := c.indexOf(productID); i >= 0 {
+		c.Items[i].Quantity += quantity
+	} else {
+		c.Items = append(c.Items, CartItem{ProductID: productID, Quantity: quantity, AddedAt: now})
+	}
+	c.UpdatedAt = now
+	return nil
+}
+
+// SetQuantity changes the quantity of a line already in the cart
+func (c *Cart) SetQuantity(productID string, quantity int) error {
+	if quantity <= 0 {
+		return errors.New("quantity must be positive")
+	}
+
+	i := c.indexOf(productID)
+	if i < 0 {
+		return ErrCartItemNotFound
+	}
+	c.Items[i].Quantity = quantity
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
+// RemoveItem removes a product's line from the cart
+func (c *Cart) RemoveItem(productID string) error {
+	i := c.indexOf(productID)
+	if i < 0 {
+		return ErrCartItemNotFound
+	}
+	c.Items = append(c.Items[:i], c.Items[i+1:]...)
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetCoupon sets the coupon to apply at checkout; an empty code removes it
+func (c *Cart) SetCoupon(code string) {
+	c.CouponCode = code
+	c.UpdatedAt = time.Now()
+}
+
+// IsEmpty checks if the cart has no lines
+func (c *Cart) IsEmpty() bool {
+	return len(c.Items) == 0
+}
+
+func (c *Cart) indexOf(productID string) int {
+	for i, item := range c.Items {
+		if item.ProductID == productID {
+			return i
+		}
+	}
+	return -1
+}
@@ -2,6 +2,7 @@ package entity
 
 import (
 	"errors"
+	"fmt"
 	"time"
 )
 
@@ -10,34 +11,156 @@ type OrderStatus string
 
 const (
 	OrderStatusPending       OrderStatus = "pending"
-	OrderStatusConfirmed     OrderStatus = "confirmed"
+	OrderStatusPaid          OrderStatus = "paid"
+	OrderStatusPicking       OrderStatus = "picking"
+	OrderStatusShipped       OrderStatus = "shipped"
+	OrderStatusDelivered     OrderStatus = "delivered"
 	OrderStatusPaymentFailed OrderStatus = "payment_failed"
-	OrderStatusCompleted     OrderStatus = "completed"
 	OrderStatusCancelled     OrderStatus = "cancelled"
-	OrderStatusDelivered     OrderStatus = "delivered"
+	OrderStatusReturned      OrderStatus = "returned"
+	OrderStatusRefunded      OrderStatus = "refunded"
+)
+
+// OrderStatuses lists every order status in lifecycle order
+var OrderStatuses = []OrderStatus{
+	OrderStatusPending,
+	OrderStatusPaid,
+	OrderStatusPicking,
+	OrderStatusShipped,
+	OrderStatusDelivered,
+	OrderStatusPaymentFailed,
+	OrderStatusCancelled,
+	OrderStatusReturned,
+	OrderStatusRefunded,
+}
+
+// orderTransitions is the order lifecycle: the statuses each status may move to.
+// Statuses without an entry are final.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending:       {OrderStatusPaid, OrderStatusPaymentFailed, OrderStatusCancelled},
+	OrderStatusPaymentFailed: {OrderStatusCancelled},
+	OrderStatusPaid:          {OrderStatusPicking, OrderStatusCancelled},
+	OrderStatusPicking:       {OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusShipped:       {OrderStatusDelivered, OrderStatusReturned},
+	OrderStatusDelivered:     {OrderStatusReturned},
+	OrderStatusCancelled:     {OrderStatusRefunded},
+	OrderStatusReturned:      {OrderStatusRefunded},
+}
+
+var (
+	// ErrOrderNotCancellable is returned when an order's status does not allow cancellation by the caller
+	ErrOrderNotCancellable = errors.New("order cannot be cancelled in its current status")
+	// ErrInvalidTransition is returned when an order cannot move from its current status to the requested one
+	ErrInvalidTransition = errors.New("invalid order status transition")
+)
+
+// ParseOrderStatus converts a string into a known order status
+func ParseOrderStatus(s string) (OrderStatus, error) {
+	for _, status := range OrderStatuses {
+		if string(status) == s {
+			return status, nil
+		}
+	}
+	return "", fmt.Errorf("unknown order status: %s", s)
+}
+
+// CanTransition reports whether the lifecycle allows moving from one status to another
+func CanTransition(from, to OrderStatus) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusChange records one transition in an order's status history
+type StatusChange struct {
+	From      OrderStatus `json:"from,omitempty"` // Empty for the order's creation
+	To        OrderStatus `json:"to"`
+	Note      string      `json:"note,omitempty"`
+	ChangedAt time.Time   `json:"changed_at"`
+}
+
+// OrderItemStatus is whether the units of an order line are on hand
+type OrderItemStatus string
+
+const (
+	OrderItemInStock     OrderItemStatus = "in_stock"    // Every unit is reserved or allocated
+	OrderItemBackordered OrderItemStatus = "backordered" // Some units wait for incoming stock
 )
 
 // OrderItem represents a single item in an order
 type OrderItem struct {
+	ProductID   string           `json:"product_id"`
+	SKU         string           `json:"sku,omitempty"` // SKU of the product or variant at the time of ordering
+	ProductName string           `json:"product_name"`
+	Quantity    int              `json:"quantity"`
+	Price       int              `json:"price"`
+	Subtotal    int              `json:"subtotal"`
+	TaxClass    TaxClass         `json:"tax_class"`
+	TaxRate     int              `json:"tax_rate"`              // Percent, fixed when the line is added
+	Allocations []ItemAllocation `json:"allocations,omitempty"` // Where the stock was taken from, set on confirmation
+	Status      OrderItemStatus  `json:"status"`
+	Backordered int              `json:"backordered,omitempty"`           // Units waiting for incoming stock
+	ExpectedAt  *time.Time       `json:"expected_available_at,omitempty"` // When the backordered units are expected
+}
+
+// setBackordered sets the units of the line waiting for stock and the status that follows
+func (i *OrderItem) setBackordered(units int) {
+	i.Backordered = units
+	if units > 0 {
+		i.Status = OrderItemBackordered
+		return
+	}
+	i.Status = OrderItemInStock
+	i.ExpectedAt = nil
+}
+
+// ItemAllocation records how many units of an order line were taken from one warehouse
+type ItemAllocation struct {
+	WarehouseID   string `json:"warehouse_id"`
+	WarehouseName string `json:"warehouse_name"` // Name at the time of allocation
+	Quantity      int    `json:"quantity"`
+}
+
+// Shipment groups the order lines that leave from one warehouse
+type Shipment struct {
+	WarehouseID   string         `json:"warehouse_id"`
+	WarehouseName string         `json:"warehouse_name"`
+	Items         []ShipmentItem `json:"items"`
+}
+
+// ShipmentItem is the part of an order line shipped from one warehouse
+type ShipmentItem struct {
 	ProductID   string `json:"product_id"`
 	ProductName string `json:"product_name"`
 	Quantity    int    `json:"quantity"`
-	Price       int    `json:"price"`
-	Subtotal    int    `json:"subtotal"`
 }
 
 // Order represents an order in the system
 type Order struct {
-	ID            string        `json:"id"`
-	UserID        string        `json:"user_id"`
-	Items         []OrderItem   `json:"items"`
-	TotalPrice    int           `json:"total_price"`
-	ShippingFee   int           `json:"shipping_fee"`
-	DiscountAmount int          `json:"discount_amount,omitempty"` // Amount discounted by coupon
-	AppliedCoupon string        `json:"applied_coupon,omitempty"`   // Code of applied coupon
-	Status        OrderStatus   `json:"status"`
-	CreatedAt     time.Time     `json:"created_at"`
-	UpdatedAt     time.Time     `json:"updated_at"`
+	ID          string      `json:"id"`
+	UserID      string      `json:"user_id"`
+	Items       []OrderItem `json:"items"`
+	TotalPrice  int         `json:"total_price"`
+	ShippingFee int         `json:"shipping_fee"`
+	// ShippingCharges is the fee of each parcel once the order is priced by shipping rules.
+	// While it is nil the standard fee applies.
+	ShippingCharges []ShippingCharge `json:"shipping_charges,omitempty"`
+	TaxAmount       int              `json:"tax_amount"`
+	TaxLines        []TaxLine        `json:"tax_lines"`                 // Tax totals per rate
+	DiscountAmount  int              `json:"discount_amount,omitempty"` // Amount discounted by coupon
+	AppliedCoupon   string           `json:"applied_coupon,omitempty"`  // Code of applied coupon
+	Status          OrderStatus      `json:"status"`
+	TrackingNumber  string           `json:"tracking_number,omitempty"` // Set when the order is shipped
+	// ShippingAddress is where the order ships to, fixed when the order is placed
+	ShippingAddress *ShippingAddress `json:"shipping_address,omitempty"`
+	StatusHistory   []StatusChange   `json:"status_history"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+
+	taxPolicy TaxPolicy // Used to tax lines added from now on; DefaultTaxPolicy when nil
 }
 
 // NewOrder creates a new order entity
@@ -51,16 +174,25 @@ func NewOrder(id, userID string) (*Order, error) {
 		ID:          id,
 		UserID:      userID,
 		Items:       []OrderItem{},
+		TaxLines:    []TaxLine{},
 		TotalPrice:  0,
 		ShippingFee: 0,
 		Status:      OrderStatusPending,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		StatusHistory: []StatusChange{
+			{To: OrderStatusPending, ChangedAt: now},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
 	}, nil
 }
 
-// AddItem adds an item to the order
+// AddItem adds a standard rated item to the order
 func (o *Order) AddItem(productID, productName string, quantity, price int) error {
+	return o.AddItemWithTaxClass(productID, productName, quantity, price, TaxClassStandard)
+}
+
+// AddItemWithTaxClass adds an item taxed at the rate the order's tax policy gives its class
+func (o *Order) AddItemWithTaxClass(productID, productName string, quantity, price int, taxClass TaxClass) error {
 	if quantity <= 0 {
 		return errors.New("quantity must be positive")
 	}
@@ -74,6 +206,9 @@ func (o *Order) AddItem(productID, productName string, quantity, price int) erro
 		Quantity:    quantity,
 		Price:       price,
 		Subtotal:    price * quantity,
+		TaxClass:    taxClass,
+		TaxRate:     o.policy().RateFor(taxClass),
+		Status:      OrderItemInStock,
 	}
 
 	o.Items = append(o.Items, item)
@@ -82,71 +217,256 @@ func (o *Order) AddItem(productID, productName string, quantity, price int) erro
 	return nil
 }
 
+// SetTaxPolicy sets the tax policy of the order and re-taxes its lines with it
+func (o *Order) SetTaxPolicy(policy TaxPolicy) {
+	o.taxPolicy = policy
+	for i := range o.Items {
+		o.Items[i].TaxRate = policy.RateFor(o.Items[i].TaxClass)
+	}
+	o.calculateTotal()
+}
+
+func (o *Order) policy() TaxPolicy {
+	if o.taxPolicy == nil {
+		return DefaultTaxPolicy()
+	}
+	return o.taxPolicy
+}
+
 // calculateTotal recalculates the total price of the order with tax and shipping
 func (o *Order) calculateTotal() {
 	// Calculate subtotal (before tax)
-	subtotal := 0
-	for _, item := range o.Items {
-		subtotal += item.Subtotal
-	}
+	subtotal := o.GetSubtotal()
 
-	// Apply 10% consumption tax
-	taxAmount := subtotal / 10 // 10% tax
-	subtotalWithTax := subtotal + taxAmount
+	// Apply consumption tax per rate
+	o.TaxLines = o.policy().Calculate(o.Items)
+	o.TaxAmount = 0
+	for _, line := range o.TaxLines {
+		o.TaxAmount += line.Tax
+	}
+	subtotalWithTax := subtotal + o.TaxAmount
 
 	// Calculate shipping fee
-	// Free shipping for orders >= 5000 yen (before tax)
-	if subtotal >= 5000 {
-		o.ShippingFee = 0
-	} else {
-		o.ShippingFee = 500
-	}
+	o.ShippingFee = o.shippingFee()
 
 	// Calculate final total (tax included + shipping)
 	o.TotalPrice = subtotalWithTax + o.ShippingFee
 }
 
-// Confirm confirms the order
-func (o *Order) Confirm() error {
-	if o.Status != OrderStatusPending {
-		return errors.New("only pending orders can be confirmed")
+// TransitionTo moves the order to a new status and records the change in its history.
+// The move must be allowed by the lifecycle; in addition, shipping requires a tracking
+// number and only orders that were paid can be refunded.
+func (o *Order) TransitionTo(to OrderStatus, note string) error {
+	if !CanTransition(o.Status, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, o.Status, to)
+	}
+	if to == OrderStatusShipped && o.TrackingNumber == "" {
+		return fmt.Errorf("%w: a tracking number is required to ship", ErrInvalidTransition)
+	}
+	if to == OrderStatusRefunded && !o.HasBeenPaid() {
+		return fmt.Errorf("%w: order was never paid", ErrInvalidTransition)
 	}
+	if to == OrderStatusPicking && o.HasBackorders() {
+		return fmt.Errorf("%w: order has backordered lines", ErrInvalidTransition)
+	}
+
+	now := time.Now()
+	o.StatusHistory = append(o.StatusHistory, StatusChange{
+		From:      o.Status,
+		To:        to,
+		Note:      note,
+		ChangedAt: now,
+	})
+	o.Status = to
+	o.UpdatedAt = now
+	return nil
+}
+
+// MarkPaid marks a pending order as paid
+func (o *Order) MarkPaid() error {
 	if len(o.Items) == 0 {
 		return errors.New("cannot confirm an empty order")
 	}
-	o.Status = OrderStatusConfirmed
-	o.UpdatedAt = time.Now()
-	return nil
+	return o.TransitionTo(OrderStatusPaid, "")
 }
 
-// Cancel cancels the order
-func (o *Order) Cancel() error {
-	if o.Status == OrderStatusDelivered {
-		return errors.New("cannot cancel delivered orders")
+// Ship marks the order as shipped under the given tracking number
+func (o *Order) Ship(trackingNumber, note string) error {
+	if trackingNumber == "" {
+		return errors.New("tracking number is required")
 	}
-	o.Status = OrderStatusCancelled
-	o.UpdatedAt = time.Now()
-	return nil
+	if !CanTransition(o.Status, OrderStatusShipped) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, o.Status, OrderStatusShipped)
+	}
+	o.TrackingNumber = trackingNumber
+	return o.TransitionTo(OrderStatusShipped, note)
+}
+
+// Shipments groups the allocated order lines by the warehouse they ship from,
+// in the order the warehouses first appear. Orders without allocations have none.
+func (o *Order) Shipments() []Shipment {
+	var shipments []Shipment
+	index := make(map[string]int)
+	for _, item := range o.Items {
+		for _, allocation := range item.Allocations {
+			i, exists := index[allocation.WarehouseID]
+			if !exists {
+				i = len(shipments)
+				index[allocation.WarehouseID] = i
+				shipments = append(shipments, Shipment{
+					WarehouseID:   allocation.WarehouseID,
+					WarehouseName: allocation.WarehouseName,
+				})
+			}
+			shipments[i].Items = append(shipments[i].Items, ShipmentItem{
+				ProductID:   item.ProductID,
+				ProductName: item.ProductName,
+				Quantity:    allocation.Quantity,
+			})
+		}
+	}
+	return shipments
 }
 
-// Complete marks the order as completed after successful payment
-func (o *Order) Complete() error {
-	if o.Status != OrderStatusConfirmed {
-		return errors.New("only confirmed orders can be completed")
+// BackorderProduct sets how many units of a product on the order wait for incoming stock,
+// replacing any earlier backorder of it. The units are taken from the product's last lines,
+// leaving its first lines to the stock on hand.
+func (o *Order) BackorderProduct(productID string, units int, expectedAt *time.Time) error {
+	if units < 0 {
+		return errors.New("backordered quantity cannot be negative")
+	}
+	if units > o.orderedQuantity(productID) {
+		return fmt.Errorf("cannot backorder more units of %s than were ordered", productID)
+	}
+	for i := len(o.Items) - 1; i >= 0; i-- {
+		item := &o.Items[i]
+		if item.ProductID != productID {
+			continue
+		}
+		backordered := min(units, item.Quantity)
+		units -= backordered
+		item.setBackordered(backordered)
+		if backordered > 0 {
+			item.ExpectedAt = expectedAt
+		}
 	}
-	o.Status = OrderStatusCompleted
 	o.UpdatedAt = time.Now()
 	return nil
 }
 
+// FillBackorder allocates units of a product that arrived to the order's backordered lines,
+// first line first, and returns how many of the units were used
+func (o *Order) FillBackorder(productID string, allocations []ItemAllocation) int {
+	used := 0
+	for i := range o.Items {
+		item := &o.Items[i]
+		if item.ProductID != productID {
+			continue
+		}
+		for item.Backordered > 0 && len(allocations) > 0 {
+			take := min(item.Backordered, allocations[0].Quantity)
+			item.addAllocation(ItemAllocation{
+				WarehouseID:   allocations[0].WarehouseID,
+				WarehouseName: allocations[0].WarehouseName,
+				Quantity:      take,
+			})
+			item.setBackordered(item.Backordered - take)
+			used += take
+			allocations[0].Quantity -= take
+			if allocations[0].Quantity == 0 {
+				allocations = allocations[1:]
+			}
+		}
+	}
+	if used > 0 {
+		o.UpdatedAt = time.Now()
+	}
+	return used
+}
+
+// addAllocation adds units taken from a warehouse to the line, merging them with earlier units from it
+func (i *OrderItem) addAllocation(allocation ItemAllocation) {
+	for j := range i.Allocations {
+		if i.Allocations[j].WarehouseID == allocation.WarehouseID {
+			i.Allocations[j].Quantity += allocation.Quantity
+			return
+		}
+	}
+	i.Allocations = append(i.Allocations, allocation)
+}
+
+// BackorderedQuantity returns the units of a product on the order that wait for incoming stock
+func (o *Order) BackorderedQuantity(productID string) int {
+	units := 0
+	for _, item := range o.Items {
+		if item.ProductID == productID {
+			units += item.Backordered
+		}
+	}
+	return units
+}
+
+// HasBackorders checks if any line of the order waits for incoming stock
+func (o *Order) HasBackorders() bool {
+	for _, item := range o.Items {
+		if item.Backordered > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// orderedQuantity returns the units of a product ordered over all lines
+func (o *Order) orderedQuantity(productID string) int {
+	units := 0
+	for _, item := range o.Items {
+		if item.ProductID == productID {
+			units += item.Quantity
+		}
+	}
+	return units
+}
+
+// IsPaid checks if the order currently holds a payment, i.e. it is paid and not cancelled or returned
+func (o *Order) IsPaid() bool {
+	switch o.Status {
+	case OrderStatusPaid, OrderStatusPicking, OrderStatusShipped, OrderStatusDelivered:
+		return true
+	}
+	return false
+}
+
+// HasBeenPaid checks if payment was taken for the order at any point in its history
+func (o *Order) HasBeenPaid() bool {
+	if o.IsPaid() || o.Status == OrderStatusReturned {
+		return true
+	}
+	for _, change := range o.StatusHistory {
+		if change.To == OrderStatusPaid || change.From == OrderStatusPaid {
+			return true
+		}
+	}
+	return false
+}
+
+// CanBeCancelledBy checks whether the order may be cancelled in its current status.
+// Customers may cancel until picking starts; administrators may also cancel orders
+// being picked and close out orders whose payment failed. Shipped orders are returned, not cancelled.
+func (o *Order) CanBeCancelledBy(byAdmin bool) error {
+	switch o.Status {
+	case OrderStatusPending, OrderStatusPaid:
+		return nil
+	case OrderStatusPicking, OrderStatusPaymentFailed:
+		if byAdmin {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrOrderNotCancellable, o.Status)
+}
+
 // FailPayment marks the order as payment failed
 func (o *Order) FailPayment() error {
-	if o.Status != OrderStatusPending {
-		return errors.New("only pending orders can fail payment")
-	}
-	o.Status = OrderStatusPaymentFailed
-	o.UpdatedAt = time.Now()
-	return nil
+	return o.TransitionTo(OrderStatusPaymentFailed, "")
 }
 
 // GetSubtotal returns the subtotal before tax and shipping
@@ -158,17 +478,46 @@ func (o *Order) GetSubtotal() int {
 	return subtotal
 }
 
-// GetTaxAmount returns the tax amount (10% of subtotal)
+// GetTaxAmount returns the tax amount summed over all rates
 func (o *Order) GetTaxAmount() int {
-	return o.GetSubtotal() / 10
+	return o.TaxAmount
 }
 
-// CalculateShippingFee determines the shipping fee based on subtotal
+// CalculateShippingFee determines the standard shipping fee based on subtotal
 func (o *Order) CalculateShippingFee() int {
-	if o.GetSubtotal() >= 5000 {
+	if o.GetSubtotal() >= StandardFreeShippingThreshold {
 		return 0
 	}
-	return 500
+	return StandardShippingFee
+}
+
+// SetShippingCharges prices the order's shipping with per-parcel charges and recalculates the total
+func (o *Order) SetShippingCharges(charges []ShippingCharge) {
+	o.ShippingCharges = append([]ShippingCharge{}, charges...)
+	o.recalculateTotalWithDiscount()
+	o.UpdatedAt = time.Now()
+}
+
+// shippingFee returns the sum of the shipping charges, or the standard fee when there are none
+func (o *Order) shippingFee() int {
+	if o.ShippingCharges == nil {
+		return o.CalculateShippingFee()
+	}
+	fee := 0
+	for _, charge := range o.ShippingCharges {
+		fee += charge.Fee
+	}
+	return fee
+}
+
+// SetShippingAddress records where the order ships to. The address is fixed once set.
+func (o *Order) SetShippingAddress(address ShippingAddress) error {
+	if o.ShippingAddress != nil {
+		return errors.New("shipping address is already set")
+	}
+	o.ShippingAddress = &address
+	o.UpdatedAt = time.Now()
+	return nil
 }
 
 // ApplyCouponDiscount applies a coupon discount to the order
@@ -185,12 +534,12 @@ func (o *Order) recalculateTotalWithDiscount() {
 	// Calculate subtotal (before tax)
 	subtotal := o.GetSubtotal()
 
-	// Apply 10% consumption tax
+	// Apply consumption tax
 	taxAmount := o.GetTaxAmount()
 	subtotalWithTax := subtotal + taxAmount
 
 	// Calculate shipping fee
-	o.ShippingFee = o.CalculateShippingFee()
+	o.ShippingFee = o.shippingFee()
 
 	// Apply coupon discount to (subtotal + tax), NOT to shipping
 	// Discount is applied to the taxed amount, but not to shipping
@@ -206,4 +555,4 @@ func (o *Order) recalculateTotalWithDiscount() {
 // GetSubtotalWithTax returns the subtotal including tax (before discount and shipping)
 func (o *Order) GetSubtotalWithTax() int {
 	return o.GetSubtotal() + o.GetTaxAmount()
-}
\ No newline at end of file
+}
@@ -1,7 +1,9 @@
 package entity
 
 import (
+	"errors"
 	"testing"
+	"time"
 )
 
 func TestOrder_CalculateTotalWithTaxAndShipping(t *testing.T) {
@@ -143,4 +145,235 @@ func TestOrder_ShippingFeeCalculation(t *testing.T) {
 	if fee := order.CalculateShippingFee(); fee != 0 {
 		t.Errorf("Expected shipping fee 0 for order over 5000 yen, got %d", fee)
 	}
-}
\ No newline at end of file
+}
+func TestOrder_CanBeCancelledBy(t *testing.T) {
+	tests := []struct {
+		status        OrderStatus
+		customerAllow bool
+		adminAllow    bool
+	}{
+		{OrderStatusPending, true, true},
+		{OrderStatusPaid, true, true},
+		{OrderStatusPicking, false, true},
+		{OrderStatusPaymentFailed, false, true},
+		{OrderStatusShipped, false, false},
+		{OrderStatusDelivered, false, false},
+		{OrderStatusCancelled, false, false},
+		{OrderStatusReturned, false, false},
+		{OrderStatusRefunded, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			order := &Order{Status: tt.status}
+			if err := order.CanBeCancelledBy(false); (err == nil) != tt.customerAllow {
+				t.Errorf("Customer cancellation in %s: got error %v, want allowed=%v", tt.status, err, tt.customerAllow)
+			}
+			if err := order.CanBeCancelledBy(true); (err == nil) != tt.adminAllow {
+				t.Errorf("Admin cancellation in %s: got error %v, want allowed=%v", tt.status, err, tt.adminAllow)
+			}
+		})
+	}
+}
+
+func TestOrder_Shipments(t *testing.T) {
+	order, _ := NewOrder("ORD-001", "USER-001")
+	order.AddItem("P001", "Product 1", 3, 1000)
+	order.AddItem("P002", "Product 2", 1, 500)
+	order.AddItem("P003", "Product 3", 1, 500)
+
+	if shipments := order.Shipments(); len(shipments) != 0 {
+		t.Fatalf("Expected no shipments before allocation, got %+v", shipments)
+	}
+
+	order.Items[0].Allocations = []ItemAllocation{
+		{WarehouseID: "WH-001", WarehouseName: "Tokyo", Quantity: 2},
+		{WarehouseID: "WH-002", WarehouseName: "Osaka", Quantity: 1},
+	}
+	order.Items[1].Allocations = []ItemAllocation{{WarehouseID: "WH-002", WarehouseName: "Osaka", Quantity: 1}}
+	order.Items[2].Allocations = []ItemAllocation{{WarehouseID: "WH-001", WarehouseName: "Tokyo", Quantity: 1}}
+
+	shipments := order.Shipments()
+	expected := []struct {
+		warehouseID string
+		products    []string
+		quantities  []int
+	}{
+		{"WH-001", []string{"P001", "P003"}, []int{2, 1}},
+		{"WH-002", []string{"P001", "P002"}, []int{1, 1}},
+	}
+
+	if len(shipments) != len(expected) {
+		t.Fatalf("Expected %d shipments, got %d", len(expected), len(shipments))
+	}
+	for i, want := range expected {
+		got := shipments[i]
+		if got.WarehouseID != want.warehouseID {
+			t.Errorf("Shipment %d: expected warehouse %s, got %s", i, want.warehouseID, got.WarehouseID)
+			continue
+		}
+		if len(got.Items) != len(want.products) {
+			t.Errorf("Shipment %d: expected %d items, got %+v", i, len(want.products), got.Items)
+			continue
+		}
+		for j, item := range got.Items {
+			if item.ProductID != want.products[j] || item.Quantity != want.quantities[j] {
+				t.Errorf("Shipment %d item %d: expected %s x%d, got %s x%d",
+					i, j, want.products[j], want.quantities[j], item.ProductID, item.Quantity)
+			}
+		}
+	}
+}
+
+func TestOrder_Backorders(t *testing.T) {
+	order, _ := NewOrder("ORD-001", "USER-001")
+	order.AddItem("P001", "Product 1", 2, 1000)
+	order.AddItem("P002", "Product 2", 1, 500)
+	order.AddItem("P001", "Product 1", 1, 1000)
+	expectedAt := time.Date(2030, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	if err := order.BackorderProduct("P001", 4, &expectedAt); err == nil {
+		t.Error("Expected backordering more than was ordered to fail")
+	}
+	// The last lines wait first
+	if err := order.BackorderProduct("P001", 2, &expectedAt); err != nil {
+		t.Fatalf("BackorderProduct failed: %v", err)
+	}
+	if order.Items[2].Backordered != 1 || order.Items[0].Backordered != 1 || order.Items[1].Status != OrderItemInStock {
+		t.Fatalf("Expected one unit backordered on each P001 line, got %+v", order.Items)
+	}
+	if !order.HasBackorders() || order.BackorderedQuantity("P001") != 2 {
+		t.Errorf("Expected 2 units of P001 backordered, got %d", order.BackorderedQuantity("P001"))
+	}
+
+	// Arrivals fill the first line first and merge with its earlier allocation
+	order.Items[0].Allocations = []ItemAllocation{{WarehouseID: "WH-001", WarehouseName: "Tokyo", Quantity: 1}}
+	used := order.FillBackorder("P001", []ItemAllocation{{WarehouseID: "WH-001", WarehouseName: "Tokyo", Quantity: 5}})
+	if used != 2 || order.HasBackorders() {
+		t.Fatalf("Expected 2 units used to fill every line, got %d and %+v", used, order.Items)
+	}
+	first := order.Items[0]
+	if first.Status != OrderItemInStock || first.ExpectedAt != nil || len(first.Allocations) != 1 || first.Allocations[0].Quantity != 2 {
+		t.Errorf("Expected the first line in stock with 2 units from WH-001, got %+v", first)
+	}
+}
+
+func TestOrder_TransitionTo(t *testing.T) {
+	legal := map[OrderStatus][]OrderStatus{
+		OrderStatusPending:       {OrderStatusPaid, OrderStatusPaymentFailed, OrderStatusCancelled},
+		OrderStatusPaid:          {OrderStatusPicking, OrderStatusCancelled},
+		OrderStatusPicking:       {OrderStatusShipped, OrderStatusCancelled},
+		OrderStatusShipped:       {OrderStatusDelivered, OrderStatusReturned},
+		OrderStatusDelivered:     {OrderStatusReturned},
+		OrderStatusPaymentFailed: {OrderStatusCancelled},
+		OrderStatusCancelled:     {OrderStatusRefunded},
+		OrderStatusReturned:      {OrderStatusRefunded},
+		OrderStatusRefunded:      {},
+	}
+
+	for _, from := range OrderStatuses {
+		for _, to := range OrderStatuses {
+			allowed := false
+			for _, next := range legal[from] {
+				if next == to {
+					allowed = true
+				}
+			}
+
+			t.Run(string(from)+"->"+string(to), func(t *testing.T) {
+				// A paid cancellation with a tracking number, so only the table decides
+				order := &Order{
+					Status:         from,
+					TrackingNumber: "TRK-001",
+					StatusHistory:  []StatusChange{{From: OrderStatusPending, To: OrderStatusPaid}},
+				}
+
+				err := order.TransitionTo(to, "note")
+				if allowed {
+					if err != nil {
+						t.Fatalf("Expected %s -> %s to be allowed, got %v", from, to, err)
+					}
+					if order.Status != to {
+						t.Errorf("Expected status %s, got %s", to, order.Status)
+					}
+					last := order.StatusHistory[len(order.StatusHistory)-1]
+					if len(order.StatusHistory) != 2 || last.From != from || last.To != to || last.Note != "note" {
+						t.Errorf("Expected history to record %s -> %s, got %+v", from, to, order.StatusHistory)
+					}
+					return
+				}
+
+				if !errors.Is(err, ErrInvalidTransition) {
+					t.Fatalf("Expected ErrInvalidTransition for %s -> %s, got %v", from, to, err)
+				}
+				if order.Status != from || len(order.StatusHistory) != 1 {
+					t.Errorf("Expected a rejected transition to leave the order unchanged, got %s with %d changes", order.Status, len(order.StatusHistory))
+				}
+			})
+		}
+	}
+}
+
+func TestOrder_TransitionGuards(t *testing.T) {
+	tests := []struct {
+		name    string
+		order   Order
+		to      OrderStatus
+		wantErr bool
+	}{
+		{"shipping requires a tracking number", Order{Status: OrderStatusPicking}, OrderStatusShipped, true},
+		{"an unpaid cancellation cannot be refunded", Order{Status: OrderStatusCancelled, StatusHistory: []StatusChange{
+			{To: OrderStatusPending}, {From: OrderStatusPending, To: OrderStatusCancelled},
+		}}, OrderStatusRefunded, true},
+		{"a paid cancellation can be refunded", Order{Status: OrderStatusCancelled, StatusHistory: []StatusChange{
+			{To: OrderStatusPending}, {From: OrderStatusPending, To: OrderStatusPaid}, {From: OrderStatusPaid, To: OrderStatusCancelled},
+		}}, OrderStatusRefunded, false},
+		{"a return can be refunded", Order{Status: OrderStatusReturned}, OrderStatusRefunded, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.order.TransitionTo(tt.to, "")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TransitionTo(%s) error = %v, wantErr %v", tt.to, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOrder_Lifecycle(t *testing.T) {
+	order, _ := NewOrder("ORD-001", "USER-001")
+	if err := order.MarkPaid(); err == nil {
+		t.Error("Expected an empty order not to be payable")
+	}
+	order.AddItem("P001", "Product 1", 1, 1000)
+
+	if err := order.MarkPaid(); err != nil {
+		t.Fatalf("MarkPaid failed: %v", err)
+	}
+	if err := order.TransitionTo(OrderStatusPicking, ""); err != nil {
+		t.Fatalf("Picking failed: %v", err)
+	}
+	if err := order.Ship("", ""); err == nil {
+		t.Error("Expected shipping without a tracking number to fail")
+	}
+	if err := order.Ship("TRK-123", "handed to carrier"); err != nil {
+		t.Fatalf("Ship failed: %v", err)
+	}
+	if err := order.TransitionTo(OrderStatusDelivered, ""); err != nil {
+		t.Fatalf("Delivery failed: %v", err)
+	}
+
+	if order.TrackingNumber != "TRK-123" {
+		t.Errorf("Expected tracking number TRK-123, got %q", order.TrackingNumber)
+	}
+	expected := []OrderStatus{OrderStatusPending, OrderStatusPaid, OrderStatusPicking, OrderStatusShipped, OrderStatusDelivered}
+	if len(order.StatusHistory) != len(expected) {
+		t.Fatalf("Expected %d history entries, got %+v", len(expected), order.StatusHistory)
+	}
+	for i, status := range expected {
+		if order.StatusHistory[i].To != status {
+			t.Errorf("History entry %d: expected %s, got %s", i, status, order.StatusHistory[i].To)
+		}
+	}
+}
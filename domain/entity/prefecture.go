@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"math"
+	"strings"
+)
+
+// Prefectures lists Japan's 47 prefectures in the order of their JIS codes
+var Prefectures = []string{
+	"北海道", "青森県", "岩手県", "宮城県", "秋田県", "山形県", "福島県",
+	"茨城県", "栃木県", "群馬県", "埼玉県", "千葉県", "東京都", "神奈川県",
+	"新潟県", "富山県", "石川県", "福井県", "山梨県", "長野県", "岐阜県",
+	"静岡県", "愛知県", "三重県", "滋賀県", "京都府", "大阪府", "兵庫県",
+	"奈良県", "和歌山県", "鳥取県", "島根県", "岡山県", "広島県", "山口県",
+	"徳島県", "香川県", "愛媛県", "高知県", "福岡県", "佐賀県", "長崎県",
+	"熊本県", "大分県", "宮崎県", "鹿児島県", "沖縄県",
+}
+
+// prefectureOffices is the latitude and longitude of each prefectural office
+var prefectureOffices = map[string][2]float64{
+	"北海道": {43.06, 141.35}, "青森県": {40.82, 140.74}, "岩手県": {39.70, 141.15}, "宮城県": {38.27, 140.87},
+	"秋田県": {39.72, 140.10}, "山形県": {38.24, 140.36}, "福島県": {37.75, 140.47}, "茨城県": {36.34, 140.45},
+	"栃木県": {36.57, 139.88}, "群馬県": {36.39, 139.06}, "埼玉県": {35.86, 139.65}, "千葉県": {35.61, 140.12},
+	"東京都": {35.69, 139.69}, "神奈川県": {35.45, 139.64}, "新潟県": {37.90, 139.02}, "富山県": {36.70, 137.21},
+	"石川県": {36.59, 136.63}, "福井県": {36.07, 136.22}, "山梨県": {35.66, 138.57}, "長野県": {36.65, 138.18},
+	"岐阜県": {35.39, 136.72}, "静岡県": {34.98, 138.38}, "愛知県": {35.18, 136.91}, "三重県": {34.73, 136.51},
+	"滋賀県": {35.00, 135.87}, "京都府": {35.02, 135.76}, "大阪府": {34.69, 135.52}, "兵庫県": {34.69, 135.18},
+	"奈良県": {34.69, 135.83}, "和歌山県": {34.23, 135.17}, "鳥取県": {35.50, 134.24}, "島根県": {35.47, 133.05},
+	"岡山県": {34.66, 133.93}, "広島県": {34.40, 132.46}, "山口県": {34.19, 131.47}, "徳島県": {34.07, 134.56},
+	"香川県": {34.34, 134.04}, "愛媛県": {33.84, 132.77}, "高知県": {33.56, 133.53}, "福岡県": {33.61, 130.42},
+	"佐賀県": {33.25, 130.30}, "長崎県": {32.74, 129.87}, "熊本県": {32.79, 130.74}, "大分県": {33.24, 131.61},
+	"宮崎県": {31.91, 131.42}, "鹿児島県": {31.56, 130.56}, "沖縄県": {26.21, 127.68},
+}
+
+// IsPrefecture checks if name is one of Japan's prefectures
+func IsPrefecture(name string) bool {
+	return containsString(Prefectures, name)
+}
+
+// PrefectureOf returns the prefecture a free-form location such as "東京都港区" starts with,
+// or an empty string when it names none
+func PrefectureOf(location string) string {
+	location = strings.TrimSpace(location)
+	for _, prefecture := range Prefectures {
+		if strings.HasPrefix(location, prefecture) {
+			return prefecture
+		}
+	}
+	return ""
+}
+
+// PrefectureDistance returns the great-circle distance in kilometres between the offices of two
+// prefectures. ok is false when either name is not a prefecture.
+func PrefectureDistance(from, to string) (km float64, ok bool) {
+	a, okFrom := prefectureOffices[from]
+	b, okTo := prefectureOffices[to]
+	if !okFrom || !okTo {
+		return 0, false
+	}
+
+	const earthRadiusKm = 6371
+	lat1, lat2 := a[0]*math.Pi/180, b[0]*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b[1] - a[1]) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h)), true
+}
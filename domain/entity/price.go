@@ -0,0 +1,98 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// PriceChange is one entry of a product's append-only price history
+type PriceChange struct {
+	ID            string    `json:"id"`
+	ProductID     string    `json:"product_id"`
+	PreviousPrice int       `json:"previous_price"`
+	Price         int       `json:"price"`
+	UserID        string    `json:"user_id,omitempty"` // Acting user; empty for system changes
+	ChangedAt     time.Time `json:"changed_at"`
+}
+
+// NewPriceChange records a change of a product's regular price
+func NewPriceChange(id, productID string, previousPrice, price int, userID string) (*PriceChange, error) {
+	if id == "" {
+		return nil, errors.New("price change ID cannot be empty")
+	}
+	if price < 0 {
+		return nil, errors.New("product price cannot be negative")
+	}
+	if price == previousPrice {
+		return nil, errors.New("price did not change")
+	}
+
+	return &PriceChange{
+		ID:            id,
+		ProductID:     productID,
+		PreviousPrice: previousPrice,
+		Price:         price,
+		UserID:        userID,
+		ChangedAt:     time.Now(),
+	}, nil
+}
+
+// Sale is a price a product sells at for a limited time instead of its regular price
+type Sale struct {
+	ID          string     `json:"id"`
+	ProductID   string     `json:"product_id"`
+	Price       int        `json:"price"`
+	StartsAt    time.Time  `json:"starts_at"`
+	EndsAt      time.Time  `json:"ends_at"` // Exclusive
+	CreatedBy   string     `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+}
+
+// NewSale schedules a sale of a product at price from startsAt until endsAt
+func NewSale(id, productID string, price int, startsAt, endsAt time.Time, createdBy string) (*Sale, error) {
+	if id == "" {
+		return nil, errors.New("sale ID cannot be empty")
+	}
+	if price < 0 {
+		return nil, errors.New("sale price cannot be negative")
+	}
+	if !endsAt.After(startsAt) {
+		return nil, errors.New("sale end must be after its start")
+	}
+
+	return &Sale{
+		ID:        id,
+		ProductID: productID,
+		Price:     price,
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// IsEffective checks if the sale is running at the given time
+func (s *Sale) IsEffective(now time.Time) bool {
+	return s.CancelledAt == nil && !now.Before(s.StartsAt) && now.Before(s.EndsAt)
+}
+
+// Overlaps checks if two sales that were not cancelled run at the same time
+func (s *Sale) Overlaps(other *Sale) bool {
+	if s.CancelledAt != nil || other.CancelledAt != nil {
+		return false
+	}
+	return s.StartsAt.Before(other.EndsAt) && other.StartsAt.Before(s.EndsAt)
+}
+
+// Cancel stops a sale that has not ended yet; it stays in the product's list of sales
+func (s *Sale) Cancel(now time.Time) error {
+	if s.CancelledAt != nil {
+		return errors.New("sale is already cancelled")
+	}
+	if !now.Before(s.EndsAt) {
+		return errors.New("sale has already ended")
+	}
+	s.CancelledAt = &now
+	return nil
+}
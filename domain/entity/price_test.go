@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSale_IsEffective(t *testing.T) {
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	sale, err := NewSale("SALE-1", "PROD-001", 800, start, start.Add(24*time.Hour), "")
+	if err != nil {
+		t.Fatalf("NewSale failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want bool
+	}{
+		{name: "before the start", at: start.Add(-time.Second), want: false},
+		{name: "at the start", at: start, want: true},
+		{name: "during the sale", at: start.Add(12 * time.Hour), want: true},
+		{name: "at the end", at: start.Add(24 * time.Hour), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sale.IsEffective(tt.at); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if err := sale.Cancel(start); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if sale.IsEffective(start.Add(time.Hour)) {
+		t.Error("Expected a cancelled sale not to run")
+	}
+	if err := sale.Cancel(start); err == nil {
+		t.Error("Expected cancelling twice to fail")
+	}
+	if _, err := NewSale("SALE-2", "PROD-001", 800, start, start, ""); err == nil {
+		t.Error("Expected a sale ending at its start to be rejected")
+	}
+}
+
+func TestSale_Overlaps(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2030, 1, d, 0, 0, 0, 0, time.UTC) }
+	first, _ := NewSale("SALE-1", "PROD-001", 800, day(1), day(3), "")
+	touching, _ := NewSale("SALE-2", "PROD-001", 700, day(3), day(5), "")
+	overlapping, _ := NewSale("SALE-3", "PROD-001", 700, day(2), day(4), "")
+
+	if first.Overlaps(touching) {
+		t.Error("Expected a sale starting as another ends not to overlap it")
+	}
+	if !first.Overlaps(overlapping) || !overlapping.Overlaps(first) {
+		t.Error("Expected sales sharing a day to overlap")
+	}
+	first.Cancel(day(1))
+	if first.Overlaps(overlapping) {
+		t.Error("Expected a cancelled sale not to overlap")
+	}
+}
+
+func TestProduct_ApplySale(t *testing.T) {
+	product, _ := NewProduct("PROD-001", "Laptop", 1000, "Electronics")
+	now := time.Now()
+	sale, _ := NewSale("SALE-1", "PROD-001", 800, now, now.Add(time.Hour), "")
+
+	product.ApplySale(sale)
+	if product.CurrentPrice() != 800 || product.Price != 1000 || product.SaleEndsAt == nil {
+		t.Errorf("Expected to sell at 800 instead of 1000, got %d and %d", product.CurrentPrice(), product.Price)
+	}
+
+	// A regular price cut below the sale price wins
+	product.Price = 700
+	product.ApplySale(sale)
+	if product.SalePrice != nil || product.CurrentPrice() != 700 {
+		t.Errorf("Expected the regular price of 700 to apply, got %d", product.CurrentPrice())
+	}
+
+	product.ApplySale(nil)
+	if product.SalePrice != nil || product.SaleEndsAt != nil {
+		t.Error("Expected no sale")
+	}
+}
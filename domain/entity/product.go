@@ -2,15 +2,50 @@ package entity
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
+// BackorderMode is whether a product can be ordered beyond the stock on hand
+type BackorderMode string
+
+const (
+	BackorderNone     BackorderMode = ""          // Orders need stock on hand
+	BackorderAllowed  BackorderMode = "backorder" // Orders beyond stock wait for restocks
+	BackorderPreorder BackorderMode = "preorder"  // Not released yet; orders wait for the first stock
+)
+
+// ParseBackorderMode converts a string into a backorder mode; "none" and "" mean BackorderNone
+func ParseBackorderMode(s string) (BackorderMode, error) {
+	switch BackorderMode(s) {
+	case BackorderNone, "none":
+		return BackorderNone, nil
+	case BackorderAllowed, BackorderPreorder:
+		return BackorderMode(s), nil
+	}
+	return "", fmt.Errorf("unknown backorder mode: %s", s)
+}
+
+// MaxDescriptionLength is the longest product description in characters
+const MaxDescriptionLength = 5000
+
+// ErrProductArchived is returned when a product that is no longer sold is ordered or archived again
+var ErrProductArchived = errors.New("product is archived")
+
+// ErrProductNotArchived is returned when restoring a product that is still sold
+var ErrProductNotArchived = errors.New("product is not archived")
+
 // Product represents a product in the system
 type Product struct {
 	ID        string      `json:"id"`
+	SKU       string      `json:"sku,omitempty"` // Merchant's stock keeping unit; unique when set
 	Name      string      `json:"name"`
-	Price     int         `json:"price"`
+	Description string    `json:"description,omitempty"`
+	Price     int         `json:"price"` // Regular price, before any sale
 	Category  string      `json:"category"`
+	Weight    int         `json:"weight,omitempty"` // Grams per unit, used for shipping
 	CreatedAt time.Time   `json:"created_at"`
 	UpdatedAt time.Time   `json:"updated_at"`
 	// Stock is now managed separately through Stock entities
@@ -18,6 +53,19 @@ type Product struct {
 	TotalStock int        `json:"total_stock,omitempty"` // Calculated total across all warehouses
 	// Wishlist information
 	IsFavorite bool        `json:"is_favorite"` // Whether the product is in the current user's wishlist
+	// Backorders
+	Backorder           BackorderMode `json:"backorder,omitempty"`
+	ExpectedAvailableAt *time.Time    `json:"expected_available_at,omitempty"` // When backordered units are expected to arrive
+	// Archived products are no longer sold but stay readable for past orders and wishlists
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	// Sale running now, filled in when the product is priced
+	SalePrice  *int       `json:"sale_price,omitempty"`
+	SaleEndsAt *time.Time `json:"sale_ends_at,omitempty"`
+	// Variants are products of their own, with a SKU, price and stock, grouped under a parent
+	ParentID      string            `json:"parent_id,omitempty"`      // Product this is a variant of
+	Options       map[string]string `json:"options,omitempty"`        // What sets the variant apart, such as size or colour
+	PriceOverride bool              `json:"price_override,omitempty"` // Whether the variant has its own price instead of its parent's
+	Variants      []*Product        `json:"variants,omitempty"`       // Variants of a parent product, filled in for responses
 }
 
 // NewProduct creates a new product entity
@@ -45,6 +93,135 @@ func NewProduct(id, name string, price int, category string) (*Product, error) {
 	}, nil
 }
 
+// UpdateDetails replaces the name, price and category of the product
+func (p *Product) UpdateDetails(name string, price int, category string) error {
+	if name == "" {
+		return errors.New("product name cannot be empty")
+	}
+	if price < 0 {
+		return errors.New("product price cannot be negative")
+	}
+	if category == "" {
+		return errors.New("product category cannot be empty")
+	}
+	p.Name = name
+	p.Price = price
+	p.Category = category
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetDescription sets the text shown on the product page and searched along with the name
+func (p *Product) SetDescription(description string) error {
+	description = strings.TrimSpace(description)
+	if utf8.RuneCountInString(description) > MaxDescriptionLength {
+		return fmt.Errorf("product description cannot be longer than %d characters", MaxDescriptionLength)
+	}
+	p.Description = description
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetWeight sets the shipping weight of one unit in grams
+func (p *Product) SetWeight(grams int) error {
+	if grams < 0 {
+		return errors.New("product weight cannot be negative")
+	}
+	p.Weight = grams
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetSKU sets the stock keeping unit of the product; an empty SKU removes it.
+// SKUs are up to 64 letters, digits, '-', '_' and '.'.
+func (p *Product) SetSKU(sku string) error {
+	if len(sku) > 64 {
+		return errors.New("SKU cannot be longer than 64 characters")
+	}
+	for _, r := range sku {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return fmt.Errorf("SKU contains an invalid character: %q", r)
+		}
+	}
+	p.SKU = sku
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetBackorder sets whether the product can be ordered beyond its stock and when missing
+// units are expected. Pre-orders need an expected date; products without backorders have none.
+func (p *Product) SetBackorder(mode BackorderMode, expectedAt *time.Time) error {
+	switch mode {
+	case BackorderNone:
+		expectedAt = nil
+	case BackorderAllowed:
+	case BackorderPreorder:
+		if expectedAt == nil {
+			return errors.New("pre-order products need an expected availability date")
+		}
+	default:
+		return fmt.Errorf("unknown backorder mode: %s", mode)
+	}
+	if expectedAt != nil {
+		at := *expectedAt
+		expectedAt = &at
+	}
+	p.Backorder = mode
+	p.ExpectedAvailableAt = expectedAt
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// Archive stops selling the product
+func (p *Product) Archive() error {
+	if p.IsArchived() {
+		return ErrProductArchived
+	}
+	now := time.Now()
+	p.ArchivedAt = &now
+	p.UpdatedAt = now
+	return nil
+}
+
+// Restore puts an archived product back on sale
+func (p *Product) Restore() error {
+	if !p.IsArchived() {
+		return ErrProductNotArchived
+	}
+	p.ArchivedAt = nil
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// IsArchived checks if the product is no longer sold
+func (p *Product) IsArchived() bool {
+	return p.ArchivedAt != nil
+}
+
+// ApplySale sets the sale the product currently sells at; nil means none. A sale that is not
+// below the regular price is ignored.
+func (p *Product) ApplySale(sale *Sale) {
+	p.SalePrice, p.SaleEndsAt = nil, nil
+	if sale != nil && sale.Price < p.Price {
+		price, endsAt := sale.Price, sale.EndsAt
+		p.SalePrice, p.SaleEndsAt = &price, &endsAt
+	}
+}
+
+// CurrentPrice returns the price the product sells at: its sale price during a sale, otherwise
+// its regular price
+func (p *Product) CurrentPrice() int {
+	if p.SalePrice != nil {
+		return *p.SalePrice
+	}
+	return p.Price
+}
+
+// AcceptsBackorders checks if orders beyond the stock on hand are accepted
+func (p *Product) AcceptsBackorders() bool {
+	return p.Backorder == BackorderAllowed || p.Backorder == BackorderPreorder
+}
+
 // CalculateTotalStock calculates the total stock across all warehouses
 func (p *Product) CalculateTotalStock() int {
 	total := 0
@@ -55,9 +232,10 @@ func (p *Product) CalculateTotalStock() int {
 	return total
 }
 
-// CanFulfillOrder checks if the product has enough total stock for the requested quantity
+// CanFulfillOrder checks if the product has enough total stock for the requested quantity,
+// or accepts backorders for what is missing
 func (p *Product) CanFulfillOrder(quantity int) bool {
-	return p.TotalStock >= quantity
+	return p.TotalStock >= quantity || p.AcceptsBackorders()
 }
 
 // AddStockInfo adds stock information for a warehouse
@@ -1,7 +1,10 @@
 package entity
 
 import (
+	"errors"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewProduct(t *testing.T) {
@@ -10,7 +13,6 @@ func TestNewProduct(t *testing.T) {
 		id       string
 		prodName string
 		price    int
-		stock    int
 		category string
 		wantErr  bool
 	}{
@@ -19,7 +21,6 @@ func TestNewProduct(t *testing.T) {
 			id:       "PROD-001",
 			prodName: "Laptop",
 			price:    1200,
-			stock:    10,
 			category: "Electronics",
 			wantErr:  false,
 		},
@@ -28,7 +29,6 @@ func TestNewProduct(t *testing.T) {
 			id:       "PROD-002",
 			prodName: "",
 			price:    100,
-			stock:    5,
 			category: "Test",
 			wantErr:  true,
 		},
@@ -37,16 +37,6 @@ func TestNewProduct(t *testing.T) {
 			id:       "PROD-003",
 			prodName: "Test",
 			price:    -100,
-			stock:    5,
-			category: "Test",
-			wantErr:  true,
-		},
-		{
-			name:     "negative stock",
-			id:       "PROD-004",
-			prodName: "Test",
-			price:    100,
-			stock:    -5,
 			category: "Test",
 			wantErr:  true,
 		},
@@ -54,7 +44,7 @@ func TestNewProduct(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			product, err := NewProduct(tt.id, tt.prodName, tt.price, tt.stock, tt.category)
+			product, err := NewProduct(tt.id, tt.prodName, tt.price, tt.category)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewProduct() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -66,8 +56,8 @@ func TestNewProduct(t *testing.T) {
 	}
 }
 
-func TestProduct_ReduceStock(t *testing.T) {
-	product, _ := NewProduct("PROD-001", "Test", 100, 10, "Test")
+func TestStock_Reduce(t *testing.T) {
+	stock, _ := NewStock("STK-001", "PROD-001", "WH-001", 10)
 
 	tests := []struct {
 		name     string
@@ -97,13 +87,163 @@ func TestProduct_ReduceStock(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := product.ReduceStock(tt.quantity)
+			err := stock.Reduce(tt.quantity)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Reduce() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && stock.Quantity != tt.expected {
+				t.Errorf("Reduce() quantity = %v, want %v", stock.Quantity, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStock_Reservations(t *testing.T) {
+	stock, _ := NewStock("STK-001", "PROD-001", "WH-001", 10)
+
+	tests := []struct {
+		name             string
+		action           func(s *Stock) error
+		wantErr          bool
+		expectedQuantity int
+		expectedReserved int
+	}{
+		{
+			name:             "reserve within stock",
+			action:           func(s *Stock) error { return s.Reserve(6) },
+			expectedQuantity: 10,
+			expectedReserved: 6,
+		},
+		{
+			name:             "reserve more than available",
+			action:           func(s *Stock) error { return s.Reserve(5) },
+			wantErr:          true,
+			expectedQuantity: 10,
+			expectedReserved: 6,
+		},
+		{
+			name:             "reduce cannot take reserved units",
+			action:           func(s *Stock) error { return s.Reduce(5) },
+			wantErr:          true,
+			expectedQuantity: 10,
+			expectedReserved: 6,
+		},
+		{
+			name:             "commit part of the reservation",
+			action:           func(s *Stock) error { return s.CommitReservation(4) },
+			expectedQuantity: 6,
+			expectedReserved: 2,
+		},
+		{
+			name:             "release more than reserved",
+			action:           func(s *Stock) error { return s.Release(3) },
+			wantErr:          true,
+			expectedQuantity: 6,
+			expectedReserved: 2,
+		},
+		{
+			name:             "release the rest",
+			action:           func(s *Stock) error { return s.Release(2) },
+			expectedQuantity: 6,
+			expectedReserved: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.action(stock)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if stock.Quantity != tt.expectedQuantity || stock.Reserved != tt.expectedReserved {
+				t.Errorf("quantity/reserved = %d/%d, want %d/%d",
+					stock.Quantity, stock.Reserved, tt.expectedQuantity, tt.expectedReserved)
+			}
+		})
+	}
+}
+
+func TestProduct_SetBackorder(t *testing.T) {
+	expectedAt := time.Date(2030, 4, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		mode       BackorderMode
+		expectedAt *time.Time
+		wantErr    bool
+		accepts    bool
+	}{
+		{name: "backorder without date", mode: BackorderAllowed, accepts: true},
+		{name: "pre-order with date", mode: BackorderPreorder, expectedAt: &expectedAt, accepts: true},
+		{name: "pre-order without date", mode: BackorderPreorder, wantErr: true},
+		{name: "unknown mode", mode: "sometimes", wantErr: true},
+		{name: "no backorders drops the date", mode: BackorderNone, expectedAt: &expectedAt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product, _ := NewProduct("PROD-001", "Laptop", 1200, "Electronics")
+			err := product.SetBackorder(tt.mode, tt.expectedAt)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("ReduceStock() error = %v, wantErr %v", err, tt.wantErr)
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if product.AcceptsBackorders() != tt.accepts {
+				t.Errorf("AcceptsBackorders() = %v, want %v", product.AcceptsBackorders(), tt.accepts)
 			}
-			if !tt.wantErr && product.Stock != tt.expected {
-				t.Errorf("ReduceStock() stock = %v, want %v", product.Stock, tt.expected)
+			if tt.mode == BackorderNone && product.ExpectedAvailableAt != nil {
+				t.Errorf("Expected no availability date, got %v", product.ExpectedAvailableAt)
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestProduct_SetSKU(t *testing.T) {
+	tests := []struct {
+		name    string
+		sku     string
+		wantErr bool
+	}{
+		{name: "letters digits and separators", sku: "DESK-oak_120.v2"},
+		{name: "empty removes the SKU", sku: ""},
+		{name: "space", sku: "DESK 120", wantErr: true},
+		{name: "non-ASCII", sku: "机-120", wantErr: true},
+		{name: "too long", sku: strings.Repeat("A", 65), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product, _ := NewProduct("PROD-001", "Laptop", 1200, "Electronics")
+			product.SKU = "OLD"
+			err := product.SetSKU(tt.sku)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && product.SKU != tt.sku {
+				t.Errorf("Expected SKU %q, got %q", tt.sku, product.SKU)
+			}
+		})
+	}
+}
+
+func TestProduct_Archive(t *testing.T) {
+	product, _ := NewProduct("PROD-001", "Laptop", 1200, "Electronics")
+	if err := product.Restore(); !errors.Is(err, ErrProductNotArchived) {
+		t.Errorf("Expected restoring a product on sale to fail, got %v", err)
+	}
+
+	if err := product.Archive(); err != nil {
+		t.Fatalf("Archive failed: %v", err)
+	}
+	if !product.IsArchived() || product.ArchivedAt == nil {
+		t.Error("Expected the product to be archived")
+	}
+	if err := product.Archive(); !errors.Is(err, ErrProductArchived) {
+		t.Errorf("Expected archiving twice to fail, got %v", err)
+	}
+
+	if err := product.Restore(); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if product.IsArchived() {
+		t.Error("Expected the product to be on sale again")
+	}
+}
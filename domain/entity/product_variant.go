@@ -0,0 +1,118 @@
+package entity
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrNotVariant is returned when a variant operation is applied to a product that is not a variant
+var ErrNotVariant = errors.New("product is not a variant")
+
+// NewVariant creates a variant of a parent product. The variant has its own SKU and stock and
+// follows the parent for everything else. It sells at the parent's price unless price is given.
+func NewVariant(id string, parent *Product, sku string, options map[string]string, price *int) (*Product, error) {
+	if parent.IsVariant() {
+		return nil, errors.New("a variant cannot have variants of its own")
+	}
+	if sku == "" {
+		return nil, errors.New("variant SKU cannot be empty")
+	}
+	if len(options) == 0 {
+		return nil, errors.New("variant needs at least one option, such as a size or colour")
+	}
+	copied := make(map[string]string, len(options))
+	for name, value := range options {
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if name == "" || value == "" {
+			return nil, errors.New("variant option names and values cannot be empty")
+		}
+		copied[name] = value
+	}
+
+	now := time.Now()
+	variant := &Product{
+		ID:        id,
+		ParentID:  parent.ID,
+		Options:   copied,
+		CreatedAt: now,
+		Stocks:    []StockInfo{},
+	}
+	if err := variant.SetSKU(sku); err != nil {
+		return nil, err
+	}
+	variant.FollowParent(parent)
+	if price != nil {
+		if err := variant.SetVariantPrice(*price); err != nil {
+			return nil, err
+		}
+	}
+	return variant, nil
+}
+
+// IsVariant checks if the product is a variant of another product
+func (p *Product) IsVariant() bool {
+	return p.ParentID != ""
+}
+
+// FollowParent copies the details a variant shares with its parent: the name, followed by the
+// option values, the description, the category, the weight, the backorder settings and, unless
+// the variant has its own, the price
+func (p *Product) FollowParent(parent *Product) {
+	values := make([]string, 0, len(p.Options))
+	for _, name := range p.OptionNames() {
+		values = append(values, p.Options[name])
+	}
+	p.Name = parent.Name + " (" + strings.Join(values, " / ") + ")"
+	p.Description = parent.Description
+	p.Category = parent.Category
+	p.Weight = parent.Weight
+	p.Backorder = parent.Backorder
+	p.ExpectedAvailableAt = nil
+	if parent.ExpectedAvailableAt != nil {
+		at := *parent.ExpectedAvailableAt
+		p.ExpectedAvailableAt = &at
+	}
+	if !p.PriceOverride {
+		p.Price = parent.Price
+	}
+	p.UpdatedAt = time.Now()
+}
+
+// SetVariantPrice gives a variant its own price instead of its parent's
+func (p *Product) SetVariantPrice(price int) error {
+	if !p.IsVariant() {
+		return ErrNotVariant
+	}
+	if price < 0 {
+		return errors.New("product price cannot be negative")
+	}
+	p.Price = price
+	p.PriceOverride = true
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// OptionNames returns the names of the variant's options in alphabetical order
+func (p *Product) OptionNames() []string {
+	names := make([]string, 0, len(p.Options))
+	for name := range p.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasOptions checks if the variant has exactly the given options
+func (p *Product) HasOptions(options map[string]string) bool {
+	if len(p.Options) != len(options) {
+		return false
+	}
+	for name, value := range options {
+		if p.Options[strings.TrimSpace(name)] != strings.TrimSpace(value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewVariant(t *testing.T) {
+	parent, _ := NewProduct("PROD-001", "T-Shirt", 2000, "Apparel")
+	parent.SetWeight(200)
+	price := 2500
+
+	tests := []struct {
+		name      string
+		sku       string
+		options   map[string]string
+		price     *int
+		wantName  string
+		wantPrice int
+		wantErr   bool
+	}{
+		{"parent's price", "TS-M-RED", map[string]string{"size": "M", "colour": "Red"}, nil, "T-Shirt (Red / M)", 2000, false},
+		{"own price", "TS-XL-RED", map[string]string{"size": "XL", "colour": "Red"}, &price, "T-Shirt (Red / XL)", 2500, false},
+		{"no SKU", "", map[string]string{"size": "M"}, nil, "", 0, true},
+		{"no options", "TS", map[string]string{}, nil, "", 0, true},
+		{"empty option value", "TS", map[string]string{"size": " "}, nil, "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variant, err := NewVariant("PROD-002", parent, tt.sku, tt.options, tt.price)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if variant.Name != tt.wantName || variant.Price != tt.wantPrice {
+				t.Errorf("Expected %q at %d, got %q at %d", tt.wantName, tt.wantPrice, variant.Name, variant.Price)
+			}
+			if !variant.IsVariant() || variant.ParentID != "PROD-001" || variant.Weight != 200 || variant.Category != "Apparel" {
+				t.Errorf("Expected a variant sharing the parent's details, got %+v", variant)
+			}
+		})
+	}
+
+	variant, _ := NewVariant("PROD-002", parent, "TS-M", map[string]string{"size": "M"}, nil)
+	if _, err := NewVariant("PROD-003", variant, "TS-M-2", map[string]string{"fit": "Slim"}, nil); err == nil {
+		t.Error("Expected a variant of a variant to be rejected")
+	}
+}
+
+func TestProduct_FollowParent(t *testing.T) {
+	parent, _ := NewProduct("PROD-001", "T-Shirt", 2000, "Apparel")
+	price := 2500
+	following, _ := NewVariant("PROD-002", parent, "TS-M", map[string]string{"size": "M"}, nil)
+	overridden, _ := NewVariant("PROD-003", parent, "TS-XL", map[string]string{"size": "XL"}, &price)
+
+	parent.UpdateDetails("Tee", 1800, "Clothing")
+	following.FollowParent(parent)
+	overridden.FollowParent(parent)
+	if following.Name != "Tee (M)" || following.Category != "Clothing" || following.Price != 1800 {
+		t.Errorf("Expected the variant to follow its parent, got %+v", following)
+	}
+	if overridden.Name != "Tee (XL)" || overridden.Price != 2500 {
+		t.Errorf("Expected the variant to keep its own price, got %+v", overridden)
+	}
+
+	if err := parent.SetVariantPrice(100); !errors.Is(err, ErrNotVariant) {
+		t.Errorf("Expected a parent's price not to be a variant price, got %v", err)
+	}
+	if !following.HasOptions(map[string]string{"size": "M"}) || following.HasOptions(map[string]string{"size": "M", "fit": "Slim"}) {
+		t.Error("Expected options to match exactly")
+	}
+}
@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// ReorderPoint is the available quantity at or below which a product needs restocking. A reorder
+// point without a warehouse is the product's default for every warehouse without its own.
+type ReorderPoint struct {
+	ProductID   string    `json:"product_id"`
+	WarehouseID string    `json:"warehouse_id,omitempty"`
+	Threshold   int       `json:"threshold"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NewReorderPoint creates a reorder point; an empty warehouseID makes it the product's default
+func NewReorderPoint(productID, warehouseID string, threshold int) (*ReorderPoint, error) {
+	if productID == "" {
+		return nil, errors.New("product ID cannot be empty")
+	}
+	if threshold < 0 {
+		return nil, errors.New("reorder point cannot be negative")
+	}
+
+	return &ReorderPoint{
+		ProductID:   productID,
+		WarehouseID: warehouseID,
+		Threshold:   threshold,
+		UpdatedAt:   time.Now(),
+	}, nil
+}
+
+// StockAlertType is how short of stock a product is in a warehouse
+type StockAlertType string
+
+const (
+	StockAlertLow        StockAlertType = "low_stock"    // Available units are at or below the reorder point
+	StockAlertOutOfStock StockAlertType = "out_of_stock" // No units are available
+)
+
+// StockAlert reports a product running short in a warehouse
+type StockAlert struct {
+	Type         StockAlertType `json:"type"`
+	ProductID    string         `json:"product_id"`
+	ProductName  string         `json:"product_name,omitempty"`
+	WarehouseID  string         `json:"warehouse_id"`
+	Available    int            `json:"available"`
+	ReorderPoint int            `json:"reorder_point"`
+	DetectedAt   time.Time      `json:"detected_at"`
+}
+
+// CheckStockLevel reports whether available units call for an alert against a reorder point.
+// Running out always does; running low only when the reorder point is positive.
+func CheckStockLevel(available, reorderPoint int) (StockAlertType, bool) {
+	switch {
+	case available <= 0:
+		return StockAlertOutOfStock, true
+	case available <= reorderPoint:
+		return StockAlertLow, true
+	}
+	return "", false
+}
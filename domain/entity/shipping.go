@@ -0,0 +1,126 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// StandardShippingFee is charged when no shipping rule covers a parcel
+	StandardShippingFee = 500
+	// StandardFreeShippingThreshold is the subtotal from which the standard fee is waived
+	StandardFreeShippingThreshold = 5000
+)
+
+// ShippingParcel is the part of an order shipped together from one warehouse
+type ShippingParcel struct {
+	WarehouseID string `json:"warehouse_id"` // Empty when the units could not be placed in a warehouse yet
+	Prefecture  string `json:"prefecture"`   // Destination; empty when unknown
+	ItemCount   int    `json:"item_count"`
+	Weight      int    `json:"weight"` // Grams
+}
+
+// ShippingCharge is the fee charged for one parcel of an order
+type ShippingCharge struct {
+	WarehouseID string `json:"warehouse_id,omitempty"`
+	RuleID      string `json:"rule_id,omitempty"` // Empty when the standard fee was charged
+	Fee         int    `json:"fee"`
+}
+
+// ShippingRule prices the parcels matching its conditions. Conditions left empty or zero
+// match every parcel; a parcel is priced by the matching active rule with the lowest priority.
+type ShippingRule struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Priority int    `json:"priority"` // Lower values are tried first
+	Active   bool   `json:"active"`
+
+	Prefectures  []string `json:"prefectures,omitempty"`   // Destination prefectures
+	WarehouseIDs []string `json:"warehouse_ids,omitempty"` // Shipping warehouses
+	MinItems     int      `json:"min_items,omitempty"`
+	MaxItems     int      `json:"max_items,omitempty"`
+	MinWeight    int      `json:"min_weight,omitempty"` // Grams
+	MaxWeight    int      `json:"max_weight,omitempty"` // Grams
+
+	Fee                   int        `json:"fee"`
+	FreeShippingThreshold int        `json:"free_shipping_threshold,omitempty"` // Order subtotal from which the fee is waived
+	StartsAt              *time.Time `json:"starts_at,omitempty"`               // Campaign start; nil for always
+	EndsAt                *time.Time `json:"ends_at,omitempty"`                 // Campaign end (exclusive); nil for never
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks that the rule's settings are consistent
+func (r *ShippingRule) Validate() error {
+	if r.ID == "" {
+		return errors.New("shipping rule ID cannot be empty")
+	}
+	if r.Name == "" {
+		return errors.New("shipping rule name cannot be empty")
+	}
+	if r.Fee < 0 || r.FreeShippingThreshold < 0 {
+		return errors.New("fee and free shipping threshold cannot be negative")
+	}
+	if r.MinItems < 0 || r.MaxItems < 0 || r.MinWeight < 0 || r.MaxWeight < 0 {
+		return errors.New("item and weight bounds cannot be negative")
+	}
+	if r.MaxItems > 0 && r.MaxItems < r.MinItems {
+		return errors.New("max items cannot be less than min items")
+	}
+	if r.MaxWeight > 0 && r.MaxWeight < r.MinWeight {
+		return errors.New("max weight cannot be less than min weight")
+	}
+	if r.StartsAt != nil && r.EndsAt != nil && !r.EndsAt.After(*r.StartsAt) {
+		return errors.New("campaign end must be after its start")
+	}
+	return nil
+}
+
+// IsEffective checks if the rule is active and within its campaign period at the given time
+func (r *ShippingRule) IsEffective(now time.Time) bool {
+	if !r.Active {
+		return false
+	}
+	if r.StartsAt != nil && now.Before(*r.StartsAt) {
+		return false
+	}
+	if r.EndsAt != nil && !now.Before(*r.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// Matches checks if a parcel meets every condition of the rule
+func (r *ShippingRule) Matches(parcel ShippingParcel) bool {
+	if len(r.Prefectures) > 0 && !containsString(r.Prefectures, parcel.Prefecture) {
+		return false
+	}
+	if len(r.WarehouseIDs) > 0 && !containsString(r.WarehouseIDs, parcel.WarehouseID) {
+		return false
+	}
+	if parcel.ItemCount < r.MinItems || (r.MaxItems > 0 && parcel.ItemCount > r.MaxItems) {
+		return false
+	}
+	if parcel.Weight < r.MinWeight || (r.MaxWeight > 0 && parcel.Weight > r.MaxWeight) {
+		return false
+	}
+	return true
+}
+
+// FeeFor returns the rule's fee for an order with the given subtotal
+func (r *ShippingRule) FeeFor(orderSubtotal int) int {
+	if r.FreeShippingThreshold > 0 && orderSubtotal >= r.FreeShippingThreshold {
+		return 0
+	}
+	return r.Fee
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
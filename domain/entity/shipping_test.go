@@ -0,0 +1,97 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShippingRule_Matches(t *testing.T) {
+	rule := &ShippingRule{
+		ID:           "SHIP-1",
+		Name:         "Hokkaido heavy parcels",
+		Prefectures:  []string{"北海道"},
+		WarehouseIDs: []string{"WH-001"},
+		MinItems:     2,
+		MaxItems:     10,
+		MinWeight:    1000,
+	}
+
+	tests := []struct {
+		name     string
+		parcel   ShippingParcel
+		expected bool
+	}{
+		{"all conditions met", ShippingParcel{WarehouseID: "WH-001", Prefecture: "北海道", ItemCount: 2, Weight: 1000}, true},
+		{"other prefecture", ShippingParcel{WarehouseID: "WH-001", Prefecture: "東京都", ItemCount: 2, Weight: 1000}, false},
+		{"other warehouse", ShippingParcel{WarehouseID: "WH-002", Prefecture: "北海道", ItemCount: 2, Weight: 1000}, false},
+		{"too few items", ShippingParcel{WarehouseID: "WH-001", Prefecture: "北海道", ItemCount: 1, Weight: 1000}, false},
+		{"too many items", ShippingParcel{WarehouseID: "WH-001", Prefecture: "北海道", ItemCount: 11, Weight: 1000}, false},
+		{"too light", ShippingParcel{WarehouseID: "WH-001", Prefecture: "北海道", ItemCount: 2, Weight: 999}, false},
+		{"no upper weight bound", ShippingParcel{WarehouseID: "WH-001", Prefecture: "北海道", ItemCount: 2, Weight: 50000}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rule.Matches(tt.parcel); got != tt.expected {
+				t.Errorf("Expected match %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	if catchAll := (&ShippingRule{}); !catchAll.Matches(ShippingParcel{}) {
+		t.Error("Expected a rule without conditions to match every parcel")
+	}
+}
+
+func TestShippingRule_CampaignAndThreshold(t *testing.T) {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	rule := &ShippingRule{Active: true, Fee: 800, FreeShippingThreshold: 3000, StartsAt: &start, EndsAt: &end}
+
+	if rule.IsEffective(start.Add(-time.Second)) || !rule.IsEffective(start) || rule.IsEffective(end) {
+		t.Error("Expected the rule to be effective from its start until just before its end")
+	}
+	rule.Active = false
+	if rule.IsEffective(start) {
+		t.Error("Expected an inactive rule not to be effective")
+	}
+
+	if fee := rule.FeeFor(2999); fee != 800 {
+		t.Errorf("Expected fee 800 below the threshold, got %d", fee)
+	}
+	if fee := rule.FeeFor(3000); fee != 0 {
+		t.Errorf("Expected free shipping at the threshold, got %d", fee)
+	}
+}
+
+func TestShippingRule_Validate(t *testing.T) {
+	valid := ShippingRule{ID: "SHIP-1", Name: "Standard", Fee: 500}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Expected a valid rule, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(r *ShippingRule)
+	}{
+		{"empty name", func(r *ShippingRule) { r.Name = "" }},
+		{"negative fee", func(r *ShippingRule) { r.Fee = -1 }},
+		{"inverted item bounds", func(r *ShippingRule) { r.MinItems, r.MaxItems = 5, 2 }},
+		{"inverted weight bounds", func(r *ShippingRule) { r.MinWeight, r.MaxWeight = 5000, 2000 }},
+		{"campaign ends before it starts", func(r *ShippingRule) {
+			start := time.Now()
+			end := start.Add(-time.Hour)
+			r.StartsAt, r.EndsAt = &start, &end
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := valid
+			tt.modify(&rule)
+			if err := rule.Validate(); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// StockMovementType is why the quantity of a stock record changed
+type StockMovementType string
+
+const (
+	StockMovementSale               StockMovementType = "sale"                // Units shipped for a paid order
+	StockMovementRestock            StockMovementType = "restock"             // Units received from a supplier
+	StockMovementCancellationReturn StockMovementType = "cancellation_return" // Units back from a cancelled or returned order
+	StockMovementTransfer           StockMovementType = "transfer"            // Units moved between warehouses
+	StockMovementAdjustment         StockMovementType = "adjustment"          // Manual correction such as damage or loss
+	StockMovementStocktake          StockMovementType = "stocktake"           // Correction to the counted quantity
+)
+
+// StockMovementTypes lists every movement type
+var StockMovementTypes = []StockMovementType{
+	StockMovementSale, StockMovementRestock, StockMovementCancellationReturn,
+	StockMovementTransfer, StockMovementAdjustment, StockMovementStocktake,
+}
+
+// StockMovement is one entry of the append-only stock ledger. Replaying the movements of a
+// product in a warehouse in order reproduces the quantity of its stock record.
+type StockMovement struct {
+	ID            string            `json:"id"`
+	ProductID     string            `json:"product_id"`
+	WarehouseID   string            `json:"warehouse_id"`
+	Type          StockMovementType `json:"type"`
+	Quantity      int               `json:"quantity"`       // Change in units; negative when units left
+	QuantityAfter int               `json:"quantity_after"` // Quantity of the stock record after the change
+	OrderID       string            `json:"order_id,omitempty"`
+	UserID        string            `json:"user_id,omitempty"` // Acting user; empty for system changes
+	Note          string            `json:"note,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+// NewStockMovement records a change of quantity units to the stock, which already holds the new quantity
+func NewStockMovement(id string, stock *Stock, movementType StockMovementType, quantity int, orderID, userID, note string) (*StockMovement, error) {
+	if id == "" {
+		return nil, errors.New("stock movement ID cannot be empty")
+	}
+	if !IsStockMovementType(movementType) {
+		return nil, fmt.Errorf("unknown stock movement type: %s", movementType)
+	}
+	if quantity == 0 {
+		return nil, errors.New("stock movement quantity cannot be zero")
+	}
+
+	return &StockMovement{
+		ID:            id,
+		ProductID:     stock.ProductID,
+		WarehouseID:   stock.WarehouseID,
+		Type:          movementType,
+		Quantity:      quantity,
+		QuantityAfter: stock.Quantity,
+		OrderID:       orderID,
+		UserID:        userID,
+		Note:          note,
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// IsStockMovementType checks if t is a known movement type
+func IsStockMovementType(t StockMovementType) bool {
+	for _, known := range StockMovementTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// StockReservation holds units of a product in one warehouse for a pending order.
+// The units stay counted in Stock.Reserved until the reservation is committed
+// on payment success, or released on payment failure or expiry.
+type StockReservation struct {
+	ID          string    `json:"id"`
+	OrderID     string    `json:"order_id"`
+	ProductID   string    `json:"product_id"`
+	WarehouseID string    `json:"warehouse_id"`
+	Quantity    int       `json:"quantity"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// NewStockReservation creates a new stock reservation
+func NewStockReservation(id, orderID, productID, warehouseID string, quantity int, expiresAt time.Time) (*StockReservation, error) {
+	if id == "" {
+		return nil, errors.New("reservation ID cannot be empty")
+	}
+	if orderID == "" {
+		return nil, errors.New("order ID cannot be empty")
+	}
+	if productID == "" {
+		return nil, errors.New("product ID cannot be empty")
+	}
+	if warehouseID == "" {
+		return nil, errors.New("warehouse ID cannot be empty")
+	}
+	if quantity <= 0 {
+		return nil, errors.New("reservation quantity must be positive")
+	}
+
+	return &StockReservation{
+		ID:          id,
+		OrderID:     orderID,
+		ProductID:   productID,
+		WarehouseID: warehouseID,
+		Quantity:    quantity,
+		ExpiresAt:   expiresAt,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// IsExpired checks if the reservation has passed its expiry time
+func (r *StockReservation) IsExpired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
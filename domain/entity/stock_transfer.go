@@ -0,0 +1,123 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// StockTransferStatus is where a transfer between warehouses stands
+type StockTransferStatus string
+
+const (
+	StockTransferRequested StockTransferStatus = "requested"  // Units are held in the source warehouse
+	StockTransferInTransit StockTransferStatus = "in_transit" // Units have left the source and are not sellable anywhere
+	StockTransferReceived  StockTransferStatus = "received"   // Units were added to the destination
+	StockTransferCancelled StockTransferStatus = "cancelled"  // The hold was released before shipping
+)
+
+// StockTransferStatuses lists every transfer status
+var StockTransferStatuses = []StockTransferStatus{
+	StockTransferRequested, StockTransferInTransit, StockTransferReceived, StockTransferCancelled,
+}
+
+// ErrInvalidTransferTransition is returned when a transfer cannot move from its current status to the requested one
+var ErrInvalidTransferTransition = errors.New("invalid stock transfer status transition")
+
+// StockTransfer moves units of a product from one warehouse to another
+type StockTransfer struct {
+	ID              string              `json:"id"`
+	ProductID       string              `json:"product_id"`
+	FromWarehouseID string              `json:"from_warehouse_id"`
+	ToWarehouseID   string              `json:"to_warehouse_id"`
+	Quantity        int                 `json:"quantity"`
+	Status          StockTransferStatus `json:"status"`
+	RequestedBy     string              `json:"requested_by"`
+	Note            string              `json:"note,omitempty"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+	ShippedAt       *time.Time          `json:"shipped_at,omitempty"`
+	ReceivedAt      *time.Time          `json:"received_at,omitempty"`
+}
+
+// ParseStockTransferStatus converts a string into a known transfer status
+func ParseStockTransferStatus(s string) (StockTransferStatus, error) {
+	for _, status := range StockTransferStatuses {
+		if string(status) == s {
+			return status, nil
+		}
+	}
+	return "", fmt.Errorf("unknown stock transfer status: %s", s)
+}
+
+// NewStockTransfer creates a requested transfer
+func NewStockTransfer(id, productID, fromWarehouseID, toWarehouseID string, quantity int, requestedBy, note string) (*StockTransfer, error) {
+	if id == "" {
+		return nil, errors.New("stock transfer ID cannot be empty")
+	}
+	if productID == "" {
+		return nil, errors.New("product ID cannot be empty")
+	}
+	if fromWarehouseID == "" || toWarehouseID == "" {
+		return nil, errors.New("source and destination warehouses are required")
+	}
+	if fromWarehouseID == toWarehouseID {
+		return nil, errors.New("source and destination warehouses must differ")
+	}
+	if quantity <= 0 {
+		return nil, errors.New("transfer quantity must be positive")
+	}
+
+	now := time.Now()
+	return &StockTransfer{
+		ID:              id,
+		ProductID:       productID,
+		FromWarehouseID: fromWarehouseID,
+		ToWarehouseID:   toWarehouseID,
+		Quantity:        quantity,
+		Status:          StockTransferRequested,
+		RequestedBy:     requestedBy,
+		Note:            note,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}, nil
+}
+
+// Ship marks a requested transfer as in transit
+func (t *StockTransfer) Ship() error {
+	if err := t.transition(StockTransferRequested, StockTransferInTransit); err != nil {
+		return err
+	}
+	shippedAt := t.UpdatedAt
+	t.ShippedAt = &shippedAt
+	return nil
+}
+
+// Receive marks an in-transit transfer as received at its destination
+func (t *StockTransfer) Receive() error {
+	if err := t.transition(StockTransferInTransit, StockTransferReceived); err != nil {
+		return err
+	}
+	receivedAt := t.UpdatedAt
+	t.ReceivedAt = &receivedAt
+	return nil
+}
+
+// Cancel cancels a transfer that has not shipped yet
+func (t *StockTransfer) Cancel() error {
+	return t.transition(StockTransferRequested, StockTransferCancelled)
+}
+
+// IsOpen reports whether the transfer still holds or carries units
+func (t *StockTransfer) IsOpen() bool {
+	return t.Status == StockTransferRequested || t.Status == StockTransferInTransit
+}
+
+func (t *StockTransfer) transition(from, to StockTransferStatus) error {
+	if t.Status != from {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransferTransition, t.Status, to)
+	}
+	t.Status = to
+	t.UpdatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,161 @@
+package entity
+
+import (
+	"fmt"
+	"sort"
+)
+
+// TaxClass groups products that are taxed at the same rate
+type TaxClass string
+
+const (
+	TaxClassStandard TaxClass = "standard" // Standard consumption tax rate
+	TaxClassReduced  TaxClass = "reduced"  // Reduced rate, e.g. for food and beverages
+)
+
+// TaxRounding is how fractional yen of tax are rounded
+type TaxRounding string
+
+const (
+	TaxRoundingFloor TaxRounding = "floor"
+	TaxRoundingRound TaxRounding = "round" // Half up
+	TaxRoundingCeil  TaxRounding = "ceil"
+)
+
+// TaxRoundingScope is where tax is rounded: on every line, or once per rate on the whole invoice
+type TaxRoundingScope string
+
+const (
+	TaxRoundPerLine    TaxRoundingScope = "line"
+	TaxRoundPerInvoice TaxRoundingScope = "invoice"
+)
+
+// TaxLine is the tax total for one rate on an order, as printed on an invoice
+type TaxLine struct {
+	Rate    int `json:"rate"`    // Percent
+	Taxable int `json:"taxable"` // Sum of the line subtotals taxed at this rate
+	Tax     int `json:"tax"`
+}
+
+// TaxPolicy decides which tax class a product belongs to and computes the tax on an order's lines
+type TaxPolicy interface {
+	// ClassFor returns the tax class of products in a category
+	ClassFor(category string) TaxClass
+
+	// RateFor returns the rate in percent applied to a tax class
+	RateFor(class TaxClass) int
+
+	// Calculate returns the per-rate tax totals of the lines, ordered by rate.
+	// Each line's TaxRate must already be set.
+	Calculate(items []OrderItem) []TaxLine
+}
+
+// RateTaxPolicy is a TaxPolicy with a fixed rate per tax class and a fixed rounding rule
+type RateTaxPolicy struct {
+	rates           map[TaxClass]int
+	categoryClasses map[string]TaxClass
+	rounding        TaxRounding
+	scope           TaxRoundingScope
+}
+
+// NewRateTaxPolicy creates a tax policy. Categories not in categoryClasses are standard rated.
+func NewRateTaxPolicy(rates map[TaxClass]int, categoryClasses map[string]TaxClass, rounding TaxRounding, scope TaxRoundingScope) (*RateTaxPolicy, error) {
+	if _, ok := rates[TaxClassStandard]; !ok {
+		return nil, fmt.Errorf("no rate for tax class %s", TaxClassStandard)
+	}
+	for class, rate := range rates {
+		if rate < 0 || rate > 100 {
+			return nil, fmt.Errorf("invalid rate %d for tax class %s", rate, class)
+		}
+	}
+	for category, class := range categoryClasses {
+		if _, ok := rates[class]; !ok {
+			return nil, fmt.Errorf("category %s uses tax class %s, which has no rate", category, class)
+		}
+	}
+	switch rounding {
+	case TaxRoundingFloor, TaxRoundingRound, TaxRoundingCeil:
+	default:
+		return nil, fmt.Errorf("unknown tax rounding: %s", rounding)
+	}
+	switch scope {
+	case TaxRoundPerLine, TaxRoundPerInvoice:
+	default:
+		return nil, fmt.Errorf("unknown tax rounding scope: %s", scope)
+	}
+
+	return &RateTaxPolicy{
+		rates:           rates,
+		categoryClasses: categoryClasses,
+		rounding:        rounding,
+		scope:           scope,
+	}, nil
+}
+
+// DefaultTaxPolicy returns Japanese consumption tax: 10%, with the 8% reduced rate for food,
+// rounded down once per rate on the invoice
+func DefaultTaxPolicy() *RateTaxPolicy {
+	return &RateTaxPolicy{
+		rates:           map[TaxClass]int{TaxClassStandard: 10, TaxClassReduced: 8},
+		categoryClasses: map[string]TaxClass{"Food": TaxClassReduced},
+		rounding:        TaxRoundingFloor,
+		scope:           TaxRoundPerInvoice,
+	}
+}
+
+// ClassFor returns the tax class of products in a category
+func (p *RateTaxPolicy) ClassFor(category string) TaxClass {
+	if class, ok := p.categoryClasses[category]; ok {
+		return class
+	}
+	return TaxClassStandard
+}
+
+// RateFor returns the rate of a tax class; unknown classes get the standard rate
+func (p *RateTaxPolicy) RateFor(class TaxClass) int {
+	if rate, ok := p.rates[class]; ok {
+		return rate
+	}
+	return p.rates[TaxClassStandard]
+}
+
+// Calculate returns the per-rate tax totals of the lines, ordered by rate
+func (p *RateTaxPolicy) Calculate(items []OrderItem) []TaxLine {
+	byRate := make(map[int]*TaxLine)
+	var rates []int
+	for _, item := range items {
+		line, ok := byRate[item.TaxRate]
+		if !ok {
+			line = &TaxLine{Rate: item.TaxRate}
+			byRate[item.TaxRate] = line
+			rates = append(rates, item.TaxRate)
+		}
+		line.Taxable += item.Subtotal
+		if p.scope == TaxRoundPerLine {
+			line.Tax += p.round(item.Subtotal * item.TaxRate)
+		}
+	}
+
+	sort.Ints(rates)
+	result := make([]TaxLine, 0, len(rates))
+	for _, rate := range rates {
+		line := byRate[rate]
+		if p.scope == TaxRoundPerInvoice {
+			line.Tax = p.round(line.Taxable * rate)
+		}
+		result = append(result, *line)
+	}
+	return result
+}
+
+// round converts an amount times a percentage into whole yen
+func (p *RateTaxPolicy) round(amountTimesRate int) int {
+	switch p.rounding {
+	case TaxRoundingCeil:
+		return (amountTimesRate + 99) / 100
+	case TaxRoundingRound:
+		return (amountTimesRate + 50) / 100
+	default:
+		return amountTimesRate / 100
+	}
+}
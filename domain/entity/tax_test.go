@@ -0,0 +1,93 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRateTaxPolicy_Calculate(t *testing.T) {
+	rates := map[TaxClass]int{TaxClassStandard: 10, TaxClassReduced: 8}
+	// Three reduced lines of 99 yen: 7.92 yen of tax each, 23.76 yen together
+	items := []OrderItem{
+		{Subtotal: 99, TaxRate: 8},
+		{Subtotal: 1005, TaxRate: 10},
+		{Subtotal: 99, TaxRate: 8},
+		{Subtotal: 99, TaxRate: 8},
+	}
+
+	tests := []struct {
+		name     string
+		rounding TaxRounding
+		scope    TaxRoundingScope
+		expected []TaxLine
+	}{
+		{"floor per invoice", TaxRoundingFloor, TaxRoundPerInvoice, []TaxLine{{8, 297, 23}, {10, 1005, 100}}},
+		{"round per invoice", TaxRoundingRound, TaxRoundPerInvoice, []TaxLine{{8, 297, 24}, {10, 1005, 101}}},
+		{"ceil per invoice", TaxRoundingCeil, TaxRoundPerInvoice, []TaxLine{{8, 297, 24}, {10, 1005, 101}}},
+		{"floor per line", TaxRoundingFloor, TaxRoundPerLine, []TaxLine{{8, 297, 21}, {10, 1005, 100}}},
+		{"round per line", TaxRoundingRound, TaxRoundPerLine, []TaxLine{{8, 297, 24}, {10, 1005, 101}}},
+		{"ceil per line", TaxRoundingCeil, TaxRoundPerLine, []TaxLine{{8, 297, 24}, {10, 1005, 101}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewRateTaxPolicy(rates, nil, tt.rounding, tt.scope)
+			if err != nil {
+				t.Fatalf("NewRateTaxPolicy failed: %v", err)
+			}
+			if lines := policy.Calculate(items); !reflect.DeepEqual(lines, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, lines)
+			}
+		})
+	}
+}
+
+func TestNewRateTaxPolicy_Validation(t *testing.T) {
+	tests := []struct {
+		name            string
+		rates           map[TaxClass]int
+		categoryClasses map[string]TaxClass
+		rounding        TaxRounding
+		scope           TaxRoundingScope
+	}{
+		{"missing standard rate", map[TaxClass]int{TaxClassReduced: 8}, nil, TaxRoundingFloor, TaxRoundPerInvoice},
+		{"negative rate", map[TaxClass]int{TaxClassStandard: -1}, nil, TaxRoundingFloor, TaxRoundPerInvoice},
+		{"category without rate", map[TaxClass]int{TaxClassStandard: 10}, map[string]TaxClass{"Food": TaxClassReduced}, TaxRoundingFloor, TaxRoundPerInvoice},
+		{"unknown rounding", map[TaxClass]int{TaxClassStandard: 10}, nil, "banker", TaxRoundPerInvoice},
+		{"unknown scope", map[TaxClass]int{TaxClassStandard: 10}, nil, TaxRoundingFloor, "order"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRateTaxPolicy(tt.rates, tt.categoryClasses, tt.rounding, tt.scope); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestOrder_ReducedRateForFood(t *testing.T) {
+	policy := DefaultTaxPolicy()
+	order, _ := NewOrder("ORD-001", "USER-001")
+	order.SetTaxPolicy(policy)
+	order.AddItemWithTaxClass("P001", "Desk", 1, 3000, policy.ClassFor("Furniture"))
+	order.AddItemWithTaxClass("P002", "Coffee", 2, 1000, policy.ClassFor("Food"))
+
+	if order.Items[1].TaxClass != TaxClassReduced || order.Items[1].TaxRate != 8 {
+		t.Errorf("Expected food to be taxed at the reduced 8%% rate, got %+v", order.Items[1])
+	}
+	// 3000 * 10% + 2000 * 8%
+	if tax := order.GetTaxAmount(); tax != 460 {
+		t.Errorf("Expected tax amount 460, got %d", tax)
+	}
+	if order.TotalPrice != 5460 {
+		t.Errorf("Expected total price 5460, got %d", order.TotalPrice)
+	}
+
+	// Changing the policy re-taxes the existing lines
+	flat, _ := NewRateTaxPolicy(map[TaxClass]int{TaxClassStandard: 10, TaxClassReduced: 10}, nil, TaxRoundingFloor, TaxRoundPerInvoice)
+	order.SetTaxPolicy(flat)
+	if tax := order.GetTaxAmount(); tax != 500 || len(order.TaxLines) != 1 {
+		t.Errorf("Expected a single 10%% tax line of 500, got %d %+v", tax, order.TaxLines)
+	}
+}
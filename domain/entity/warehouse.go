@@ -34,13 +34,29 @@ func NewWarehouse(id, name, location string) (*Warehouse, error) {
 	}, nil
 }
 
+// Update replaces the warehouse's name and location
+func (w *Warehouse) Update(name, location string) error {
+	if name == "" {
+		return errors.New("warehouse name cannot be empty")
+	}
+	w.Name = name
+	w.Location = location
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+// Prefecture returns the prefecture the warehouse's location is in, or an empty string when unknown
+func (w *Warehouse) Prefecture() string {
+	return PrefectureOf(w.Location)
+}
+
 // Stock represents the inventory of a product in a specific warehouse
 type Stock struct {
 	ID          string    `json:"id"`
 	ProductID   string    `json:"product_id"`
 	WarehouseID string    `json:"warehouse_id"`
 	Quantity    int       `json:"quantity"`
-	Reserved    int       `json:"reserved,omitempty"` // For future use: reserved quantity
+	Reserved    int       `json:"reserved,omitempty"` // Quantity held for pending orders, still included in Quantity
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -72,24 +88,83 @@ func NewStock(id, productID, warehouseID string, quantity int) (*Stock, error) {
 	}, nil
 }
 
+// Available returns the quantity that is neither sold nor reserved
+func (s *Stock) Available() int {
+	return s.Quantity - s.Reserved
+}
+
 // CanFulfill checks if the stock can fulfill the requested quantity
 func (s *Stock) CanFulfill(requestedQuantity int) bool {
-	return s.Quantity >= requestedQuantity
+	return s.Available() >= requestedQuantity
 }
 
-// Reduce reduces the stock quantity
+// Reduce reduces the stock quantity without touching reserved units
 func (s *Stock) Reduce(quantity int) error {
 	if quantity <= 0 {
 		return errors.New("reduction quantity must be positive")
 	}
-	if s.Quantity < quantity {
-		return fmt.Errorf("insufficient stock: available=%d, requested=%d", s.Quantity, quantity)
+	if s.Available() < quantity {
+		return fmt.Errorf("insufficient stock: available=%d, requested=%d", s.Available(), quantity)
+	}
+	s.Quantity -= quantity
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// Reserve holds quantity for a pending order so nobody else can take it
+func (s *Stock) Reserve(quantity int) error {
+	if quantity <= 0 {
+		return errors.New("reservation quantity must be positive")
+	}
+	if s.Available() < quantity {
+		return fmt.Errorf("insufficient stock: available=%d, requested=%d", s.Available(), quantity)
+	}
+	s.Reserved += quantity
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// Release gives reserved quantity back to the available pool
+func (s *Stock) Release(quantity int) error {
+	if quantity <= 0 {
+		return errors.New("release quantity must be positive")
+	}
+	if s.Reserved < quantity {
+		return fmt.Errorf("cannot release more than reserved: reserved=%d, requested=%d", s.Reserved, quantity)
 	}
+	s.Reserved -= quantity
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// CommitReservation turns reserved quantity into a deduction once the order is paid
+func (s *Stock) CommitReservation(quantity int) error {
+	if quantity <= 0 {
+		return errors.New("commit quantity must be positive")
+	}
+	if s.Reserved < quantity {
+		return fmt.Errorf("cannot commit more than reserved: reserved=%d, requested=%d", s.Reserved, quantity)
+	}
+	s.Reserved -= quantity
 	s.Quantity -= quantity
 	s.UpdatedAt = time.Now()
 	return nil
 }
 
+// SetQuantity sets the quantity on hand, as after a stocktake. It cannot drop below the units
+// reserved for pending orders.
+func (s *Stock) SetQuantity(quantity int) error {
+	if quantity < 0 {
+		return errors.New("quantity cannot be negative")
+	}
+	if quantity < s.Reserved {
+		return fmt.Errorf("quantity cannot be less than reserved: reserved=%d, requested=%d", s.Reserved, quantity)
+	}
+	s.Quantity = quantity
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
 // Add increases the stock quantity
 func (s *Stock) Add(quantity int) error {
 	if quantity <= 0 {
@@ -104,5 +179,5 @@ func (s *Stock) Add(quantity int) error {
 type StockInfo struct {
 	WarehouseID   string `json:"warehouse_id"`
 	WarehouseName string `json:"warehouse_name"`
-	Quantity      int    `json:"quantity"`
+	Quantity      int    `json:"quantity"` // Available quantity, excluding units reserved for pending orders
 }
\ No newline at end of file
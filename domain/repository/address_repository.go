@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+)
+
+// AddressRepository defines the interface for address book persistence
+type AddressRepository interface {
+	// Create creates a new address
+	Create(ctx context.Context, address *entity.Address) error
+
+	// FindByID finds an address by its ID
+	FindByID(ctx context.Context, id string) (*entity.Address, error)
+
+	// FindByUserID returns a user's addresses, oldest first
+	FindByUserID(ctx context.Context, userID string) ([]*entity.Address, error)
+
+	// Update updates an address; its default flag is changed only through SetDefault
+	Update(ctx context.Context, address *entity.Address) error
+
+	// Delete deletes an address
+	Delete(ctx context.Context, id string) error
+
+	// SetDefault makes an address its user's default and clears the flag on the user's other addresses
+	SetDefault(ctx context.Context, userID, addressID string) error
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+)
+
+// CartRepository defines the interface for shopping cart persistence
+type CartRepository interface {
+	// FindByUserID finds the cart of a user
+	FindByUserID(ctx context.Context, userID string) (*entity.Cart, error)
+
+	// Save creates or replaces the cart of its user
+	Save(ctx context.Context, cart *entity.Cart) error
+
+	// Delete removes the cart of a user; deleting a missing cart is not an error
+	Delete(ctx context.Context, userID string) error
+}
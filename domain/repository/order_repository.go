@@ -2,10 +2,37 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
 )
 
+// OrderSortField is a field orders can be sorted by
+type OrderSortField string
+
+const (
+	OrderSortByCreatedAt  OrderSortField = "created_at"
+	OrderSortByUpdatedAt  OrderSortField = "updated_at"
+	OrderSortByTotalPrice OrderSortField = "total_price"
+)
+
+// OrderFilter narrows down an order search. Zero-valued fields match every order.
+type OrderFilter struct {
+	Status        entity.OrderStatus
+	UserID        string
+	CreatedFrom   time.Time // Inclusive
+	CreatedBefore time.Time // Exclusive
+	CouponCode    string
+	WarehouseID   string // Orders with at least one line allocated from this warehouse
+	// BackorderedProductID selects orders with units of this product waiting for incoming stock
+	BackorderedProductID string
+
+	SortBy     OrderSortField // Defaults to created_at; ties are broken by order ID
+	Descending bool
+	Offset     int
+	Limit      int // Zero means no limit
+}
+
 // OrderRepository defines the interface for order persistence
 type OrderRepository interface {
 	// Create creates a new order
@@ -17,9 +44,12 @@ type OrderRepository interface {
 	// FindByUserID finds orders by user ID
 	FindByUserID(ctx context.Context, userID string) ([]*entity.Order, error)
 
-	// Update updates an order
+	// Update updates an order; its shipping address is fixed when the order is created
 	Update(ctx context.Context, order *entity.Order) error
 
 	// FindAll finds all orders
 	FindAll(ctx context.Context) ([]*entity.Order, error)
+
+	// Search finds one page of the orders matching filter, together with the total number of matches
+	Search(ctx context.Context, filter OrderFilter) ([]*entity.Order, int, error)
 }
\ No newline at end of file
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+)
+
+// PriceHistoryRepository defines the interface for the append-only history of regular prices
+type PriceHistoryRepository interface {
+	// Append adds a price change to the history. Changes are never changed or removed.
+	Append(ctx context.Context, change *entity.PriceChange) error
+	// FindByProduct returns the price changes of a product in the order they were appended
+	FindByProduct(ctx context.Context, productID string) ([]*entity.PriceChange, error)
+}
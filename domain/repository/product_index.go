@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+)
+
+// IndexedProduct is a product as the search index holds it
+type IndexedProduct struct {
+	Product   *entity.Product // Copy of the product; changing it does not change the index
+	Available int             // Units available to order across all warehouses
+	Sold      int             // Units sold and not returned
+}
+
+// ProductIndex keeps the catalog in memory for searching. It is kept in sync with product
+// writes; stock figures are set separately as stock changes.
+type ProductIndex interface {
+	// Put adds a product or replaces the indexed copy of it, keeping its stock figures
+	Put(product *entity.Product)
+
+	// SetStock sets the units of an indexed product available to order and sold
+	SetStock(productID string, available, sold int)
+
+	// Products returns every indexed product
+	Products() []IndexedProduct
+
+	// Match scores the indexed products whose name or description contains every word of the
+	// keyword. Products that do not match are left out.
+	Match(keyword string) map[string]int
+}
@@ -2,22 +2,32 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
 )
 
+// ErrSKUTaken is returned when saving a product with a SKU another product already uses
+var ErrSKUTaken = errors.New("SKU is already used by another product")
+
 // ProductRepository defines the interface for product persistence
 type ProductRepository interface {
-	// Create creates a new product
+	// Create creates a new product. A SKU already used by another product is rejected.
 	Create(ctx context.Context, product *entity.Product) error
 
 	// FindByID finds a product by its ID
 	FindByID(ctx context.Context, id string) (*entity.Product, error)
 
+	// FindBySKU finds a product by its SKU
+	FindBySKU(ctx context.Context, sku string) (*entity.Product, error)
+
 	// FindAll finds all products with optional category filter
 	FindAll(ctx context.Context, category string) ([]*entity.Product, error)
 
-	// Update updates a product
+	// FindVariants finds the variants of a parent product ordered by SKU
+	FindVariants(ctx context.Context, parentID string) ([]*entity.Product, error)
+
+	// Update updates a product. A SKU already used by another product is rejected.
 	Update(ctx context.Context, product *entity.Product) error
 
 	// Note: Stock management is now handled through StockRepository
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+)
+
+// ReorderPointRepository defines the interface for reorder point persistence.
+// A reorder point is identified by its product and warehouse; the warehouse is empty for a product default.
+type ReorderPointRepository interface {
+	// Save creates the reorder point or replaces the one for the same product and warehouse
+	Save(ctx context.Context, reorderPoint *entity.ReorderPoint) error
+	Delete(ctx context.Context, productID, warehouseID string) error
+	// FindAll returns every reorder point ordered by product and warehouse
+	FindAll(ctx context.Context) ([]*entity.ReorderPoint, error)
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+)
+
+// SaleRepository defines the interface for sale persistence
+type SaleRepository interface {
+	Create(ctx context.Context, sale *entity.Sale) error
+	Update(ctx context.Context, sale *entity.Sale) error
+	FindByID(ctx context.Context, id string) (*entity.Sale, error)
+	// FindByProduct returns every sale of a product, cancelled ones included, ordered by start
+	FindByProduct(ctx context.Context, productID string) ([]*entity.Sale, error)
+	// FindEffective returns the sales running at the given time
+	FindEffective(ctx context.Context, at time.Time) ([]*entity.Sale, error)
+}
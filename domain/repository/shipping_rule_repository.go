@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+)
+
+// ShippingRuleRepository defines the interface for shipping rule persistence
+type ShippingRuleRepository interface {
+	// Create creates a new shipping rule
+	Create(ctx context.Context, rule *entity.ShippingRule) error
+
+	// FindByID finds a shipping rule by its ID
+	FindByID(ctx context.Context, id string) (*entity.ShippingRule, error)
+
+	// FindAll returns all shipping rules ordered by priority, then ID
+	FindAll(ctx context.Context) ([]*entity.ShippingRule, error)
+
+	// Update updates a shipping rule
+	Update(ctx context.Context, rule *entity.ShippingRule) error
+
+	// Delete deletes a shipping rule
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+)
+
+// StockMovementFilter selects ledger entries; empty fields match every entry
+type StockMovementFilter struct {
+	ProductID   string
+	WarehouseID string
+	OrderID     string
+}
+
+// StockMovementRepository defines the interface for the append-only stock movement ledger
+type StockMovementRepository interface {
+	// Append adds a movement to the end of the ledger. Movements are never changed or removed.
+	Append(ctx context.Context, movement *entity.StockMovement) error
+	// Find returns the matching movements in the order they were appended
+	Find(ctx context.Context, filter StockMovementFilter) ([]*entity.StockMovement, error)
+}
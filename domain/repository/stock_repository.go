@@ -23,6 +23,8 @@ type StockRepository interface {
 // StockTransaction represents a stock transaction
 type StockTransaction interface {
 	GetStockRepository() StockRepository
+	GetStockMovementRepository() StockMovementRepository
+	GetStockTransferRepository() StockTransferRepository
 	Commit() error
 	Rollback() error
 }
\ No newline at end of file
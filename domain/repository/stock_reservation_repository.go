@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+)
+
+// StockReservationRepository defines the interface for stock reservation persistence
+type StockReservationRepository interface {
+	Create(ctx context.Context, reservation *entity.StockReservation) error
+	Delete(ctx context.Context, id string) error
+	FindByOrderID(ctx context.Context, orderID string) ([]*entity.StockReservation, error)
+	// FindExpired returns reservations whose expiry time is at or before now
+	FindExpired(ctx context.Context, now time.Time) ([]*entity.StockReservation, error)
+}
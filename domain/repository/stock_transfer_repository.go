@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+)
+
+// StockTransferRepository defines the interface for stock transfer persistence
+type StockTransferRepository interface {
+	Create(ctx context.Context, transfer *entity.StockTransfer) error
+	Update(ctx context.Context, transfer *entity.StockTransfer) error
+	FindByID(ctx context.Context, id string) (*entity.StockTransfer, error)
+	// FindAll returns every transfer, newest first
+	FindAll(ctx context.Context) ([]*entity.StockTransfer, error)
+}
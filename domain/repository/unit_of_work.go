@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrTransactionConflict is returned by Commit when data read by the transaction
+// was changed by someone else before it could commit. The whole unit of work can be retried.
+var ErrTransactionConflict = errors.New("transaction conflict: data was modified concurrently")
+
+// ErrStorage is wrapped around failures of the storage itself, such as a lost connection or
+// a failed write, to tell them apart from requests the domain rejects
+var ErrStorage = errors.New("storage error")
+
+// UnitOfWork starts transactions that span the stock, stock movement, stock reservation, coupon and
+// order repositories
+type UnitOfWork interface {
+	Begin(ctx context.Context) (UnitOfWorkTransaction, error)
+}
+
+// UnitOfWorkTransaction exposes repositories bound to a single transaction.
+// Changes made through them become visible to others only after Commit,
+// and are discarded together on Rollback.
+type UnitOfWorkTransaction interface {
+	GetStockRepository() StockRepository
+	GetStockMovementRepository() StockMovementRepository
+	GetStockReservationRepository() StockReservationRepository
+	GetCouponRepository() CouponRepository
+	GetOrderRepository() OrderRepository
+	Commit() error
+	Rollback() error
+}
@@ -23,6 +23,12 @@ type WishlistRepository interface {
 	// FindByProduct gets all wishlist entries for a product
 	FindByProduct(ctx context.Context, productID string) ([]*entity.Wishlist, error)
 
+	// DeleteByProduct removes every wishlist entry for a product and returns how many there were
+	DeleteByProduct(ctx context.Context, productID string) (int, error)
+
+	// FindProductIDs gets the IDs of the products in any wishlist, each once
+	FindProductIDs(ctx context.Context) ([]string, error)
+
 	// CountByUser gets the count of wishlist items for a user
 	CountByUser(ctx context.Context, userID string) (int, error)
 }
\ No newline at end of file
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// ErrShippingAddressRequired is returned when an order is placed by a user without a default address
+// and without choosing one
+var ErrShippingAddressRequired = errors.New("shipping address required: choose an address or set a default one")
+
+// AddressService handles the address books of users
+type AddressService struct {
+	addressRepo repository.AddressRepository
+}
+
+// NewAddressService creates a new address service
+func NewAddressService(addressRepo repository.AddressRepository) *AddressService {
+	return &AddressService{
+		addressRepo: addressRepo,
+	}
+}
+
+// ListAddresses returns a user's addresses, oldest first
+func (s *AddressService) ListAddresses(ctx context.Context, userID string) ([]*entity.Address, error) {
+	addresses, err := s.addressRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if addresses == nil {
+		addresses = []*entity.Address{}
+	}
+	return addresses, nil
+}
+
+// AddAddress adds an address to a user's address book. A user's first address becomes the default.
+func (s *AddressService) AddAddress(ctx context.Context, userID string, fields entity.AddressFields) (*entity.Address, error) {
+	addressID := fmt.Sprintf("ADDR-%d-%d", time.Now().Unix(), time.Now().Nanosecond())
+	address, err := entity.NewAddress(addressID, userID, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.addressRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.addressRepo.Create(ctx, address); err != nil {
+		return nil, fmt.Errorf("failed to save address: %w", err)
+	}
+	if len(existing) == 0 {
+		if err := s.addressRepo.SetDefault(ctx, userID, address.ID); err != nil {
+			return nil, err
+		}
+		address.IsDefault = true
+	}
+	return address, nil
+}
+
+// UpdateAddress replaces the fields of one of a user's addresses. Orders already placed keep
+// the address they were placed with.
+func (s *AddressService) UpdateAddress(ctx context.Context, userID, addressID string, fields entity.AddressFields) (*entity.Address, error) {
+	address, err := s.GetAddress(ctx, userID, addressID)
+	if err != nil {
+		return nil, err
+	}
+	if err := address.Update(fields); err != nil {
+		return nil, err
+	}
+	if err := s.addressRepo.Update(ctx, address); err != nil {
+		return nil, fmt.Errorf("failed to save address: %w", err)
+	}
+	return address, nil
+}
+
+// DeleteAddress removes one of a user's addresses. When the default is removed, the oldest
+// remaining address becomes the default.
+func (s *AddressService) DeleteAddress(ctx context.Context, userID, addressID string) error {
+	address, err := s.GetAddress(ctx, userID, addressID)
+	if err != nil {
+		return err
+	}
+	if err := s.addressRepo.Delete(ctx, address.ID); err != nil {
+		return err
+	}
+	if !address.IsDefault {
+		return nil
+	}
+
+	remaining, err := s.addressRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if len(remaining) == 0 {
+		return nil
+	}
+	return s.addressRepo.SetDefault(ctx, userID, remaining[0].ID)
+}
+
+// SetDefaultAddress makes one of a user's addresses the default
+func (s *AddressService) SetDefaultAddress(ctx context.Context, userID, addressID string) (*entity.Address, error) {
+	if err := s.addressRepo.SetDefault(ctx, userID, addressID); err != nil {
+		return nil, fmt.Errorf("address not found: %s", addressID)
+	}
+	return s.GetAddress(ctx, userID, addressID)
+}
+
+// GetAddress gets one of a user's addresses. Other users' addresses are reported as not found.
+func (s *AddressService) GetAddress(ctx context.Context, userID, addressID string) (*entity.Address, error) {
+	address, err := s.addressRepo.FindByID(ctx, addressID)
+	if err != nil || address.UserID != userID {
+		return nil, fmt.Errorf("address not found: %s", addressID)
+	}
+	return address, nil
+}
+
+// DefaultAddress returns a user's default address, or nil when the user has none
+func (s *AddressService) DefaultAddress(ctx context.Context, userID string) (*entity.Address, error) {
+	addresses, err := s.addressRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, address := range addresses {
+		if address.IsDefault {
+			return address, nil
+		}
+	}
+	return nil, nil
+}
+
+// ShippingAddressFor returns the snapshot of the address an order ships to: the chosen address,
+// or the user's default when addressID is empty
+func (s *AddressService) ShippingAddressFor(ctx context.Context, userID, addressID string) (*entity.ShippingAddress, error) {
+	var address *entity.Address
+	var err error
+	if addressID != "" {
+		address, err = s.GetAddress(ctx, userID, addressID)
+	} else {
+		address, err = s.DefaultAddress(ctx, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if address == nil {
+		return nil, ErrShippingAddressRequired
+	}
+
+	snapshot := address.Snapshot()
+	return &snapshot, nil
+}
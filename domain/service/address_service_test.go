@@ -0,0 +1,65 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/persistence"
+)
+
+func testAddressFields(prefecture string) entity.AddressFields {
+	return entity.AddressFields{
+		Recipient:  "山田 太郎",
+		PostalCode: "100-0001",
+		Prefecture: prefecture,
+		City:       "千代田区",
+		Line1:      "千代田1-1",
+		Phone:      "0312345678",
+	}
+}
+
+func TestAddressService_DefaultAddress(t *testing.T) {
+	ctx := context.Background()
+	addressService := service.NewAddressService(persistence.NewMemoryAddressRepository())
+
+	if _, err := addressService.ShippingAddressFor(ctx, "USER-001", ""); !errors.Is(err, service.ErrShippingAddressRequired) {
+		t.Errorf("Expected ErrShippingAddressRequired without addresses, got %v", err)
+	}
+
+	home, err := addressService.AddAddress(ctx, "USER-001", testAddressFields("東京都"))
+	if err != nil {
+		t.Fatalf("AddAddress failed: %v", err)
+	}
+	office, _ := addressService.AddAddress(ctx, "USER-001", testAddressFields("大阪府"))
+	if !home.IsDefault || office.IsDefault {
+		t.Errorf("Expected only the first address to be the default, got %v and %v", home.IsDefault, office.IsDefault)
+	}
+
+	if _, err := addressService.SetDefaultAddress(ctx, "USER-001", office.ID); err != nil {
+		t.Fatalf("SetDefaultAddress failed: %v", err)
+	}
+	snapshot, _ := addressService.ShippingAddressFor(ctx, "USER-001", "")
+	if snapshot.AddressID != office.ID {
+		t.Errorf("Expected the new default %s, got %s", office.ID, snapshot.AddressID)
+	}
+
+	// Another user's address cannot be used, changed or made their default
+	if _, err := addressService.ShippingAddressFor(ctx, "USER-002", home.ID); err == nil {
+		t.Error("Expected another user's address to be rejected")
+	}
+	if _, err := addressService.SetDefaultAddress(ctx, "USER-002", home.ID); err == nil {
+		t.Error("Expected making another user's address the default to fail")
+	}
+
+	// Deleting the default promotes the remaining address
+	if err := addressService.DeleteAddress(ctx, "USER-001", office.ID); err != nil {
+		t.Fatalf("DeleteAddress failed: %v", err)
+	}
+	addresses, _ := addressService.ListAddresses(ctx, "USER-001")
+	if len(addresses) != 1 || !addresses[0].IsDefault || addresses[0].ID != home.ID {
+		t.Errorf("Expected %s to become the default, got %+v", home.ID, addresses)
+	}
+}
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+)
+
+// Allocation strategy names accepted by NewAllocationStrategy
+const (
+	AllocationStrategyPriority        = "priority"
+	AllocationStrategyNearest         = "nearest"
+	AllocationStrategyFewestShipments = "fewest_shipments"
+)
+
+// maxExactShipmentSearch bounds the number of candidate warehouses the fewest-shipments
+// strategy searches exhaustively; with more it picks warehouses greedily
+const maxExactShipmentSearch = 12
+
+// StockDemand is the quantity of one product an order needs
+type StockDemand struct {
+	ProductID string
+	Quantity  int
+}
+
+// AllocationRequest is everything a strategy needs to decide where an order's stock comes from
+type AllocationRequest struct {
+	Destination string        // Prefecture the order ships to; empty when unknown
+	Demands     []StockDemand // One entry per product
+	// Available is the number of units each warehouse can supply, keyed by product ID and then warehouse ID
+	Available  map[string]map[string]int
+	Warehouses map[string]*entity.Warehouse // Keyed by warehouse ID
+}
+
+// AllocationStrategy decides which warehouses an order's stock is taken from.
+// Allocate returns the allocations per product ID in the order the warehouses are drawn from.
+// It never allocates more than a warehouse has available; units no warehouse can supply are
+// left unallocated.
+type AllocationStrategy interface {
+	Allocate(request AllocationRequest) map[string][]StockAllocation
+}
+
+// NewAllocationStrategy creates the strategy with the given name. priority lists the
+// warehouse IDs the priority strategy draws from first.
+func NewAllocationStrategy(name string, priority []string) (AllocationStrategy, error) {
+	switch name {
+	case AllocationStrategyPriority:
+		return NewPriorityAllocation(priority), nil
+	case AllocationStrategyNearest:
+		return NewNearestAllocation(), nil
+	case AllocationStrategyFewestShipments:
+		return NewFewestShipmentsAllocation(), nil
+	}
+	return nil, fmt.Errorf("unknown allocation strategy %q: must be one of %s", name,
+		strings.Join([]string{AllocationStrategyPriority, AllocationStrategyNearest, AllocationStrategyFewestShipments}, ", "))
+}
+
+// PriorityAllocation draws from warehouses in a fixed order: the listed warehouses first,
+// then any others by ID
+type PriorityAllocation struct {
+	priority []string
+}
+
+// NewPriorityAllocation creates a priority strategy for the given warehouse IDs, most preferred first
+func NewPriorityAllocation(warehouseIDs []string) *PriorityAllocation {
+	return &PriorityAllocation{priority: append([]string(nil), warehouseIDs...)}
+}
+
+// Allocate implements AllocationStrategy
+func (a *PriorityAllocation) Allocate(request AllocationRequest) map[string][]StockAllocation {
+	rank := make(map[string]int, len(a.priority))
+	for i, id := range a.priority {
+		if _, seen := rank[id]; !seen {
+			rank[id] = i
+		}
+	}
+
+	ranking := candidateWarehouses(request)
+	sort.SliceStable(ranking, func(i, j int) bool {
+		ri, iListed := rank[ranking[i]]
+		rj, jListed := rank[ranking[j]]
+		if iListed != jListed {
+			return iListed
+		}
+		return iListed && ri < rj
+	})
+	return fillInOrder(request, ranking)
+}
+
+// NearestAllocation draws from the warehouses closest to the destination first.
+// Warehouses whose location is not in a known prefecture, and every warehouse when the
+// destination is unknown, come after the others in ID order.
+type NearestAllocation struct{}
+
+// NewNearestAllocation creates a nearest-warehouse strategy
+func NewNearestAllocation() *NearestAllocation {
+	return &NearestAllocation{}
+}
+
+// Allocate implements AllocationStrategy
+func (a *NearestAllocation) Allocate(request AllocationRequest) map[string][]StockAllocation {
+	return fillInOrder(request, nearestFirst(request))
+}
+
+// FewestShipmentsAllocation takes the whole order from as few warehouses as possible, so it
+// ships in as few parcels as possible. Among equally small sets of warehouses the nearest are
+// preferred, and within the set the nearest warehouse is drawn from first.
+type FewestShipmentsAllocation struct{}
+
+// NewFewestShipmentsAllocation creates a fewest-shipments strategy
+func NewFewestShipmentsAllocation() *FewestShipmentsAllocation {
+	return &FewestShipmentsAllocation{}
+}
+
+// Allocate implements AllocationStrategy
+func (a *FewestShipmentsAllocation) Allocate(request AllocationRequest) map[string][]StockAllocation {
+	ranking := nearestFirst(request)
+
+	// Only warehouses holding something the order needs are worth shipping from
+	var candidates []string
+	for _, id := range ranking {
+		for _, demand := range request.Demands {
+			if request.Available[demand.ProductID][id] > 0 && demand.Quantity > 0 {
+				candidates = append(candidates, id)
+				break
+			}
+		}
+	}
+
+	// Aim to supply as many units as all candidates together could
+	target := make(map[string]int, len(request.Demands))
+	for _, demand := range request.Demands {
+		target[demand.ProductID] = min(demand.Quantity, sumAvailable(request.Available[demand.ProductID], candidates))
+	}
+
+	var chosen []string
+	if len(candidates) <= maxExactShipmentSearch {
+		chosen = smallestCover(request, candidates, target)
+	} else {
+		chosen = greedyCover(request, candidates, target)
+	}
+	return fillInOrder(request, chosen)
+}
+
+// smallestCover returns the first set of candidates, by size and then by candidate order,
+// that can supply every target
+func smallestCover(request AllocationRequest, candidates []string, target map[string]int) []string {
+	for size := 0; size <= len(candidates); size++ {
+		if chosen := coverOfSize(request, candidates, nil, size, target); chosen != nil {
+			return chosen
+		}
+	}
+	return candidates
+}
+
+// coverOfSize extends chosen with size more candidates until the set supplies every target
+func coverOfSize(request AllocationRequest, candidates, chosen []string, size int, target map[string]int) []string {
+	if size == 0 {
+		if covers(request, chosen, target) {
+			return append([]string{}, chosen...)
+		}
+		return nil
+	}
+	for i := 0; i+size <= len(candidates); i++ {
+		if found := coverOfSize(request, candidates[i+1:], append(chosen, candidates[i]), size-1, target); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// greedyCover repeatedly adds the candidate supplying the most still-missing units
+func greedyCover(request AllocationRequest, candidates []string, target map[string]int) []string {
+	missing := make(map[string]int, len(target))
+	for productID, quantity := range target {
+		missing[productID] = quantity
+	}
+
+	var chosen []string
+	used := make(map[string]bool)
+	for !covers(request, chosen, target) {
+		best, bestUnits := "", 0
+		for _, id := range candidates {
+			if used[id] {
+				continue
+			}
+			units := 0
+			for productID, quantity := range missing {
+				units += min(quantity, request.Available[productID][id])
+			}
+			if units > bestUnits {
+				best, bestUnits = id, units
+			}
+		}
+		if best == "" {
+			break
+		}
+		used[best] = true
+		chosen = append(chosen, best)
+		for productID, quantity := range missing {
+			missing[productID] = quantity - min(quantity, request.Available[productID][best])
+		}
+	}
+
+	// Draw from the chosen warehouses nearest first
+	sort.SliceStable(chosen, func(i, j int) bool { return indexOf(candidates, chosen[i]) < indexOf(candidates, chosen[j]) })
+	return chosen
+}
+
+// covers reports whether the warehouses together can supply every target
+func covers(request AllocationRequest, warehouseIDs []string, target map[string]int) bool {
+	for productID, quantity := range target {
+		if sumAvailable(request.Available[productID], warehouseIDs) < quantity {
+			return false
+		}
+	}
+	return true
+}
+
+// nearestFirst ranks the candidate warehouses by distance to the destination, then by ID
+func nearestFirst(request AllocationRequest) []string {
+	distance := make(map[string]float64)
+	for _, id := range candidateWarehouses(request) {
+		distance[id] = math.Inf(1)
+		if warehouse, ok := request.Warehouses[id]; ok {
+			if km, ok := entity.PrefectureDistance(warehouse.Prefecture(), request.Destination); ok {
+				distance[id] = km
+			}
+		}
+	}
+
+	ranking := candidateWarehouses(request)
+	sort.SliceStable(ranking, func(i, j int) bool { return distance[ranking[i]] < distance[ranking[j]] })
+	return ranking
+}
+
+// candidateWarehouses returns every warehouse in the request in ID order
+func candidateWarehouses(request AllocationRequest) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	add := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for id := range request.Warehouses {
+		add(id)
+	}
+	for _, available := range request.Available {
+		for id := range available {
+			add(id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// fillInOrder takes each demand from the warehouses in the given order
+func fillInOrder(request AllocationRequest, warehouseIDs []string) map[string][]StockAllocation {
+	allocations := make(map[string][]StockAllocation, len(request.Demands))
+	for _, demand := range request.Demands {
+		remaining := demand.Quantity
+		for _, id := range warehouseIDs {
+			if remaining <= 0 {
+				break
+			}
+			take := min(remaining, request.Available[demand.ProductID][id])
+			if take <= 0 {
+				continue
+			}
+			allocations[demand.ProductID] = append(allocations[demand.ProductID], StockAllocation{WarehouseID: id, Quantity: take})
+			remaining -= take
+		}
+	}
+	return allocations
+}
+
+// sumAvailable adds up the units the given warehouses have available
+func sumAvailable(available map[string]int, warehouseIDs []string) int {
+	total := 0
+	for _, id := range warehouseIDs {
+		total += max(available[id], 0)
+	}
+	return total
+}
+
+func indexOf(list []string, value string) int {
+	for i, v := range list {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,159 @@
+package service_test
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/persistence"
+)
+
+// newAllocationStockService stocks two products in warehouses in Tokyo, Osaka and Fukuoka.
+// Tokyo has most of P001, Fukuoka is the only warehouse holding everything the tests order.
+func newAllocationStockService(t *testing.T, strategy service.AllocationStrategy) (*service.StockService, *persistence.MemoryStockRepository) {
+	t.Helper()
+	ctx := context.Background()
+	stockRepo := persistence.NewMemoryStockRepository()
+	warehouseRepo := persistence.NewMemoryWarehouseRepository()
+
+	locations := map[string]string{"WH-001": "東京都港区", "WH-002": "大阪府大阪市", "WH-003": "福岡県福岡市"}
+	stocked := map[string]map[string]int{
+		"P001": {"WH-001": 2, "WH-002": 1, "WH-003": 3},
+		"P002": {"WH-002": 1, "WH-003": 1},
+	}
+	for id, location := range locations {
+		warehouse, _ := entity.NewWarehouse(id, "Warehouse "+id, location)
+		warehouseRepo.Create(ctx, warehouse)
+	}
+	for productID, perWarehouse := range stocked {
+		for warehouseID, quantity := range perWarehouse {
+			stock, _ := entity.NewStock("STK-"+productID+"-"+warehouseID, productID, warehouseID, quantity)
+			stockRepo.Create(ctx, stock)
+		}
+	}
+	return service.NewStockService(stockRepo, warehouseRepo, stockRepo.Ledger(), strategy), stockRepo
+}
+
+func TestStockService_AllocationStrategies(t *testing.T) {
+	type taken map[string][]string // product ID -> "warehouse:quantity" in draw order
+
+	tests := []struct {
+		name        string
+		strategy    service.AllocationStrategy
+		destination string
+		demands     []service.StockDemand
+		want        taken
+		shortfall   int
+	}{
+		{
+			name:        "priority falls back to warehouse ID order",
+			strategy:    service.NewPriorityAllocation(nil),
+			destination: "福岡県",
+			demands:     []service.StockDemand{{ProductID: "P001", Quantity: 3}},
+			want:        taken{"P001": {"WH-001:2", "WH-002:1"}},
+		},
+		{
+			name:        "priority draws from listed warehouses first",
+			strategy:    service.NewPriorityAllocation([]string{"WH-002", "WH-003"}),
+			destination: "東京都",
+			demands:     []service.StockDemand{{ProductID: "P001", Quantity: 5}},
+			want:        taken{"P001": {"WH-002:1", "WH-003:3", "WH-001:1"}},
+		},
+		{
+			name:        "nearest to Fukuoka",
+			strategy:    service.NewNearestAllocation(),
+			destination: "福岡県",
+			demands:     []service.StockDemand{{ProductID: "P001", Quantity: 4}},
+			want:        taken{"P001": {"WH-003:3", "WH-002:1"}},
+		},
+		{
+			name:        "nearest to Hokkaido goes via Tokyo",
+			strategy:    service.NewNearestAllocation(),
+			destination: "北海道",
+			demands:     []service.StockDemand{{ProductID: "P001", Quantity: 3}, {ProductID: "P002", Quantity: 1}},
+			want:        taken{"P001": {"WH-001:2", "WH-002:1"}, "P002": {"WH-002:1"}},
+		},
+		{
+			name:        "nearest without a destination uses warehouse ID order",
+			strategy:    service.NewNearestAllocation(),
+			destination: "",
+			demands:     []service.StockDemand{{ProductID: "P001", Quantity: 3}},
+			want:        taken{"P001": {"WH-001:2", "WH-002:1"}},
+		},
+		{
+			name:        "fewest shipments ships the whole order from one warehouse",
+			strategy:    service.NewFewestShipmentsAllocation(),
+			destination: "東京都",
+			demands:     []service.StockDemand{{ProductID: "P001", Quantity: 3}, {ProductID: "P002", Quantity: 1}},
+			want:        taken{"P001": {"WH-003:3"}, "P002": {"WH-003:1"}},
+		},
+		{
+			name:        "fewest shipments prefers the nearest of equally small sets",
+			strategy:    service.NewFewestShipmentsAllocation(),
+			destination: "東京都",
+			demands:     []service.StockDemand{{ProductID: "P001", Quantity: 1}},
+			want:        taken{"P001": {"WH-001:1"}},
+		},
+		{
+			name:        "fewest shipments draws from the chosen warehouses nearest first",
+			strategy:    service.NewFewestShipmentsAllocation(),
+			destination: "大阪府",
+			demands:     []service.StockDemand{{ProductID: "P001", Quantity: 5}, {ProductID: "P002", Quantity: 2}},
+			want:        taken{"P001": {"WH-002:1", "WH-001:2", "WH-003:2"}, "P002": {"WH-002:1", "WH-003:1"}},
+		},
+		{
+			name:        "units nobody has are left as a shortfall",
+			strategy:    service.NewFewestShipmentsAllocation(),
+			destination: "東京都",
+			demands:     []service.StockDemand{{ProductID: "P002", Quantity: 3}},
+			want:        taken{"P002": {"WH-002:1", "WH-003:1"}},
+			shortfall:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stockService, _ := newAllocationStockService(t, tt.strategy)
+
+			// Planning is repeatable, so the same order always ships the same way
+			for run := 0; run < 5; run++ {
+				plan, err := stockService.PlanAllocation(context.Background(), tt.destination, tt.demands)
+				if err != nil {
+					t.Fatalf("PlanAllocation failed: %v", err)
+				}
+
+				got := taken{}
+				shortfall := 0
+				for _, demand := range tt.demands {
+					for _, allocation := range plan.Allocations[demand.ProductID] {
+						got[demand.ProductID] = append(got[demand.ProductID], allocation.WarehouseID+":"+strconv.Itoa(allocation.Quantity))
+						if allocation.WarehouseName != "Warehouse "+allocation.WarehouseID {
+							t.Errorf("Expected the warehouse name to be filled in, got %q", allocation.WarehouseName)
+						}
+					}
+					shortfall += plan.Shortfalls[demand.ProductID]
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("Expected allocations %v, got %v", tt.want, got)
+				}
+				if shortfall != tt.shortfall {
+					t.Errorf("Expected shortfall %d, got %d", tt.shortfall, shortfall)
+				}
+			}
+		})
+	}
+}
+
+func TestNewAllocationStrategy(t *testing.T) {
+	for _, name := range []string{service.AllocationStrategyPriority, service.AllocationStrategyNearest, service.AllocationStrategyFewestShipments} {
+		if _, err := service.NewAllocationStrategy(name, nil); err != nil {
+			t.Errorf("Expected strategy %s to be accepted, got %v", name, err)
+		}
+	}
+	if _, err := service.NewAllocationStrategy("random", nil); err == nil {
+		t.Error("Expected an unknown strategy to be rejected")
+	}
+}
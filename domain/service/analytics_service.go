@@ -94,13 +94,13 @@ func (s *AnalyticsService) GenerateSalesReport(ctx context.Context) (*SalesRepor
 	}, nil
 }
 
-// calculateSalesSummary calculates total revenue and orders from completed orders
+// calculateSalesSummary calculates total revenue and orders from paid orders
 func (s *AnalyticsService) calculateSalesSummary(orders []*entity.Order) SalesSummary {
 	totalRevenue := 0
 	totalOrders := 0
 
 	for _, order := range orders {
-		if order.Status == entity.OrderStatusCompleted {
+		if order.IsPaid() {
 			totalRevenue += order.TotalPrice
 			totalOrders++
 		}
@@ -119,9 +119,9 @@ func (s *AnalyticsService) calculateTopProducts(orders []*entity.Order) []Produc
 		quantity int
 	})
 
-	// Aggregate product sales from completed orders
+	// Aggregate product sales from paid orders
 	for _, order := range orders {
-		if order.Status == entity.OrderStatusCompleted {
+		if order.IsPaid() {
 			for _, item := range order.Items {
 				if existing, ok := productSales[item.ProductID]; ok {
 					productSales[item.ProductID] = struct {
@@ -203,8 +203,8 @@ func (s *AnalyticsService) calculateCouponAnalytics(orders []*entity.Order) Coup
 	ordersWithCoupon := 0
 
 	for _, order := range orders {
-		// Only count completed orders (exclude payment failed)
-		if order.Status == entity.OrderStatusCompleted {
+		// Only count paid orders (exclude payment failed, cancelled and returned)
+		if order.IsPaid() {
 			totalOrders++
 			if order.AppliedCoupon != "" {
 				ordersWithCoupon++
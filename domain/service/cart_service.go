@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// CartService handles shopping cart business logic
+type CartService struct {
+	cartRepo       repository.CartRepository
+	productRepo    repository.ProductRepository
+	orderService   *OrderService
+	couponService  *CouponService
+	addressService *AddressService
+}
+
+// NewCartService creates a new cart service
+func NewCartService(
+	cartRepo repository.CartRepository,
+	productRepo repository.ProductRepository,
+	orderService *OrderService,
+	couponService *CouponService,
+	addressService *AddressService,
+) *CartService {
+	return &CartService{
+		cartRepo:       cartRepo,
+		productRepo:    productRepo,
+		orderService:   orderService,
+		couponService:  couponService,
+		addressService: addressService,
+	}
+}
+
+// GetCart gets a user's cart; a user without a saved cart gets an empty one
+func (s *CartService) GetCart(ctx context.Context, userID string) (*entity.Cart, error) {
+	cart, err := s.cartRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return entity.NewCart(userID)
+	}
+	return cart, nil
+}
+
+// AddItem adds quantity units of a product to a user's cart
+func (s *CartService) AddItem(ctx context.Context, userID, productID string, quantity int) (*entity.Cart, error) {
+	// Verify the product exists and can be ordered
+	if _, err := s.orderService.FindOrderable(ctx, OrderRequest{ProductID: productID}); err != nil {
+		return nil, err
+	}
+
+	return s.update(ctx, userID, func(cart *entity.Cart) error {
+		return cart.AddItem(productID, quantity)
+	})
+}
+
+// UpdateItem sets the quantity of a product already in a user's cart
+func (s *CartService) UpdateItem(ctx context.Context, userID, productID string, quantity int) (*entity.Cart, error) {
+	return s.update(ctx, userID, func(cart *entity.Cart) error {
+		return cart.SetQuantity(productID, quantity)
+	})
+}
+
+// RemoveItem removes a product from a user's cart
+func (s *CartService) RemoveItem(ctx context.Context, userID, productID string) (*entity.Cart, error) {
+	return s.update(ctx, userID, func(cart *entity.Cart) error {
+		return cart.RemoveItem(productID)
+	})
+}
+
+// ApplyCoupon sets the coupon used at checkout. Only the code itself is validated here;
+// conditions that depend on the cart's contents are reported when the cart is priced.
+func (s *CartService) ApplyCoupon(ctx context.Context, userID, code string) (*entity.Cart, error) {
+	if code == "" {
+		return nil, fmt.Errorf("coupon code cannot be empty")
+	}
+	if _, err := s.couponService.ValidateAndGetCoupon(ctx, code); err != nil {
+		return nil, err
+	}
+
+	return s.update(ctx, userID, func(cart *entity.Cart) error {
+		cart.SetCoupon(code)
+		return nil
+	})
+}
+
+// RemoveCoupon removes the coupon from a user's cart
+func (s *CartService) RemoveCoupon(ctx context.Context, userID string) (*entity.Cart, error) {
+	return s.update(ctx, userID, func(cart *entity.Cart) error {
+		cart.SetCoupon("")
+		return nil
+	})
+}
+
+// PriceCart prices a cart against the current product prices and stock levels, shipped to
+// the user's default address. Lines whose product was archived or has since been split into
+// variants are listed as unavailable and left out of the totals. An empty cart has no quote.
+func (s *CartService) PriceCart(ctx context.Context, cart *entity.Cart) (*OrderQuote, error) {
+	if cart.IsEmpty() {
+		return nil, nil
+	}
+
+	// Without a default address shipping is priced for an unknown destination
+	address, err := s.addressService.ShippingAddressFor(ctx, cart.UserID, "")
+	if err != nil && !errors.Is(err, ErrShippingAddressRequired) {
+		return nil, err
+	}
+
+	items, err := s.OrderableItems(ctx, cart)
+	if err != nil {
+		return nil, err
+	}
+	quote := &OrderQuote{}
+	if len(items) > 0 {
+		requests := make([]OrderRequest, len(items))
+		for i, item := range items {
+			requests[i] = OrderRequest{ProductID: item.ProductID, Quantity: item.Quantity}
+		}
+		quote, err = s.orderService.QuoteOrder(ctx, cart.UserID, requests, cart.CouponCode, address)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Keep the lines in cart order, with the unavailable ones where the user put them
+	priced := make(map[string]QuoteLine, len(quote.Lines))
+	for _, line := range quote.Lines {
+		priced[line.ProductID] = line
+	}
+	quote.Lines = make([]QuoteLine, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		if line, ok := priced[item.ProductID]; ok {
+			quote.Lines = append(quote.Lines, line)
+			continue
+		}
+		line := QuoteLine{ProductID: item.ProductID, Quantity: item.Quantity, Unavailable: true}
+		if product, err := s.productRepo.FindByID(ctx, item.ProductID); err == nil {
+			line.SKU = product.SKU
+			line.ProductName = product.Name
+		}
+		quote.Lines = append(quote.Lines, line)
+	}
+	return quote, nil
+}
+
+// OrderableItems returns the lines of a cart that can still be ordered, skipping those whose
+// product was archived or has since been split into variants
+func (s *CartService) OrderableItems(ctx context.Context, cart *entity.Cart) ([]entity.CartItem, error) {
+	var items []entity.CartItem
+	for _, item := range cart.Items {
+		_, err := s.orderService.FindOrderable(ctx, OrderRequest{ProductID: item.ProductID})
+		switch {
+		case err == nil:
+			items = append(items, item)
+		case errors.Is(err, entity.ErrProductArchived), errors.Is(err, ErrVariantRequired):
+			continue
+		default:
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+// ClearCart deletes a user's cart
+func (s *CartService) ClearCart(ctx context.Context, userID string) error {
+	return s.cartRepo.Delete(ctx, userID)
+}
+
+// update loads a user's cart, applies fn and saves the result
+func (s *CartService) update(ctx context.Context, userID string, fn func(cart *entity.Cart) error) (*entity.Cart, error) {
+	cart, err := s.GetCart(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := fn(cart); err != nil {
+		return nil, err
+	}
+	if err := s.cartRepo.Save(ctx, cart); err != nil {
+		return nil, fmt.Errorf("failed to save cart: %w: %w", repository.ErrStorage, err)
+	}
+	return cart, nil
+}
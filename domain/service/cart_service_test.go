@@ -0,0 +1,58 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/persistence"
+)
+
+func TestCartService_PricesLiveAndKeepsLines(t *testing.T) {
+	ctx := context.Background()
+	f := newOrderFixture(t)
+	cartService := service.NewCartService(persistence.NewMemoryCartRepository(), f.productRepo, f.orderService, service.NewCouponService(f.couponRepo),
+		service.NewAddressService(persistence.NewMemoryAddressRepository()))
+
+	if _, err := cartService.AddItem(ctx, "USER-001", "P404", 1); err == nil {
+		t.Error("Expected adding an unknown product to fail")
+	}
+	cartService.AddItem(ctx, "USER-001", "P001", 1)
+	cart, err := cartService.AddItem(ctx, "USER-001", "P001", 2)
+	if err != nil {
+		t.Fatalf("AddItem failed: %v", err)
+	}
+	if len(cart.Items) != 1 || cart.Items[0].Quantity != 3 {
+		t.Fatalf("Expected one line of 3 units, got %+v", cart.Items)
+	}
+
+	// Only 2 units are in stock, and the price follows the product
+	quote, err := cartService.PriceCart(ctx, cart)
+	if err != nil {
+		t.Fatalf("PriceCart failed: %v", err)
+	}
+	if quote.Lines[0].InStock || quote.Lines[0].UnitPrice != 300 {
+		t.Errorf("Expected an out-of-stock line at 300, got %+v", quote.Lines[0])
+	}
+	product, _ := f.productRepo.FindByID(ctx, "P001")
+	product.Price = 250
+	f.productRepo.Update(ctx, product)
+	cart, _ = cartService.UpdateItem(ctx, "USER-001", "P001", 2)
+	quote, _ = cartService.PriceCart(ctx, cart)
+	if !quote.Lines[0].InStock || quote.Lines[0].UnitPrice != 250 || quote.Subtotal != 500 {
+		t.Errorf("Expected 2 in-stock units at 250, got %+v", quote)
+	}
+
+	if _, err := cartService.ApplyCoupon(ctx, "USER-001", "NOPE"); err == nil {
+		t.Error("Expected an unknown coupon to be rejected")
+	}
+	if _, err := cartService.RemoveItem(ctx, "USER-001", "P404"); !errors.Is(err, entity.ErrCartItemNotFound) {
+		t.Errorf("Expected ErrCartItemNotFound, got %v", err)
+	}
+	cart, _ = cartService.RemoveItem(ctx, "USER-001", "P001")
+	if quote, _ := cartService.PriceCart(ctx, cart); quote != nil {
+		t.Errorf("Expected no quote for an empty cart, got %+v", quote)
+	}
+}
@@ -65,16 +65,38 @@ func (s *CouponService) ApplyCoupon(ctx context.Context, coupon *entity.Coupon,
 	return discount, nil
 }
 
-// RollbackCouponUsage rolls back the usage count of a coupon (used when payment fails)
-func (s *CouponService) RollbackCouponUsage(ctx context.Context, couponCode string) error {
+// RecordCouponUsage increments the usage count of an order's coupon through couponRepo,
+// which is typically bound to the unit of work confirming the order.
+// A coupon that is no longer valid is skipped: its discount was already granted when the order was priced.
+func (s *CouponService) RecordCouponUsage(ctx context.Context, couponRepo repository.CouponRepository, couponCode string) error {
 	if couponCode == "" {
 		return nil // No coupon was used
 	}
 
-	coupon, err := s.couponRepo.FindByCode(ctx, couponCode)
+	coupon, err := couponRepo.FindByCode(ctx, couponCode)
+	if err != nil || !coupon.IsValid(time.Now()) {
+		return nil
+	}
+
+	coupon.IncrementUsage()
+	err = couponRepo.Update(ctx, coupon)
+	if err != nil {
+		return fmt.Errorf("failed to update coupon usage: %w", err)
+	}
+
+	return nil
+}
+
+// RollbackCouponUsage gives back one use of an order's coupon through couponRepo,
+// which is typically bound to the unit of work cancelling the order.
+// A coupon that no longer exists is skipped.
+func (s *CouponService) RollbackCouponUsage(ctx context.Context, couponRepo repository.CouponRepository, couponCode string) error {
+	if couponCode == "" {
+		return nil // No coupon was used
+	}
+
+	coupon, err := couponRepo.FindByCode(ctx, couponCode)
 	if err != nil {
-		// Log the error but don't fail the rollback
-		// In production, this should be logged properly
 		return nil
 	}
 
@@ -82,8 +104,11 @@ func (s *CouponService) RollbackCouponUsage(ctx context.Context, couponCode stri
 	if coupon.UsageCount > 0 {
 		coupon.UsageCount--
 		coupon.UpdatedAt = time.Now()
-		_ = s.couponRepo.Update(ctx, coupon)
+		err = couponRepo.Update(ctx, coupon)
+		if err != nil {
+			return fmt.Errorf("failed to roll back coupon usage: %w", err)
+		}
 	}
 
 	return nil
-}
\ No newline at end of file
+}
@@ -16,170 +16,752 @@ type OrderService struct {
 	productRepo    repository.ProductRepository
 	orderRepo      repository.OrderRepository
 	stockService   *StockService
+	pricing        *PricingService
 	couponService  *CouponService
+	unitOfWork     repository.UnitOfWork
+	taxPolicy      entity.TaxPolicy
+	shipping       ShippingCalculator
+	reservationTTL time.Duration // how long stock stays reserved for a pending order
 }
 
 // NewOrderService creates a new order service
-func NewOrderService(productRepo repository.ProductRepository, orderRepo repository.OrderRepository, stockService *StockService, couponService *CouponService) *OrderService {
+func NewOrderService(productRepo repository.ProductRepository, orderRepo repository.OrderRepository, stockService *StockService, pricing *PricingService, couponService *CouponService, unitOfWork repository.UnitOfWork, taxPolicy entity.TaxPolicy, shipping ShippingCalculator, reservationTTL time.Duration) *OrderService {
 	return &OrderService{
 		productRepo:    productRepo,
 		orderRepo:      orderRepo,
 		stockService:   stockService,
+		pricing:        pricing,
 		couponService:  couponService,
+		unitOfWork:     unitOfWork,
+		taxPolicy:      taxPolicy,
+		shipping:       shipping,
+		reservationTTL: reservationTTL,
 	}
 }
 
-// OrderRequest represents a request to create an order
+// ErrVariantRequired is returned when ordering a product that is sold as variants
+var ErrVariantRequired = errors.New("product has variants: order one of their SKUs")
+
+// OrderRequest represents a request to create an order. The product is named by its ID or,
+// when ProductID is empty, by the SKU of the product or variant.
 type OrderRequest struct {
 	ProductID string
+	SKU       string
 	Quantity  int
 }
 
-// ProcessOrder creates a pending order with stock validation but without reducing stock
-// Stock reduction happens after payment is confirmed
-func (s *OrderService) ProcessOrder(ctx context.Context, userID string, requests []OrderRequest, couponCode string) (*entity.Order, error) {
+// OrderQuote is the price breakdown of an order that has not been placed
+type OrderQuote struct {
+	Lines       []QuoteLine      `json:"lines"`
+	Subtotal    int              `json:"subtotal"`
+	Tax         int              `json:"tax"`
+	TaxLines    []entity.TaxLine `json:"tax_lines"` // Tax totals per rate
+	Discount    int              `json:"discount"`
+	ShippingFee int              `json:"shipping_fee"`
+	// ShippingCharges is the fee of each parcel the order would ship in
+	ShippingCharges []entity.ShippingCharge `json:"shipping_charges"`
+	// ShippingAddress is the destination shipping was priced for; nil when it is unknown
+	ShippingAddress *entity.ShippingAddress `json:"shipping_address,omitempty"`
+	Total           int                     `json:"total"`
+	CouponCode      string                  `json:"coupon_code,omitempty"`  // Set when the coupon was applied
+	CouponError     string                  `json:"coupon_error,omitempty"` // Why the requested coupon could not be applied
+}
+
+// QuoteLine is the price of one requested line
+type QuoteLine struct {
+	ProductID   string `json:"product_id"`
+	SKU         string `json:"sku,omitempty"`
+	ProductName string `json:"product_name"`
+	Quantity    int    `json:"quantity"`
+	UnitPrice   int    `json:"unit_price"`
+	Subtotal    int    `json:"subtotal"`
+	InStock     bool   `json:"in_stock"` // Whether the quantity is currently available
+	// Backorder is set when the missing units would wait for incoming stock instead of failing the order
+	Backorder  bool       `json:"backorder,omitempty"`
+	ExpectedAt *time.Time `json:"expected_available_at,omitempty"` // When backordered units are expected
+	// Unavailable is set for cart lines whose product is no longer sold; they are neither priced nor ordered
+	Unavailable bool `json:"unavailable,omitempty"`
+}
+
+// ProcessOrder creates a pending order and reserves its stock without reducing it.
+// Stock reduction happens after payment is confirmed; until then the reservation
+// keeps other orders from taking the same units, and it expires after the reservation TTL.
+// Units of backorderable products that are not in stock are backordered instead of failing the order.
+// The order keeps a copy of the shipping address; a nil address leaves the destination unknown.
+func (s *OrderService) ProcessOrder(ctx context.Context, userID string, requests []OrderRequest, couponCode string, address *entity.ShippingAddress) (*entity.Order, error) {
 	// Create new order
 	orderID := generateOrderID() // This would be implemented with a proper ID generator
+	backorderable := make(map[string]*entity.Product)
+	weights := make(map[string]int)
+	order, err := s.priceOrder(ctx, orderID, userID, requests, address, func(item entity.OrderItem, product *entity.Product, available bool, totalStock int) error {
+		weights[product.ID] = product.Weight
+		if product.AcceptsBackorders() {
+			backorderable[product.ID] = product
+			return nil
+		}
+		if !available {
+			return fmt.Errorf("insufficient stock for product %s: requested %d, available %d",
+				item.ProductName, item.Quantity, totalStock)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply coupon if provided
+	err = s.applyCoupon(ctx, order, couponCode)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep order in pending status for payment processing
+	// Don't confirm yet - wait for payment
+
+	// Reserve the stock and save the order together
+	err = s.inTransaction(ctx, func(tx repository.UnitOfWorkTransaction) error {
+		return s.reserveAndCreateOrder(ctx, tx, order, backorderable, weights)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.stockService.stockChanged(orderedProducts(order)...)
+
+	return order, nil
+}
+
+// QuoteOrder prices the requested items and coupon exactly as ProcessOrder would, without
+// saving, reserving or charging anything. Lines that are out of stock and coupons that cannot
+// be applied are reported in the quote instead of failing it.
+func (s *OrderService) QuoteOrder(ctx context.Context, userID string, requests []OrderRequest, couponCode string, address *entity.ShippingAddress) (*OrderQuote, error) {
+	var inStock []bool
+	var backorders []*entity.Product // The product of each line whose missing units would be backordered
+	order, err := s.priceOrder(ctx, "", userID, requests, address, func(item entity.OrderItem, product *entity.Product, available bool, totalStock int) error {
+		inStock = append(inStock, available)
+		if !available && product.AcceptsBackorders() {
+			backorders = append(backorders, product)
+		} else {
+			backorders = append(backorders, nil)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &OrderQuote{}
+	if err := s.applyCoupon(ctx, order, couponCode); err != nil {
+		quote.CouponError = err.Error()
+	} else {
+		quote.CouponCode = order.AppliedCoupon
+	}
+
+	for i, item := range order.Items {
+		line := QuoteLine{
+			ProductID:   item.ProductID,
+			SKU:         item.SKU,
+			ProductName: item.ProductName,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.Price,
+			Subtotal:    item.Subtotal,
+			InStock:     inStock[i],
+		}
+		if product := backorders[i]; product != nil {
+			line.Backorder = true
+			line.ExpectedAt = product.ExpectedAvailableAt
+		}
+		quote.Lines = append(quote.Lines, line)
+	}
+	quote.Subtotal = order.GetSubtotal()
+	quote.Tax = order.GetTaxAmount()
+	quote.TaxLines = order.TaxLines
+	quote.Discount = order.DiscountAmount
+	quote.ShippingFee = order.ShippingFee
+	quote.ShippingCharges = order.ShippingCharges
+	quote.ShippingAddress = order.ShippingAddress
+	quote.Total = order.TotalPrice
+	return quote, nil
+}
+
+// priceOrder builds a pending order from the requested items at their current prices,
+// shipped to the given address. checkStock is called with each line, its product and its stock
+// availability, and may reject the line.
+func (s *OrderService) priceOrder(ctx context.Context, orderID, userID string, requests []OrderRequest, address *entity.ShippingAddress,
+	checkStock func(item entity.OrderItem, product *entity.Product, available bool, totalStock int) error) (*entity.Order, error) {
 	order, err := entity.NewOrder(orderID, userID)
 	if err != nil {
 		return nil, err
 	}
+	order.SetTaxPolicy(s.taxPolicy)
+	if address != nil {
+		if err := order.SetShippingAddress(*address); err != nil {
+			return nil, err
+		}
+	}
 
 	// Validate and add items to order
+	weights := make(map[string]int)
 	for _, req := range requests {
-		// Check product exists
-		product, err := s.productRepo.FindByID(ctx, req.ProductID)
-		if err != nil {
-			return nil, fmt.Errorf("product not found: %s", req.ProductID)
-		}
-
-		// Check stock availability across all warehouses
-		available, totalStock, err := s.stockService.CheckAvailability(ctx, req.ProductID, req.Quantity)
+		// Check product exists and is sold
+		product, err := s.FindOrderable(ctx, req)
 		if err != nil {
-			return nil, fmt.Errorf("failed to check stock availability: %w", err)
+			return nil, err
 		}
+		weights[product.ID] = product.Weight
 
-		if !available {
-			return nil, fmt.Errorf("insufficient stock for product %s: requested %d, available %d",
-				product.Name, req.Quantity, totalStock)
+		// Charge the sale price while a sale is running
+		if err := s.pricing.ApplySale(ctx, product); err != nil {
+			return nil, err
 		}
 
 		// Add item to order (without reducing stock)
-		err = order.AddItem(product.ID, product.Name, req.Quantity, product.Price)
+		err = order.AddItemWithTaxClass(product.ID, product.Name, req.Quantity, product.CurrentPrice(), s.taxPolicy.ClassFor(product.Category))
 		if err != nil {
 			return nil, err
 		}
-	}
+		order.Items[len(order.Items)-1].SKU = product.SKU
 
-	// Apply coupon if provided
-	if couponCode != "" {
-		coupon, err := s.couponService.ValidateAndGetCoupon(ctx, couponCode)
+		// Check stock availability across all warehouses
+		available, totalStock, err := s.stockService.CheckAvailability(ctx, product.ID, req.Quantity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check stock availability: %w: %w", repository.ErrStorage, err)
+		}
+		err = checkStock(order.Items[len(order.Items)-1], product, available, totalStock)
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		if coupon != nil {
-			// Calculate discount on (subtotal + tax)
-			baseAmount := order.GetSubtotalWithTax()
-			discountAmount := coupon.CalculateDiscount(baseAmount)
+	// Price shipping for the parcels the stock would currently be reserved from
+	parcels, err := s.planParcels(ctx, order, weights)
+	if err != nil {
+		return nil, err
+	}
+	charges, err := s.shipping.Calculate(ctx, parcels, order.GetSubtotal())
+	if err != nil {
+		return nil, err
+	}
+	order.SetShippingCharges(charges)
+	return order, nil
+}
 
-			// Check minimum order requirement
-			if !coupon.CanApplyToOrder(baseAmount) {
-				return nil, fmt.Errorf("order amount does not meet minimum requirement for coupon %s (minimum: %d yen)",
-					coupon.Code, coupon.MinimumOrder)
-			}
+// planParcels splits the order into one parcel per warehouse its stock would currently be reserved from
+func (s *OrderService) planParcels(ctx context.Context, order *entity.Order, weights map[string]int) ([]entity.ShippingParcel, error) {
+	plan, err := s.stockService.PlanAllocation(ctx, destinationOf(order), orderDemands(order))
+	if err != nil {
+		return nil, err
+	}
+	return parcelsOf(plan, destinationOf(order), weights), nil
+}
 
-			// Apply discount to order
-			order.ApplyCouponDiscount(coupon.Code, discountAmount)
+// parcelsOf splits an allocation plan into one parcel per warehouse it draws from.
+// Units no warehouse has available go into a parcel without a warehouse.
+func parcelsOf(plan *AllocationPlan, prefecture string, weights map[string]int) []entity.ShippingParcel {
+	var parcels []entity.ShippingParcel
+	index := make(map[string]int) // warehouse ID -> position in parcels
+	add := func(warehouseID, productID string, quantity int) {
+		i, ok := index[warehouseID]
+		if !ok {
+			i = len(parcels)
+			index[warehouseID] = i
+			parcels = append(parcels, entity.ShippingParcel{WarehouseID: warehouseID, Prefecture: prefecture})
 		}
+		parcels[i].ItemCount += quantity
+		parcels[i].Weight += quantity * weights[productID]
 	}
 
-	// Keep order in pending status for payment processing
-	// Don't confirm yet - wait for payment
+	for _, demand := range plan.Demands {
+		for _, allocation := range plan.Allocations[demand.ProductID] {
+			add(allocation.WarehouseID, demand.ProductID, allocation.Quantity)
+		}
+		if shortfall := plan.Shortfalls[demand.ProductID]; shortfall > 0 {
+			add("", demand.ProductID, shortfall)
+		}
+	}
+	return parcels
+}
 
-	// Save the order
-	err = s.orderRepo.Create(ctx, order)
+// applyCoupon validates couponCode against the order and applies its discount
+func (s *OrderService) applyCoupon(ctx context.Context, order *entity.Order, couponCode string) error {
+	if couponCode == "" {
+		return nil
+	}
+
+	coupon, err := s.couponService.ValidateAndGetCoupon(ctx, couponCode)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create order: %w", err)
+		return err
 	}
 
-	return order, nil
+	// Calculate discount on (subtotal + tax)
+	baseAmount := order.GetSubtotalWithTax()
+	discountAmount := coupon.CalculateDiscount(baseAmount)
+
+	// Check minimum order requirement
+	if !coupon.CanApplyToOrder(baseAmount) {
+		return fmt.Errorf("order amount does not meet minimum requirement for coupon %s (minimum: %d yen)",
+			coupon.Code, coupon.MinimumOrder)
+	}
+
+	// Apply discount to order
+	order.ApplyCouponDiscount(coupon.Code, discountAmount)
+	return nil
+}
+
+// reserveAndCreateOrder reserves stock for every product in the order and saves the order.
+// The allocation strategy picks the warehouses for the whole order and its destination.
+// Units of the backorderable products, keyed by ID, that no warehouse has are backordered.
+// Shipping is charged for the parcels actually reserved, using the product weights keyed by ID.
+func (s *OrderService) reserveAndCreateOrder(ctx context.Context, tx repository.UnitOfWorkTransaction, order *entity.Order, backorderable map[string]*entity.Product, weights map[string]int) error {
+	// Reserve once per product so repeated lines share a reservation
+	plan, err := s.stockService.PlanAllocationWith(ctx, tx.GetStockRepository(), destinationOf(order), orderDemands(order))
+	if err != nil {
+		return err
+	}
+	for _, demand := range plan.Demands {
+		if product, ok := backorderable[demand.ProductID]; ok {
+			err = order.BackorderProduct(demand.ProductID, plan.Shortfalls[demand.ProductID], product.ExpectedAvailableAt)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if err := plan.CheckAvailable(demand); err != nil {
+			return fmt.Errorf("failed to reserve stock for product %s: %w", productNameOf(order, demand.ProductID), err)
+		}
+	}
+	_, err = s.stockService.ReservePlanWith(ctx, tx.GetStockRepository(), tx.GetStockReservationRepository(),
+		order.ID, plan, time.Now().Add(s.reservationTTL))
+	if err != nil {
+		return err
+	}
+
+	// Stock may have moved since the order was priced, so shipping follows this plan
+	charges, err := s.shipping.Calculate(ctx, parcelsOf(plan, destinationOf(order), weights), order.GetSubtotal())
+	if err != nil {
+		return err
+	}
+	order.SetShippingCharges(charges)
+
+	err = tx.GetOrderRepository().Create(ctx, order)
+	if err != nil {
+		return fmt.Errorf("failed to create order: %w: %w", repository.ErrStorage, err)
+	}
+	return nil
 }
 
-// ConfirmOrderAndReduceStock confirms the order and reduces stock after successful payment
+// ConfirmOrderAndReduceStock confirms the order and reduces stock after successful payment.
+// The order's reservations are converted into deductions, and stock for any reservation that
+// already expired or any backordered unit is allocated afresh; backorderable units still
+// missing stay backordered until stock arrives. Stock, coupon usage and the status change are committed
+// in a single unit of work, so either all of them take effect or none do.
 func (s *OrderService) ConfirmOrderAndReduceStock(ctx context.Context, order *entity.Order) error {
-	// First, increment coupon usage if a coupon was applied
-	if order.AppliedCoupon != "" {
-		coupon, err := s.couponService.ValidateAndGetCoupon(ctx, order.AppliedCoupon)
-		if err == nil && coupon != nil {
-			// Apply the coupon (increments usage count)
-			baseAmount := order.GetSubtotalWithTax()
-			_, err = s.couponService.ApplyCoupon(ctx, coupon, baseAmount)
+	var confirmed entity.Order
+	err := s.inTransaction(ctx, func(tx repository.UnitOfWorkTransaction) error {
+		confirmed = *order
+		confirmed.Items = append([]entity.OrderItem(nil), order.Items...)
+		confirmed.StatusHistory = append([]entity.StatusChange(nil), order.StatusHistory...)
+		return s.confirmOrder(ctx, tx, &confirmed)
+	})
+	if err != nil {
+		return err
+	}
+	s.stockService.stockChanged(orderedProducts(&confirmed)...)
+
+	*order = confirmed
+	return nil
+}
+
+// confirmOrder reduces stock, records coupon usage and confirms the order inside tx.
+// The warehouses each line was fulfilled from are recorded on the order.
+func (s *OrderService) confirmOrder(ctx context.Context, tx repository.UnitOfWorkTransaction, order *entity.Order) error {
+	stockRepo := tx.GetStockRepository()
+	ledger := tx.GetStockMovementRepository()
+
+	allocations, err := s.stockService.CommitReservationsWith(ctx, stockRepo, tx.GetStockReservationRepository(), ledger, order.ID, order.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to commit stock reservations: %w", err)
+	}
+
+	// Allocate whatever the reservations no longer cover
+	var uncovered []StockDemand
+	for _, demand := range orderDemands(order) {
+		for _, allocation := range allocations[demand.ProductID] {
+			demand.Quantity -= allocation.Quantity
+		}
+		if demand.Quantity > 0 {
+			uncovered = append(uncovered, demand)
+		}
+	}
+	if len(uncovered) > 0 {
+		plan, err := s.stockService.PlanAllocationWith(ctx, stockRepo, destinationOf(order), uncovered)
+		if err != nil {
+			return err
+		}
+		for _, demand := range uncovered {
+			expectedAt, backorderable, err := s.backorderTerms(ctx, order, demand.ProductID)
 			if err != nil {
-				// Log error but don't fail the order
-				// In production, this should be logged properly
+				return err
 			}
+			if backorderable {
+				err = order.BackorderProduct(demand.ProductID, plan.Shortfalls[demand.ProductID], expectedAt)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			if err := plan.CheckAvailable(demand); err != nil {
+				return fmt.Errorf("failed to allocate stock for product %s: %w", productNameOf(order, demand.ProductID), err)
+			}
+		}
+		sale := MovementRef{Type: entity.StockMovementSale, OrderID: order.ID, UserID: order.UserID}
+		if err := s.stockService.ReducePlanWith(ctx, stockRepo, ledger, plan, sale); err != nil {
+			return err
+		}
+		for _, demand := range uncovered {
+			allocations[demand.ProductID] = append(allocations[demand.ProductID], plan.Allocations[demand.ProductID]...)
 		}
 	}
+	assignAllocations(order, allocations)
 
-	// Store allocations for potential rollback
-	allocations := make(map[string][]StockAllocation)
+	// Increment coupon usage if a coupon was applied
+	err = s.couponService.RecordCouponUsage(ctx, tx.GetCouponRepository(), order.AppliedCoupon)
+	if err != nil {
+		return err
+	}
 
-	// Allocate stock for each item in the order
-	for _, item := range order.Items {
-		// Double-check stock availability
-		available, totalStock, err := s.stockService.CheckAvailability(ctx, item.ProductID, item.Quantity)
+	// Mark the order as paid
+	err = order.MarkPaid()
+	if err != nil {
+		return err
+	}
+	err = tx.GetOrderRepository().Update(ctx, order)
+	if err != nil {
+		return fmt.Errorf("failed to update order: %w: %w", repository.ErrStorage, err)
+	}
+	return nil
+}
+
+// FailPaymentAndReleaseStock marks the order as payment failed and releases its stock reservations
+func (s *OrderService) FailPaymentAndReleaseStock(ctx context.Context, order *entity.Order) error {
+	var failed entity.Order
+	err := s.inTransaction(ctx, func(tx repository.UnitOfWorkTransaction) error {
+		failed = *order
+		failed.StatusHistory = append([]entity.StatusChange(nil), order.StatusHistory...)
+
+		reservationRepo := tx.GetStockReservationRepository()
+		reservations, err := reservationRepo.FindByOrderID(ctx, order.ID)
 		if err != nil {
-			// Rollback any previous allocations
-			s.rollbackAllocations(ctx, allocations)
-			return fmt.Errorf("failed to check stock: %w", err)
+			return fmt.Errorf("failed to find reservations: %w: %w", repository.ErrStorage, err)
+		}
+		err = s.stockService.ReleaseReservationsWith(ctx, tx.GetStockRepository(), reservationRepo, reservations)
+		if err != nil {
+			return err
 		}
 
-		if !available {
-			// Rollback any previous allocations
-			s.rollbackAllocations(ctx, allocations)
-			return fmt.Errorf("insufficient stock for product %s: requested %d, available %d",
-				item.ProductName, item.Quantity, totalStock)
+		err = failed.FailPayment()
+		if err != nil {
+			return err
+		}
+		err = tx.GetOrderRepository().Update(ctx, &failed)
+		if err != nil {
+			return fmt.Errorf("failed to update order: %w: %w", repository.ErrStorage, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.stockService.stockChanged(orderedProducts(&failed)...)
+
+	*order = failed
+	return nil
+}
+
+// ReleaseExpiredReservations releases every reservation that expired at or before now
+// and returns how many were released. The order itself stays pending; if its payment
+// still succeeds, stock is allocated afresh on confirmation.
+func (s *OrderService) ReleaseExpiredReservations(ctx context.Context, now time.Time) (int, error) {
+	var expired []*entity.StockReservation
+	err := s.inTransaction(ctx, func(tx repository.UnitOfWorkTransaction) error {
+		reservationRepo := tx.GetStockReservationRepository()
+		var err error
+		expired, err = reservationRepo.FindExpired(ctx, now)
+		if err != nil {
+			return fmt.Errorf("failed to find expired reservations: %w: %w", repository.ErrStorage, err)
+		}
+		return s.stockService.ReleaseReservationsWith(ctx, tx.GetStockRepository(), reservationRepo, expired)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(expired) > 0 {
+		var productIDs []string
+		for _, reservation := range expired {
+			productIDs = append(productIDs, reservation.ProductID)
+		}
+		s.stockService.stockChanged(productIDs...)
+	}
+	return len(expired), nil
+}
+
+// CancelOrderAndRestoreStock cancels an order, returns its stock to the warehouses it was
+// taken from and gives back its coupon use, all in one unit of work. actorID is the user
+// cancelling, byAdmin selects the administrator cancellation policy, and note is recorded in
+// the status history and stock ledger. It reports whether the order had been paid, in which
+// case the caller must refund the payment.
+func (s *OrderService) CancelOrderAndRestoreStock(ctx context.Context, orderID, actorID string, byAdmin bool, note string) (*entity.Order, bool, error) {
+	refundDue := false
+	cancelled, err := s.updateOrder(ctx, orderID, func(tx repository.UnitOfWorkTransaction, order *entity.Order) error {
+		if err := order.CanBeCancelledBy(byAdmin); err != nil {
+			return err
 		}
+		refundDue = order.IsPaid()
 
-		// Allocate stock from warehouses
-		itemAllocations, err := s.stockService.AllocateStock(ctx, item.ProductID, item.Quantity)
+		// Unpaid orders still hold reservations
+		stockRepo := tx.GetStockRepository()
+		reservationRepo := tx.GetStockReservationRepository()
+		reservations, err := reservationRepo.FindByOrderID(ctx, order.ID)
 		if err != nil {
-			// Rollback any previous allocations
-			s.rollbackAllocations(ctx, allocations)
-			return fmt.Errorf("failed to allocate stock for product %s: %w", item.ProductName, err)
+			return fmt.Errorf("failed to find reservations: %w: %w", repository.ErrStorage, err)
+		}
+		err = s.stockService.ReleaseReservationsWith(ctx, stockRepo, reservationRepo, reservations)
+		if err != nil {
+			return err
+		}
+
+		// Paid orders already deducted stock from specific warehouses
+		ref := MovementRef{Type: entity.StockMovementCancellationReturn, OrderID: order.ID, UserID: actorID, Note: note}
+		for _, item := range order.Items {
+			err = s.stockService.RestoreOrderItemWith(ctx, stockRepo, tx.GetStockMovementRepository(), item, ref)
+			if err != nil {
+				return err
+			}
+		}
+
+		if refundDue {
+			err = s.couponService.RollbackCouponUsage(ctx, tx.GetCouponRepository(), order.AppliedCoupon)
+			if err != nil {
+				return err
+			}
 		}
 
-		allocations[item.ProductID] = itemAllocations
+		return order.TransitionTo(entity.OrderStatusCancelled, note)
+	})
+	if err != nil {
+		return nil, false, err
 	}
+	s.stockService.stockChanged(orderedProducts(cancelled)...)
+
+	return cancelled, refundDue, nil
+}
+
+// AdvanceFulfilment moves a paid order through picking, shipping and delivery.
+// A tracking number is required when the order is shipped.
+func (s *OrderService) AdvanceFulfilment(ctx context.Context, orderID string, to entity.OrderStatus, trackingNumber, note string) (*entity.Order, error) {
+	return s.updateOrder(ctx, orderID, func(tx repository.UnitOfWorkTransaction, order *entity.Order) error {
+		switch to {
+		case entity.OrderStatusPicking, entity.OrderStatusDelivered:
+			return order.TransitionTo(to, note)
+		case entity.OrderStatusShipped:
+			return order.Ship(trackingNumber, note)
+		}
+		return fmt.Errorf("%w: %s is not a fulfilment status", entity.ErrInvalidTransition, to)
+	})
+}
 
-	// Confirm the order
-	err := order.Confirm()
+// ReturnOrderAndRestoreStock records that a shipped or delivered order came back, returns
+// its stock to the warehouses it was taken from and gives back its coupon use, all in one
+// unit of work. actorID is the user recording the return. The caller must refund the payment afterwards.
+func (s *OrderService) ReturnOrderAndRestoreStock(ctx context.Context, orderID, actorID, note string) (*entity.Order, error) {
+	returned, err := s.updateOrder(ctx, orderID, func(tx repository.UnitOfWorkTransaction, order *entity.Order) error {
+		// Check the transition before touching stock
+		err := order.TransitionTo(entity.OrderStatusReturned, note)
+		if err != nil {
+			return err
+		}
+
+		ref := MovementRef{Type: entity.StockMovementCancellationReturn, OrderID: order.ID, UserID: actorID, Note: note}
+		for _, item := range order.Items {
+			err = s.stockService.RestoreOrderItemWith(ctx, tx.GetStockRepository(), tx.GetStockMovementRepository(), item, ref)
+			if err != nil {
+				return err
+			}
+		}
+		return s.couponService.RollbackCouponUsage(ctx, tx.GetCouponRepository(), order.AppliedCoupon)
+	})
 	if err != nil {
-		// Rollback all allocations if order confirmation fails
-		s.rollbackAllocations(ctx, allocations)
-		return err
+		return nil, err
+	}
+	s.stockService.stockChanged(orderedProducts(returned)...)
+	return returned, nil
+}
+
+// FillBackorders allocates the available stock of the products to the paid orders waiting for
+// it, oldest order first, and returns how many units were allocated. Each product is filled in
+// its own unit of work; an order the stock runs out on keeps the rest of its units backordered.
+func (s *OrderService) FillBackorders(ctx context.Context, productIDs []string) (int, error) {
+	filled := 0
+	var changed []string
+	for _, productID := range productIDs {
+		units := 0
+		err := s.inTransaction(ctx, func(tx repository.UnitOfWorkTransaction) error {
+			var err error
+			units, err = s.fillBackorders(ctx, tx, productID)
+			return err
+		})
+		if err != nil {
+			return filled, err
+		}
+		if units > 0 {
+			filled += units
+			changed = append(changed, productID)
+		}
 	}
+	if len(changed) > 0 {
+		s.stockService.stockChanged(changed...)
+	}
+	return filled, nil
+}
 
-	return nil
+// fillBackorders allocates the available stock of one product to its waiting orders inside tx
+func (s *OrderService) fillBackorders(ctx context.Context, tx repository.UnitOfWorkTransaction, productID string) (int, error) {
+	orderRepo := tx.GetOrderRepository()
+	waiting, _, err := orderRepo.Search(ctx, repository.OrderFilter{
+		Status:               entity.OrderStatusPaid,
+		BackorderedProductID: productID,
+		SortBy:               repository.OrderSortByCreatedAt,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to find backordered orders: %w: %w", repository.ErrStorage, err)
+	}
+
+	filled := 0
+	for _, order := range waiting {
+		demand := StockDemand{ProductID: productID, Quantity: order.BackorderedQuantity(productID)}
+		plan, err := s.stockService.PlanAllocationWith(ctx, tx.GetStockRepository(), destinationOf(order), []StockDemand{demand})
+		if err != nil {
+			return 0, err
+		}
+		allocations := plan.Allocations[productID]
+		if len(allocations) == 0 {
+			break
+		}
+
+		sale := MovementRef{Type: entity.StockMovementSale, OrderID: order.ID, UserID: order.UserID, Note: "backorder filled"}
+		if err := s.stockService.ReducePlanWith(ctx, tx.GetStockRepository(), tx.GetStockMovementRepository(), plan, sale); err != nil {
+			return 0, err
+		}
+		filled += order.FillBackorder(productID, itemAllocations(allocations))
+		if err := orderRepo.Update(ctx, order); err != nil {
+			return 0, fmt.Errorf("failed to update order: %w: %w", repository.ErrStorage, err)
+		}
+		if plan.Shortfalls[productID] > 0 {
+			break
+		}
+	}
+	return filled, nil
+}
+
+// backorderTerms reports whether units of a product on the order may be backordered and when
+// they are expected: the product must accept backorders now, or have been backordered on the
+// order when it was placed
+func (s *OrderService) backorderTerms(ctx context.Context, order *entity.Order, productID string) (*time.Time, bool, error) {
+	product, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, false, fmt.Errorf("product not found: %s", productID)
+	}
+	if product.AcceptsBackorders() {
+		return product.ExpectedAvailableAt, true, nil
+	}
+	for _, item := range order.Items {
+		if item.ProductID == productID && item.Backordered > 0 {
+			return item.ExpectedAt, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// MarkRefunded records that the payment of a cancelled or returned order was refunded
+func (s *OrderService) MarkRefunded(ctx context.Context, orderID, note string) (*entity.Order, error) {
+	return s.updateOrder(ctx, orderID, func(tx repository.UnitOfWorkTransaction, order *entity.Order) error {
+		return order.TransitionTo(entity.OrderStatusRefunded, note)
+	})
+}
+
+// updateOrder re-reads an order inside a unit of work, applies fn to it and saves it.
+// Reading the order in the transaction makes a concurrent status change conflict on commit.
+func (s *OrderService) updateOrder(ctx context.Context, orderID string, fn func(tx repository.UnitOfWorkTransaction, order *entity.Order) error) (*entity.Order, error) {
+	var updated *entity.Order
+	err := s.inTransaction(ctx, func(tx repository.UnitOfWorkTransaction) error {
+		order, err := tx.GetOrderRepository().FindByID(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if err := fn(tx, order); err != nil {
+			return err
+		}
+		err = tx.GetOrderRepository().Update(ctx, order)
+		if err != nil {
+			return fmt.Errorf("failed to update order: %w: %w", repository.ErrStorage, err)
+		}
+
+		updated = order
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
 }
 
-// rollbackAllocations restores stock allocations in case of failure
-func (s *OrderService) rollbackAllocations(ctx context.Context, allocations map[string][]StockAllocation) {
-	for productID, stockAllocations := range allocations {
-		_ = s.stockService.RestoreStock(ctx, productID, stockAllocations)
+// inTransaction runs fn in a new unit of work and commits it. The whole function is
+// retried if the commit loses a race with a concurrent transaction, so fn must not
+// change anything outside the transaction.
+func (s *OrderService) inTransaction(ctx context.Context, fn func(tx repository.UnitOfWorkTransaction) error) error {
+	var err error
+	for attempt := 0; attempt < maxTransactionAttempts; attempt++ {
+		err = s.runTransaction(ctx, fn)
+		if !errors.Is(err, repository.ErrTransactionConflict) {
+			return err
+		}
 	}
+	return err
 }
 
-// rollbackAllocationsAndCoupon restores stock allocations and coupon usage in case of failure
-func (s *OrderService) rollbackAllocationsAndCoupon(ctx context.Context, allocations map[string][]StockAllocation, couponCode string) {
-	// Rollback stock allocations
-	s.rollbackAllocations(ctx, allocations)
+// runTransaction runs one attempt of fn in a new unit of work
+func (s *OrderService) runTransaction(ctx context.Context, fn func(tx repository.UnitOfWorkTransaction) error) error {
+	tx, err := s.unitOfWork.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w: %w", repository.ErrStorage, err)
+	}
+
+	log := &movementLog{StockMovementRepository: tx.GetStockMovementRepository()}
+	err = fn(loggedTransaction{UnitOfWorkTransaction: tx, log: log})
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
 
-	// Rollback coupon usage
-	if couponCode != "" {
-		_ = s.couponService.RollbackCouponUsage(ctx, couponCode)
+	err = tx.Commit()
+	if errors.Is(err, repository.ErrTransactionConflict) {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w: %w", repository.ErrStorage, err)
 	}
+	s.stockService.stockMoved(log.movements)
+	return nil
+}
+
+// loggedTransaction is a unit of work transaction whose ledger appends are kept in log
+type loggedTransaction struct {
+	repository.UnitOfWorkTransaction
+	log *movementLog
+}
+
+// GetStockMovementRepository returns the transaction's ledger through the log
+func (tx loggedTransaction) GetStockMovementRepository() repository.StockMovementRepository {
+	return tx.log
 }
 
 // ValidateOrderItems validates that all requested items can be fulfilled
@@ -189,9 +771,9 @@ func (s *OrderService) ValidateOrderItems(ctx context.Context, requests []OrderR
 			return errors.New("quantity must be positive")
 		}
 
-		product, err := s.productRepo.FindByID(ctx, req.ProductID)
+		product, err := s.FindOrderable(ctx, req)
 		if err != nil {
-			return fmt.Errorf("product not found: %s", req.ProductID)
+			return err
 		}
 
 		if !product.CanFulfillOrder(req.Quantity) {
@@ -201,10 +783,139 @@ func (s *OrderService) ValidateOrderItems(ctx context.Context, requests []OrderR
 	return nil
 }
 
+// FindOrderable finds the product or variant an order line asks for and checks that it can be
+// ordered: neither it nor its parent is archived, and it is not a parent sold as variants
+func (s *OrderService) FindOrderable(ctx context.Context, req OrderRequest) (*entity.Product, error) {
+	var product *entity.Product
+	var err error
+	switch {
+	case req.ProductID != "":
+		if product, err = s.productRepo.FindByID(ctx, req.ProductID); err != nil {
+			return nil, fmt.Errorf("product not found: %s", req.ProductID)
+		}
+		if req.SKU != "" && product.SKU != req.SKU {
+			return nil, fmt.Errorf("SKU %s does not belong to product %s", req.SKU, req.ProductID)
+		}
+	case req.SKU != "":
+		if product, err = s.productRepo.FindBySKU(ctx, req.SKU); err != nil {
+			return nil, fmt.Errorf("product not found: SKU %s", req.SKU)
+		}
+	default:
+		return nil, errors.New("product ID or SKU is required")
+	}
+	if product.IsArchived() {
+		return nil, fmt.Errorf("%w: %s", entity.ErrProductArchived, product.ID)
+	}
+
+	if product.IsVariant() {
+		parent, err := s.productRepo.FindByID(ctx, product.ParentID)
+		if err != nil {
+			return nil, fmt.Errorf("product not found: %s", product.ParentID)
+		}
+		if parent.IsArchived() {
+			return nil, fmt.Errorf("%w: %s", entity.ErrProductArchived, parent.ID)
+		}
+		return product, nil
+	}
+	variants, err := s.productRepo.FindVariants(ctx, product.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find variants: %w", err)
+	}
+	if len(variants) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrVariantRequired, product.ID)
+	}
+	return product, nil
+}
+
+// orderDemands sums the ordered quantity per product, keeping the products in line order
+func orderDemands(order *entity.Order) []StockDemand {
+	var demands []StockDemand
+	index := make(map[string]int) // product ID -> position in demands
+	for _, item := range order.Items {
+		i, seen := index[item.ProductID]
+		if !seen {
+			i = len(demands)
+			index[item.ProductID] = i
+			demands = append(demands, StockDemand{ProductID: item.ProductID})
+		}
+		demands[i].Quantity += item.Quantity
+	}
+	return demands
+}
+
+// orderedProducts returns the IDs of the products on an order, each once
+func orderedProducts(order *entity.Order) []string {
+	var productIDs []string
+	for _, demand := range orderDemands(order) {
+		productIDs = append(productIDs, demand.ProductID)
+	}
+	return productIDs
+}
+
+// destinationOf returns the prefecture the order ships to, or an empty string when it is unknown
+func destinationOf(order *entity.Order) string {
+	if order.ShippingAddress == nil {
+		return ""
+	}
+	return order.ShippingAddress.Prefecture
+}
+
+// productNameOf returns the name of a product as recorded on the order
+func productNameOf(order *entity.Order, productID string) string {
+	for _, item := range order.Items {
+		if item.ProductID == productID {
+			return item.ProductName
+		}
+	}
+	return productID
+}
+
+// assignAllocations splits the per-product allocations across the order lines in line order
+func assignAllocations(order *entity.Order, allocations map[string][]StockAllocation) {
+	remaining := make(map[string][]StockAllocation, len(allocations))
+	for productID, list := range allocations {
+		remaining[productID] = append([]StockAllocation(nil), list...)
+	}
+
+	for i := range order.Items {
+		item := &order.Items[i]
+		item.Allocations = nil
+		needed := item.Quantity - item.Backordered
+		pool := remaining[item.ProductID]
+		for needed > 0 && len(pool) > 0 {
+			take := min(needed, pool[0].Quantity)
+			item.Allocations = append(item.Allocations, entity.ItemAllocation{
+				WarehouseID:   pool[0].WarehouseID,
+				WarehouseName: pool[0].WarehouseName,
+				Quantity:      take,
+			})
+			needed -= take
+			pool[0].Quantity -= take
+			if pool[0].Quantity == 0 {
+				pool = pool[1:]
+			}
+		}
+		remaining[item.ProductID] = pool
+	}
+}
+
+// itemAllocations converts stock allocations into the allocations recorded on order lines
+func itemAllocations(allocations []StockAllocation) []entity.ItemAllocation {
+	result := make([]entity.ItemAllocation, 0, len(allocations))
+	for _, allocation := range allocations {
+		result = append(result, entity.ItemAllocation{
+			WarehouseID:   allocation.WarehouseID,
+			WarehouseName: allocation.WarehouseName,
+			Quantity:      allocation.Quantity,
+		})
+	}
+	return result
+}
+
 // generateOrderID generates a unique order ID
 // This is a placeholder implementation
 func generateOrderID() string {
 	// In a real implementation, this would use a proper ID generator
 	// For now, we'll use a timestamp with random component
 	return fmt.Sprintf("ORD-%d-%d", time.Now().Unix(), rand.Intn(100000))
-}
\ No newline at end of file
+}
@@ -0,0 +1,636 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/persistence"
+)
+
+type orderFixture struct {
+	*stockFixture
+	orderService    *service.OrderService
+	pricing         *service.PricingService
+	reservationRepo *persistence.MemoryStockReservationRepository
+	couponRepo      *persistence.MemoryCouponRepository
+	orderRepo       *persistence.MemoryOrderRepository
+	shippingRules   repository.ShippingRuleRepository
+}
+
+// newOrderFixture sets up an order service selling the desk of newStockFixture, with stock
+// reserved for a minute, no coupons and no shipping rules
+func newOrderFixture(t *testing.T) *orderFixture {
+	t.Helper()
+	f := &orderFixture{
+		stockFixture:    newStockFixture(t),
+		reservationRepo: persistence.NewMemoryStockReservationRepository(),
+		couponRepo:      persistence.NewMemoryCouponRepository(),
+		orderRepo:       persistence.NewMemoryOrderRepository(),
+		shippingRules:   persistence.NewMemoryShippingRuleRepository(),
+	}
+	f.pricing = service.NewPricingService(f.productRepo, persistence.NewMemoryPriceHistoryRepository(), persistence.NewMemorySaleRepository())
+	unitOfWork := persistence.NewMemoryUnitOfWork(f.stockRepo, f.reservationRepo, f.couponRepo, f.orderRepo)
+	f.orderService = service.NewOrderService(f.productRepo, f.orderRepo, f.stockService, f.pricing, service.NewCouponService(f.couponRepo),
+		unitOfWork, entity.DefaultTaxPolicy(), service.NewRuleShippingCalculator(f.shippingRules), time.Minute)
+	return f
+}
+
+func TestOrderService_PendingOrderReservesStock(t *testing.T) {
+	ctx := context.Background()
+	f := newOrderFixture(t)
+
+	first, err := f.orderService.ProcessOrder(ctx, "USER-001", []service.OrderRequest{{ProductID: "P001", Quantity: 2}}, "", nil)
+	if err != nil {
+		t.Fatalf("ProcessOrder failed: %v", err)
+	}
+	if got := f.available(t); got != 0 {
+		t.Errorf("Expected reserved units to be unavailable, got %d available", got)
+	}
+
+	// A second buyer can no longer pass the availability check for the same units
+	if _, err := f.orderService.ProcessOrder(ctx, "USER-002", []service.OrderRequest{{ProductID: "P001", Quantity: 1}}, "", nil); err == nil {
+		t.Fatal("Expected the second order to fail while stock is reserved")
+	}
+
+	if _, total, _ := f.stockService.GetProductStockInfo(ctx, "P001"); total != 0 {
+		t.Errorf("Expected GetProductStockInfo to report 0 available, got %d", total)
+	}
+
+	if err := f.orderService.ConfirmOrderAndReduceStock(ctx, first); err != nil {
+		t.Fatalf("ConfirmOrderAndReduceStock failed: %v", err)
+	}
+	if got := f.quantity(t); got != 0 {
+		t.Errorf("Expected the reservation to become a deduction, got quantity %d", got)
+	}
+	if reservations, _ := f.reservationRepo.FindByOrderID(ctx, first.ID); len(reservations) != 0 {
+		t.Errorf("Expected reservations to be removed after confirmation, got %d", len(reservations))
+	}
+}
+
+func TestOrderService_FailedPaymentReleasesReservation(t *testing.T) {
+	ctx := context.Background()
+	f := newOrderFixture(t)
+
+	order, err := f.orderService.ProcessOrder(ctx, "USER-001", []service.OrderRequest{{ProductID: "P001", Quantity: 2}}, "", nil)
+	if err != nil {
+		t.Fatalf("ProcessOrder failed: %v", err)
+	}
+
+	if err := f.orderService.FailPaymentAndReleaseStock(ctx, order); err != nil {
+		t.Fatalf("FailPaymentAndReleaseStock failed: %v", err)
+	}
+	if order.Status != entity.OrderStatusPaymentFailed {
+		t.Errorf("Expected status %s, got %s", entity.OrderStatusPaymentFailed, order.Status)
+	}
+	if got := f.available(t); got != 2 {
+		t.Errorf("Expected 2 units available again, got %d", got)
+	}
+	if got := f.quantity(t); got != 2 {
+		t.Errorf("Expected quantity to stay 2, got %d", got)
+	}
+}
+
+func TestOrderService_ExpiredReservations(t *testing.T) {
+	tests := []struct {
+		name             string
+		competingOrder   bool
+		wantConfirmErr   bool
+		expectedQuantity int
+	}{
+		{"confirmation allocates afresh after expiry", false, false, 0},
+		{"confirmation fails if the units were sold meanwhile", true, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newOrderFixture(t)
+
+			order, err := f.orderService.ProcessOrder(ctx, "USER-001", []service.OrderRequest{{ProductID: "P001", Quantity: 2}}, "", nil)
+			if err != nil {
+				t.Fatalf("ProcessOrder failed: %v", err)
+			}
+
+			// Nothing has expired yet
+			if released, _ := f.orderService.ReleaseExpiredReservations(ctx, time.Now()); released != 0 {
+				t.Errorf("Expected no reservations to expire yet, released %d", released)
+			}
+
+			released, err := f.orderService.ReleaseExpiredReservations(ctx, time.Now().Add(2*time.Minute))
+			if err != nil {
+				t.Fatalf("ReleaseExpiredReservations failed: %v", err)
+			}
+			if released != 2 {
+				t.Errorf("Expected 2 reservations to be released, got %d", released)
+			}
+			if got := f.available(t); got != 2 {
+				t.Errorf("Expected 2 units available after expiry, got %d", got)
+			}
+
+			if tt.competingOrder {
+				competing, err := f.orderService.ProcessOrder(ctx, "USER-002", []service.OrderRequest{{ProductID: "P001", Quantity: 2}}, "", nil)
+				if err != nil {
+					t.Fatalf("Competing ProcessOrder failed: %v", err)
+				}
+				if err := f.orderService.ConfirmOrderAndReduceStock(ctx, competing); err != nil {
+					t.Fatalf("Competing confirmation failed: %v", err)
+				}
+			}
+
+			err = f.orderService.ConfirmOrderAndReduceStock(ctx, order)
+			if (err != nil) != tt.wantConfirmErr {
+				t.Fatalf("ConfirmOrderAndReduceStock error = %v, wantErr %v", err, tt.wantConfirmErr)
+			}
+			if got := f.quantity(t); got != tt.expectedQuantity {
+				t.Errorf("Expected quantity %d, got %d", tt.expectedQuantity, got)
+			}
+		})
+	}
+}
+
+func TestOrderService_CancelOrderRestoresStock(t *testing.T) {
+	tests := []struct {
+		name          string
+		confirm       bool
+		byAdmin       bool
+		wantRefundDue bool
+	}{
+		{"pending order releases its reservation", false, false, false},
+		{"paid order returns stock to its warehouses", true, false, true},
+		{"admin cancels a paid order", true, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newOrderFixture(t)
+
+			// Two lines of the same product span both warehouses
+			order, err := f.orderService.ProcessOrder(ctx, "USER-001", []service.OrderRequest{
+				{ProductID: "P001", Quantity: 1},
+				{ProductID: "P001", Quantity: 1},
+			}, "", nil)
+			if err != nil {
+				t.Fatalf("ProcessOrder failed: %v", err)
+			}
+			if tt.confirm {
+				if err := f.orderService.ConfirmOrderAndReduceStock(ctx, order); err != nil {
+					t.Fatalf("ConfirmOrderAndReduceStock failed: %v", err)
+				}
+				for i, item := range order.Items {
+					if len(item.Allocations) != 1 || item.Allocations[0].Quantity != 1 || item.Allocations[0].WarehouseName == "" {
+						t.Errorf("Expected line %d to record one named single-unit allocation, got %+v", i, item.Allocations)
+					}
+				}
+			}
+
+			cancelled, refundDue, err := f.orderService.CancelOrderAndRestoreStock(ctx, order.ID, "USER-001", tt.byAdmin, "")
+			if err != nil {
+				t.Fatalf("CancelOrderAndRestoreStock failed: %v", err)
+			}
+			if cancelled.Status != entity.OrderStatusCancelled {
+				t.Errorf("Expected status %s, got %s", entity.OrderStatusCancelled, cancelled.Status)
+			}
+			if refundDue != tt.wantRefundDue {
+				t.Errorf("Expected refundDue %v, got %v", tt.wantRefundDue, refundDue)
+			}
+
+			// Each warehouse gets back exactly the unit it gave
+			for _, id := range []string{"WH-001", "WH-002"} {
+				stock, _ := f.stockRepo.FindByProductAndWarehouse(ctx, "P001", id)
+				if stock.Quantity != 1 || stock.Reserved != 0 {
+					t.Errorf("Expected %s to hold 1 unit with nothing reserved, got %d/%d", id, stock.Quantity, stock.Reserved)
+				}
+			}
+
+			// A second cancellation is rejected
+			if _, _, err := f.orderService.CancelOrderAndRestoreStock(ctx, order.ID, "USER-001", tt.byAdmin, ""); !errors.Is(err, entity.ErrOrderNotCancellable) {
+				t.Errorf("Expected ErrOrderNotCancellable, got %v", err)
+			}
+		})
+	}
+}
+
+func TestOrderService_FulfilmentAndReturn(t *testing.T) {
+	ctx := context.Background()
+	f := newOrderFixture(t)
+
+	order, err := f.orderService.ProcessOrder(ctx, "USER-001", []service.OrderRequest{{ProductID: "P001", Quantity: 2}}, "", nil)
+	if err != nil {
+		t.Fatalf("ProcessOrder failed: %v", err)
+	}
+
+	// Fulfilment cannot start before payment
+	if _, err := f.orderService.AdvanceFulfilment(ctx, order.ID, entity.OrderStatusPicking, "", ""); !errors.Is(err, entity.ErrInvalidTransition) {
+		t.Errorf("Expected ErrInvalidTransition for an unpaid order, got %v", err)
+	}
+	if err := f.orderService.ConfirmOrderAndReduceStock(ctx, order); err != nil {
+		t.Fatalf("ConfirmOrderAndReduceStock failed: %v", err)
+	}
+
+	steps := []struct {
+		to             entity.OrderStatus
+		trackingNumber string
+		wantErr        bool
+	}{
+		{entity.OrderStatusShipped, "TRK-001", true}, // picking comes first
+		{entity.OrderStatusPicking, "", false},
+		{entity.OrderStatusShipped, "", true},
+		{entity.OrderStatusShipped, "TRK-001", false},
+		{entity.OrderStatusRefunded, "", true}, // not a fulfilment status
+		{entity.OrderStatusDelivered, "", false},
+	}
+	for _, step := range steps {
+		_, err := f.orderService.AdvanceFulfilment(ctx, order.ID, step.to, step.trackingNumber, "")
+		if (err != nil) != step.wantErr {
+			t.Errorf("AdvanceFulfilment(%s) error = %v, wantErr %v", step.to, err, step.wantErr)
+		}
+	}
+
+	// Delivered orders are returned, not cancelled
+	if _, _, err := f.orderService.CancelOrderAndRestoreStock(ctx, order.ID, "ADMIN-001", true, ""); !errors.Is(err, entity.ErrOrderNotCancellable) {
+		t.Errorf("Expected ErrOrderNotCancellable for a delivered order, got %v", err)
+	}
+
+	returned, err := f.orderService.ReturnOrderAndRestoreStock(ctx, order.ID, "ADMIN-001", "damaged")
+	if err != nil {
+		t.Fatalf("ReturnOrderAndRestoreStock failed: %v", err)
+	}
+	if returned.Status != entity.OrderStatusReturned || returned.TrackingNumber != "TRK-001" {
+		t.Errorf("Expected returned order keeping tracking TRK-001, got %s with %q", returned.Status, returned.TrackingNumber)
+	}
+	if got := f.quantity(t); got != 2 {
+		t.Errorf("Expected returned units back in stock, got quantity %d", got)
+	}
+	if _, err := f.orderService.ReturnOrderAndRestoreStock(ctx, order.ID, "ADMIN-001", ""); !errors.Is(err, entity.ErrInvalidTransition) {
+		t.Errorf("Expected a second return to be rejected, got %v", err)
+	}
+
+	refunded, err := f.orderService.MarkRefunded(ctx, order.ID, "")
+	if err != nil {
+		t.Fatalf("MarkRefunded failed: %v", err)
+	}
+	expected := []entity.OrderStatus{
+		entity.OrderStatusPending, entity.OrderStatusPaid, entity.OrderStatusPicking, entity.OrderStatusShipped,
+		entity.OrderStatusDelivered, entity.OrderStatusReturned, entity.OrderStatusRefunded,
+	}
+	if len(refunded.StatusHistory) != len(expected) {
+		t.Fatalf("Expected %d history entries, got %+v", len(expected), refunded.StatusHistory)
+	}
+	for i, status := range expected {
+		if refunded.StatusHistory[i].To != status {
+			t.Errorf("History entry %d: expected %s, got %s", i, status, refunded.StatusHistory[i].To)
+		}
+	}
+}
+
+func TestOrderService_QuoteOrder(t *testing.T) {
+	ctx := context.Background()
+	f := newOrderFixture(t)
+
+	flat, _ := entity.NewCoupon("CPN-001", "FLAT100", "", entity.CouponTypeFixed, 100)
+	flat.ValidFrom = time.Now().Add(-time.Hour)
+	f.couponRepo.Create(ctx, flat)
+	large, _ := entity.NewCoupon("CPN-002", "BIG", "", entity.CouponTypeFixed, 100)
+	large.ValidFrom = time.Now().Add(-time.Hour)
+	large.MinimumOrder = 5000
+	f.couponRepo.Create(ctx, large)
+
+	tests := []struct {
+		name            string
+		quantity        int
+		couponCode      string
+		expectedInStock bool
+		expectedTotal   int // subtotal + 10% tax - discount + shipping
+		wantCouponError bool
+	}{
+		{"no coupon", 2, "", true, 600 + 60 + 500, false},
+		{"more than is in stock", 3, "", false, 900 + 90 + 500, false},
+		{"coupon applied", 2, "FLAT100", true, 600 + 60 - 100 + 500, false},
+		{"coupon below its minimum", 2, "BIG", true, 600 + 60 + 500, true},
+		{"unknown coupon", 2, "NOPE", true, 600 + 60 + 500, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := f.orderService.QuoteOrder(ctx, "USER-001", []service.OrderRequest{{ProductID: "P001", Quantity: tt.quantity}}, tt.couponCode, nil)
+			if err != nil {
+				t.Fatalf("QuoteOrder failed: %v", err)
+			}
+			if len(quote.Lines) != 1 || quote.Lines[0].InStock != tt.expectedInStock || quote.Lines[0].Subtotal != 300*tt.quantity {
+				t.Errorf("Expected one line of %d with in_stock=%v, got %+v", 300*tt.quantity, tt.expectedInStock, quote.Lines)
+			}
+			if quote.Total != tt.expectedTotal {
+				t.Errorf("Expected total %d, got %d", tt.expectedTotal, quote.Total)
+			}
+			if (quote.CouponError != "") != tt.wantCouponError {
+				t.Errorf("Expected coupon error %v, got %q", tt.wantCouponError, quote.CouponError)
+			}
+		})
+	}
+
+	if _, err := f.orderService.QuoteOrder(ctx, "USER-001", []service.OrderRequest{{ProductID: "P404", Quantity: 1}}, "", nil); err == nil {
+		t.Error("Expected an unknown product to fail the quote")
+	}
+
+	// Quoting never reserves stock, creates orders or uses coupons
+	if got := f.available(t); got != 2 {
+		t.Errorf("Expected 2 units still available, got %d", got)
+	}
+	if orders, _ := f.orderRepo.FindAll(ctx); len(orders) != 0 {
+		t.Errorf("Expected no orders to be saved, got %d", len(orders))
+	}
+	if coupon, _ := f.couponRepo.FindByCode(ctx, "FLAT100"); coupon.UsageCount != 0 {
+		t.Errorf("Expected coupon usage to stay 0, got %d", coupon.UsageCount)
+	}
+}
+
+func TestOrderService_ShippingRules(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	hourAgo, inAnHour := now.Add(-time.Hour), now.Add(time.Hour)
+	request := []service.OrderRequest{{ProductID: "P001", Quantity: 2}} // One unit from each warehouse
+
+	tests := []struct {
+		name     string
+		rules    []*entity.ShippingRule
+		expected []entity.ShippingCharge
+	}{
+		{
+			name:     "no rules charges the standard fee once",
+			expected: []entity.ShippingCharge{{Fee: 500}},
+		},
+		{
+			name: "one fee per warehouse parcel",
+			rules: []*entity.ShippingRule{
+				{ID: "R1", Name: "Tokyo", Active: true, WarehouseIDs: []string{"WH-001"}, Fee: 300},
+				{ID: "R2", Name: "Any", Active: true, Priority: 1, Fee: 700},
+			},
+			expected: []entity.ShippingCharge{{WarehouseID: "WH-001", RuleID: "R1", Fee: 300}, {WarehouseID: "WH-002", RuleID: "R2", Fee: 700}},
+		},
+		{
+			name: "unmatched parcels share the standard fee",
+			rules: []*entity.ShippingRule{
+				{ID: "R1", Name: "Okinawa", Active: true, Prefectures: []string{"沖縄県"}, Fee: 1500},
+				{ID: "R2", Name: "Heavy", Active: true, MinWeight: 10000, Fee: 2000},
+			},
+			expected: []entity.ShippingCharge{{Fee: 500}},
+		},
+		{
+			name: "campaign waives its fee above the threshold",
+			rules: []*entity.ShippingRule{
+				{ID: "R1", Name: "Campaign", Active: true, Fee: 400, FreeShippingThreshold: 600, StartsAt: &hourAgo, EndsAt: &inAnHour},
+				{ID: "R2", Name: "Expired campaign", Active: true, Priority: -1, Fee: 0, EndsAt: &hourAgo},
+			},
+			expected: []entity.ShippingCharge{{WarehouseID: "WH-001", RuleID: "R1", Fee: 0}, {WarehouseID: "WH-002", RuleID: "R1", Fee: 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newOrderFixture(t)
+			for _, rule := range tt.rules {
+				f.shippingRules.Create(ctx, rule)
+			}
+
+			quote, err := f.orderService.QuoteOrder(ctx, "USER-001", request, "", &entity.ShippingAddress{Prefecture: "東京都"})
+			if err != nil {
+				t.Fatalf("QuoteOrder failed: %v", err)
+			}
+			if !reflect.DeepEqual(quote.ShippingCharges, tt.expected) {
+				t.Errorf("Expected charges %+v, got %+v", tt.expected, quote.ShippingCharges)
+			}
+			fee := 0
+			for _, charge := range tt.expected {
+				fee += charge.Fee
+			}
+			if quote.ShippingFee != fee || quote.Total != quote.Subtotal+quote.Tax+fee {
+				t.Errorf("Expected shipping fee %d in the total, got %+v", fee, quote)
+			}
+		})
+	}
+}
+
+// stockMovingShipping runs move before it first prices shipping, as if stock changed
+// between pricing an order and reserving its stock
+type stockMovingShipping struct {
+	service.ShippingCalculator
+	move func()
+}
+
+func (s *stockMovingShipping) Calculate(ctx context.Context, parcels []entity.ShippingParcel, orderSubtotal int) ([]entity.ShippingCharge, error) {
+	if s.move != nil {
+		s.move()
+		s.move = nil
+	}
+	return s.ShippingCalculator.Calculate(ctx, parcels, orderSubtotal)
+}
+
+func TestOrderService_ChargesShippingForReservedParcels(t *testing.T) {
+	ctx := context.Background()
+	f := newOrderFixture(t)
+	f.shippingRules.Create(ctx, &entity.ShippingRule{ID: "R1", Name: "Tokyo", Active: true, WarehouseIDs: []string{"WH-001"}, Fee: 300})
+	f.shippingRules.Create(ctx, &entity.ShippingRule{ID: "R2", Name: "Any", Active: true, Priority: 1, Fee: 700})
+
+	// The unit in WH-001 is sold elsewhere after the order is priced from it
+	shipping := &stockMovingShipping{ShippingCalculator: service.NewRuleShippingCalculator(f.shippingRules), move: func() {
+		sold := service.MovementRef{Type: entity.StockMovementAdjustment}
+		if _, err := f.stockService.AdjustStockLevel(ctx, "P001", "WH-001", -1, sold); err != nil {
+			t.Fatalf("AdjustStockLevel failed: %v", err)
+		}
+	}}
+	unitOfWork := persistence.NewMemoryUnitOfWork(f.stockRepo, f.reservationRepo, f.couponRepo, f.orderRepo)
+	orderService := service.NewOrderService(f.productRepo, f.orderRepo, f.stockService, f.pricing, service.NewCouponService(f.couponRepo),
+		unitOfWork, entity.DefaultTaxPolicy(), shipping, time.Minute)
+
+	order, err := orderService.ProcessOrder(ctx, "USER-001", []service.OrderRequest{{ProductID: "P001", Quantity: 1}}, "", &entity.ShippingAddress{Prefecture: "東京都"})
+	if err != nil {
+		t.Fatalf("ProcessOrder failed: %v", err)
+	}
+	expected := []entity.ShippingCharge{{WarehouseID: "WH-002", RuleID: "R2", Fee: 700}}
+	saved, _ := f.orderRepo.FindByID(ctx, order.ID)
+	if !reflect.DeepEqual(saved.ShippingCharges, expected) || saved.ShippingFee != 700 {
+		t.Errorf("Expected the parcel reserved from WH-002 to be charged, got %+v", saved.ShippingCharges)
+	}
+	if saved.TotalPrice != saved.GetSubtotalWithTax()+700 {
+		t.Errorf("Expected the total to include the shipping fee of 700, got %d", saved.TotalPrice)
+	}
+}
+
+func TestOrderService_KeepsShippingAddressSnapshot(t *testing.T) {
+	ctx := context.Background()
+	f := newOrderFixture(t)
+	addressService := service.NewAddressService(persistence.NewMemoryAddressRepository())
+
+	address, _ := addressService.AddAddress(ctx, "USER-001", testAddressFields("東京都"))
+	snapshot, _ := addressService.ShippingAddressFor(ctx, "USER-001", "")
+	order, err := f.orderService.ProcessOrder(ctx, "USER-001", []service.OrderRequest{{ProductID: "P001", Quantity: 1}}, "", snapshot)
+	if err != nil {
+		t.Fatalf("ProcessOrder failed: %v", err)
+	}
+	if err := f.orderService.ConfirmOrderAndReduceStock(ctx, order); err != nil {
+		t.Fatalf("ConfirmOrderAndReduceStock failed: %v", err)
+	}
+
+	// Moving house does not redirect orders already placed
+	if _, err := addressService.UpdateAddress(ctx, "USER-001", address.ID, testAddressFields("北海道")); err != nil {
+		t.Fatalf("UpdateAddress failed: %v", err)
+	}
+	saved, _ := f.orderRepo.FindByID(ctx, order.ID)
+	if saved.ShippingAddress == nil || saved.ShippingAddress.Prefecture != "東京都" || saved.ShippingAddress.AddressID != address.ID {
+		t.Errorf("Expected the order to keep its 東京都 address, got %+v", saved.ShippingAddress)
+	}
+
+	// Updating the order does not replace its address either
+	saved.ShippingAddress = &entity.ShippingAddress{Prefecture: "沖縄県"}
+	f.orderRepo.Update(ctx, saved)
+	saved, _ = f.orderRepo.FindByID(ctx, order.ID)
+	if saved.ShippingAddress.Prefecture != "東京都" {
+		t.Errorf("Expected the order update to keep 東京都, got %s", saved.ShippingAddress.Prefecture)
+	}
+}
+
+func TestOrderService_ReservesFromNearestWarehouse(t *testing.T) {
+	ctx := context.Background()
+	stockService, stockRepo := newAllocationStockService(t, service.NewNearestAllocation())
+	productRepo := persistence.NewMemoryProductRepository()
+	for _, id := range []string{"P001", "P002"} {
+		product, _ := entity.NewProduct(id, "Product "+id, 1000, "Furniture")
+		productRepo.Create(ctx, product)
+	}
+	reservationRepo := persistence.NewMemoryStockReservationRepository()
+	couponRepo := persistence.NewMemoryCouponRepository()
+	orderRepo := persistence.NewMemoryOrderRepository()
+	shippingRuleRepo := persistence.NewMemoryShippingRuleRepository()
+	shippingRuleRepo.Create(ctx, &entity.ShippingRule{ID: "SR-FLAT", Name: "Flat rate per parcel", Active: true, Fee: 800})
+	pricing := service.NewPricingService(productRepo, persistence.NewMemoryPriceHistoryRepository(), persistence.NewMemorySaleRepository())
+	orderService := service.NewOrderService(productRepo, orderRepo, stockService, pricing, service.NewCouponService(couponRepo),
+		persistence.NewMemoryUnitOfWork(stockRepo, reservationRepo, couponRepo, orderRepo), entity.DefaultTaxPolicy(),
+		service.NewRuleShippingCalculator(shippingRuleRepo), time.Minute)
+
+	requests := []service.OrderRequest{{ProductID: "P001", Quantity: 2}, {ProductID: "P002", Quantity: 1}}
+	address := &entity.ShippingAddress{Prefecture: "福岡県"}
+
+	// The quote prices the same single parcel the order then reserves
+	quote, err := orderService.QuoteOrder(ctx, "USER-001", requests, "", address)
+	if err != nil {
+		t.Fatalf("QuoteOrder failed: %v", err)
+	}
+	if len(quote.ShippingCharges) != 1 || quote.ShippingCharges[0].WarehouseID != "WH-003" {
+		t.Errorf("Expected one parcel from WH-003, got %+v", quote.ShippingCharges)
+	}
+
+	order, err := orderService.ProcessOrder(ctx, "USER-001", requests, "", address)
+	if err != nil {
+		t.Fatalf("ProcessOrder failed: %v", err)
+	}
+	reservations, _ := reservationRepo.FindByOrderID(ctx, order.ID)
+	for _, reservation := range reservations {
+		if reservation.WarehouseID != "WH-003" {
+			t.Errorf("Expected every reservation in WH-003, got %s for %s", reservation.WarehouseID, reservation.ProductID)
+		}
+	}
+
+	if err := orderService.ConfirmOrderAndReduceStock(ctx, order); err != nil {
+		t.Fatalf("ConfirmOrderAndReduceStock failed: %v", err)
+	}
+	for _, item := range order.Items {
+		if len(item.Allocations) != 1 || item.Allocations[0].WarehouseID != "WH-003" {
+			t.Errorf("Expected %s to ship from WH-003, got %+v", item.ProductID, item.Allocations)
+		}
+	}
+}
+
+func TestOrderService_BackordersFillOldestFirst(t *testing.T) {
+	ctx := context.Background()
+	f := newOrderFixture(t)
+	order := func(userID string, quantity int) *entity.Order {
+		t.Helper()
+		order, err := f.orderService.ProcessOrder(ctx, userID, []service.OrderRequest{{ProductID: "P001", Quantity: quantity}}, "", nil)
+		if err != nil {
+			t.Fatalf("ProcessOrder failed: %v", err)
+		}
+		if err := f.orderService.ConfirmOrderAndReduceStock(ctx, order); err != nil {
+			t.Fatalf("ConfirmOrderAndReduceStock failed: %v", err)
+		}
+		return order
+	}
+	restock := func(quantity int) {
+		t.Helper()
+		ref := service.MovementRef{Type: entity.StockMovementRestock}
+		if _, err := f.stockService.AdjustStockLevel(ctx, "P001", "WH-001", quantity, ref); err != nil {
+			t.Fatalf("AdjustStockLevel failed: %v", err)
+		}
+	}
+	backordered := func(orderID string) int {
+		t.Helper()
+		order, _ := f.orderRepo.FindByID(ctx, orderID)
+		return order.BackorderedQuantity("P001")
+	}
+
+	if _, err := f.orderService.ProcessOrder(ctx, "USER-001", []service.OrderRequest{{ProductID: "P001", Quantity: 3}}, "", nil); err == nil {
+		t.Fatal("Expected an order beyond stock to fail before backorders are allowed")
+	}
+	product, _ := f.productRepo.FindByID(ctx, "P001")
+	expectedAt := time.Now().Add(7 * 24 * time.Hour)
+	if err := product.SetBackorder(entity.BackorderAllowed, &expectedAt); err != nil {
+		t.Fatalf("SetBackorder failed: %v", err)
+	}
+	f.productRepo.Update(ctx, product)
+
+	// The first order takes the 2 units on hand and waits for 1, the second waits for both
+	first := order("USER-001", 3)
+	if first.Status != entity.OrderStatusPaid || first.Items[0].Status != entity.OrderItemBackordered || first.Items[0].Backordered != 1 {
+		t.Fatalf("Expected a paid order with 1 unit backordered, got %+v", first)
+	}
+	if first.Items[0].ExpectedAt == nil || !first.Items[0].ExpectedAt.Equal(expectedAt) {
+		t.Errorf("Expected the line to carry the expected date, got %v", first.Items[0].ExpectedAt)
+	}
+	second := order("USER-002", 2)
+	if backordered(second.ID) != 2 {
+		t.Fatalf("Expected the second order to wait for 2 units, got %d", backordered(second.ID))
+	}
+	if _, err := f.orderService.AdvanceFulfilment(ctx, first.ID, entity.OrderStatusPicking, "", ""); !errors.Is(err, entity.ErrInvalidTransition) {
+		t.Errorf("Expected picking a backordered order to fail, got %v", err)
+	}
+
+	// Incoming stock goes to the oldest order first
+	restock(2)
+	filled, err := f.orderService.FillBackorders(ctx, []string{"P001"})
+	if err != nil {
+		t.Fatalf("FillBackorders failed: %v", err)
+	}
+	if filled != 2 || backordered(first.ID) != 0 || backordered(second.ID) != 1 {
+		t.Errorf("Expected the first order filled and the second waiting for 1, got filled=%d, %d and %d", filled, backordered(first.ID), backordered(second.ID))
+	}
+	if got := f.available(t); got != 0 {
+		t.Errorf("Expected the restock to be used up, got %d available", got)
+	}
+	filledFirst, _ := f.orderRepo.FindByID(ctx, first.ID)
+	if filledFirst.Items[0].Status != entity.OrderItemInStock || len(filledFirst.Items[0].Allocations) != 2 || filledFirst.Items[0].Allocations[0].Quantity != 2 {
+		t.Errorf("Expected the first order's line in stock with WH-001 supplying 2 units, got %+v", filledFirst.Items[0])
+	}
+
+	// Wired to stock changes, restocks fill backorders as they arrive
+	f.stockService.OnStockChanged(func(productIDs []string) {
+		if _, err := f.orderService.FillBackorders(ctx, productIDs); err != nil {
+			t.Errorf("FillBackorders failed: %v", err)
+		}
+	})
+	restock(3)
+	if backordered(second.ID) != 0 {
+		t.Errorf("Expected the restock to fill the second order, got %d waiting", backordered(second.ID))
+	}
+	if got := f.available(t); got != 2 {
+		t.Errorf("Expected 2 units left after filling, got %d available", got)
+	}
+	if discrepancies, _ := f.stockService.VerifyLedger(ctx); len(discrepancies) != 0 {
+		t.Errorf("Expected the ledger to match the stock, got %+v", discrepancies)
+	}
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// ErrSaleOverlaps is returned when a sale would run at the same time as another sale of the product
+var ErrSaleOverlaps = errors.New("sale overlaps another sale of the product")
+
+// pricingSeq makes price change and sale IDs generated in the same nanosecond unique
+var pricingSeq atomic.Uint64
+
+// PricingService keeps the history of regular prices and the sales that temporarily replace them
+type PricingService struct {
+	productRepo repository.ProductRepository
+	historyRepo repository.PriceHistoryRepository
+	saleRepo    repository.SaleRepository
+}
+
+// NewPricingService creates a new pricing service
+func NewPricingService(productRepo repository.ProductRepository, historyRepo repository.PriceHistoryRepository, saleRepo repository.SaleRepository) *PricingService {
+	return &PricingService{
+		productRepo: productRepo,
+		historyRepo: historyRepo,
+		saleRepo:    saleRepo,
+	}
+}
+
+// ApplySales fills in the sale each product is currently sold at
+func (s *PricingService) ApplySales(ctx context.Context, products []*entity.Product) error {
+	sales, err := s.saleRepo.FindEffective(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to find sales: %w", err)
+	}
+	byProduct := make(map[string]*entity.Sale)
+	for _, sale := range sales {
+		byProduct[sale.ProductID] = sale
+	}
+	for _, product := range products {
+		product.ApplySale(byProduct[product.ID])
+	}
+	return nil
+}
+
+// ApplySale fills in the sale the product is currently sold at
+func (s *PricingService) ApplySale(ctx context.Context, product *entity.Product) error {
+	sales, err := s.saleRepo.FindByProduct(ctx, product.ID)
+	if err != nil {
+		return fmt.Errorf("failed to find sales: %w", err)
+	}
+	now := time.Now()
+	var running *entity.Sale
+	for _, sale := range sales {
+		if sale.IsEffective(now) {
+			running = sale
+		}
+	}
+	product.ApplySale(running)
+	return nil
+}
+
+// RecordPriceChange adds a change of a product's regular price to its history; an unchanged
+// price is not recorded
+func (s *PricingService) RecordPriceChange(ctx context.Context, productID string, previousPrice, price int, userID string) error {
+	if price == previousPrice {
+		return nil
+	}
+	change, err := entity.NewPriceChange(generatePricingID("PRC"), productID, previousPrice, price, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.historyRepo.Append(ctx, change); err != nil {
+		return fmt.Errorf("failed to record price change: %w", err)
+	}
+	return nil
+}
+
+// PriceHistory lists the changes of a product's regular price, oldest first
+func (s *PricingService) PriceHistory(ctx context.Context, productID string) ([]*entity.PriceChange, error) {
+	if _, err := s.productRepo.FindByID(ctx, productID); err != nil {
+		return nil, fmt.Errorf("product not found: %s", productID)
+	}
+	changes, err := s.historyRepo.FindByProduct(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find price history: %w", err)
+	}
+	if changes == nil {
+		changes = []*entity.PriceChange{}
+	}
+	return changes, nil
+}
+
+// ScheduleSale schedules a sale of a product at price from startsAt until endsAt. The sale price
+// must be below the regular price, the sale must not have ended already and it must not overlap
+// another sale of the product.
+func (s *PricingService) ScheduleSale(ctx context.Context, productID string, price int, startsAt, endsAt time.Time, userID string) (*entity.Sale, error) {
+	product, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %s", productID)
+	}
+	if price >= product.Price {
+		return nil, fmt.Errorf("sale price must be below the regular price of %d", product.Price)
+	}
+	if !endsAt.After(time.Now()) {
+		return nil, errors.New("sale end must be in the future")
+	}
+	sale, err := entity.NewSale(generatePricingID("SALE"), productID, price, startsAt, endsAt, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sales, err := s.saleRepo.FindByProduct(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sales: %w", err)
+	}
+	for _, other := range sales {
+		if sale.Overlaps(other) {
+			return nil, fmt.Errorf("%w: %s", ErrSaleOverlaps, other.ID)
+		}
+	}
+	if err := s.saleRepo.Create(ctx, sale); err != nil {
+		return nil, fmt.Errorf("failed to create sale: %w", err)
+	}
+	return sale, nil
+}
+
+// CancelSale cancels a sale of a product that has not ended yet
+func (s *PricingService) CancelSale(ctx context.Context, productID, saleID string) (*entity.Sale, error) {
+	sale, err := s.saleRepo.FindByID(ctx, saleID)
+	if err != nil || sale.ProductID != productID {
+		return nil, fmt.Errorf("sale not found: %s", saleID)
+	}
+	if err := sale.Cancel(time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.saleRepo.Update(ctx, sale); err != nil {
+		return nil, fmt.Errorf("failed to update sale: %w", err)
+	}
+	return sale, nil
+}
+
+// ListSales lists every sale of a product, cancelled ones included, ordered by start
+func (s *PricingService) ListSales(ctx context.Context, productID string) ([]*entity.Sale, error) {
+	if _, err := s.productRepo.FindByID(ctx, productID); err != nil {
+		return nil, fmt.Errorf("product not found: %s", productID)
+	}
+	sales, err := s.saleRepo.FindByProduct(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sales: %w", err)
+	}
+	if sales == nil {
+		sales = []*entity.Sale{}
+	}
+	return sales, nil
+}
+
+// generatePricingID generates a unique ID for a price change or sale
+func generatePricingID(prefix string) string {
+	return fmt.Sprintf("%s-%d-%d", prefix, time.Now().UnixNano(), pricingSeq.Add(1))
+}
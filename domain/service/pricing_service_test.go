@@ -0,0 +1,78 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/persistence"
+)
+
+func TestPricingService_SaleIsCharged(t *testing.T) {
+	f := newOrderFixture(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	// A sale starting tomorrow changes nothing yet
+	if _, err := f.pricing.ScheduleSale(ctx, "P001", 200, now.Add(24*time.Hour), now.Add(48*time.Hour), "USR-ADMIN"); err != nil {
+		t.Fatalf("ScheduleSale failed: %v", err)
+	}
+	running, err := f.pricing.ScheduleSale(ctx, "P001", 250, now.Add(-time.Hour), now.Add(time.Hour), "USR-ADMIN")
+	if err != nil {
+		t.Fatalf("ScheduleSale failed: %v", err)
+	}
+	if _, err := f.pricing.ScheduleSale(ctx, "P001", 240, now, now.Add(30*time.Hour), ""); !errors.Is(err, service.ErrSaleOverlaps) {
+		t.Errorf("Expected an overlapping sale to be rejected, got %v", err)
+	}
+	if _, err := f.pricing.ScheduleSale(ctx, "P001", 300, now, now.Add(time.Hour), ""); err == nil {
+		t.Error("Expected a sale price at the regular price to be rejected")
+	}
+
+	order, err := f.orderService.ProcessOrder(ctx, "USER-001", []service.OrderRequest{{ProductID: "P001", Quantity: 1}}, "", nil)
+	if err != nil {
+		t.Fatalf("ProcessOrder failed: %v", err)
+	}
+	if order.Items[0].Price != 250 {
+		t.Errorf("Expected the sale price of 250 to be charged, got %d", order.Items[0].Price)
+	}
+
+	// Once the sale is cancelled the regular price applies again
+	if _, err := f.pricing.CancelSale(ctx, "P001", running.ID); err != nil {
+		t.Fatalf("CancelSale failed: %v", err)
+	}
+	desk, _ := f.productRepo.FindByID(ctx, "P001")
+	f.pricing.ApplySale(ctx, desk)
+	if desk.SalePrice != nil || desk.CurrentPrice() != 300 {
+		t.Errorf("Expected the regular price of 300, got %d", desk.CurrentPrice())
+	}
+	sales, _ := f.pricing.ListSales(ctx, "P001")
+	if len(sales) != 2 || sales[0].ID != running.ID || sales[0].CancelledAt == nil {
+		t.Errorf("Expected the cancelled sale to stay listed first, got %+v", sales)
+	}
+}
+
+func TestPricingService_PriceHistory(t *testing.T) {
+	ctx := context.Background()
+	productRepo := persistence.NewMemoryProductRepository()
+	desk, _ := entity.NewProduct("P001", "Desk", 300, "Furniture")
+	productRepo.Create(ctx, desk)
+	pricing := service.NewPricingService(productRepo, persistence.NewMemoryPriceHistoryRepository(), persistence.NewMemorySaleRepository())
+
+	pricing.RecordPriceChange(ctx, "P001", 300, 300, "USR-ADMIN")
+	pricing.RecordPriceChange(ctx, "P001", 300, 350, "USR-ADMIN")
+	pricing.RecordPriceChange(ctx, "P001", 350, 320, "")
+
+	changes, err := pricing.PriceHistory(ctx, "P001")
+	if err != nil {
+		t.Fatalf("PriceHistory failed: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 price changes, got %d", len(changes))
+	}
+	if changes[0].PreviousPrice != 300 || changes[0].Price != 350 || changes[1].Price != 320 {
+		t.Errorf("Expected 300 -> 350 -> 320, got %+v, %+v", changes[0], changes[1])
+	}
+}
@@ -0,0 +1,345 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// ErrInvalidCursor is returned when a search cursor was not issued for the same sort order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ProductSort is an order of search results
+type ProductSort string
+
+const (
+	ProductSortRelevance  ProductSort = "relevance"  // Best keyword matches first; the default with a keyword
+	ProductSortNewest     ProductSort = "newest"     // The default without a keyword
+	ProductSortPriceAsc   ProductSort = "price_asc"  // Cheapest first
+	ProductSortPriceDesc  ProductSort = "price_desc" // Most expensive first
+	ProductSortPopularity ProductSort = "popularity" // Most units sold first
+)
+
+// ParseProductSort converts a string into a product sort; "" picks the default for the keyword
+func ParseProductSort(s string) (ProductSort, error) {
+	switch ProductSort(s) {
+	case "", ProductSortRelevance, ProductSortNewest, ProductSortPriceAsc, ProductSortPriceDesc, ProductSortPopularity:
+		return ProductSort(s), nil
+	}
+	return "", fmt.Errorf("invalid sort: %s", s)
+}
+
+// ProductSearch narrows down and orders a catalog search. Zero-valued fields match every product.
+// Variants are searched with their parent, which is found when any of them matches.
+type ProductSearch struct {
+	Keyword  string // Words that must all be found in the name or description
+	Category string
+	MinPrice *int // Inclusive; compared with the price sold at now, the lowest of a product's variants
+	MaxPrice *int // Inclusive
+	InStock  bool // Only products with units available to order, counting their variants
+	Sort     ProductSort
+	Cursor   string // NextCursor of the previous page; empty for the first page
+	Limit    int    // Zero means no limit
+}
+
+// CategoryFacet is the number of results in a category
+type CategoryFacet struct {
+	Category string `json:"category"`
+	Count    int    `json:"count"`
+}
+
+// ProductSearchResult is one page of a catalog search
+type ProductSearchResult struct {
+	Products   []*entity.Product `json:"products"`
+	Total      int               `json:"total"`                 // Results on every page
+	NextCursor string            `json:"next_cursor,omitempty"` // Empty on the last page
+	// Facets count the results in each category as if no category was asked for
+	Facets []CategoryFacet `json:"facets"`
+}
+
+// ProductSearchService searches the catalog through an in-memory index. The index follows
+// product writes by itself; the service keeps its stock and sales figures up to date.
+type ProductSearchService struct {
+	index          repository.ProductIndex
+	productRepo    repository.ProductRepository
+	stockService   *StockService
+	pricingService *PricingService
+	mu             sync.Mutex
+	sold           map[string]int // Units sold by product ID, kept from the stock ledger
+}
+
+// NewProductSearchService creates a new product search service
+func NewProductSearchService(
+	index repository.ProductIndex,
+	productRepo repository.ProductRepository,
+	stockService *StockService,
+	pricingService *PricingService,
+) *ProductSearchService {
+	return &ProductSearchService{
+		index:          index,
+		productRepo:    productRepo,
+		stockService:   stockService,
+		pricingService: pricingService,
+		sold:           make(map[string]int),
+	}
+}
+
+// Reindex puts every product with its stock figures into the index, counting the units sold
+// from the whole stock ledger once
+func (s *ProductSearchService) Reindex(ctx context.Context) error {
+	products, err := s.productRepo.FindAll(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list products: %w", err)
+	}
+	movements, err := s.stockService.StockMovements(ctx, repository.StockMovementFilter{})
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.sold = make(map[string]int)
+	s.mu.Unlock()
+	s.RecordMovements(movements)
+	productIDs := make([]string, 0, len(products))
+	for _, product := range products {
+		s.index.Put(product)
+		productIDs = append(productIDs, product.ID)
+	}
+	return s.RefreshStock(ctx, productIDs)
+}
+
+// RecordMovements adds committed ledger movements to the units sold: units shipped for paid
+// orders less the units returned. Register it with StockService.OnStockMoved.
+func (s *ProductSearchService) RecordMovements(movements []*entity.StockMovement) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, movement := range movements {
+		if movement.Type == entity.StockMovementSale || movement.Type == entity.StockMovementCancellationReturn {
+			s.sold[movement.ProductID] -= movement.Quantity
+		}
+	}
+}
+
+// RefreshStock updates the units available and sold of products in the index
+func (s *ProductSearchService) RefreshStock(ctx context.Context, productIDs []string) error {
+	for _, productID := range productIDs {
+		_, available, err := s.stockService.GetProductStockInfo(ctx, productID)
+		if err != nil {
+			return fmt.Errorf("failed to get stock: %w", err)
+		}
+		s.mu.Lock()
+		sold := s.sold[productID]
+		s.mu.Unlock()
+		s.index.SetStock(productID, available, sold)
+	}
+	return nil
+}
+
+// searchHit is a product on sale together with its variants and the figures it is ordered by
+type searchHit struct {
+	product   *entity.Product
+	variants  []*entity.Product
+	score     int
+	price     int
+	available int
+	sold      int
+}
+
+// Search finds one page of the products on sale matching the search
+func (s *ProductSearchService) Search(ctx context.Context, search ProductSearch) (*ProductSearchResult, error) {
+	sortBy := search.Sort
+	if sortBy == "" {
+		sortBy = ProductSortNewest
+		if strings.TrimSpace(search.Keyword) != "" {
+			sortBy = ProductSortRelevance
+		}
+	}
+
+	hits, err := s.hits(ctx, search.Keyword)
+	if err != nil {
+		return nil, err
+	}
+
+	// Filter by everything but the category, count the categories, then filter by category
+	counts := make(map[string]int)
+	matched := make([]*searchHit, 0, len(hits))
+	for _, hit := range hits {
+		if search.InStock && hit.available <= 0 ||
+			search.MinPrice != nil && hit.price < *search.MinPrice ||
+			search.MaxPrice != nil && hit.price > *search.MaxPrice {
+			continue
+		}
+		counts[hit.product.Category]++
+		if search.Category == "" || hit.product.Category == search.Category {
+			matched = append(matched, hit)
+		}
+	}
+	facets := make([]CategoryFacet, 0, len(counts))
+	for category, count := range counts {
+		facets = append(facets, CategoryFacet{Category: category, Count: count})
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return facets[i].Category < facets[j].Category
+	})
+
+	key, descending := sortKey(sortBy)
+	before := func(aKey int, aID string, bKey int, bID string) bool {
+		if aKey != bKey {
+			if descending {
+				return aKey > bKey
+			}
+			return aKey < bKey
+		}
+		return aID < bID
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return before(key(matched[i]), matched[i].product.ID, key(matched[j]), matched[j].product.ID)
+	})
+
+	// The page starts after the last result of the previous page, wherever it is now
+	start := 0
+	if search.Cursor != "" {
+		cursorKey, cursorID, err := decodeCursor(search.Cursor, sortBy)
+		if err != nil {
+			return nil, err
+		}
+		start = sort.Search(len(matched), func(i int) bool {
+			return before(cursorKey, cursorID, key(matched[i]), matched[i].product.ID)
+		})
+	}
+	end := len(matched)
+	if search.Limit > 0 && start+search.Limit < end {
+		end = start + search.Limit
+	}
+
+	result := &ProductSearchResult{
+		Products: make([]*entity.Product, 0, end-start),
+		Total:    len(matched),
+		Facets:   facets,
+	}
+	for _, hit := range matched[start:end] {
+		hit.product.TotalStock = hit.available
+		hit.product.Variants = hit.variants
+		result.Products = append(result.Products, hit.product)
+	}
+	if end < len(matched) {
+		last := matched[end-1]
+		result.NextCursor = encodeCursor(sortBy, key(last), last.product.ID)
+	}
+	return result, nil
+}
+
+// hits returns the products on sale matching the keyword, with their variants on sale grouped
+// under them and their current prices filled in
+func (s *ProductSearchService) hits(ctx context.Context, keyword string) ([]*searchHit, error) {
+	var scores map[string]int
+	if strings.TrimSpace(keyword) != "" {
+		scores = s.index.Match(keyword)
+	}
+
+	indexed := s.index.Products()
+	products := make([]*entity.Product, 0, len(indexed))
+	hits := make(map[string]*searchHit)
+	var variants []repository.IndexedProduct
+	for _, entry := range indexed {
+		if entry.Product.IsArchived() {
+			continue
+		}
+		products = append(products, entry.Product)
+		if entry.Product.IsVariant() {
+			variants = append(variants, entry)
+			continue
+		}
+		hits[entry.Product.ID] = &searchHit{
+			product:   entry.Product,
+			score:     scores[entry.Product.ID],
+			available: entry.Available,
+			sold:      entry.Sold,
+		}
+	}
+	if err := s.pricingService.ApplySales(ctx, products); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(variants, func(i, j int) bool {
+		a, b := variants[i].Product, variants[j].Product
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+	for _, entry := range variants {
+		hit, ok := hits[entry.Product.ParentID]
+		if !ok {
+			continue // Variants of archived parents are not sold
+		}
+		if len(hit.variants) == 0 || entry.Product.CurrentPrice() < hit.price {
+			hit.price = entry.Product.CurrentPrice()
+		}
+		entry.Product.TotalStock = entry.Available
+		hit.variants = append(hit.variants, entry.Product)
+		hit.score = max(hit.score, scores[entry.Product.ID])
+		hit.available += entry.Available
+		hit.sold += entry.Sold
+	}
+
+	result := make([]*searchHit, 0, len(hits))
+	for _, hit := range hits {
+		if len(hit.variants) == 0 {
+			hit.price = hit.product.CurrentPrice()
+		}
+		if scores != nil && hit.score == 0 {
+			continue
+		}
+		result = append(result, hit)
+	}
+	return result, nil
+}
+
+// sortKey returns the value hits are ordered by and whether the largest comes first
+func sortKey(sortBy ProductSort) (func(hit *searchHit) int, bool) {
+	switch sortBy {
+	case ProductSortRelevance:
+		return func(hit *searchHit) int { return hit.score }, true
+	case ProductSortPriceAsc:
+		return func(hit *searchHit) int { return hit.price }, false
+	case ProductSortPriceDesc:
+		return func(hit *searchHit) int { return hit.price }, true
+	case ProductSortPopularity:
+		return func(hit *searchHit) int { return hit.sold }, true
+	default:
+		return func(hit *searchHit) int { return int(hit.product.CreatedAt.UnixNano()) }, true
+	}
+}
+
+// encodeCursor returns an opaque cursor pointing just after a result
+func encodeCursor(sortBy ProductSort, key int, productID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%d:%s", sortBy, key, productID)))
+}
+
+// decodeCursor reads a cursor issued for the same sort order
+func decodeCursor(cursor string, sortBy ProductSort) (int, string, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, "", ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(decoded), ":", 3)
+	if len(parts) != 3 || ProductSort(parts[0]) != sortBy {
+		return 0, "", ErrInvalidCursor
+	}
+	key, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, "", ErrInvalidCursor
+	}
+	return key, parts[2], nil
+}
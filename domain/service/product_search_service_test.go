@@ -0,0 +1,122 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/persistence"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/search"
+)
+
+func TestProductSearchService_Search(t *testing.T) {
+	f := newOrderFixture(t)
+	ctx := context.Background()
+	index := search.NewProductIndex()
+	productRepo := search.NewIndexedProductRepository(f.productRepo, index)
+	searchService := service.NewProductSearchService(index, productRepo, f.stockService, f.pricing)
+	if err := searchService.Reindex(ctx); err != nil {
+		t.Fatalf("Reindex failed: %v", err)
+	}
+	f.stockService.OnStockMoved(searchService.RecordMovements)
+	f.stockService.OnStockChanged(func(productIDs []string) {
+		searchService.RefreshStock(ctx, productIDs)
+	})
+
+	// The desk comes from storage; the rest is written through the indexed repository
+	lamp, _ := entity.NewProduct("P002", "Desk Lamp", 80, "Lighting")
+	chair, _ := entity.NewProduct("P003", "椅子", 150, "Furniture")
+	chair.SetDescription("デスク用の椅子")
+	shirt, _ := entity.NewProduct("P010", "T-Shirt", 2000, "Apparel")
+	for _, product := range []*entity.Product{lamp, chair, shirt} {
+		productRepo.Create(ctx, product)
+	}
+	products := service.NewProductService(productRepo, persistence.NewMemoryWishlistRepository(), f.warehouseRepo, f.stockService)
+	price := 2500
+	products.CreateVariant(ctx, "P011", "P010", "TS-M", map[string]string{"size": "M"}, nil)
+	products.CreateVariant(ctx, "P012", "P010", "TS-XL", map[string]string{"size": "XL"}, &price)
+	f.stockService.AdjustStockLevel(ctx, "P011", "WH-001", 1, service.MovementRef{Type: entity.StockMovementRestock})
+
+	low, high := 100, 300
+	ids := func(result *service.ProductSearchResult) string {
+		var found []string
+		for _, product := range result.Products {
+			found = append(found, product.ID)
+		}
+		return strings.Join(found, ",")
+	}
+
+	tests := []struct {
+		name   string
+		search service.ProductSearch
+		want   string
+	}{
+		{"keyword in names", service.ProductSearch{Keyword: "desk"}, "P001,P002"},
+		{"keyword in a description", service.ProductSearch{Keyword: "デスク"}, "P003"},
+		{"variant option finds its parent", service.ProductSearch{Keyword: "xl"}, "P010"},
+		{"in stock", service.ProductSearch{InStock: true, Sort: service.ProductSortPriceAsc}, "P001,P010"},
+		{"price range", service.ProductSearch{MinPrice: &low, MaxPrice: &high, Sort: service.ProductSortPriceDesc}, "P001,P003"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := searchService.Search(ctx, tt.search)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if got := ids(result); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	// Facets count every category; the shirt lists its variants and sells from the cheapest
+	result, _ := searchService.Search(ctx, service.ProductSearch{Category: "Apparel"})
+	if len(result.Facets) != 3 || result.Facets[0] != (service.CategoryFacet{Category: "Furniture", Count: 2}) || result.Total != 1 {
+		t.Errorf("Expected 3 categories, the desk and chair first, and one result, got %+v", result)
+	}
+	if variants := result.Products[0].Variants; len(variants) != 2 || result.Products[0].TotalStock != 1 {
+		t.Errorf("Expected the shirt with 2 variants and 1 unit in stock, got %+v", result.Products[0])
+	}
+
+	// A page continues after the previous one even when a product is added in between
+	first, _ := searchService.Search(ctx, service.ProductSearch{Sort: service.ProductSortPriceAsc, Limit: 2})
+	if ids(first) != "P002,P003" || first.NextCursor == "" {
+		t.Fatalf("Expected the lamp and chair with a cursor, got %s", ids(first))
+	}
+	stool, _ := entity.NewProduct("P004", "Stool", 50, "Furniture")
+	productRepo.Create(ctx, stool)
+	second, err := searchService.Search(ctx, service.ProductSearch{Sort: service.ProductSortPriceAsc, Limit: 2, Cursor: first.NextCursor})
+	if err != nil || ids(second) != "P001,P010" || second.NextCursor != "" {
+		t.Errorf("Expected the desk and shirt on the last page, got %s, %v", ids(second), err)
+	}
+	if _, err := searchService.Search(ctx, service.ProductSearch{Sort: service.ProductSortNewest, Cursor: first.NextCursor}); !errors.Is(err, service.ErrInvalidCursor) {
+		t.Errorf("Expected a cursor of another sort order to be rejected, got %v", err)
+	}
+
+	// Selling the desk makes it the most popular; archiving the lamp hides it
+	order, err := f.orderService.ProcessOrder(ctx, "USER-001", []service.OrderRequest{{ProductID: "P001", Quantity: 1}}, "", nil)
+	if err != nil {
+		t.Fatalf("ProcessOrder failed: %v", err)
+	}
+	if err := f.orderService.ConfirmOrderAndReduceStock(ctx, order); err != nil {
+		t.Fatalf("ConfirmOrderAndReduceStock failed: %v", err)
+	}
+	if result, _ := searchService.Search(ctx, service.ProductSearch{Sort: service.ProductSortPopularity, Limit: 1}); ids(result) != "P001" {
+		t.Errorf("Expected the desk to be the most popular, got %s", ids(result))
+	}
+	// A new index counts the sales already in the ledger
+	rebuilt := service.NewProductSearchService(index, productRepo, f.stockService, f.pricing)
+	if err := rebuilt.Reindex(ctx); err != nil {
+		t.Fatalf("Reindex failed: %v", err)
+	}
+	if result, _ := rebuilt.Search(ctx, service.ProductSearch{Sort: service.ProductSortPopularity, Limit: 1}); ids(result) != "P001" {
+		t.Errorf("Expected the desk to stay the most popular after a reindex, got %s", ids(result))
+	}
+	products.Archive(ctx, "P002")
+	if result, _ := searchService.Search(ctx, service.ProductSearch{Keyword: "desk"}); ids(result) != "P001" {
+		t.Errorf("Expected the archived lamp to be left out, got %s", ids(result))
+	}
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// ProductService handles product variants, archiving products and cleaning up the records left
+// behind by products that were deleted
+type ProductService struct {
+	productRepo   repository.ProductRepository
+	wishlistRepo  repository.WishlistRepository
+	warehouseRepo repository.WarehouseRepository
+	stockService  *StockService
+}
+
+// NewProductService creates a new product service
+func NewProductService(
+	productRepo repository.ProductRepository,
+	wishlistRepo repository.WishlistRepository,
+	warehouseRepo repository.WarehouseRepository,
+	stockService *StockService,
+) *ProductService {
+	return &ProductService{
+		productRepo:   productRepo,
+		wishlistRepo:  wishlistRepo,
+		warehouseRepo: warehouseRepo,
+		stockService:  stockService,
+	}
+}
+
+// CatalogCleanup reports what a cleanup removed
+type CatalogCleanup struct {
+	WishlistEntries  int `json:"wishlist_entries"`   // Wishlist entries of products that no longer exist
+	StockRecords     int `json:"stock_records"`      // Stock records of missing products and empty ones of archived products
+	UnitsWrittenOff  int `json:"units_written_off"`  // Units left in removed records, recorded as adjustments
+	KeptStockRecords int `json:"kept_stock_records"` // Records kept because units are reserved for orders or transfers
+}
+
+// CreateVariant adds a variant with its own SKU to a parent product. Once a product has variants
+// it is ordered through them, so the parent must not hold stock of its own, and no two variants
+// of a parent may have the same options.
+func (s *ProductService) CreateVariant(ctx context.Context, id, parentID, sku string, options map[string]string, price *int) (*entity.Product, error) {
+	parent, err := s.productRepo.FindByID(ctx, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %s", parentID)
+	}
+	if parent.IsArchived() {
+		return nil, fmt.Errorf("%w: %s", entity.ErrProductArchived, parentID)
+	}
+	variant, err := entity.NewVariant(id, parent, sku, options, price)
+	if err != nil {
+		return nil, fmt.Errorf("invalid variant data: %w", err)
+	}
+
+	stocks, err := s.stockService.ListProductStock(ctx, parentID)
+	if err != nil {
+		return nil, err
+	}
+	for _, stock := range stocks {
+		if stock.Quantity > 0 || stock.Reserved > 0 {
+			return nil, fmt.Errorf("product %s still holds stock in %s: move it to a variant first", parentID, stock.WarehouseID)
+		}
+	}
+	siblings, err := s.productRepo.FindVariants(ctx, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find variants: %w", err)
+	}
+	for _, sibling := range siblings {
+		if sibling.HasOptions(variant.Options) {
+			return nil, fmt.Errorf("variant with the same options already exists: %s", sibling.SKU)
+		}
+	}
+
+	if err := s.productRepo.Create(ctx, variant); err != nil {
+		return nil, fmt.Errorf("failed to create variant: %w", err)
+	}
+	return variant, nil
+}
+
+// Archive stops selling a product. It stays readable for past orders and wishlists.
+func (s *ProductService) Archive(ctx context.Context, id string) (*entity.Product, error) {
+	return s.update(ctx, id, (*entity.Product).Archive)
+}
+
+// Restore puts an archived product back on sale
+func (s *ProductService) Restore(ctx context.Context, id string) (*entity.Product, error) {
+	return s.update(ctx, id, (*entity.Product).Restore)
+}
+
+// update applies change to a product and saves it
+func (s *ProductService) update(ctx context.Context, id string, change func(p *entity.Product) error) (*entity.Product, error) {
+	product, err := s.productRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %s", id)
+	}
+	if err := change(product); err != nil {
+		return nil, err
+	}
+	if err := s.productRepo.Update(ctx, product); err != nil {
+		return nil, fmt.Errorf("failed to update product: %w", err)
+	}
+	return product, nil
+}
+
+// Cleanup removes wishlist entries and stock records of products that no longer exist, and the
+// empty stock records of archived products. Units still held for a missing product are written
+// off in the ledger; records with reserved units are kept until the orders or transfers finish.
+// Products are only archived through the API, so missing products are left over from data
+// written before archiving existed or outside the API; this repairs that data. Wishlist entries
+// of archived products are kept, since they stay readable and come back if the product is restored.
+func (s *ProductService) Cleanup(ctx context.Context, ref MovementRef) (*CatalogCleanup, error) {
+	result := &CatalogCleanup{}
+	products := make(map[string]*entity.Product) // nil for products that no longer exist
+	find := func(productID string) *entity.Product {
+		product, ok := products[productID]
+		if !ok {
+			product, _ = s.productRepo.FindByID(ctx, productID)
+			products[productID] = product
+		}
+		return product
+	}
+
+	productIDs, err := s.wishlistRepo.FindProductIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find wishlist products: %w", err)
+	}
+	for _, productID := range productIDs {
+		if find(productID) != nil {
+			continue
+		}
+		removed, err := s.wishlistRepo.DeleteByProduct(ctx, productID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete wishlist entries: %w", err)
+		}
+		result.WishlistEntries += removed
+	}
+
+	warehouses, err := s.warehouseRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find warehouses: %w", err)
+	}
+	for _, warehouse := range warehouses {
+		stocks, err := s.stockService.ListWarehouseStock(ctx, warehouse.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, stock := range stocks {
+			product := find(stock.ProductID)
+			if product != nil && !(product.IsArchived() && stock.Quantity == 0 && stock.Reserved == 0) {
+				continue
+			}
+			writtenOff, err := s.stockService.RemoveStock(ctx, stock.ProductID, stock.WarehouseID, ref)
+			if errors.Is(err, ErrStockReserved) {
+				result.KeptStockRecords++
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			result.StockRecords++
+			result.UnitsWrittenOff += writtenOff
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,118 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/persistence"
+)
+
+func TestProductService_ArchiveAndCleanup(t *testing.T) {
+	f := newOrderFixture(t)
+	ctx := context.Background()
+	wishlistRepo := persistence.NewMemoryWishlistRepository()
+	products := service.NewProductService(f.productRepo, wishlistRepo, f.warehouseRepo, f.stockService)
+
+	// An archived product can no longer be ordered but stays readable
+	if _, err := products.Archive(ctx, "P001"); err != nil {
+		t.Fatalf("Archive failed: %v", err)
+	}
+	_, err := f.orderService.ProcessOrder(ctx, "USER-001", []service.OrderRequest{{ProductID: "P001", Quantity: 1}}, "", nil)
+	if !errors.Is(err, entity.ErrProductArchived) {
+		t.Errorf("Expected an archived product to be refused, got %v", err)
+	}
+	if desk, err := f.productRepo.FindByID(ctx, "P001"); err != nil || !desk.IsArchived() {
+		t.Errorf("Expected the archived desk to stay readable, got %+v, %v", desk, err)
+	}
+
+	// Records of a product that no longer exists are dangling, as is the archived desk's empty stock
+	restock := service.MovementRef{Type: entity.StockMovementRestock}
+	f.stockService.AdjustStockLevel(ctx, "P404", "WH-001", 3, restock)
+	for _, productID := range []string{"P001", "P404"} {
+		entry, _ := entity.NewWishlist("WL-"+productID, "USER-001", productID)
+		wishlistRepo.Create(ctx, entry)
+	}
+	f.stockService.SetStockLevel(ctx, "P001", "WH-001", 0, service.MovementRef{})
+
+	result, err := products.Cleanup(ctx, service.MovementRef{Note: "cleanup"})
+	if err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+	expected := service.CatalogCleanup{WishlistEntries: 1, StockRecords: 2, UnitsWrittenOff: 3}
+	if *result != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *result)
+	}
+	if entries, _ := wishlistRepo.FindByUser(ctx, "USER-001"); len(entries) != 1 || entries[0].ProductID != "P001" {
+		t.Errorf("Expected only the archived desk to stay wishlisted, got %+v", entries)
+	}
+	if stocks, _ := f.stockRepo.FindByProductID(ctx, "P001"); len(stocks) != 1 || stocks[0].WarehouseID != "WH-002" {
+		t.Errorf("Expected only the desk's stock in WH-002 to stay, got %+v", stocks)
+	}
+	if discrepancies, err := f.stockService.VerifyLedger(ctx); err != nil || len(discrepancies) != 0 {
+		t.Errorf("Expected the ledger to balance after the cleanup, got %+v, %v", discrepancies, err)
+	}
+}
+
+func TestProductService_Variants(t *testing.T) {
+	f := newOrderFixture(t)
+	ctx := context.Background()
+	products := service.NewProductService(f.productRepo, persistence.NewMemoryWishlistRepository(), f.warehouseRepo, f.stockService)
+
+	// The desk holds stock of its own, so it cannot be split into variants
+	if _, err := products.CreateVariant(ctx, "P001-S", "P001", "DESK-S", map[string]string{"size": "S"}, nil); err == nil {
+		t.Error("Expected a product holding stock not to get variants")
+	}
+
+	shirt, _ := entity.NewProduct("P010", "T-Shirt", 2000, "Apparel")
+	f.productRepo.Create(ctx, shirt)
+	price := 2500
+	medium, err := products.CreateVariant(ctx, "P011", "P010", "TS-M", map[string]string{"size": "M"}, nil)
+	if err != nil {
+		t.Fatalf("CreateVariant failed: %v", err)
+	}
+	if _, err := products.CreateVariant(ctx, "P012", "P010", "TS-XL", map[string]string{"size": "XL"}, &price); err != nil {
+		t.Fatalf("CreateVariant failed: %v", err)
+	}
+	if _, err := products.CreateVariant(ctx, "P013", "P010", "TS-M2", map[string]string{"size": "M"}, nil); err == nil {
+		t.Error("Expected a second variant with the same options to be rejected")
+	}
+	if _, err := products.CreateVariant(ctx, "P013", "P010", "TS-M", map[string]string{"size": "S"}, nil); !errors.Is(err, repository.ErrSKUTaken) {
+		t.Errorf("Expected a taken SKU to be rejected, got %v", err)
+	}
+	if variants, _ := f.productRepo.FindVariants(ctx, "P010"); len(variants) != 2 || variants[0].ID != "P011" {
+		t.Errorf("Expected the two variants in SKU order, got %+v", variants)
+	}
+
+	// A variant is ordered by its SKU from its own stock; the parent itself cannot be ordered
+	f.stockService.AdjustStockLevel(ctx, medium.ID, "WH-001", 2, service.MovementRef{Type: entity.StockMovementRestock})
+	order, err := f.orderService.ProcessOrder(ctx, "USER-001", []service.OrderRequest{{SKU: "TS-M", Quantity: 1}}, "", nil)
+	if err != nil {
+		t.Fatalf("ProcessOrder failed: %v", err)
+	}
+	item := order.Items[0]
+	if item.ProductID != "P011" || item.SKU != "TS-M" || item.Price != 2000 || item.ProductName != "T-Shirt (M)" {
+		t.Errorf("Expected one T-Shirt (M) at 2000, got %+v", item)
+	}
+	if _, total, _ := f.stockService.GetProductStockInfo(ctx, "P011"); total != 1 {
+		t.Errorf("Expected 1 unit of the variant left, got %d", total)
+	}
+	_, err = f.orderService.ProcessOrder(ctx, "USER-001", []service.OrderRequest{{ProductID: "P010", Quantity: 1}}, "", nil)
+	if !errors.Is(err, service.ErrVariantRequired) {
+		t.Errorf("Expected the parent to be refused, got %v", err)
+	}
+	_, err = f.orderService.ProcessOrder(ctx, "USER-001", []service.OrderRequest{{ProductID: "P011", SKU: "TS-XL", Quantity: 1}}, "", nil)
+	if err == nil {
+		t.Error("Expected a SKU of another product to be rejected")
+	}
+
+	// Once the parent is archived its variants are no longer sold either
+	products.Archive(ctx, "P010")
+	_, err = f.orderService.ProcessOrder(ctx, "USER-001", []service.OrderRequest{{SKU: "TS-M", Quantity: 1}}, "", nil)
+	if !errors.Is(err, entity.ErrProductArchived) {
+		t.Errorf("Expected a variant of an archived product to be refused, got %v", err)
+	}
+}
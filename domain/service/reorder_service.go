@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// ReorderService manages reorder points and checks available stock against them
+type ReorderService struct {
+	reorderRepo   repository.ReorderPointRepository
+	stockRepo     repository.StockRepository
+	warehouseRepo repository.WarehouseRepository
+}
+
+// NewReorderService creates a new reorder service
+func NewReorderService(reorderRepo repository.ReorderPointRepository, stockRepo repository.StockRepository, warehouseRepo repository.WarehouseRepository) *ReorderService {
+	return &ReorderService{
+		reorderRepo:   reorderRepo,
+		stockRepo:     stockRepo,
+		warehouseRepo: warehouseRepo,
+	}
+}
+
+// SetReorderPoint sets the reorder point of a product in a warehouse, or its default for every
+// warehouse when warehouseID is empty
+func (s *ReorderService) SetReorderPoint(ctx context.Context, productID, warehouseID string, threshold int) (*entity.ReorderPoint, error) {
+	if warehouseID != "" {
+		if _, err := s.warehouseRepo.FindByID(ctx, warehouseID); err != nil {
+			return nil, fmt.Errorf("warehouse not found: %s", warehouseID)
+		}
+	}
+	reorderPoint, err := entity.NewReorderPoint(productID, warehouseID, threshold)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.reorderRepo.Save(ctx, reorderPoint); err != nil {
+		return nil, fmt.Errorf("failed to save reorder point: %w", err)
+	}
+	return reorderPoint, nil
+}
+
+// DeleteReorderPoint removes the reorder point of a product in a warehouse, or its default
+func (s *ReorderService) DeleteReorderPoint(ctx context.Context, productID, warehouseID string) error {
+	if err := s.reorderRepo.Delete(ctx, productID, warehouseID); err != nil {
+		return fmt.Errorf("reorder point not found: %s", productID)
+	}
+	return nil
+}
+
+// ListReorderPoints lists every reorder point by product and warehouse
+func (s *ReorderService) ListReorderPoints(ctx context.Context) ([]*entity.ReorderPoint, error) {
+	reorderPoints, err := s.reorderRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reorder points: %w", err)
+	}
+	if reorderPoints == nil {
+		reorderPoints = []*entity.ReorderPoint{}
+	}
+	return reorderPoints, nil
+}
+
+// CheckStock returns an alert for every stock record of the given products, or of all products
+// when productIDs is nil, whose available units are at or below its reorder point or gone.
+// A warehouse with its own reorder point for a product it holds no record of is out of stock.
+// Alerts are ordered by product and warehouse.
+func (s *ReorderService) CheckStock(ctx context.Context, productIDs []string) ([]entity.StockAlert, error) {
+	var only map[string]bool
+	if productIDs != nil {
+		only = make(map[string]bool)
+		for _, productID := range productIDs {
+			only[productID] = true
+		}
+	}
+	checked := func(productID string) bool { return only == nil || only[productID] }
+
+	reorderPoints, err := s.reorderRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reorder points: %w", err)
+	}
+	type key struct{ productID, warehouseID string }
+	thresholds := make(map[key]int)
+	for _, reorderPoint := range reorderPoints {
+		thresholds[key{reorderPoint.ProductID, reorderPoint.WarehouseID}] = reorderPoint.Threshold
+	}
+	thresholdOf := func(productID, warehouseID string) int {
+		if threshold, ok := thresholds[key{productID, warehouseID}]; ok {
+			return threshold
+		}
+		return thresholds[key{productID, ""}]
+	}
+
+	now := time.Now()
+	var alerts []entity.StockAlert
+	alert := func(productID, warehouseID string, available int) {
+		threshold := thresholdOf(productID, warehouseID)
+		if alertType, ok := entity.CheckStockLevel(available, threshold); ok {
+			alerts = append(alerts, entity.StockAlert{
+				Type:         alertType,
+				ProductID:    productID,
+				WarehouseID:  warehouseID,
+				Available:    available,
+				ReorderPoint: threshold,
+				DetectedAt:   now,
+			})
+		}
+	}
+
+	held := make(map[key]bool)
+	warehouses, err := s.warehouseRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find warehouses: %w", err)
+	}
+	existing := make(map[string]bool)
+	for _, warehouse := range warehouses {
+		existing[warehouse.ID] = true
+
+		stocks, err := s.stockRepo.FindByWarehouseID(ctx, warehouse.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find stocks: %w", err)
+		}
+		for _, stock := range stocks {
+			held[key{stock.ProductID, stock.WarehouseID}] = true
+			if checked(stock.ProductID) {
+				alert(stock.ProductID, stock.WarehouseID, stock.Available())
+			}
+		}
+	}
+	for _, reorderPoint := range reorderPoints {
+		k := key{reorderPoint.ProductID, reorderPoint.WarehouseID}
+		if existing[reorderPoint.WarehouseID] && !held[k] && checked(reorderPoint.ProductID) {
+			alert(reorderPoint.ProductID, reorderPoint.WarehouseID, 0)
+		}
+	}
+
+	sort.Slice(alerts, func(i, j int) bool {
+		if alerts[i].ProductID != alerts[j].ProductID {
+			return alerts[i].ProductID < alerts[j].ProductID
+		}
+		return alerts[i].WarehouseID < alerts[j].WarehouseID
+	})
+	return alerts, nil
+}
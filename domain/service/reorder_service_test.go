@@ -0,0 +1,60 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/persistence"
+)
+
+func TestReorderService_CheckStock(t *testing.T) {
+	ctx := context.Background()
+	f := newStockFixture(t)
+	reorderService := service.NewReorderService(persistence.NewMemoryReorderPointRepository(), f.stockRepo, f.warehouseRepo)
+
+	if alerts, _ := reorderService.CheckStock(ctx, nil); len(alerts) != 0 {
+		t.Errorf("Expected no alerts without reorder points, got %+v", alerts)
+	}
+
+	// The product default applies to every warehouse unless one overrides it
+	if _, err := reorderService.SetReorderPoint(ctx, "P001", "", 2); err != nil {
+		t.Fatalf("SetReorderPoint failed: %v", err)
+	}
+	if _, err := reorderService.SetReorderPoint(ctx, "P001", "WH-002", 0); err != nil {
+		t.Fatalf("SetReorderPoint failed: %v", err)
+	}
+	if _, err := reorderService.SetReorderPoint(ctx, "P001", "WH-404", 1); err == nil {
+		t.Error("Expected a reorder point in an unknown warehouse to be rejected")
+	}
+	alerts, err := reorderService.CheckStock(ctx, nil)
+	if err != nil {
+		t.Fatalf("CheckStock failed: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].WarehouseID != "WH-001" || alerts[0].Type != entity.StockAlertLow || alerts[0].ReorderPoint != 2 {
+		t.Fatalf("Expected WH-001 to be low against the default, got %+v", alerts)
+	}
+
+	// Held units are not available
+	if _, err := f.stockService.AllocateStock(ctx, "P001", 2); err != nil {
+		t.Fatalf("AllocateStock failed: %v", err)
+	}
+	alerts, _ = reorderService.CheckStock(ctx, []string{"P001"})
+	if len(alerts) != 2 || alerts[0].Type != entity.StockAlertOutOfStock || alerts[1].Type != entity.StockAlertOutOfStock {
+		t.Errorf("Expected both warehouses to be out of stock, got %+v", alerts)
+	}
+	if alerts, _ := reorderService.CheckStock(ctx, []string{"P002"}); len(alerts) != 0 {
+		t.Errorf("Expected only the given products to be checked, got %+v", alerts)
+	}
+
+	if err := reorderService.DeleteReorderPoint(ctx, "P001", "WH-002"); err != nil {
+		t.Fatalf("DeleteReorderPoint failed: %v", err)
+	}
+	if err := reorderService.DeleteReorderPoint(ctx, "P001", "WH-002"); err == nil {
+		t.Error("Expected deleting a missing reorder point to fail")
+	}
+	if reorderPoints, _ := reorderService.ListReorderPoints(ctx); len(reorderPoints) != 1 || reorderPoints[0].WarehouseID != "" {
+		t.Errorf("Expected only the product default to remain, got %+v", reorderPoints)
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// ShippingCalculator prices the parcels an order is shipped in
+type ShippingCalculator interface {
+	// Calculate returns the charges for shipping the parcels of an order with the given subtotal
+	Calculate(ctx context.Context, parcels []entity.ShippingParcel, orderSubtotal int) ([]entity.ShippingCharge, error)
+}
+
+// RuleShippingCalculator prices each parcel with the first effective shipping rule that matches it.
+// Parcels no rule matches are shipped together for the standard fee, which is waived from the
+// standard free shipping threshold, so without any rules every order pays the standard fee once.
+type RuleShippingCalculator struct {
+	ruleRepo repository.ShippingRuleRepository
+	now      func() time.Time
+}
+
+// NewRuleShippingCalculator creates a shipping calculator driven by the stored shipping rules
+func NewRuleShippingCalculator(ruleRepo repository.ShippingRuleRepository) *RuleShippingCalculator {
+	return &RuleShippingCalculator{
+		ruleRepo: ruleRepo,
+		now:      time.Now,
+	}
+}
+
+// Calculate returns one charge per parcel matched by a rule, plus one standard charge for the rest
+func (c *RuleShippingCalculator) Calculate(ctx context.Context, parcels []entity.ShippingParcel, orderSubtotal int) ([]entity.ShippingCharge, error) {
+	rules, err := c.ruleRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shipping rules: %w", err)
+	}
+
+	now := c.now()
+	var effective []*entity.ShippingRule
+	for _, rule := range rules {
+		if rule.IsEffective(now) {
+			effective = append(effective, rule)
+		}
+	}
+
+	charges := []entity.ShippingCharge{}
+	unmatched := false
+	for _, parcel := range parcels {
+		rule := firstMatchingRule(effective, parcel)
+		if rule == nil {
+			unmatched = true
+			continue
+		}
+		charges = append(charges, entity.ShippingCharge{
+			WarehouseID: parcel.WarehouseID,
+			RuleID:      rule.ID,
+			Fee:         rule.FeeFor(orderSubtotal),
+		})
+	}
+
+	if unmatched {
+		fee := entity.StandardShippingFee
+		if orderSubtotal >= entity.StandardFreeShippingThreshold {
+			fee = 0
+		}
+		charges = append(charges, entity.ShippingCharge{Fee: fee})
+	}
+	return charges, nil
+}
+
+// firstMatchingRule returns the first rule, in priority order, that matches the parcel
+func firstMatchingRule(rules []*entity.ShippingRule, parcel entity.ShippingParcel) *entity.ShippingRule {
+	for _, rule := range rules {
+		if rule.Matches(parcel) {
+			return rule
+		}
+	}
+	return nil
+}
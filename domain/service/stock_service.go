@@ -2,26 +2,121 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
 
 	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
 	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
 )
 
-// StockService handles stock management across warehouses
+// maxTransactionAttempts bounds how often a transaction is retried after a conflict
+const maxTransactionAttempts = 10
+
+// ErrStockReserved is returned when a stock record still has units reserved for orders or transfers
+var ErrStockReserved = errors.New("stock is reserved")
+
+// movementSeq makes stock movement IDs generated in the same nanosecond unique
+var movementSeq atomic.Uint64
+
+// StockService handles stock management across warehouses. Every change to a stock
+// quantity is recorded in the stock movement ledger in the same transaction.
 type StockService struct {
 	stockRepo     repository.StockRepository
 	warehouseRepo repository.WarehouseRepository
+	movementRepo  repository.StockMovementRepository
+	strategy      AllocationStrategy // decides which warehouses stock is taken from
+	listeners     []func(productIDs []string)
+	movedHandlers []func(movements []*entity.StockMovement)
 }
 
 // NewStockService creates a new stock service
-func NewStockService(stockRepo repository.StockRepository, warehouseRepo repository.WarehouseRepository) *StockService {
+func NewStockService(stockRepo repository.StockRepository, warehouseRepo repository.WarehouseRepository, movementRepo repository.StockMovementRepository, strategy AllocationStrategy) *StockService {
 	return &StockService{
 		stockRepo:     stockRepo,
 		warehouseRepo: warehouseRepo,
+		movementRepo:  movementRepo,
+		strategy:      strategy,
+	}
+}
+
+// MovementRef says why stock changes and on whose behalf, for the stock movement ledger
+type MovementRef struct {
+	Type    entity.StockMovementType
+	OrderID string
+	UserID  string // Acting user; empty for system changes
+	Note    string
+}
+
+// OnStockChanged registers fn to be called with the products whose stock changed each time
+// such a change commits. Listeners run in registration order on the goroutine that made the
+// change and may change stock themselves; slow work should be handed off. Listeners must be
+// registered before the service is used concurrently.
+func (s *StockService) OnStockChanged(fn func(productIDs []string)) {
+	s.listeners = append(s.listeners, fn)
+}
+
+// stockChanged tells the listeners that the stock of the products changed
+func (s *StockService) stockChanged(productIDs ...string) {
+	for _, listener := range s.listeners {
+		listener(productIDs)
 	}
 }
 
+// OnStockMoved registers fn to be called with the ledger movements of each transaction as it
+// commits, before the OnStockChanged listeners hear of the change. The same rules apply as for
+// OnStockChanged.
+func (s *StockService) OnStockMoved(fn func(movements []*entity.StockMovement)) {
+	s.movedHandlers = append(s.movedHandlers, fn)
+}
+
+// stockMoved tells the OnStockMoved listeners about the movements a transaction committed
+func (s *StockService) stockMoved(movements []*entity.StockMovement) {
+	if len(movements) == 0 {
+		return
+	}
+	for _, handler := range s.movedHandlers {
+		handler(movements)
+	}
+}
+
+// movementLog passes appends through to a transaction's ledger and keeps the movements, so
+// they can be announced once the transaction commits
+type movementLog struct {
+	repository.StockMovementRepository
+	movements []*entity.StockMovement
+}
+
+// Append appends the movement to the ledger and keeps it
+func (l *movementLog) Append(ctx context.Context, movement *entity.StockMovement) error {
+	if err := l.StockMovementRepository.Append(ctx, movement); err != nil {
+		return err
+	}
+	l.movements = append(l.movements, movement)
+	return nil
+}
+
+// loggedStockTransaction is a stock transaction whose ledger appends are kept in log
+type loggedStockTransaction struct {
+	repository.StockTransaction
+	log *movementLog
+}
+
+// GetStockMovementRepository returns the transaction's ledger through the log
+func (tx loggedStockTransaction) GetStockMovementRepository() repository.StockMovementRepository {
+	return tx.log
+}
+
+// LedgerDiscrepancy is a stock record whose quantity differs from the replayed ledger
+type LedgerDiscrepancy struct {
+	ProductID      string `json:"product_id"`
+	WarehouseID    string `json:"warehouse_id"`
+	Quantity       int    `json:"quantity"`        // Quantity of the stock record; 0 when there is none
+	LedgerQuantity int    `json:"ledger_quantity"` // Sum of the record's movements
+}
+
 // StockAllocation represents how stock is allocated from different warehouses
 type StockAllocation struct {
 	WarehouseID   string
@@ -38,100 +133,451 @@ func (s *StockService) CheckAvailability(ctx context.Context, productID string,
 
 	totalAvailable := 0
 	for _, stock := range stocks {
-		totalAvailable += stock.Quantity
+		totalAvailable += stock.Available()
 	}
 
 	return totalAvailable >= requiredQuantity, totalAvailable, nil
 }
 
-// AllocateStock allocates stock from multiple warehouses for an order
-func (s *StockService) AllocateStock(ctx context.Context, productID string, requiredQuantity int) ([]StockAllocation, error) {
-	// Start a transaction
-	tx, err := s.stockRepo.BeginTransaction(ctx)
+// AllocationPlan is where the allocation strategy takes each product of an order from
+type AllocationPlan struct {
+	Demands     []StockDemand
+	Allocations map[string][]StockAllocation // Keyed by product ID, in the order the warehouses are drawn from
+	Shortfalls  map[string]int               // Units of each product no warehouse has available
+}
+
+// CheckAvailable returns an error naming the shortfall if the plan cannot supply a demand in full
+func (p *AllocationPlan) CheckAvailable(demand StockDemand) error {
+	shortfall := p.Shortfalls[demand.ProductID]
+	if shortfall <= 0 {
+		return nil
+	}
+	return fmt.Errorf("insufficient stock: required=%d, available=%d", demand.Quantity, demand.Quantity-shortfall)
+}
+
+// PlanAllocation returns where the allocation strategy would currently take the demands
+// shipped to destination from, without reserving or reducing anything
+func (s *StockService) PlanAllocation(ctx context.Context, destination string, demands []StockDemand) (*AllocationPlan, error) {
+	return s.PlanAllocationWith(ctx, s.stockRepo, destination, demands)
+}
+
+// PlanAllocationWith plans an allocation from the stock read through the given repository,
+// which is typically bound to a unit of work that then reserves or reduces the planned stock
+func (s *StockService) PlanAllocationWith(ctx context.Context, stockRepo repository.StockRepository, destination string, demands []StockDemand) (*AllocationPlan, error) {
+	warehouses, err := s.warehouseRepo.FindAll(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, fmt.Errorf("failed to find warehouses: %w", err)
+	}
+	request := AllocationRequest{
+		Destination: destination,
+		Demands:     demands,
+		Available:   make(map[string]map[string]int, len(demands)),
+		Warehouses:  make(map[string]*entity.Warehouse, len(warehouses)),
 	}
-	defer func() {
+	for _, warehouse := range warehouses {
+		request.Warehouses[warehouse.ID] = warehouse
+	}
+	for _, demand := range demands {
+		stocks, err := stockRepo.FindByProductID(ctx, demand.ProductID)
 		if err != nil {
-			_ = tx.Rollback()
+			return nil, fmt.Errorf("failed to find stocks: %w", err)
+		}
+		available := make(map[string]int, len(stocks))
+		for _, stock := range stocks {
+			available[stock.WarehouseID] = stock.Available()
 		}
-	}()
+		request.Available[demand.ProductID] = available
+	}
 
-	stockRepo := tx.GetStockRepository()
+	plan := &AllocationPlan{
+		Demands:     demands,
+		Allocations: s.strategy.Allocate(request),
+		Shortfalls:  make(map[string]int),
+	}
+	for _, demand := range demands {
+		remaining := demand.Quantity
+		allocations := plan.Allocations[demand.ProductID]
+		for i := range allocations {
+			if warehouse, ok := request.Warehouses[allocations[i].WarehouseID]; ok {
+				allocations[i].WarehouseName = warehouse.Name
+			}
+			remaining -= allocations[i].Quantity
+		}
+		if remaining > 0 {
+			plan.Shortfalls[demand.ProductID] = remaining
+		}
+	}
+	return plan, nil
+}
 
-	// Get all stocks for the product
-	stocks, err := stockRepo.FindByProductID(ctx, productID)
+// AllocateStock allocates stock from multiple warehouses for an order.
+// The allocation runs in its own stock transaction and is retried if a concurrent
+// allocation touched the same stock rows first.
+func (s *StockService) AllocateStock(ctx context.Context, productID string, requiredQuantity int) ([]StockAllocation, error) {
+	var allocations []StockAllocation
+	err := s.inStockTransaction(ctx, func(tx repository.StockTransaction) error {
+		stockRepo := tx.GetStockRepository()
+		demand := StockDemand{ProductID: productID, Quantity: requiredQuantity}
+		plan, err := s.PlanAllocationWith(ctx, stockRepo, "", []StockDemand{demand})
+		if err != nil {
+			return err
+		}
+		if err := plan.CheckAvailable(demand); err != nil {
+			return err
+		}
+		allocations = plan.Allocations[productID]
+		return s.ReducePlanWith(ctx, stockRepo, tx.GetStockMovementRepository(), plan, MovementRef{Type: entity.StockMovementSale})
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to find stocks: %w", err)
+		return nil, err
 	}
+	s.stockChanged(productID)
+	return allocations, nil
+}
 
-	// Check total availability
-	totalAvailable := 0
-	for _, stock := range stocks {
-		totalAvailable += stock.Quantity
+// SetStockLevel sets the quantity of a product held in a warehouse, as after a stocktake,
+// creating the stock record if the warehouse did not hold the product yet. The difference
+// is recorded as a stocktake movement.
+func (s *StockService) SetStockLevel(ctx context.Context, productID, warehouseID string, quantity int, ref MovementRef) (*entity.Stock, error) {
+	ref.Type = entity.StockMovementStocktake
+	return s.changeStockLevel(ctx, productID, warehouseID, ref, func(stock *entity.Stock) error {
+		return stock.SetQuantity(quantity)
+	})
+}
+
+// AdjustStockLevel adds delta units of a product to a warehouse, or removes them when delta is
+// negative. Units reserved for pending orders cannot be removed. ref.Type must be a restock,
+// which only adds units, or an adjustment.
+func (s *StockService) AdjustStockLevel(ctx context.Context, productID, warehouseID string, delta int, ref MovementRef) (*entity.Stock, error) {
+	if delta == 0 {
+		return nil, errors.New("adjustment cannot be zero")
+	}
+	switch ref.Type {
+	case entity.StockMovementAdjustment:
+	case entity.StockMovementRestock:
+		if delta < 0 {
+			return nil, errors.New("a restock cannot remove units")
+		}
+	default:
+		return nil, fmt.Errorf("stock cannot be adjusted as %q: use %s or %s", ref.Type, entity.StockMovementRestock, entity.StockMovementAdjustment)
 	}
+	return s.changeStockLevel(ctx, productID, warehouseID, ref, func(stock *entity.Stock) error {
+		return stock.SetQuantity(stock.Quantity + delta)
+	})
+}
 
-	if totalAvailable < requiredQuantity {
-		return nil, fmt.Errorf("insufficient stock: required=%d, available=%d", requiredQuantity, totalAvailable)
+// changeStockLevel applies change to the product's stock record in the warehouse in a stock
+// transaction and records the difference in the ledger
+func (s *StockService) changeStockLevel(ctx context.Context, productID, warehouseID string, ref MovementRef, change func(stock *entity.Stock) error) (*entity.Stock, error) {
+	if _, err := s.warehouseRepo.FindByID(ctx, warehouseID); err != nil {
+		return nil, fmt.Errorf("warehouse not found: %s", warehouseID)
 	}
 
-	// Allocate stock from warehouses
-	allocations := []StockAllocation{}
-	remaining := requiredQuantity
+	var changed *entity.Stock
+	err := s.inStockTransaction(ctx, func(tx repository.StockTransaction) error {
+		var err error
+		changed, err = s.changeStockWith(ctx, tx, productID, warehouseID, ref, change)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.stockChanged(productID)
+	return changed, nil
+}
 
-	for _, stock := range stocks {
-		if remaining == 0 {
-			break
+// changeStockWith applies change to the product's stock record in the warehouse inside tx,
+// creating the record if the warehouse did not hold the product yet, and records any change
+// of quantity in the ledger
+func (s *StockService) changeStockWith(ctx context.Context, tx repository.StockTransaction, productID, warehouseID string, ref MovementRef, change func(stock *entity.Stock) error) (*entity.Stock, error) {
+	stockRepo := tx.GetStockRepository()
+	stock, err := stockRepo.FindByProductAndWarehouse(ctx, productID, warehouseID)
+	create := err != nil
+	if create {
+		stock, err = entity.NewStock(fmt.Sprintf("STK-%s-%s", productID, warehouseID), productID, warehouseID, 0)
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		// Get warehouse details
-		warehouse, err := s.warehouseRepo.FindByID(ctx, stock.WarehouseID)
+	before := stock.Quantity
+	if err := change(stock); err != nil {
+		return nil, err
+	}
+	if create {
+		err = stockRepo.Create(ctx, stock)
+	} else {
+		err = stockRepo.Update(ctx, stock)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save stock: %w", err)
+	}
+	if stock.Quantity != before {
+		if err := recordMovement(ctx, tx.GetStockMovementRepository(), stock, stock.Quantity-before, ref); err != nil {
+			return nil, err
+		}
+	}
+	return stock, nil
+}
+
+// RemoveStock deletes the stock record of a product in a warehouse and returns the units it
+// still held, which are first written off as an adjustment so the ledger keeps balancing.
+// A record with units reserved for orders or transfers is kept and ErrStockReserved returned.
+func (s *StockService) RemoveStock(ctx context.Context, productID, warehouseID string, ref MovementRef) (int, error) {
+	ref.Type = entity.StockMovementAdjustment
+	writtenOff := 0
+	err := s.inStockTransaction(ctx, func(tx repository.StockTransaction) error {
+		stockRepo := tx.GetStockRepository()
+		stock, err := stockRepo.FindByProductAndWarehouse(ctx, productID, warehouseID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to find warehouse %s: %w", stock.WarehouseID, err)
+			return fmt.Errorf("stock not found: %s in %s", productID, warehouseID)
+		}
+		if stock.Reserved > 0 {
+			return fmt.Errorf("%w: %d units of product %s in %s", ErrStockReserved, stock.Reserved, productID, warehouseID)
+		}
+		writtenOff = stock.Quantity
+		if writtenOff > 0 {
+			if _, err := s.changeStockWith(ctx, tx, productID, warehouseID, ref, func(stock *entity.Stock) error {
+				return stock.SetQuantity(0)
+			}); err != nil {
+				return err
+			}
 		}
+		if err := stockRepo.Delete(ctx, stock.ID); err != nil {
+			return fmt.Errorf("failed to delete stock: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if writtenOff > 0 {
+		s.stockChanged(productID)
+	}
+	return writtenOff, nil
+}
+
+// ListWarehouseStock lists the stock records of a warehouse by product ID
+func (s *StockService) ListWarehouseStock(ctx context.Context, warehouseID string) ([]*entity.Stock, error) {
+	if _, err := s.warehouseRepo.FindByID(ctx, warehouseID); err != nil {
+		return nil, fmt.Errorf("warehouse not found: %s", warehouseID)
+	}
+
+	stocks, err := s.stockRepo.FindByWarehouseID(ctx, warehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find stocks: %w", err)
+	}
+	sort.Slice(stocks, func(i, j int) bool { return stocks[i].ProductID < stocks[j].ProductID })
+	return stocks, nil
+}
+
+// ListProductStock lists the stock records of a product, reserved units included
+func (s *StockService) ListProductStock(ctx context.Context, productID string) ([]*entity.Stock, error) {
+	stocks, err := s.stockRepo.FindByProductID(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find stocks: %w", err)
+	}
+	return stocks, nil
+}
+
+// inStockTransaction runs fn in a new stock transaction and commits it. The whole function is
+// retried if a concurrent transaction touched the same stock rows first.
+func (s *StockService) inStockTransaction(ctx context.Context, fn func(tx repository.StockTransaction) error) error {
+	var err error
+	for attempt := 0; attempt < maxTransactionAttempts; attempt++ {
+		err = s.runStockTransaction(ctx, fn)
+		if !errors.Is(err, repository.ErrTransactionConflict) {
+			return err
+		}
+	}
+	return err
+}
+
+// runStockTransaction runs one attempt of fn in a new stock transaction
+func (s *StockService) runStockTransaction(ctx context.Context, fn func(tx repository.StockTransaction) error) error {
+	tx, err := s.stockRepo.BeginTransaction(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
 
-		// Determine how much to allocate from this warehouse
-		toAllocate := remaining
-		if stock.Quantity < remaining {
-			toAllocate = stock.Quantity
+	log := &movementLog{StockMovementRepository: tx.GetStockMovementRepository()}
+	err = fn(loggedStockTransaction{StockTransaction: tx, log: log})
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	s.stockMoved(log.movements)
+	return nil
+}
+
+// ReducePlanWith deducts the planned stock and records it in the ledger using the given
+// repositories, which are typically bound to a caller-managed transaction such as a unit of work
+func (s *StockService) ReducePlanWith(ctx context.Context, stockRepo repository.StockRepository, ledger repository.StockMovementRepository, plan *AllocationPlan, ref MovementRef) error {
+	for _, demand := range plan.Demands {
+		for _, allocation := range plan.Allocations[demand.ProductID] {
+			stock, err := stockRepo.FindByProductAndWarehouse(ctx, demand.ProductID, allocation.WarehouseID)
+			if err != nil {
+				return err
+			}
+
+			err = stock.Reduce(allocation.Quantity)
+			if err != nil {
+				return fmt.Errorf("failed to reduce stock: %w", err)
+			}
+			err = stockRepo.Update(ctx, stock)
+			if err != nil {
+				return fmt.Errorf("failed to update stock: %w", err)
+			}
+			err = recordMovement(ctx, ledger, stock, -allocation.Quantity, ref)
+			if err != nil {
+				return err
+			}
 		}
+	}
+	return nil
+}
 
-		if toAllocate > 0 {
-			// Reduce stock in this warehouse
-			err = stock.Reduce(toAllocate)
+// ReservePlanWith reserves the planned stock for a pending order using the given repositories,
+// which are typically bound to a unit of work. Reserved units are no longer available to other
+// orders until the reservations are committed or released.
+func (s *StockService) ReservePlanWith(
+	ctx context.Context,
+	stockRepo repository.StockRepository,
+	reservationRepo repository.StockReservationRepository,
+	orderID string,
+	plan *AllocationPlan,
+	expiresAt time.Time,
+) ([]*entity.StockReservation, error) {
+	reservations := []*entity.StockReservation{}
+	for _, demand := range plan.Demands {
+		productID := demand.ProductID
+		for _, allocation := range plan.Allocations[productID] {
+			stock, err := stockRepo.FindByProductAndWarehouse(ctx, productID, allocation.WarehouseID)
 			if err != nil {
-				return nil, fmt.Errorf("failed to reduce stock: %w", err)
+				return nil, err
 			}
 
-			// Update the stock record
+			err = stock.Reserve(allocation.Quantity)
+			if err != nil {
+				return nil, fmt.Errorf("failed to reserve stock: %w", err)
+			}
 			err = stockRepo.Update(ctx, stock)
 			if err != nil {
 				return nil, fmt.Errorf("failed to update stock: %w", err)
 			}
 
-			allocations = append(allocations, StockAllocation{
-				WarehouseID:   stock.WarehouseID,
-				WarehouseName: warehouse.Name,
-				Quantity:      toAllocate,
-			})
+			reservationID := fmt.Sprintf("RSV-%s-%s-%s", orderID, productID, stock.WarehouseID)
+			reservation, err := entity.NewStockReservation(reservationID, orderID, productID, stock.WarehouseID, allocation.Quantity, expiresAt)
+			if err != nil {
+				return nil, err
+			}
+			err = reservationRepo.Create(ctx, reservation)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create reservation: %w", err)
+			}
 
-			remaining -= toAllocate
+			reservations = append(reservations, reservation)
 		}
 	}
 
-	// Commit the transaction
-	err = tx.Commit()
+	return reservations, nil
+}
+
+// CommitReservationsWith turns every reservation of an order into a stock deduction, recorded
+// as a sale on behalf of userID, and deletes the reservations. It returns the committed
+// allocations keyed by product ID.
+func (s *StockService) CommitReservationsWith(
+	ctx context.Context,
+	stockRepo repository.StockRepository,
+	reservationRepo repository.StockReservationRepository,
+	ledger repository.StockMovementRepository,
+	orderID, userID string,
+) (map[string][]StockAllocation, error) {
+	ref := MovementRef{Type: entity.StockMovementSale, OrderID: orderID, UserID: userID}
+	reservations, err := reservationRepo.FindByOrderID(ctx, orderID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, fmt.Errorf("failed to find reservations: %w", err)
+	}
+
+	allocations := make(map[string][]StockAllocation)
+	for _, reservation := range reservations {
+		stock, err := stockRepo.FindByProductAndWarehouse(ctx, reservation.ProductID, reservation.WarehouseID)
+		if err != nil {
+			return nil, err
+		}
+		warehouse, err := s.warehouseRepo.FindByID(ctx, reservation.WarehouseID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find warehouse %s: %w", reservation.WarehouseID, err)
+		}
+
+		err = stock.CommitReservation(reservation.Quantity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to commit reservation %s: %w", reservation.ID, err)
+		}
+		err = stockRepo.Update(ctx, stock)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update stock: %w", err)
+		}
+		err = recordMovement(ctx, ledger, stock, -reservation.Quantity, ref)
+		if err != nil {
+			return nil, err
+		}
+		err = reservationRepo.Delete(ctx, reservation.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete reservation: %w", err)
+		}
+
+		allocations[reservation.ProductID] = append(allocations[reservation.ProductID], StockAllocation{
+			WarehouseID:   reservation.WarehouseID,
+			WarehouseName: warehouse.Name,
+			Quantity:      reservation.Quantity,
+		})
 	}
 
 	return allocations, nil
 }
 
-// RestoreStock restores stock to warehouses (for rollback scenarios)
-func (s *StockService) RestoreStock(ctx context.Context, productID string, allocations []StockAllocation) error {
+// ReleaseReservationsWith returns the reserved units to the available pool and deletes the reservations
+func (s *StockService) ReleaseReservationsWith(
+	ctx context.Context,
+	stockRepo repository.StockRepository,
+	reservationRepo repository.StockReservationRepository,
+	reservations []*entity.StockReservation,
+) error {
+	for _, reservation := range reservations {
+		stock, err := stockRepo.FindByProductAndWarehouse(ctx, reservation.ProductID, reservation.WarehouseID)
+		if err != nil {
+			return err
+		}
+
+		err = stock.Release(reservation.Quantity)
+		if err != nil {
+			return fmt.Errorf("failed to release reservation %s: %w", reservation.ID, err)
+		}
+		err = stockRepo.Update(ctx, stock)
+		if err != nil {
+			return fmt.Errorf("failed to update stock: %w", err)
+		}
+		err = reservationRepo.Delete(ctx, reservation.ID)
+		if err != nil {
+			return fmt.Errorf("failed to delete reservation: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// RestoreStockWith restores stock to the warehouses it was allocated from and records it in the
+// ledger using the given repositories, which are typically bound to a unit of work such as an
+// order cancellation
+func (s *StockService) RestoreStockWith(ctx context.Context, stockRepo repository.StockRepository, ledger repository.StockMovementRepository, productID string, allocations []StockAllocation, ref MovementRef) error {
 	for _, allocation := range allocations {
-		stock, err := s.stockRepo.FindByProductAndWarehouse(ctx, productID, allocation.WarehouseID)
+		stock, err := stockRepo.FindByProductAndWarehouse(ctx, productID, allocation.WarehouseID)
 		if err != nil {
 			// If stock record doesn't exist, create a new one
 			stockID := fmt.Sprintf("STK-%s-%s", productID, allocation.WarehouseID)
@@ -139,7 +585,7 @@ func (s *StockService) RestoreStock(ctx context.Context, productID string, alloc
 			if err != nil {
 				return fmt.Errorf("failed to create stock record: %w", err)
 			}
-			err = s.stockRepo.Create(ctx, stock)
+			err = stockRepo.Create(ctx, stock)
 			if err != nil {
 				return fmt.Errorf("failed to create stock: %w", err)
 			}
@@ -151,15 +597,38 @@ func (s *StockService) RestoreStock(ctx context.Context, productID string, alloc
 			return fmt.Errorf("failed to restore stock: %w", err)
 		}
 
-		err = s.stockRepo.Update(ctx, stock)
+		err = stockRepo.Update(ctx, stock)
 		if err != nil {
 			return fmt.Errorf("failed to update stock: %w", err)
 		}
+		err = recordMovement(ctx, ledger, stock, allocation.Quantity, ref)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// RestoreOrderItemWith returns an order line's units to the warehouses recorded in its allocations,
+// as needed when a paid order is cancelled or its goods come back
+func (s *StockService) RestoreOrderItemWith(ctx context.Context, stockRepo repository.StockRepository, ledger repository.StockMovementRepository, item entity.OrderItem, ref MovementRef) error {
+	allocations := make([]StockAllocation, len(item.Allocations))
+	for i, allocation := range item.Allocations {
+		allocations[i] = StockAllocation{
+			WarehouseID:   allocation.WarehouseID,
+			WarehouseName: allocation.WarehouseName,
+			Quantity:      allocation.Quantity,
+		}
+	}
+
+	err := s.RestoreStockWith(ctx, stockRepo, ledger, item.ProductID, allocations, ref)
+	if err != nil {
+		return fmt.Errorf("failed to restore stock for product %s: %w", item.ProductName, err)
+	}
+	return nil
+}
+
 // GetProductStockInfo gets stock information for a product across all warehouses
 func (s *StockService) GetProductStockInfo(ctx context.Context, productID string) ([]entity.StockInfo, int, error) {
 	stocks, err := s.stockRepo.FindByProductID(ctx, productID)
@@ -179,10 +648,92 @@ func (s *StockService) GetProductStockInfo(ctx context.Context, productID string
 		stockInfos = append(stockInfos, entity.StockInfo{
 			WarehouseID:   stock.WarehouseID,
 			WarehouseName: warehouse.Name,
-			Quantity:      stock.Quantity,
+			Quantity:      stock.Available(),
 		})
-		totalStock += stock.Quantity
+		totalStock += stock.Available()
 	}
 
 	return stockInfos, totalStock, nil
-}
\ No newline at end of file
+}
+
+// StockMovements returns the ledger entries matching the filter, oldest first
+func (s *StockService) StockMovements(ctx context.Context, filter repository.StockMovementFilter) ([]*entity.StockMovement, error) {
+	movements, err := s.movementRepo.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find stock movements: %w", err)
+	}
+	if movements == nil {
+		movements = []*entity.StockMovement{}
+	}
+	return movements, nil
+}
+
+// VerifyLedger replays the whole ledger and returns every stock record whose quantity differs
+// from the sum of its movements, ordered by product and warehouse. An empty result means the
+// ledger accounts for all stock.
+func (s *StockService) VerifyLedger(ctx context.Context) ([]LedgerDiscrepancy, error) {
+	type key struct{ productID, warehouseID string }
+	replayed := make(map[key]int)
+	movements, err := s.movementRepo.Find(ctx, repository.StockMovementFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find stock movements: %w", err)
+	}
+	for _, movement := range movements {
+		replayed[key{movement.ProductID, movement.WarehouseID}] += movement.Quantity
+	}
+
+	recorded := make(map[key]int)
+	warehouses, err := s.warehouseRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find warehouses: %w", err)
+	}
+	for _, warehouse := range warehouses {
+		stocks, err := s.stockRepo.FindByWarehouseID(ctx, warehouse.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find stocks: %w", err)
+		}
+		for _, stock := range stocks {
+			recorded[key{stock.ProductID, stock.WarehouseID}] = stock.Quantity
+		}
+	}
+
+	discrepancies := []LedgerDiscrepancy{}
+	check := func(k key) {
+		if recorded[k] != replayed[k] {
+			discrepancies = append(discrepancies, LedgerDiscrepancy{
+				ProductID:      k.productID,
+				WarehouseID:    k.warehouseID,
+				Quantity:       recorded[k],
+				LedgerQuantity: replayed[k],
+			})
+		}
+	}
+	for k := range recorded {
+		check(k)
+	}
+	for k := range replayed {
+		if _, ok := recorded[k]; !ok {
+			check(k)
+		}
+	}
+	sort.Slice(discrepancies, func(i, j int) bool {
+		if discrepancies[i].ProductID != discrepancies[j].ProductID {
+			return discrepancies[i].ProductID < discrepancies[j].ProductID
+		}
+		return discrepancies[i].WarehouseID < discrepancies[j].WarehouseID
+	})
+	return discrepancies, nil
+}
+
+// recordMovement appends a change of quantity units to the ledger; stock already holds the new quantity
+func recordMovement(ctx context.Context, ledger repository.StockMovementRepository, stock *entity.Stock, quantity int, ref MovementRef) error {
+	id := fmt.Sprintf("MOV-%d-%d", time.Now().UnixNano(), movementSeq.Add(1))
+	movement, err := entity.NewStockMovement(id, stock, ref.Type, quantity, ref.OrderID, ref.UserID, ref.Note)
+	if err != nil {
+		return err
+	}
+	if err := ledger.Append(ctx, movement); err != nil {
+		return fmt.Errorf("failed to record stock movement: %w", err)
+	}
+	return nil
+}
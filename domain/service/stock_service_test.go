@@ -0,0 +1,212 @@
+package service_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/persistence"
+)
+
+type stockFixture struct {
+	productRepo   *persistence.MemoryProductRepository
+	warehouseRepo repository.WarehouseRepository
+	stockRepo     *persistence.MemoryStockRepository
+	stockService  *service.StockService
+}
+
+// newStockFixture sets up one product with a single unit left in each of two warehouses
+func newStockFixture(t *testing.T) *stockFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	productRepo := persistence.NewMemoryProductRepository()
+	warehouseRepo := persistence.NewMemoryWarehouseRepository()
+	stockRepo := persistence.NewMemoryStockRepository()
+
+	product, _ := entity.NewProduct("P001", "Desk", 300, "Furniture")
+	productRepo.Create(ctx, product)
+	stockService := service.NewStockService(stockRepo, warehouseRepo, stockRepo.Ledger(), service.NewPriorityAllocation(nil))
+	for _, id := range []string{"WH-001", "WH-002"} {
+		warehouse, _ := entity.NewWarehouse(id, id, "")
+		warehouseRepo.Create(ctx, warehouse)
+		restock := service.MovementRef{Type: entity.StockMovementRestock}
+		if _, err := stockService.AdjustStockLevel(ctx, "P001", id, 1, restock); err != nil {
+			t.Fatalf("AdjustStockLevel failed: %v", err)
+		}
+	}
+
+	return &stockFixture{
+		productRepo:   productRepo,
+		warehouseRepo: warehouseRepo,
+		stockRepo:     stockRepo,
+		stockService:  stockService,
+	}
+}
+
+// available returns the units of the desk that can still be sold
+func (f *stockFixture) available(t *testing.T) int {
+	t.Helper()
+	_, available, err := f.stockService.CheckAvailability(context.Background(), "P001", 0)
+	if err != nil {
+		t.Fatalf("CheckAvailability failed: %v", err)
+	}
+	return available
+}
+
+// quantity returns the units of the desk held across all warehouses, reserved or not
+func (f *stockFixture) quantity(t *testing.T) int {
+	t.Helper()
+	stocks, _ := f.stockRepo.FindByProductID(context.Background(), "P001")
+	total := 0
+	for _, stock := range stocks {
+		total += stock.Quantity
+	}
+	return total
+}
+
+func TestStockService_SetAndAdjustStockLevel(t *testing.T) {
+	ctx := context.Background()
+	f := newOrderFixture(t)
+	adjustment := service.MovementRef{Type: entity.StockMovementAdjustment}
+
+	// Reserve the unit in WH-001 for a pending order
+	if _, err := f.orderService.ProcessOrder(ctx, "USER-001", []service.OrderRequest{{ProductID: "P001", Quantity: 1}}, "", nil); err != nil {
+		t.Fatalf("ProcessOrder failed: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		change       func() error
+		wantErr      bool
+		wantQuantity int
+	}{
+		{"add units", func() error {
+			_, err := f.stockService.AdjustStockLevel(ctx, "P001", "WH-001", 4, adjustment)
+			return err
+		}, false, 5},
+		{"remove available units", func() error {
+			_, err := f.stockService.AdjustStockLevel(ctx, "P001", "WH-001", -3, adjustment)
+			return err
+		}, false, 2},
+		{"cannot remove reserved units", func() error {
+			_, err := f.stockService.AdjustStockLevel(ctx, "P001", "WH-001", -2, adjustment)
+			return err
+		}, true, 2},
+		{"zero adjustment", func() error {
+			_, err := f.stockService.AdjustStockLevel(ctx, "P001", "WH-001", 0, adjustment)
+			return err
+		}, true, 2},
+		{"set after a stocktake", func() error {
+			_, err := f.stockService.SetStockLevel(ctx, "P001", "WH-001", 7, service.MovementRef{})
+			return err
+		}, false, 7},
+		{"cannot set below reserved", func() error {
+			_, err := f.stockService.SetStockLevel(ctx, "P001", "WH-001", 0, service.MovementRef{})
+			return err
+		}, true, 7},
+		{"unknown warehouse", func() error {
+			_, err := f.stockService.SetStockLevel(ctx, "P001", "WH-404", 1, service.MovementRef{})
+			return err
+		}, true, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.change()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			stock, _ := f.stockRepo.FindByProductAndWarehouse(ctx, "P001", "WH-001")
+			if stock.Quantity != tt.wantQuantity || stock.Reserved != 1 {
+				t.Errorf("Expected quantity %d with 1 reserved, got %d with %d reserved", tt.wantQuantity, stock.Quantity, stock.Reserved)
+			}
+		})
+	}
+
+	// Setting stock in a warehouse that never held the product creates the record
+	if _, err := f.stockService.SetStockLevel(ctx, "P002", "WH-002", 3, service.MovementRef{}); err != nil {
+		t.Fatalf("SetStockLevel failed: %v", err)
+	}
+	stocks, _ := f.stockService.ListWarehouseStock(ctx, "WH-002")
+	if len(stocks) != 2 || stocks[0].ProductID != "P001" || stocks[1].ProductID != "P002" || stocks[1].Quantity != 3 {
+		t.Errorf("Expected P001 and 3 units of P002 in WH-002, got %+v", stocks)
+	}
+}
+
+func TestStockService_LedgerReplaysStock(t *testing.T) {
+	ctx := context.Background()
+	f := newOrderFixture(t)
+
+	// Sell both units, cancel the order, then correct the stock by hand
+	order, err := f.orderService.ProcessOrder(ctx, "USER-001", []service.OrderRequest{{ProductID: "P001", Quantity: 2}}, "", nil)
+	if err != nil {
+		t.Fatalf("ProcessOrder failed: %v", err)
+	}
+	if err := f.orderService.ConfirmOrderAndReduceStock(ctx, order); err != nil {
+		t.Fatalf("ConfirmOrderAndReduceStock failed: %v", err)
+	}
+	if _, _, err := f.orderService.CancelOrderAndRestoreStock(ctx, order.ID, "ADMIN-001", true, "customer request"); err != nil {
+		t.Fatalf("CancelOrderAndRestoreStock failed: %v", err)
+	}
+	restock := service.MovementRef{Type: entity.StockMovementRestock, UserID: "ADMIN-001"}
+	if _, err := f.stockService.AdjustStockLevel(ctx, "P001", "WH-001", 5, restock); err != nil {
+		t.Fatalf("AdjustStockLevel failed: %v", err)
+	}
+	damaged := service.MovementRef{Type: entity.StockMovementAdjustment, UserID: "ADMIN-001", Note: "damaged"}
+	if _, err := f.stockService.AdjustStockLevel(ctx, "P001", "WH-002", -1, damaged); err != nil {
+		t.Fatalf("AdjustStockLevel failed: %v", err)
+	}
+	if _, err := f.stockService.SetStockLevel(ctx, "P001", "WH-001", 4, service.MovementRef{UserID: "ADMIN-001"}); err != nil {
+		t.Fatalf("SetStockLevel failed: %v", err)
+	}
+	if _, err := f.stockService.AdjustStockLevel(ctx, "P001", "WH-001", -1, restock); err == nil {
+		t.Error("Expected a negative restock to be rejected")
+	}
+
+	movements, err := f.stockService.StockMovements(ctx, repository.StockMovementFilter{ProductID: "P001", WarehouseID: "WH-001"})
+	if err != nil {
+		t.Fatalf("StockMovements failed: %v", err)
+	}
+	var got []string
+	for _, m := range movements {
+		got = append(got, string(m.Type))
+	}
+	expected := []string{"restock", "sale", "cancellation_return", "restock", "stocktake"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected movements %v, got %v", expected, got)
+	}
+	if last := movements[len(movements)-1]; last.Quantity != -2 || last.QuantityAfter != 4 {
+		t.Errorf("Expected the stocktake to record -2 leaving 4, got %d leaving %d", last.Quantity, last.QuantityAfter)
+	}
+
+	// Order movements carry the order and the acting user
+	orderMovements, _ := f.stockService.StockMovements(ctx, repository.StockMovementFilter{OrderID: order.ID})
+	if len(orderMovements) != 4 {
+		t.Fatalf("Expected 2 sales and 2 returns for the order, got %d", len(orderMovements))
+	}
+	if orderMovements[0].UserID != "USER-001" || orderMovements[3].UserID != "ADMIN-001" || orderMovements[3].Note != "customer request" {
+		t.Errorf("Expected the sale by USER-001 and the return by ADMIN-001, got %+v and %+v", orderMovements[0], orderMovements[3])
+	}
+
+	discrepancies, err := f.stockService.VerifyLedger(ctx)
+	if err != nil {
+		t.Fatalf("VerifyLedger failed: %v", err)
+	}
+	if len(discrepancies) != 0 {
+		t.Errorf("Expected the ledger to reproduce all stock, got %+v", discrepancies)
+	}
+
+	// A change made behind the ledger's back is reported
+	stock, _ := f.stockRepo.FindByProductAndWarehouse(ctx, "P001", "WH-002")
+	stock.Quantity = 9
+	f.stockRepo.Update(ctx, stock)
+	discrepancies, _ = f.stockService.VerifyLedger(ctx)
+	want := []service.LedgerDiscrepancy{{ProductID: "P001", WarehouseID: "WH-002", Quantity: 9, LedgerQuantity: 0}}
+	if !reflect.DeepEqual(discrepancies, want) {
+		t.Errorf("Expected %+v, got %+v", want, discrepancies)
+	}
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// StockTransferService moves stock between warehouses. A requested transfer holds its units in
+// the source warehouse, shipping debits them from the source and receiving credits them to the
+// destination, each step in one stock transaction. Units in transit are not sellable anywhere.
+type StockTransferService struct {
+	stockService  *StockService
+	warehouseRepo repository.WarehouseRepository
+	transferRepo  repository.StockTransferRepository
+}
+
+// NewStockTransferService creates a new stock transfer service
+func NewStockTransferService(stockService *StockService, warehouseRepo repository.WarehouseRepository, transferRepo repository.StockTransferRepository) *StockTransferService {
+	return &StockTransferService{
+		stockService:  stockService,
+		warehouseRepo: warehouseRepo,
+		transferRepo:  transferRepo,
+	}
+}
+
+// RequestTransfer requests moving quantity units of a product between two warehouses and holds
+// them in the source warehouse until the transfer ships or is cancelled
+func (s *StockTransferService) RequestTransfer(ctx context.Context, productID, fromWarehouseID, toWarehouseID string, quantity int, requestedBy, note string) (*entity.StockTransfer, error) {
+	for _, warehouseID := range []string{fromWarehouseID, toWarehouseID} {
+		if _, err := s.warehouseRepo.FindByID(ctx, warehouseID); err != nil {
+			return nil, fmt.Errorf("warehouse not found: %s", warehouseID)
+		}
+	}
+	transfer, err := entity.NewStockTransfer(generateTransferID(), productID, fromWarehouseID, toWarehouseID, quantity, requestedBy, note)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.stockService.inStockTransaction(ctx, func(tx repository.StockTransaction) error {
+		stockRepo := tx.GetStockRepository()
+		stock, err := stockRepo.FindByProductAndWarehouse(ctx, productID, fromWarehouseID)
+		if err != nil {
+			return fmt.Errorf("insufficient stock in %s: %w", fromWarehouseID, err)
+		}
+		if err := stock.Reserve(quantity); err != nil {
+			return fmt.Errorf("insufficient stock in %s: %w", fromWarehouseID, err)
+		}
+		if err := stockRepo.Update(ctx, stock); err != nil {
+			return fmt.Errorf("failed to update stock: %w", err)
+		}
+		if err := tx.GetStockTransferRepository().Create(ctx, transfer); err != nil {
+			return fmt.Errorf("failed to save stock transfer: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.stockService.stockChanged(productID)
+	return transfer, nil
+}
+
+// ShipTransfer debits the held units from the source warehouse and marks the transfer in transit
+func (s *StockTransferService) ShipTransfer(ctx context.Context, id, actorID string) (*entity.StockTransfer, error) {
+	return s.updateTransfer(ctx, id, func(tx repository.StockTransaction, transfer *entity.StockTransfer) error {
+		if err := transfer.Ship(); err != nil {
+			return err
+		}
+		ref := MovementRef{Type: entity.StockMovementTransfer, UserID: actorID, Note: fmt.Sprintf("%s to %s", transfer.ID, transfer.ToWarehouseID)}
+		_, err := s.stockService.changeStockWith(ctx, tx, transfer.ProductID, transfer.FromWarehouseID, ref, func(stock *entity.Stock) error {
+			return stock.CommitReservation(transfer.Quantity)
+		})
+		return err
+	})
+}
+
+// ReceiveTransfer credits the units to the destination warehouse and marks the transfer received
+func (s *StockTransferService) ReceiveTransfer(ctx context.Context, id, actorID string) (*entity.StockTransfer, error) {
+	return s.updateTransfer(ctx, id, func(tx repository.StockTransaction, transfer *entity.StockTransfer) error {
+		if _, err := s.warehouseRepo.FindByID(ctx, transfer.ToWarehouseID); err != nil {
+			return fmt.Errorf("warehouse not found: %s", transfer.ToWarehouseID)
+		}
+		if err := transfer.Receive(); err != nil {
+			return err
+		}
+		ref := MovementRef{Type: entity.StockMovementTransfer, UserID: actorID, Note: fmt.Sprintf("%s from %s", transfer.ID, transfer.FromWarehouseID)}
+		_, err := s.stockService.changeStockWith(ctx, tx, transfer.ProductID, transfer.ToWarehouseID, ref, func(stock *entity.Stock) error {
+			return stock.Add(transfer.Quantity)
+		})
+		return err
+	})
+}
+
+// CancelTransfer cancels a transfer that has not shipped and releases its hold on the source stock
+func (s *StockTransferService) CancelTransfer(ctx context.Context, id string) (*entity.StockTransfer, error) {
+	return s.updateTransfer(ctx, id, func(tx repository.StockTransaction, transfer *entity.StockTransfer) error {
+		if err := transfer.Cancel(); err != nil {
+			return err
+		}
+		stockRepo := tx.GetStockRepository()
+		stock, err := stockRepo.FindByProductAndWarehouse(ctx, transfer.ProductID, transfer.FromWarehouseID)
+		if err != nil {
+			return err
+		}
+		if err := stock.Release(transfer.Quantity); err != nil {
+			return err
+		}
+		if err := stockRepo.Update(ctx, stock); err != nil {
+			return fmt.Errorf("failed to update stock: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetTransfer returns a stock transfer by ID
+func (s *StockTransferService) GetTransfer(ctx context.Context, id string) (*entity.StockTransfer, error) {
+	transfer, err := s.transferRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("stock transfer not found: %s", id)
+	}
+	return transfer, nil
+}
+
+// ListTransfers lists transfers newest first, only those with the given status unless it is empty
+func (s *StockTransferService) ListTransfers(ctx context.Context, status entity.StockTransferStatus) ([]*entity.StockTransfer, error) {
+	transfers, err := s.transferRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find stock transfers: %w", err)
+	}
+
+	result := []*entity.StockTransfer{}
+	for _, transfer := range transfers {
+		if status == "" || transfer.Status == status {
+			result = append(result, transfer)
+		}
+	}
+	return result, nil
+}
+
+// InTransitTo returns the units of each product on their way to a warehouse, keyed by product ID
+func (s *StockTransferService) InTransitTo(ctx context.Context, warehouseID string) (map[string]int, error) {
+	transfers, err := s.ListTransfers(ctx, entity.StockTransferInTransit)
+	if err != nil {
+		return nil, err
+	}
+
+	inTransit := make(map[string]int)
+	for _, transfer := range transfers {
+		if transfer.ToWarehouseID == warehouseID {
+			inTransit[transfer.ProductID] += transfer.Quantity
+		}
+	}
+	return inTransit, nil
+}
+
+// updateTransfer re-reads a transfer inside a stock transaction, applies fn to it and saves it.
+// Reading the transfer in the transaction makes a concurrent status change conflict on commit.
+func (s *StockTransferService) updateTransfer(ctx context.Context, id string, fn func(tx repository.StockTransaction, transfer *entity.StockTransfer) error) (*entity.StockTransfer, error) {
+	var updated *entity.StockTransfer
+	err := s.stockService.inStockTransaction(ctx, func(tx repository.StockTransaction) error {
+		transferRepo := tx.GetStockTransferRepository()
+		transfer, err := transferRepo.FindByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("stock transfer not found: %s", id)
+		}
+		if err := fn(tx, transfer); err != nil {
+			return err
+		}
+		if err := transferRepo.Update(ctx, transfer); err != nil {
+			return fmt.Errorf("failed to update stock transfer: %w", err)
+		}
+		updated = transfer
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.stockService.stockChanged(updated.ProductID)
+	return updated, nil
+}
+
+// generateTransferID generates a unique stock transfer ID
+func generateTransferID() string {
+	return fmt.Sprintf("TRF-%d-%d", time.Now().Unix(), time.Now().Nanosecond())
+}
@@ -0,0 +1,105 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+)
+
+func TestStockTransferService_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	f := newStockFixture(t)
+	transferService := service.NewStockTransferService(f.stockService, f.warehouseRepo, f.stockRepo.Transfers())
+
+	expectStock := func(warehouseID string, quantity, reserved int) {
+		t.Helper()
+		stock, _ := f.stockRepo.FindByProductAndWarehouse(ctx, "P001", warehouseID)
+		if stock.Quantity != quantity || stock.Reserved != reserved {
+			t.Errorf("Expected %s to hold %d with %d reserved, got %d with %d", warehouseID, quantity, reserved, stock.Quantity, stock.Reserved)
+		}
+	}
+
+	if _, err := transferService.RequestTransfer(ctx, "P001", "WH-001", "WH-002", 2, "ADMIN-001", ""); err == nil {
+		t.Error("Expected a transfer of more than the source holds to be rejected")
+	}
+	if _, err := transferService.RequestTransfer(ctx, "P001", "WH-001", "WH-001", 1, "ADMIN-001", ""); err == nil {
+		t.Error("Expected a transfer within one warehouse to be rejected")
+	}
+	restock := service.MovementRef{Type: entity.StockMovementRestock}
+	if _, err := f.stockService.AdjustStockLevel(ctx, "P001", "WH-001", 2, restock); err != nil {
+		t.Fatalf("AdjustStockLevel failed: %v", err)
+	}
+
+	// Requesting holds the units in the source warehouse
+	transfer, err := transferService.RequestTransfer(ctx, "P001", "WH-001", "WH-002", 2, "ADMIN-001", "rebalance")
+	if err != nil {
+		t.Fatalf("RequestTransfer failed: %v", err)
+	}
+	expectStock("WH-001", 3, 2)
+	if got := f.available(t); got != 2 {
+		t.Errorf("Expected held units to be unsellable, got %d available", got)
+	}
+	if _, err := transferService.ReceiveTransfer(ctx, transfer.ID, "ADMIN-001"); !errors.Is(err, entity.ErrInvalidTransferTransition) {
+		t.Errorf("Expected receiving an unshipped transfer to fail, got %v", err)
+	}
+
+	// In transit the units are in neither warehouse
+	if _, err := transferService.ShipTransfer(ctx, transfer.ID, "ADMIN-001"); err != nil {
+		t.Fatalf("ShipTransfer failed: %v", err)
+	}
+	expectStock("WH-001", 1, 0)
+	expectStock("WH-002", 1, 0)
+	if got := f.available(t); got != 2 {
+		t.Errorf("Expected units in transit to be unsellable, got %d available", got)
+	}
+	if inTransit, _ := transferService.InTransitTo(ctx, "WH-002"); inTransit["P001"] != 2 {
+		t.Errorf("Expected 2 units in transit to WH-002, got %d", inTransit["P001"])
+	}
+	if _, err := transferService.CancelTransfer(ctx, transfer.ID); !errors.Is(err, entity.ErrInvalidTransferTransition) {
+		t.Errorf("Expected cancelling a shipped transfer to fail, got %v", err)
+	}
+
+	received, err := transferService.ReceiveTransfer(ctx, transfer.ID, "ADMIN-001")
+	if err != nil {
+		t.Fatalf("ReceiveTransfer failed: %v", err)
+	}
+	if received.Status != entity.StockTransferReceived || received.ShippedAt == nil || received.ReceivedAt == nil {
+		t.Errorf("Expected a received transfer with both timestamps, got %+v", received)
+	}
+	expectStock("WH-002", 3, 0)
+	if _, err := transferService.ReceiveTransfer(ctx, transfer.ID, "ADMIN-001"); !errors.Is(err, entity.ErrInvalidTransferTransition) {
+		t.Errorf("Expected a second receipt to fail, got %v", err)
+	}
+
+	// Cancelling a requested transfer releases the hold
+	cancelled, err := transferService.RequestTransfer(ctx, "P001", "WH-002", "WH-001", 1, "ADMIN-001", "")
+	if err != nil {
+		t.Fatalf("RequestTransfer failed: %v", err)
+	}
+	if _, err := transferService.CancelTransfer(ctx, cancelled.ID); err != nil {
+		t.Fatalf("CancelTransfer failed: %v", err)
+	}
+	expectStock("WH-002", 3, 0)
+
+	// Both legs are in the ledger, which still reproduces the stock
+	movements, _ := f.stockService.StockMovements(ctx, repository.StockMovementFilter{ProductID: "P001"})
+	var legs []int
+	for _, m := range movements {
+		if m.Type == entity.StockMovementTransfer {
+			legs = append(legs, m.Quantity)
+		}
+	}
+	if len(legs) != 2 || legs[0] != -2 || legs[1] != 2 {
+		t.Errorf("Expected transfer movements [-2 2], got %v", legs)
+	}
+	if discrepancies, _ := f.stockService.VerifyLedger(ctx); len(discrepancies) != 0 {
+		t.Errorf("Expected the ledger to reproduce all stock, got %+v", discrepancies)
+	}
+	if transfers, _ := transferService.ListTransfers(ctx, entity.StockTransferCancelled); len(transfers) != 1 || transfers[0].ID != cancelled.ID {
+		t.Errorf("Expected only %s to be cancelled, got %+v", cancelled.ID, transfers)
+	}
+}
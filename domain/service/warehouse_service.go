@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// ErrWarehouseHasStock is returned when deleting a warehouse that still holds or has reserved stock
+var ErrWarehouseHasStock = errors.New("warehouse still holds stock")
+
+// WarehouseService handles the warehouses stock is kept in
+type WarehouseService struct {
+	warehouseRepo repository.WarehouseRepository
+	stockRepo     repository.StockRepository
+	transferRepo  repository.StockTransferRepository
+}
+
+// NewWarehouseService creates a new warehouse service
+func NewWarehouseService(warehouseRepo repository.WarehouseRepository, stockRepo repository.StockRepository, transferRepo repository.StockTransferRepository) *WarehouseService {
+	return &WarehouseService{
+		warehouseRepo: warehouseRepo,
+		stockRepo:     stockRepo,
+		transferRepo:  transferRepo,
+	}
+}
+
+// ListWarehouses lists all warehouses by ID
+func (s *WarehouseService) ListWarehouses(ctx context.Context) ([]*entity.Warehouse, error) {
+	warehouses, err := s.warehouseRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list warehouses: %w", err)
+	}
+	if warehouses == nil {
+		warehouses = []*entity.Warehouse{}
+	}
+	return warehouses, nil
+}
+
+// CreateWarehouse creates a warehouse with the given ID
+func (s *WarehouseService) CreateWarehouse(ctx context.Context, id, name, location string) (*entity.Warehouse, error) {
+	warehouse, err := entity.NewWarehouse(id, name, location)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.warehouseRepo.Create(ctx, warehouse); err != nil {
+		return nil, fmt.Errorf("failed to create warehouse: %w", err)
+	}
+	return warehouse, nil
+}
+
+// UpdateWarehouse replaces the name and location of a warehouse
+func (s *WarehouseService) UpdateWarehouse(ctx context.Context, id, name, location string) (*entity.Warehouse, error) {
+	warehouse, err := s.warehouseRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("warehouse not found: %s", id)
+	}
+	if err := warehouse.Update(name, location); err != nil {
+		return nil, err
+	}
+	if err := s.warehouseRepo.Update(ctx, warehouse); err != nil {
+		return nil, fmt.Errorf("failed to update warehouse: %w", err)
+	}
+	return warehouse, nil
+}
+
+// DeleteWarehouse deletes a warehouse. A warehouse still holding stock, stock reserved for
+// pending orders or transfers, or awaiting an open transfer cannot be deleted; its empty stock
+// records are deleted with it.
+func (s *WarehouseService) DeleteWarehouse(ctx context.Context, id string) error {
+	if _, err := s.warehouseRepo.FindByID(ctx, id); err != nil {
+		return fmt.Errorf("warehouse not found: %s", id)
+	}
+
+	stocks, err := s.stockRepo.FindByWarehouseID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to find stocks: %w", err)
+	}
+	for _, stock := range stocks {
+		if stock.Quantity > 0 || stock.Reserved > 0 {
+			return fmt.Errorf("%w: %d units of product %s", ErrWarehouseHasStock, stock.Quantity, stock.ProductID)
+		}
+	}
+	transfers, err := s.transferRepo.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to find stock transfers: %w", err)
+	}
+	for _, transfer := range transfers {
+		if transfer.IsOpen() && transfer.ToWarehouseID == id {
+			return fmt.Errorf("%w: transfer %s of %d units is on its way", ErrWarehouseHasStock, transfer.ID, transfer.Quantity)
+		}
+	}
+	for _, stock := range stocks {
+		if err := s.stockRepo.Delete(ctx, stock.ID); err != nil {
+			return fmt.Errorf("failed to delete stock: %w", err)
+		}
+	}
+
+	return s.warehouseRepo.Delete(ctx, id)
+}
@@ -0,0 +1,45 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/persistence"
+)
+
+func TestWarehouseService_DeleteRequiresEmptyStock(t *testing.T) {
+	ctx := context.Background()
+	warehouseRepo := persistence.NewMemoryWarehouseRepository()
+	stockRepo := persistence.NewMemoryStockRepository()
+	warehouseService := service.NewWarehouseService(warehouseRepo, stockRepo, stockRepo.Transfers())
+	stockService := service.NewStockService(stockRepo, warehouseRepo, stockRepo.Ledger(), service.NewPriorityAllocation(nil))
+	adjustment := service.MovementRef{Type: entity.StockMovementAdjustment}
+
+	if _, err := warehouseService.CreateWarehouse(ctx, "WH-009", "札幌倉庫", "北海道札幌市"); err != nil {
+		t.Fatalf("CreateWarehouse failed: %v", err)
+	}
+	if _, err := warehouseService.CreateWarehouse(ctx, "WH-009", "札幌倉庫", ""); err == nil {
+		t.Error("Expected a duplicate warehouse ID to be rejected")
+	}
+	if _, err := stockService.SetStockLevel(ctx, "P001", "WH-009", 2, service.MovementRef{}); err != nil {
+		t.Fatalf("SetStockLevel failed: %v", err)
+	}
+
+	if err := warehouseService.DeleteWarehouse(ctx, "WH-009"); !errors.Is(err, service.ErrWarehouseHasStock) {
+		t.Errorf("Expected ErrWarehouseHasStock, got %v", err)
+	}
+
+	// Once emptied, the warehouse and its empty stock records can go
+	if _, err := stockService.AdjustStockLevel(ctx, "P001", "WH-009", -2, adjustment); err != nil {
+		t.Fatalf("AdjustStockLevel failed: %v", err)
+	}
+	if err := warehouseService.DeleteWarehouse(ctx, "WH-009"); err != nil {
+		t.Fatalf("DeleteWarehouse failed: %v", err)
+	}
+	if stocks, _ := stockRepo.FindByWarehouseID(ctx, "WH-009"); len(stocks) != 0 {
+		t.Errorf("Expected the empty stock records to be deleted, got %d", len(stocks))
+	}
+}
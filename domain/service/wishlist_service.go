@@ -38,11 +38,14 @@ func (s *WishlistService) AddToWishlist(ctx context.Context, userID, productID s
 		return fmt.Errorf("user not found: %w", err)
 	}
 
-	// Verify product exists
-	_, err = s.productRepo.FindByID(ctx, productID)
+	// Verify product exists and is still sold
+	product, err := s.productRepo.FindByID(ctx, productID)
 	if err != nil {
 		return fmt.Errorf("product not found: %w", err)
 	}
+	if product.IsArchived() {
+		return fmt.Errorf("%w: %s", entity.ErrProductArchived, productID)
+	}
 
 	// Check if already in wishlist
 	_, err = s.wishlistRepo.FindByUserAndProduct(ctx, userID, productID)
@@ -145,8 +148,8 @@ func (s *WishlistService) GetRecommendations(ctx context.Context, userID string,
 
 	recommendations := make([]*RecommendationItem, 0)
 	for _, product := range allProducts {
-		// Skip if already in wishlist
-		if wishlistProductIDs[product.ID] {
+		// Skip if already in wishlist, no longer sold or a variant, which is shown with its parent
+		if wishlistProductIDs[product.ID] || product.IsArchived() || product.IsVariant() {
 			continue
 		}
 
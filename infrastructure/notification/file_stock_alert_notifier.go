@@ -0,0 +1,43 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+)
+
+// FileStockAlertNotifier appends stock alerts to a file as one JSON object per line
+type FileStockAlertNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileStockAlertNotifier creates a notifier appending to the file at path, created if missing
+func NewFileStockAlertNotifier(path string) *FileStockAlertNotifier {
+	return &FileStockAlertNotifier{path: path}
+}
+
+// NotifyStockAlert appends the alert to the file
+func (n *FileStockAlertNotifier) NotifyStockAlert(ctx context.Context, alert entity.StockAlert) error {
+	line, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to encode stock alert: %w", err)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	file, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open stock alert file: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write stock alert: %w", err)
+	}
+	return file.Close()
+}
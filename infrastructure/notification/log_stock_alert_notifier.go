@@ -0,0 +1,23 @@
+package notification
+
+import (
+	"context"
+	"log"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+)
+
+// LogStockAlertNotifier writes stock alerts to the standard logger
+type LogStockAlertNotifier struct{}
+
+// NewLogStockAlertNotifier creates a new log stock alert notifier
+func NewLogStockAlertNotifier() *LogStockAlertNotifier {
+	return &LogStockAlertNotifier{}
+}
+
+// NotifyStockAlert logs the alert
+func (n *LogStockAlertNotifier) NotifyStockAlert(ctx context.Context, alert entity.StockAlert) error {
+	log.Printf("Stock alert: Type=%s, ProductID=%s, WarehouseID=%s, Available=%d, ReorderPoint=%d",
+		alert.Type, alert.ProductID, alert.WarehouseID, alert.Available, alert.ReorderPoint)
+	return nil
+}
@@ -56,6 +56,24 @@ func (s *SimulatedPaymentService) ProcessPayment(ctx context.Context, amount int
 	return success, nil
 }
 
+// RefundPayment simulates refunding a payment. Refunds always succeed unless the context is cancelled.
+func (s *SimulatedPaymentService) RefundPayment(ctx context.Context, amount int, userID string, orderID string) error {
+	log.Printf("Processing refund: OrderID=%s, UserID=%s, Amount=%d", orderID, userID, amount)
+
+	// Simulate network delay (50-200ms)
+	delay := time.Duration(50+rand.Intn(150)) * time.Millisecond
+	time.Sleep(delay)
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	log.Printf("Refund successful: RefundID=RFD-%d-%s", time.Now().Unix(), orderID)
+	return nil
+}
+
 // SetSuccessRate allows changing the success rate for testing
 func (s *SimulatedPaymentService) SetSuccessRate(rate float64) {
 	if rate < 0 {
@@ -0,0 +1,115 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// MemoryAddressRepository is an in-memory implementation of AddressRepository
+type MemoryAddressRepository struct {
+	mu        sync.RWMutex
+	addresses map[string]*entity.Address
+}
+
+// NewMemoryAddressRepository creates a new in-memory address repository
+func NewMemoryAddressRepository() repository.AddressRepository {
+	return &MemoryAddressRepository{
+		addresses: make(map[string]*entity.Address),
+	}
+}
+
+// Create creates a new address
+func (r *MemoryAddressRepository) Create(ctx context.Context, address *entity.Address) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.addresses[address.ID]; exists {
+		return errors.New("address already exists")
+	}
+	addressCopy := *address
+	r.addresses[address.ID] = &addressCopy
+	return nil
+}
+
+// FindByID finds an address by its ID
+func (r *MemoryAddressRepository) FindByID(ctx context.Context, id string) (*entity.Address, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	address, exists := r.addresses[id]
+	if !exists {
+		return nil, errors.New("address not found")
+	}
+	addressCopy := *address
+	return &addressCopy, nil
+}
+
+// FindByUserID returns a user's addresses, oldest first
+func (r *MemoryAddressRepository) FindByUserID(ctx context.Context, userID string) ([]*entity.Address, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*entity.Address
+	for _, address := range r.addresses {
+		if address.UserID == userID {
+			addressCopy := *address
+			result = append(result, &addressCopy)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// Update updates an address; its default flag is changed only through SetDefault
+func (r *MemoryAddressRepository) Update(ctx context.Context, address *entity.Address) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.addresses[address.ID]
+	if !exists {
+		return errors.New("address not found")
+	}
+	addressCopy := *address
+	addressCopy.IsDefault = existing.IsDefault
+	r.addresses[address.ID] = &addressCopy
+	return nil
+}
+
+// Delete deletes an address
+func (r *MemoryAddressRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.addresses[id]; !exists {
+		return errors.New("address not found")
+	}
+	delete(r.addresses, id)
+	return nil
+}
+
+// SetDefault makes an address its user's default and clears the flag on the user's other addresses
+func (r *MemoryAddressRepository) SetDefault(ctx context.Context, userID, addressID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	target, exists := r.addresses[addressID]
+	if !exists || target.UserID != userID {
+		return errors.New("address not found")
+	}
+	for _, address := range r.addresses {
+		if address.UserID == userID {
+			address.IsDefault = address.ID == addressID
+		}
+	}
+	return nil
+}
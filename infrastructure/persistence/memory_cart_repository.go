@@ -0,0 +1,60 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// MemoryCartRepository is an in-memory implementation of CartRepository
+type MemoryCartRepository struct {
+	mu    sync.RWMutex
+	carts map[string]*entity.Cart // key: user ID
+}
+
+// NewMemoryCartRepository creates a new in-memory cart repository
+func NewMemoryCartRepository() repository.CartRepository {
+	return &MemoryCartRepository{
+		carts: make(map[string]*entity.Cart),
+	}
+}
+
+// FindByUserID finds the cart of a user
+func (r *MemoryCartRepository) FindByUserID(ctx context.Context, userID string) (*entity.Cart, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cart, exists := r.carts[userID]
+	if !exists {
+		return nil, errors.New("cart not found")
+	}
+	return cloneCart(cart), nil
+}
+
+// Save creates or replaces the cart of its user
+func (r *MemoryCartRepository) Save(ctx context.Context, cart *entity.Cart) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.carts[cart.UserID] = cloneCart(cart)
+	return nil
+}
+
+// Delete removes the cart of a user
+func (r *MemoryCartRepository) Delete(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.carts, userID)
+	return nil
+}
+
+// cloneCart returns a copy of a cart that shares no memory with it
+func cloneCart(cart *entity.Cart) *entity.Cart {
+	cartCopy := *cart
+	cartCopy.Items = append([]entity.CartItem{}, cart.Items...)
+	return &cartCopy
+}
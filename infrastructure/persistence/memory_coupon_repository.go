@@ -6,19 +6,20 @@ import (
 	"sync"
 
 	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
-	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
 )
 
 // MemoryCouponRepository is an in-memory implementation of CouponRepository
 type MemoryCouponRepository struct {
-	mu      sync.RWMutex
-	coupons map[string]*entity.Coupon
+	mu       sync.RWMutex
+	coupons  map[string]*entity.Coupon
+	versions map[string]uint64 // bumped on every write so transactions can detect conflicts
 }
 
 // NewMemoryCouponRepository creates a new memory coupon repository
-func NewMemoryCouponRepository() repository.CouponRepository {
+func NewMemoryCouponRepository() *MemoryCouponRepository {
 	return &MemoryCouponRepository{
-		coupons: make(map[string]*entity.Coupon),
+		coupons:  make(map[string]*entity.Coupon),
+		versions: make(map[string]uint64),
 	}
 }
 
@@ -38,6 +39,7 @@ func (r *MemoryCouponRepository) Create(ctx context.Context, coupon *entity.Coup
 	}
 
 	r.coupons[coupon.ID] = coupon
+	r.versions[coupon.ID]++
 	return nil
 }
 
@@ -92,6 +94,7 @@ func (r *MemoryCouponRepository) Update(ctx context.Context, coupon *entity.Coup
 	}
 
 	r.coupons[coupon.ID] = coupon
+	r.versions[coupon.ID]++
 	return nil
 }
 
@@ -104,5 +107,90 @@ func (r *MemoryCouponRepository) Delete(ctx context.Context, id string) error {
 	}
 
 	delete(r.coupons, id)
+	r.versions[id]++
 	return nil
-}
\ No newline at end of file
+}
+
+// cloneCoupon returns a copy of a coupon
+func cloneCoupon(coupon *entity.Coupon) *entity.Coupon {
+	copy := *coupon
+	return &copy
+}
+
+// memoryCouponTxRepository is a CouponRepository view bound to a unit-of-work transaction
+type memoryCouponTxRepository struct {
+	base  *MemoryCouponRepository
+	table *txTable[entity.Coupon]
+}
+
+func newMemoryCouponTxRepository(base *MemoryCouponRepository) *memoryCouponTxRepository {
+	return &memoryCouponTxRepository{
+		base:  base,
+		table: newTxTable(base.coupons, base.versions, cloneCoupon),
+	}
+}
+
+func (r *memoryCouponTxRepository) Create(ctx context.Context, coupon *entity.Coupon) error {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	if _, exists := r.table.get(coupon.ID); exists {
+		return errors.New("coupon already exists")
+	}
+	if len(r.table.scan(func(c *entity.Coupon) bool { return c.Code == coupon.Code })) > 0 {
+		return errors.New("coupon code already exists")
+	}
+	r.table.put(coupon.ID, coupon)
+	return nil
+}
+
+func (r *memoryCouponTxRepository) FindByCode(ctx context.Context, code string) (*entity.Coupon, error) {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	coupons := r.table.scan(func(c *entity.Coupon) bool { return c.Code == code })
+	if len(coupons) == 0 {
+		return nil, errors.New("coupon not found")
+	}
+	return coupons[0], nil
+}
+
+func (r *memoryCouponTxRepository) FindByID(ctx context.Context, id string) (*entity.Coupon, error) {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	coupon, exists := r.table.get(id)
+	if !exists {
+		return nil, errors.New("coupon not found")
+	}
+	return coupon, nil
+}
+
+func (r *memoryCouponTxRepository) FindAll(ctx context.Context) ([]*entity.Coupon, error) {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	return r.table.scan(func(c *entity.Coupon) bool { return true }), nil
+}
+
+func (r *memoryCouponTxRepository) Update(ctx context.Context, coupon *entity.Coupon) error {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	if _, exists := r.table.get(coupon.ID); !exists {
+		return errors.New("coupon not found")
+	}
+	r.table.put(coupon.ID, coupon)
+	return nil
+}
+
+func (r *memoryCouponTxRepository) Delete(ctx context.Context, id string) error {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	if _, exists := r.table.get(id); !exists {
+		return errors.New("coupon not found")
+	}
+	r.table.remove(id)
+	return nil
+}
@@ -0,0 +1,84 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/port"
+)
+
+// MemoryIdempotencyStore is an in-memory implementation of port.IdempotencyStore
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[idempotencyKey]*port.IdempotencyRecord
+}
+
+type idempotencyKey struct {
+	userID string
+	key    string
+}
+
+// NewMemoryIdempotencyStore creates a new in-memory idempotency store
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		records: make(map[idempotencyKey]*port.IdempotencyRecord),
+	}
+}
+
+func (s *MemoryIdempotencyStore) Begin(ctx context.Context, record *port.IdempotencyRecord) (*port.IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := idempotencyKey{record.UserID, record.Key}
+	if existing, exists := s.records[id]; exists && existing.ExpiresAt.After(record.CreatedAt) {
+		return cloneIdempotencyRecord(existing), false, nil
+	}
+
+	s.records[id] = cloneIdempotencyRecord(record)
+	return nil, true, nil
+}
+
+func (s *MemoryIdempotencyStore) Complete(ctx context.Context, userID, key string, statusCode int, contentType string, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, exists := s.records[idempotencyKey{userID, key}]
+	if !exists {
+		return errors.New("idempotency key not found")
+	}
+	record.StatusCode = statusCode
+	record.ContentType = contentType
+	record.Body = append([]byte(nil), body...)
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) Release(ctx context.Context, userID, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, idempotencyKey{userID, key})
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleted := 0
+	for id, record := range s.records {
+		if !record.ExpiresAt.After(now) {
+			delete(s.records, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// cloneIdempotencyRecord returns a copy of a record that shares no memory with it
+func cloneIdempotencyRecord(record *port.IdempotencyRecord) *port.IdempotencyRecord {
+	copy := *record
+	copy.Body = append([]byte(nil), record.Body...)
+	return &copy
+}
@@ -0,0 +1,42 @@
+package persistence
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/port"
+)
+
+func TestMemoryIdempotencyStore_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryIdempotencyStore()
+	now := time.Now()
+	record := func(at time.Time, hash string) *port.IdempotencyRecord {
+		return &port.IdempotencyRecord{UserID: "USER-001", Key: "key-1", RequestHash: hash, CreatedAt: at, ExpiresAt: at.Add(time.Hour)}
+	}
+
+	if _, claimed, _ := store.Begin(ctx, record(now, "h1")); !claimed {
+		t.Fatal("Expected the first request to claim the key")
+	}
+	existing, claimed, _ := store.Begin(ctx, record(now, "h1"))
+	if claimed || existing.IsCompleted() {
+		t.Fatalf("Expected an in-progress record, got claimed=%v %+v", claimed, existing)
+	}
+
+	if err := store.Complete(ctx, "USER-001", "key-1", 201, "application/json", []byte(`{}`)); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	existing, _, _ = store.Begin(ctx, record(now, "h2"))
+	if existing.StatusCode != 201 || string(existing.Body) != `{}` || existing.RequestHash != "h1" {
+		t.Errorf("Expected the stored response of the first request, got %+v", existing)
+	}
+
+	// Once expired the key can be claimed again
+	if _, claimed, _ := store.Begin(ctx, record(now.Add(2*time.Hour), "h2")); !claimed {
+		t.Error("Expected an expired key to be claimable")
+	}
+	if deleted, _ := store.DeleteExpired(ctx, now.Add(4*time.Hour)); deleted != 1 {
+		t.Errorf("Expected 1 expired key to be deleted, got %d", deleted)
+	}
+}
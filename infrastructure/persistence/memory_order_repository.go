@@ -3,6 +3,8 @@ package persistence
 import (
 	"context"
 	"errors"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
@@ -11,14 +13,16 @@ import (
 
 // MemoryOrderRepository is an in-memory implementation of OrderRepository
 type MemoryOrderRepository struct {
-	mu     sync.RWMutex
-	orders map[string]*entity.Order
+	mu       sync.RWMutex
+	orders   map[string]*entity.Order
+	versions map[string]uint64 // bumped on every write so transactions can detect conflicts
 }
 
 // NewMemoryOrderRepository creates a new in-memory order repository
-func NewMemoryOrderRepository() repository.OrderRepository {
+func NewMemoryOrderRepository() *MemoryOrderRepository {
 	return &MemoryOrderRepository{
-		orders: make(map[string]*entity.Order),
+		orders:   make(map[string]*entity.Order),
+		versions: make(map[string]uint64),
 	}
 }
 
@@ -32,10 +36,8 @@ func (r *MemoryOrderRepository) Create(ctx context.Context, order *entity.Order)
 	}
 
 	// Create a deep copy to avoid external modifications
-	orderCopy := *order
-	orderCopy.Items = make([]entity.OrderItem, len(order.Items))
-	copy(orderCopy.Items, order.Items)
-	r.orders[order.ID] = &orderCopy
+	r.orders[order.ID] = cloneOrder(order)
+	r.versions[order.ID]++
 	return nil
 }
 
@@ -50,10 +52,7 @@ func (r *MemoryOrderRepository) FindByID(ctx context.Context, id string) (*entit
 	}
 
 	// Return a deep copy to avoid external modifications
-	orderCopy := *order
-	orderCopy.Items = make([]entity.OrderItem, len(order.Items))
-	copy(orderCopy.Items, order.Items)
-	return &orderCopy, nil
+	return cloneOrder(order), nil
 }
 
 // FindByUserID finds orders by user ID
@@ -65,29 +64,27 @@ func (r *MemoryOrderRepository) FindByUserID(ctx context.Context, userID string)
 	for _, order := range r.orders {
 		if order.UserID == userID {
 			// Create a deep copy to avoid external modifications
-			orderCopy := *order
-			orderCopy.Items = make([]entity.OrderItem, len(order.Items))
-			copy(orderCopy.Items, order.Items)
-			result = append(result, &orderCopy)
+			result = append(result, cloneOrder(order))
 		}
 	}
 	return result, nil
 }
 
-// Update updates an order
+// Update updates an order; its shipping address is fixed when the order is created
 func (r *MemoryOrderRepository) Update(ctx context.Context, order *entity.Order) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.orders[order.ID]; !exists {
+	existing, exists := r.orders[order.ID]
+	if !exists {
 		return errors.New("order not found")
 	}
 
-	// Create a deep copy to avoid external modifications
-	orderCopy := *order
-	orderCopy.Items = make([]entity.OrderItem, len(order.Items))
-	copy(orderCopy.Items, order.Items)
-	r.orders[order.ID] = &orderCopy
+	// Create a deep copy to avoid external modifications; the shipping address stays as created
+	updated := cloneOrder(order)
+	updated.ShippingAddress = existing.ShippingAddress
+	r.orders[order.ID] = updated
+	r.versions[order.ID]++
 	return nil
 }
 
@@ -99,10 +96,193 @@ func (r *MemoryOrderRepository) FindAll(ctx context.Context) ([]*entity.Order, e
 	var result []*entity.Order
 	for _, order := range r.orders {
 		// Create a deep copy to avoid external modifications
-		orderCopy := *order
-		orderCopy.Items = make([]entity.OrderItem, len(order.Items))
-		copy(orderCopy.Items, order.Items)
-		result = append(result, &orderCopy)
+		result = append(result, cloneOrder(order))
 	}
 	return result, nil
-}
\ No newline at end of file
+}
+
+// Search finds one page of the orders matching filter, together with the total number of matches
+func (r *MemoryOrderRepository) Search(ctx context.Context, filter repository.OrderFilter) ([]*entity.Order, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []*entity.Order
+	for _, order := range r.orders {
+		if matchesOrderFilter(order, filter) {
+			matched = append(matched, cloneOrder(order))
+		}
+	}
+	page, total := sortAndPageOrders(matched, filter)
+	return page, total, nil
+}
+
+// matchesOrderFilter checks an order against every condition set in filter
+func matchesOrderFilter(order *entity.Order, filter repository.OrderFilter) bool {
+	if filter.Status != "" && order.Status != filter.Status {
+		return false
+	}
+	if filter.UserID != "" && order.UserID != filter.UserID {
+		return false
+	}
+	if !filter.CreatedFrom.IsZero() && order.CreatedAt.Before(filter.CreatedFrom) {
+		return false
+	}
+	if !filter.CreatedBefore.IsZero() && !order.CreatedAt.Before(filter.CreatedBefore) {
+		return false
+	}
+	if filter.CouponCode != "" && order.AppliedCoupon != filter.CouponCode {
+		return false
+	}
+	if filter.BackorderedProductID != "" && order.BackorderedQuantity(filter.BackorderedProductID) == 0 {
+		return false
+	}
+	if filter.WarehouseID != "" {
+		for _, item := range order.Items {
+			for _, allocation := range item.Allocations {
+				if allocation.WarehouseID == filter.WarehouseID {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// sortAndPageOrders sorts the matched orders as filter asks and cuts out the requested page
+func sortAndPageOrders(orders []*entity.Order, filter repository.OrderFilter) ([]*entity.Order, int) {
+	compare := func(a, b *entity.Order) int {
+		switch filter.SortBy {
+		case repository.OrderSortByUpdatedAt:
+			return a.UpdatedAt.Compare(b.UpdatedAt)
+		case repository.OrderSortByTotalPrice:
+			return a.TotalPrice - b.TotalPrice
+		default:
+			return a.CreatedAt.Compare(b.CreatedAt)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		c := compare(orders[i], orders[j])
+		if c == 0 {
+			c = strings.Compare(orders[i].ID, orders[j].ID)
+		}
+		if filter.Descending {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	total := len(orders)
+	start := min(max(filter.Offset, 0), total)
+	end := total
+	if filter.Limit > 0 {
+		end = min(start+filter.Limit, total)
+	}
+	return orders[start:end], total
+}
+
+// cloneOrder returns a deep copy of an order
+func cloneOrder(order *entity.Order) *entity.Order {
+	orderCopy := *order
+	orderCopy.Items = make([]entity.OrderItem, len(order.Items))
+	copy(orderCopy.Items, order.Items)
+	for i, item := range order.Items {
+		if item.Allocations != nil {
+			orderCopy.Items[i].Allocations = append([]entity.ItemAllocation(nil), item.Allocations...)
+		}
+	}
+	if order.ShippingCharges != nil {
+		orderCopy.ShippingCharges = append([]entity.ShippingCharge{}, order.ShippingCharges...)
+	}
+	if order.ShippingAddress != nil {
+		address := *order.ShippingAddress
+		orderCopy.ShippingAddress = &address
+	}
+	if order.TaxLines != nil {
+		orderCopy.TaxLines = append([]entity.TaxLine(nil), order.TaxLines...)
+	}
+	if order.StatusHistory != nil {
+		orderCopy.StatusHistory = append([]entity.StatusChange(nil), order.StatusHistory...)
+	}
+	return &orderCopy
+}
+
+// memoryOrderTxRepository is an OrderRepository view bound to a unit-of-work transaction
+type memoryOrderTxRepository struct {
+	base  *MemoryOrderRepository
+	table *txTable[entity.Order]
+}
+
+func newMemoryOrderTxRepository(base *MemoryOrderRepository) *memoryOrderTxRepository {
+	return &memoryOrderTxRepository{
+		base:  base,
+		table: newTxTable(base.orders, base.versions, cloneOrder),
+	}
+}
+
+// Create creates a new order
+func (r *memoryOrderTxRepository) Create(ctx context.Context, order *entity.Order) error {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	if _, exists := r.table.get(order.ID); exists {
+		return errors.New("order already exists")
+	}
+	r.table.put(order.ID, order)
+	return nil
+}
+
+// FindByID finds an order by its ID
+func (r *memoryOrderTxRepository) FindByID(ctx context.Context, id string) (*entity.Order, error) {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	order, exists := r.table.get(id)
+	if !exists {
+		return nil, errors.New("order not found")
+	}
+	return order, nil
+}
+
+// FindByUserID finds orders by user ID
+func (r *memoryOrderTxRepository) FindByUserID(ctx context.Context, userID string) ([]*entity.Order, error) {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	return r.table.scan(func(o *entity.Order) bool { return o.UserID == userID }), nil
+}
+
+// Update updates an order; its shipping address is fixed when the order is created
+func (r *memoryOrderTxRepository) Update(ctx context.Context, order *entity.Order) error {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	existing, exists := r.table.get(order.ID)
+	if !exists {
+		return errors.New("order not found")
+	}
+
+	// The shipping address stays as created
+	updated := *order
+	updated.ShippingAddress = existing.ShippingAddress
+	r.table.put(order.ID, &updated)
+	return nil
+}
+
+// FindAll finds all orders
+func (r *memoryOrderTxRepository) FindAll(ctx context.Context) ([]*entity.Order, error) {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	return r.table.scan(func(o *entity.Order) bool { return true }), nil
+}
+
+// Search finds one page of the orders matching filter, together with the total number of matches
+func (r *memoryOrderTxRepository) Search(ctx context.Context, filter repository.OrderFilter) ([]*entity.Order, int, error) {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	matched := r.table.scan(func(o *entity.Order) bool { return matchesOrderFilter(o, filter) })
+	page, total := sortAndPageOrders(matched, filter)
+	return page, total, nil
+}
@@ -0,0 +1,84 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+func TestMemoryOrderRepository_Search(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryOrderRepository()
+
+	// ORD-1..ORD-4 are created a day apart; ORD-2 and ORD-4 ship from WH-002
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	orders := []struct {
+		userID    string
+		price     int
+		coupon    string
+		warehouse string
+		paid      bool
+	}{
+		{"USER-001", 1000, "", "WH-001", true},
+		{"USER-002", 3000, "SAVE10", "WH-002", true},
+		{"USER-001", 2000, "SAVE10", "", false},
+		{"USER-002", 500, "", "WH-002", true},
+	}
+	for i, o := range orders {
+		order, _ := entity.NewOrder(fmt.Sprintf("ORD-%d", i+1), o.userID)
+		order.AddItem("P001", "Product 1", 1, o.price)
+		order.AppliedCoupon = o.coupon
+		order.CreatedAt = base.AddDate(0, 0, i)
+		if o.warehouse != "" {
+			order.Items[0].Allocations = []entity.ItemAllocation{{WarehouseID: o.warehouse, Quantity: 1}}
+		}
+		if o.paid {
+			order.MarkPaid()
+		}
+		if err := repo.Create(ctx, order); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		filter        repository.OrderFilter
+		expectedIDs   []string
+		expectedTotal int
+	}{
+		{"no filter sorts by creation", repository.OrderFilter{}, []string{"ORD-1", "ORD-2", "ORD-3", "ORD-4"}, 4},
+		{"status", repository.OrderFilter{Status: entity.OrderStatusPending}, []string{"ORD-3"}, 1},
+		{"user", repository.OrderFilter{UserID: "USER-002"}, []string{"ORD-2", "ORD-4"}, 2},
+		{"date range", repository.OrderFilter{CreatedFrom: base.AddDate(0, 0, 1), CreatedBefore: base.AddDate(0, 0, 3)}, []string{"ORD-2", "ORD-3"}, 2},
+		{"coupon", repository.OrderFilter{CouponCode: "SAVE10"}, []string{"ORD-2", "ORD-3"}, 2},
+		{"warehouse", repository.OrderFilter{WarehouseID: "WH-002"}, []string{"ORD-2", "ORD-4"}, 2},
+		{"combined", repository.OrderFilter{UserID: "USER-002", CouponCode: "SAVE10"}, []string{"ORD-2"}, 1},
+		{"sort by price descending", repository.OrderFilter{SortBy: repository.OrderSortByTotalPrice, Descending: true}, []string{"ORD-2", "ORD-3", "ORD-1", "ORD-4"}, 4},
+		{"page", repository.OrderFilter{Offset: 1, Limit: 2}, []string{"ORD-2", "ORD-3"}, 4},
+		{"page past the end", repository.OrderFilter{Offset: 10, Limit: 2}, []string{}, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, total, err := repo.Search(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if total != tt.expectedTotal {
+				t.Errorf("Expected total %d, got %d", tt.expectedTotal, total)
+			}
+			if len(found) != len(tt.expectedIDs) {
+				t.Fatalf("Expected %d orders, got %d", len(tt.expectedIDs), len(found))
+			}
+			for i, id := range tt.expectedIDs {
+				if found[i].ID != id {
+					t.Errorf("Expected order %d to be %s, got %s", i, id, found[i].ID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package persistence
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// MemoryPriceHistoryRepository is an in-memory implementation of PriceHistoryRepository
+type MemoryPriceHistoryRepository struct {
+	mu      sync.RWMutex
+	changes []*entity.PriceChange
+}
+
+// NewMemoryPriceHistoryRepository creates a new memory price history
+func NewMemoryPriceHistoryRepository() repository.PriceHistoryRepository {
+	return &MemoryPriceHistoryRepository{}
+}
+
+func (r *MemoryPriceHistoryRepository) Append(ctx context.Context, change *entity.PriceChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copy := *change
+	r.changes = append(r.changes, &copy)
+	return nil
+}
+
+func (r *MemoryPriceHistoryRepository) FindByProduct(ctx context.Context, productID string) ([]*entity.PriceChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*entity.PriceChange
+	for _, change := range r.changes {
+		if change.ProductID == productID {
+			copy := *change
+			result = append(result, &copy)
+		}
+	}
+	return result, nil
+}
@@ -3,6 +3,7 @@ package persistence
 import (
 	"context"
 	"errors"
+	"sort"
 	"sync"
 
 	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
@@ -30,6 +31,9 @@ func (r *MemoryProductRepository) Create(ctx context.Context, product *entity.Pr
 	if _, exists := r.products[product.ID]; exists {
 		return errors.New("product already exists")
 	}
+	if r.skuTaken(product) {
+		return repository.ErrSKUTaken
+	}
 
 	// Create a copy to avoid external modifications
 	productCopy := *product
@@ -52,6 +56,22 @@ func (r *MemoryProductRepository) FindByID(ctx context.Context, id string) (*ent
 	return &productCopy, nil
 }
 
+// FindBySKU finds a product by its SKU
+func (r *MemoryProductRepository) FindBySKU(ctx context.Context, sku string) (*entity.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if sku != "" {
+		for _, product := range r.products {
+			if product.SKU == sku {
+				productCopy := *product
+				return &productCopy, nil
+			}
+		}
+	}
+	return nil, errors.New("product not found")
+}
+
 // FindAll finds all products with optional category filter
 func (r *MemoryProductRepository) FindAll(ctx context.Context, category string) ([]*entity.Product, error) {
 	r.mu.RLock()
@@ -68,6 +88,27 @@ func (r *MemoryProductRepository) FindAll(ctx context.Context, category string)
 	return result, nil
 }
 
+// FindVariants finds the variants of a parent product ordered by SKU
+func (r *MemoryProductRepository) FindVariants(ctx context.Context, parentID string) ([]*entity.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*entity.Product
+	for _, product := range r.products {
+		if product.ParentID == parentID && parentID != "" {
+			productCopy := *product
+			result = append(result, &productCopy)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].SKU != result[j].SKU {
+			return result[i].SKU < result[j].SKU
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
 // Update updates a product
 func (r *MemoryProductRepository) Update(ctx context.Context, product *entity.Product) error {
 	r.mu.Lock()
@@ -76,6 +117,9 @@ func (r *MemoryProductRepository) Update(ctx context.Context, product *entity.Pr
 	if _, exists := r.products[product.ID]; !exists {
 		return errors.New("product not found")
 	}
+	if r.skuTaken(product) {
+		return repository.ErrSKUTaken
+	}
 
 	// Create a copy to avoid external modifications
 	productCopy := *product
@@ -83,6 +127,19 @@ func (r *MemoryProductRepository) Update(ctx context.Context, product *entity.Pr
 	return nil
 }
 
+// skuTaken checks if another product already uses the product's SKU; the caller must hold the lock
+func (r *MemoryProductRepository) skuTaken(product *entity.Product) bool {
+	if product.SKU == "" {
+		return false
+	}
+	for _, other := range r.products {
+		if other.SKU == product.SKU && other.ID != product.ID {
+			return true
+		}
+	}
+	return false
+}
+
 // Note: Stock management is now handled through StockService and StockRepository
 // Products themselves don't maintain stock counts anymore
 
@@ -0,0 +1,54 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+func TestMemoryProductRepository_SKU(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryProductRepository()
+
+	desk, _ := entity.NewProduct("P001", "Desk", 300, "Furniture")
+	desk.SetSKU("DESK-OAK")
+	repo.Create(ctx, desk)
+	chair, _ := entity.NewProduct("P002", "Chair", 100, "Furniture")
+	chair.SetSKU("DESK-OAK")
+	if err := repo.Create(ctx, chair); !errors.Is(err, repository.ErrSKUTaken) {
+		t.Errorf("Expected a taken SKU to be rejected, got %v", err)
+	}
+
+	found, err := repo.FindBySKU(ctx, "DESK-OAK")
+	if err != nil || found.ID != "P001" {
+		t.Fatalf("Expected to find the desk by SKU, got %+v, %v", found, err)
+	}
+	if _, err := repo.FindBySKU(ctx, ""); err == nil {
+		t.Error("Expected an empty SKU to match no product")
+	}
+}
+
+func TestMemoryProductRepository_FindVariants(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryProductRepository()
+
+	shirt, _ := entity.NewProduct("P010", "T-Shirt", 2000, "Apparel")
+	repo.Create(ctx, shirt)
+	for _, v := range []struct{ id, sku, size string }{{"P011", "TS-XL", "XL"}, {"P012", "TS-M", "M"}, {"P013", "TS-L", "L"}} {
+		variant, _ := entity.NewVariant(v.id, shirt, v.sku, map[string]string{"size": v.size}, nil)
+		repo.Create(ctx, variant)
+	}
+
+	variants, err := repo.FindVariants(ctx, "P010")
+	if err != nil || len(variants) != 3 {
+		t.Fatalf("Expected 3 variants, got %+v, %v", variants, err)
+	}
+	for i, sku := range []string{"TS-L", "TS-M", "TS-XL"} {
+		if variants[i].SKU != sku {
+			t.Errorf("Expected variant %d to be %s, got %s", i, sku, variants[i].SKU)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// MemoryReorderPointRepository is an in-memory implementation of ReorderPointRepository
+type MemoryReorderPointRepository struct {
+	mu            sync.RWMutex
+	reorderPoints map[reorderPointKey]*entity.ReorderPoint
+}
+
+type reorderPointKey struct {
+	productID   string
+	warehouseID string
+}
+
+// NewMemoryReorderPointRepository creates a new memory reorder point repository
+func NewMemoryReorderPointRepository() repository.ReorderPointRepository {
+	return &MemoryReorderPointRepository{
+		reorderPoints: make(map[reorderPointKey]*entity.ReorderPoint),
+	}
+}
+
+func (r *MemoryReorderPointRepository) Save(ctx context.Context, reorderPoint *entity.ReorderPoint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copy := *reorderPoint
+	r.reorderPoints[reorderPointKey{reorderPoint.ProductID, reorderPoint.WarehouseID}] = &copy
+	return nil
+}
+
+func (r *MemoryReorderPointRepository) Delete(ctx context.Context, productID, warehouseID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := reorderPointKey{productID, warehouseID}
+	if _, exists := r.reorderPoints[key]; !exists {
+		return errors.New("reorder point not found")
+	}
+	delete(r.reorderPoints, key)
+	return nil
+}
+
+func (r *MemoryReorderPointRepository) FindAll(ctx context.Context) ([]*entity.ReorderPoint, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*entity.ReorderPoint
+	for _, reorderPoint := range r.reorderPoints {
+		copy := *reorderPoint
+		result = append(result, &copy)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ProductID != result[j].ProductID {
+			return result[i].ProductID < result[j].ProductID
+		}
+		return result[i].WarehouseID < result[j].WarehouseID
+	})
+	return result, nil
+}
@@ -0,0 +1,90 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// MemorySaleRepository is an in-memory implementation of SaleRepository
+type MemorySaleRepository struct {
+	mu    sync.RWMutex
+	sales map[string]*entity.Sale
+}
+
+// NewMemorySaleRepository creates a new memory sale repository
+func NewMemorySaleRepository() repository.SaleRepository {
+	return &MemorySaleRepository{
+		sales: make(map[string]*entity.Sale),
+	}
+}
+
+func (r *MemorySaleRepository) Create(ctx context.Context, sale *entity.Sale) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.sales[sale.ID]; exists {
+		return errors.New("sale already exists")
+	}
+	copy := *sale
+	r.sales[sale.ID] = &copy
+	return nil
+}
+
+func (r *MemorySaleRepository) Update(ctx context.Context, sale *entity.Sale) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.sales[sale.ID]; !exists {
+		return errors.New("sale not found")
+	}
+	copy := *sale
+	r.sales[sale.ID] = &copy
+	return nil
+}
+
+func (r *MemorySaleRepository) FindByID(ctx context.Context, id string) (*entity.Sale, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sale, exists := r.sales[id]
+	if !exists {
+		return nil, errors.New("sale not found")
+	}
+	copy := *sale
+	return &copy, nil
+}
+
+func (r *MemorySaleRepository) FindByProduct(ctx context.Context, productID string) ([]*entity.Sale, error) {
+	return r.find(func(sale *entity.Sale) bool { return sale.ProductID == productID }), nil
+}
+
+func (r *MemorySaleRepository) FindEffective(ctx context.Context, at time.Time) ([]*entity.Sale, error) {
+	return r.find(func(sale *entity.Sale) bool { return sale.IsEffective(at) }), nil
+}
+
+// find returns copies of the sales matching match, ordered by start
+func (r *MemorySaleRepository) find(match func(sale *entity.Sale) bool) []*entity.Sale {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*entity.Sale
+	for _, sale := range r.sales {
+		if match(sale) {
+			copy := *sale
+			result = append(result, &copy)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].StartsAt.Equal(result[j].StartsAt) {
+			return result[i].StartsAt.Before(result[j].StartsAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
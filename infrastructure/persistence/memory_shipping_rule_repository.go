@@ -0,0 +1,106 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// MemoryShippingRuleRepository is an in-memory implementation of ShippingRuleRepository
+type MemoryShippingRuleRepository struct {
+	mu    sync.RWMutex
+	rules map[string]*entity.ShippingRule
+}
+
+// NewMemoryShippingRuleRepository creates a new in-memory shipping rule repository
+func NewMemoryShippingRuleRepository() repository.ShippingRuleRepository {
+	return &MemoryShippingRuleRepository{
+		rules: make(map[string]*entity.ShippingRule),
+	}
+}
+
+// Create creates a new shipping rule
+func (r *MemoryShippingRuleRepository) Create(ctx context.Context, rule *entity.ShippingRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.rules[rule.ID]; exists {
+		return errors.New("shipping rule already exists")
+	}
+	r.rules[rule.ID] = cloneShippingRule(rule)
+	return nil
+}
+
+// FindByID finds a shipping rule by its ID
+func (r *MemoryShippingRuleRepository) FindByID(ctx context.Context, id string) (*entity.ShippingRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rule, exists := r.rules[id]
+	if !exists {
+		return nil, errors.New("shipping rule not found")
+	}
+	return cloneShippingRule(rule), nil
+}
+
+// FindAll returns all shipping rules ordered by priority, then ID
+func (r *MemoryShippingRuleRepository) FindAll(ctx context.Context) ([]*entity.ShippingRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*entity.ShippingRule, 0, len(r.rules))
+	for _, rule := range r.rules {
+		result = append(result, cloneShippingRule(rule))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Priority != result[j].Priority {
+			return result[i].Priority < result[j].Priority
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// Update updates a shipping rule
+func (r *MemoryShippingRuleRepository) Update(ctx context.Context, rule *entity.ShippingRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.rules[rule.ID]; !exists {
+		return errors.New("shipping rule not found")
+	}
+	r.rules[rule.ID] = cloneShippingRule(rule)
+	return nil
+}
+
+// Delete deletes a shipping rule
+func (r *MemoryShippingRuleRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.rules[id]; !exists {
+		return errors.New("shipping rule not found")
+	}
+	delete(r.rules, id)
+	return nil
+}
+
+// cloneShippingRule returns a copy of a rule that shares no memory with it
+func cloneShippingRule(rule *entity.ShippingRule) *entity.ShippingRule {
+	ruleCopy := *rule
+	ruleCopy.Prefectures = append([]string(nil), rule.Prefectures...)
+	ruleCopy.WarehouseIDs = append([]string(nil), rule.WarehouseIDs...)
+	if rule.StartsAt != nil {
+		startsAt := *rule.StartsAt
+		ruleCopy.StartsAt = &startsAt
+	}
+	if rule.EndsAt != nil {
+		endsAt := *rule.EndsAt
+		ruleCopy.EndsAt = &endsAt
+	}
+	return &ruleCopy
+}
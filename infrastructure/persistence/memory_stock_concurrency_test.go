@@ -0,0 +1,112 @@
+package persistence_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/persistence"
+)
+
+func TestStockService_AllocateStockConcurrently(t *testing.T) {
+	const (
+		perWarehouse = 100
+		workers      = 50
+		attempts     = 20
+	)
+
+	ctx := context.Background()
+	stockRepo := persistence.NewMemoryStockRepository()
+	warehouseRepo := persistence.NewMemoryWarehouseRepository()
+	stockService := service.NewStockService(stockRepo, warehouseRepo, stockRepo.Ledger(), service.NewPriorityAllocation(nil))
+
+	warehouseIDs := []string{"WH-001", "WH-002", "WH-003"}
+	for _, id := range warehouseIDs {
+		warehouse, _ := entity.NewWarehouse(id, id, "")
+		warehouseRepo.Create(ctx, warehouse)
+		stock, _ := entity.NewStock("STK-P001-"+id, "P001", id, perWarehouse)
+		stockRepo.Create(ctx, stock)
+	}
+	initial := perWarehouse * len(warehouseIDs)
+
+	var (
+		allocated int64
+		wg        sync.WaitGroup
+		negative  atomic.Bool
+		stop      = make(chan struct{})
+	)
+
+	// Watch the committed state for negative quantities while allocations run
+	watcherDone := make(chan struct{})
+	go func() {
+		defer close(watcherDone)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			stocks, _ := stockRepo.FindByProductID(ctx, "P001")
+			for _, s := range stocks {
+				if s.Quantity < 0 {
+					negative.Store(true)
+				}
+			}
+		}
+	}()
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < attempts; i++ {
+				quantity := 1 + (worker+i)%3
+				if i%5 == 0 {
+					// Impossible requests make the transaction roll back
+					quantity = initial + 1
+				}
+
+				allocations, err := stockService.AllocateStock(ctx, "P001", quantity)
+				if err != nil {
+					continue
+				}
+
+				sum := 0
+				for _, a := range allocations {
+					sum += a.Quantity
+				}
+				if sum != quantity {
+					t.Errorf("Allocation returned %d units for a request of %d", sum, quantity)
+				}
+				atomic.AddInt64(&allocated, int64(sum))
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(stop)
+	<-watcherDone
+
+	if negative.Load() {
+		t.Error("Observed a negative stock quantity during concurrent allocation")
+	}
+
+	stocks, _ := stockRepo.FindByProductID(ctx, "P001")
+	remaining := 0
+	for _, s := range stocks {
+		if s.Quantity < 0 {
+			t.Errorf("Stock %s ended negative: %d", s.ID, s.Quantity)
+		}
+		remaining += s.Quantity
+	}
+
+	if allocated == 0 {
+		t.Fatal("Expected at least some allocations to succeed")
+	}
+	if got := int64(remaining) + allocated; got != int64(initial) {
+		t.Errorf("Stock was lost or resurrected: remaining %d + allocated %d = %d, want %d",
+			remaining, allocated, got, initial)
+	}
+}
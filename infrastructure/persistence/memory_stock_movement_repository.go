@@ -0,0 +1,84 @@
+package persistence
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// MemoryStockMovementRepository is an in-memory implementation of StockMovementRepository
+type MemoryStockMovementRepository struct {
+	mu        sync.RWMutex
+	movements []*entity.StockMovement
+}
+
+// NewMemoryStockMovementRepository creates a new memory stock movement ledger
+func NewMemoryStockMovementRepository() *MemoryStockMovementRepository {
+	return &MemoryStockMovementRepository{}
+}
+
+func (r *MemoryStockMovementRepository) Append(ctx context.Context, movement *entity.StockMovement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copy := *movement
+	r.movements = append(r.movements, &copy)
+	return nil
+}
+
+func (r *MemoryStockMovementRepository) Find(ctx context.Context, filter repository.StockMovementFilter) ([]*entity.StockMovement, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return matchMovements(r.movements, filter), nil
+}
+
+// matchMovements returns copies of the movements matching the filter, keeping their order
+func matchMovements(movements []*entity.StockMovement, filter repository.StockMovementFilter) []*entity.StockMovement {
+	var result []*entity.StockMovement
+	for _, movement := range movements {
+		if filter.ProductID != "" && movement.ProductID != filter.ProductID {
+			continue
+		}
+		if filter.WarehouseID != "" && movement.WarehouseID != filter.WarehouseID {
+			continue
+		}
+		if filter.OrderID != "" && movement.OrderID != filter.OrderID {
+			continue
+		}
+		copy := *movement
+		result = append(result, &copy)
+	}
+	return result
+}
+
+// memoryStockMovementTxRepository buffers the movements appended in a transaction.
+// Appends never conflict, so nothing needs validating at commit.
+type memoryStockMovementTxRepository struct {
+	base    *MemoryStockMovementRepository
+	pending []*entity.StockMovement
+}
+
+func newMemoryStockMovementTxRepository(base *MemoryStockMovementRepository) *memoryStockMovementTxRepository {
+	return &memoryStockMovementTxRepository{base: base}
+}
+
+func (r *memoryStockMovementTxRepository) Append(ctx context.Context, movement *entity.StockMovement) error {
+	copy := *movement
+	r.pending = append(r.pending, &copy)
+	return nil
+}
+
+func (r *memoryStockMovementTxRepository) Find(ctx context.Context, filter repository.StockMovementFilter) ([]*entity.StockMovement, error) {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	return append(matchMovements(r.base.movements, filter), matchMovements(r.pending, filter)...), nil
+}
+
+// apply appends the buffered movements to the ledger; the caller must hold the ledger's write lock
+func (r *memoryStockMovementTxRepository) apply() {
+	r.base.movements = append(r.base.movements, r.pending...)
+}
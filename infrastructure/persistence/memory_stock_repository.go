@@ -4,25 +4,43 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
 	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
 )
 
-// MemoryStockRepository is an in-memory implementation of StockRepository
+// MemoryStockRepository is an in-memory implementation of StockRepository.
+// It keeps the stock movement ledger and the stock transfers its transactions write to.
 type MemoryStockRepository struct {
-	mu     sync.RWMutex
-	stocks map[string]*entity.Stock
+	mu        sync.RWMutex
+	stocks    map[string]*entity.Stock
+	versions  map[string]uint64 // bumped on every write so transactions can detect conflicts
+	ledger    *MemoryStockMovementRepository
+	transfers *MemoryStockTransferRepository
 }
 
 // NewMemoryStockRepository creates a new memory stock repository
-func NewMemoryStockRepository() repository.StockRepository {
+func NewMemoryStockRepository() *MemoryStockRepository {
 	return &MemoryStockRepository{
-		stocks: make(map[string]*entity.Stock),
+		stocks:    make(map[string]*entity.Stock),
+		versions:  make(map[string]uint64),
+		ledger:    NewMemoryStockMovementRepository(),
+		transfers: NewMemoryStockTransferRepository(),
 	}
 }
 
+// Ledger returns the stock movement ledger written by this repository's transactions
+func (r *MemoryStockRepository) Ledger() *MemoryStockMovementRepository {
+	return r.ledger
+}
+
+// Transfers returns the stock transfers written by this repository's transactions
+func (r *MemoryStockRepository) Transfers() *MemoryStockTransferRepository {
+	return r.transfers
+}
+
 func (r *MemoryStockRepository) Create(ctx context.Context, stock *entity.Stock) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -32,6 +50,7 @@ func (r *MemoryStockRepository) Create(ctx context.Context, stock *entity.Stock)
 	}
 
 	r.stocks[stock.ID] = stock
+	r.versions[stock.ID]++
 	return nil
 }
 
@@ -44,6 +63,7 @@ func (r *MemoryStockRepository) Update(ctx context.Context, stock *entity.Stock)
 	}
 
 	r.stocks[stock.ID] = stock
+	r.versions[stock.ID]++
 	return nil
 }
 
@@ -56,6 +76,7 @@ func (r *MemoryStockRepository) Delete(ctx context.Context, id string) error {
 	}
 
 	delete(r.stocks, id)
+	r.versions[id]++
 	return nil
 }
 
@@ -85,6 +106,7 @@ func (r *MemoryStockRepository) FindByProductID(ctx context.Context, productID s
 		}
 	}
 
+	sortByWarehouse(result)
 	return result, nil
 }
 
@@ -117,55 +139,172 @@ func (r *MemoryStockRepository) FindByProductAndWarehouse(ctx context.Context, p
 	return nil, fmt.Errorf("stock not found for product %s in warehouse %s", productID, warehouseID)
 }
 
-// BeginTransaction begins a new transaction
+// BeginTransaction begins a new transaction.
+// Writes made through the transaction's repository are private to it until Commit,
+// so concurrent transactions never see or undo each other's uncommitted changes.
 func (r *MemoryStockRepository) BeginTransaction(ctx context.Context) (repository.StockTransaction, error) {
 	return &MemoryStockTransaction{
-		repo:           r,
-		originalStocks: r.cloneStocks(),
+		repo:        r,
+		txRepo:      newMemoryStockTxRepository(r),
+		txLedger:    newMemoryStockMovementTxRepository(r.ledger),
+		txTransfers: newMemoryStockTransferTxRepository(r.transfers),
 	}, nil
 }
 
-// cloneStocks creates a deep copy of all stocks
-func (r *MemoryStockRepository) cloneStocks() map[string]*entity.Stock {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	clone := make(map[string]*entity.Stock)
-	for k, v := range r.stocks {
-		copy := *v
-		clone[k] = &copy
-	}
-	return clone
-}
-
 // MemoryStockTransaction represents a memory-based transaction
 type MemoryStockTransaction struct {
-	repo           *MemoryStockRepository
-	originalStocks map[string]*entity.Stock
-	committed      bool
+	repo        *MemoryStockRepository
+	txRepo      *memoryStockTxRepository
+	txLedger    *memoryStockMovementTxRepository
+	txTransfers *memoryStockTransferTxRepository
+	done        bool
 }
 
 func (t *MemoryStockTransaction) GetStockRepository() repository.StockRepository {
-	return t.repo
+	return t.txRepo
+}
+
+func (t *MemoryStockTransaction) GetStockMovementRepository() repository.StockMovementRepository {
+	return t.txLedger
+}
+
+func (t *MemoryStockTransaction) GetStockTransferRepository() repository.StockTransferRepository {
+	return t.txTransfers
 }
 
+// Commit publishes the transaction's writes, or returns repository.ErrTransactionConflict
+// without applying anything if a stock or transfer row it read was changed by someone else meanwhile
 func (t *MemoryStockTransaction) Commit() error {
-	if t.committed {
-		return errors.New("transaction already committed")
+	if t.done {
+		return errors.New("transaction already finished")
+	}
+	t.done = true
+
+	t.repo.mu.Lock()
+	defer t.repo.mu.Unlock()
+	t.repo.ledger.mu.Lock()
+	defer t.repo.ledger.mu.Unlock()
+	t.repo.transfers.mu.Lock()
+	defer t.repo.transfers.mu.Unlock()
+
+	if err := t.txRepo.table.validate(); err != nil {
+		return err
+	}
+	if err := t.txTransfers.table.validate(); err != nil {
+		return err
 	}
-	t.committed = true
-	// Changes are already applied to the main repository
+	t.txRepo.table.apply()
+	t.txLedger.apply()
+	t.txTransfers.table.apply()
 	return nil
 }
 
+// Rollback discards the transaction's writes; rows committed by others are left untouched
 func (t *MemoryStockTransaction) Rollback() error {
-	if t.committed {
-		return errors.New("cannot rollback committed transaction")
+	if t.done {
+		return errors.New("transaction already finished")
 	}
+	t.done = true
+	return nil
+}
 
-	// Restore original stocks
-	t.repo.mu.Lock()
-	defer t.repo.mu.Unlock()
-	t.repo.stocks = t.originalStocks
+// cloneStock returns a copy of a stock row
+func cloneStock(stock *entity.Stock) *entity.Stock {
+	copy := *stock
+	return &copy
+}
+
+// memoryStockTxRepository is a StockRepository view bound to a unit-of-work transaction
+type memoryStockTxRepository struct {
+	base  *MemoryStockRepository
+	table *txTable[entity.Stock]
+}
+
+func newMemoryStockTxRepository(base *MemoryStockRepository) *memoryStockTxRepository {
+	return &memoryStockTxRepository{
+		base:  base,
+		table: newTxTable(base.stocks, base.versions, cloneStock),
+	}
+}
+
+func (r *memoryStockTxRepository) Create(ctx context.Context, stock *entity.Stock) error {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	if _, exists := r.table.get(stock.ID); exists {
+		return errors.New("stock already exists")
+	}
+	r.table.put(stock.ID, stock)
 	return nil
-}
\ No newline at end of file
+}
+
+func (r *memoryStockTxRepository) Update(ctx context.Context, stock *entity.Stock) error {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	if _, exists := r.table.get(stock.ID); !exists {
+		return errors.New("stock not found")
+	}
+	r.table.put(stock.ID, stock)
+	return nil
+}
+
+func (r *memoryStockTxRepository) Delete(ctx context.Context, id string) error {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	if _, exists := r.table.get(id); !exists {
+		return errors.New("stock not found")
+	}
+	r.table.remove(id)
+	return nil
+}
+
+func (r *memoryStockTxRepository) FindByID(ctx context.Context, id string) (*entity.Stock, error) {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	stock, exists := r.table.get(id)
+	if !exists {
+		return nil, errors.New("stock not found")
+	}
+	return stock, nil
+}
+
+func (r *memoryStockTxRepository) FindByProductID(ctx context.Context, productID string) ([]*entity.Stock, error) {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	stocks := r.table.scan(func(s *entity.Stock) bool { return s.ProductID == productID })
+	sortByWarehouse(stocks)
+	return stocks, nil
+}
+
+func (r *memoryStockTxRepository) FindByWarehouseID(ctx context.Context, warehouseID string) ([]*entity.Stock, error) {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	return r.table.scan(func(s *entity.Stock) bool { return s.WarehouseID == warehouseID }), nil
+}
+
+func (r *memoryStockTxRepository) FindByProductAndWarehouse(ctx context.Context, productID, warehouseID string) (*entity.Stock, error) {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	stocks := r.table.scan(func(s *entity.Stock) bool {
+		return s.ProductID == productID && s.WarehouseID == warehouseID
+	})
+	if len(stocks) == 0 {
+		return nil, fmt.Errorf("stock not found for product %s in warehouse %s", productID, warehouseID)
+	}
+	return stocks[0], nil
+}
+
+func (r *memoryStockTxRepository) BeginTransaction(ctx context.Context) (repository.StockTransaction, error) {
+	return nil, errors.New("nested transactions are not supported")
+}
+
+// sortByWarehouse orders a product's stocks by warehouse ID, as the SQLite repository does
+func sortByWarehouse(stocks []*entity.Stock) {
+	sort.Slice(stocks, func(i, j int) bool { return stocks[i].WarehouseID < stocks[j].WarehouseID })
+}
@@ -0,0 +1,68 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+func TestMemoryStockTransaction_RollbackKeepsOtherCommits(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryStockRepository()
+	stock, _ := entity.NewStock("STK-001", "P001", "WH-001", 10)
+	repo.Create(ctx, stock)
+
+	// A long-running transaction starts first and later rolls back
+	slow, _ := repo.BeginTransaction(ctx)
+	slowStock, _ := slow.GetStockRepository().FindByID(ctx, "STK-001")
+	slowStock.Reduce(1)
+	slow.GetStockRepository().Update(ctx, slowStock)
+
+	// Meanwhile another transaction commits a reduction
+	fast, _ := repo.BeginTransaction(ctx)
+	fastStock, _ := fast.GetStockRepository().FindByID(ctx, "STK-001")
+	fastStock.Reduce(4)
+	fast.GetStockRepository().Update(ctx, fastStock)
+	if err := fast.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	if err := slow.Rollback(); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+
+	found, _ := repo.FindByID(ctx, "STK-001")
+	if found.Quantity != 6 {
+		t.Errorf("Expected committed quantity 6 to survive the rollback, got %d", found.Quantity)
+	}
+}
+
+func TestMemoryStockTransaction_StaleCommitIsRejected(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryStockRepository()
+	stock, _ := entity.NewStock("STK-001", "P001", "WH-001", 5)
+	repo.Create(ctx, stock)
+
+	first, _ := repo.BeginTransaction(ctx)
+	second, _ := repo.BeginTransaction(ctx)
+	for _, tx := range []repository.StockTransaction{first, second} {
+		s, _ := tx.GetStockRepository().FindByID(ctx, "STK-001")
+		s.Reduce(5)
+		tx.GetStockRepository().Update(ctx, s)
+	}
+
+	if err := first.Commit(); err != nil {
+		t.Fatalf("First commit failed: %v", err)
+	}
+	if err := second.Commit(); !errors.Is(err, repository.ErrTransactionConflict) {
+		t.Fatalf("Expected ErrTransactionConflict for the stale commit, got %v", err)
+	}
+
+	found, _ := repo.FindByID(ctx, "STK-001")
+	if found.Quantity != 0 {
+		t.Errorf("Expected quantity 0, got %d", found.Quantity)
+	}
+}
@@ -0,0 +1,154 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+)
+
+// MemoryStockReservationRepository is an in-memory implementation of StockReservationRepository
+type MemoryStockReservationRepository struct {
+	mu           sync.RWMutex
+	reservations map[string]*entity.StockReservation
+	versions     map[string]uint64 // bumped on every write so transactions can detect conflicts
+}
+
+// NewMemoryStockReservationRepository creates a new memory stock reservation repository
+func NewMemoryStockReservationRepository() *MemoryStockReservationRepository {
+	return &MemoryStockReservationRepository{
+		reservations: make(map[string]*entity.StockReservation),
+		versions:     make(map[string]uint64),
+	}
+}
+
+func (r *MemoryStockReservationRepository) Create(ctx context.Context, reservation *entity.StockReservation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.reservations[reservation.ID]; exists {
+		return errors.New("reservation already exists")
+	}
+
+	r.reservations[reservation.ID] = cloneStockReservation(reservation)
+	r.versions[reservation.ID]++
+	return nil
+}
+
+func (r *MemoryStockReservationRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.reservations[id]; !exists {
+		return errors.New("reservation not found")
+	}
+
+	delete(r.reservations, id)
+	r.versions[id]++
+	return nil
+}
+
+func (r *MemoryStockReservationRepository) FindByOrderID(ctx context.Context, orderID string) ([]*entity.StockReservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*entity.StockReservation
+	for _, reservation := range r.reservations {
+		if reservation.OrderID == orderID {
+			result = append(result, cloneStockReservation(reservation))
+		}
+	}
+	sortReservationsByID(result)
+	return result, nil
+}
+
+func (r *MemoryStockReservationRepository) FindExpired(ctx context.Context, now time.Time) ([]*entity.StockReservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*entity.StockReservation
+	for _, reservation := range r.reservations {
+		if reservation.IsExpired(now) {
+			result = append(result, cloneStockReservation(reservation))
+		}
+	}
+	sortReservationsByExpiry(result)
+	return result, nil
+}
+
+// sortReservationsByID orders reservations by ID, as the SQLite repository does
+func sortReservationsByID(reservations []*entity.StockReservation) {
+	sort.Slice(reservations, func(i, j int) bool { return reservations[i].ID < reservations[j].ID })
+}
+
+// sortReservationsByExpiry orders reservations by expiry time, then ID, as the SQLite repository does
+func sortReservationsByExpiry(reservations []*entity.StockReservation) {
+	sort.Slice(reservations, func(i, j int) bool {
+		if !reservations[i].ExpiresAt.Equal(reservations[j].ExpiresAt) {
+			return reservations[i].ExpiresAt.Before(reservations[j].ExpiresAt)
+		}
+		return reservations[i].ID < reservations[j].ID
+	})
+}
+
+// cloneStockReservation returns a copy of a reservation
+func cloneStockReservation(reservation *entity.StockReservation) *entity.StockReservation {
+	copy := *reservation
+	return &copy
+}
+
+// memoryStockReservationTxRepository is a StockReservationRepository view bound to a unit-of-work transaction
+type memoryStockReservationTxRepository struct {
+	base  *MemoryStockReservationRepository
+	table *txTable[entity.StockReservation]
+}
+
+func newMemoryStockReservationTxRepository(base *MemoryStockReservationRepository) *memoryStockReservationTxRepository {
+	return &memoryStockReservationTxRepository{
+		base:  base,
+		table: newTxTable(base.reservations, base.versions, cloneStockReservation),
+	}
+}
+
+func (r *memoryStockReservationTxRepository) Create(ctx context.Context, reservation *entity.StockReservation) error {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	if _, exists := r.table.get(reservation.ID); exists {
+		return errors.New("reservation already exists")
+	}
+	r.table.put(reservation.ID, reservation)
+	return nil
+}
+
+func (r *memoryStockReservationTxRepository) Delete(ctx context.Context, id string) error {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	if _, exists := r.table.get(id); !exists {
+		return errors.New("reservation not found")
+	}
+	r.table.remove(id)
+	return nil
+}
+
+func (r *memoryStockReservationTxRepository) FindByOrderID(ctx context.Context, orderID string) ([]*entity.StockReservation, error) {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	result := r.table.scan(func(sr *entity.StockReservation) bool { return sr.OrderID == orderID })
+	sortReservationsByID(result)
+	return result, nil
+}
+
+func (r *memoryStockReservationTxRepository) FindExpired(ctx context.Context, now time.Time) ([]*entity.StockReservation, error) {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	result := r.table.scan(func(sr *entity.StockReservation) bool { return sr.IsExpired(now) })
+	sortReservationsByExpiry(result)
+	return result, nil
+}
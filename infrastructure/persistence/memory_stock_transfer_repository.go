@@ -0,0 +1,153 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+)
+
+// MemoryStockTransferRepository is an in-memory implementation of StockTransferRepository
+type MemoryStockTransferRepository struct {
+	mu        sync.RWMutex
+	transfers map[string]*entity.StockTransfer
+	versions  map[string]uint64 // bumped on every write so transactions can detect conflicts
+}
+
+// NewMemoryStockTransferRepository creates a new memory stock transfer repository
+func NewMemoryStockTransferRepository() *MemoryStockTransferRepository {
+	return &MemoryStockTransferRepository{
+		transfers: make(map[string]*entity.StockTransfer),
+		versions:  make(map[string]uint64),
+	}
+}
+
+func (r *MemoryStockTransferRepository) Create(ctx context.Context, transfer *entity.StockTransfer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.transfers[transfer.ID]; exists {
+		return errors.New("stock transfer already exists")
+	}
+
+	r.transfers[transfer.ID] = cloneStockTransfer(transfer)
+	r.versions[transfer.ID]++
+	return nil
+}
+
+func (r *MemoryStockTransferRepository) Update(ctx context.Context, transfer *entity.StockTransfer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.transfers[transfer.ID]; !exists {
+		return errors.New("stock transfer not found")
+	}
+
+	r.transfers[transfer.ID] = cloneStockTransfer(transfer)
+	r.versions[transfer.ID]++
+	return nil
+}
+
+func (r *MemoryStockTransferRepository) FindByID(ctx context.Context, id string) (*entity.StockTransfer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	transfer, exists := r.transfers[id]
+	if !exists {
+		return nil, errors.New("stock transfer not found")
+	}
+	return cloneStockTransfer(transfer), nil
+}
+
+func (r *MemoryStockTransferRepository) FindAll(ctx context.Context) ([]*entity.StockTransfer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*entity.StockTransfer
+	for _, transfer := range r.transfers {
+		result = append(result, cloneStockTransfer(transfer))
+	}
+	sortNewestFirst(result)
+	return result, nil
+}
+
+// cloneStockTransfer returns a copy of a transfer that shares no timestamps with it
+func cloneStockTransfer(transfer *entity.StockTransfer) *entity.StockTransfer {
+	copy := *transfer
+	if transfer.ShippedAt != nil {
+		shippedAt := *transfer.ShippedAt
+		copy.ShippedAt = &shippedAt
+	}
+	if transfer.ReceivedAt != nil {
+		receivedAt := *transfer.ReceivedAt
+		copy.ReceivedAt = &receivedAt
+	}
+	return &copy
+}
+
+// sortNewestFirst orders transfers by creation time, newest first, as the SQLite repository does
+func sortNewestFirst(transfers []*entity.StockTransfer) {
+	sort.Slice(transfers, func(i, j int) bool {
+		if !transfers[i].CreatedAt.Equal(transfers[j].CreatedAt) {
+			return transfers[i].CreatedAt.After(transfers[j].CreatedAt)
+		}
+		return transfers[i].ID > transfers[j].ID
+	})
+}
+
+// memoryStockTransferTxRepository is a StockTransferRepository view bound to a stock transaction
+type memoryStockTransferTxRepository struct {
+	base  *MemoryStockTransferRepository
+	table *txTable[entity.StockTransfer]
+}
+
+func newMemoryStockTransferTxRepository(base *MemoryStockTransferRepository) *memoryStockTransferTxRepository {
+	return &memoryStockTransferTxRepository{
+		base:  base,
+		table: newTxTable(base.transfers, base.versions, cloneStockTransfer),
+	}
+}
+
+func (r *memoryStockTransferTxRepository) Create(ctx context.Context, transfer *entity.StockTransfer) error {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	if _, exists := r.table.get(transfer.ID); exists {
+		return errors.New("stock transfer already exists")
+	}
+	r.table.put(transfer.ID, transfer)
+	return nil
+}
+
+func (r *memoryStockTransferTxRepository) Update(ctx context.Context, transfer *entity.StockTransfer) error {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	if _, exists := r.table.get(transfer.ID); !exists {
+		return errors.New("stock transfer not found")
+	}
+	r.table.put(transfer.ID, transfer)
+	return nil
+}
+
+func (r *memoryStockTransferTxRepository) FindByID(ctx context.Context, id string) (*entity.StockTransfer, error) {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	transfer, exists := r.table.get(id)
+	if !exists {
+		return nil, errors.New("stock transfer not found")
+	}
+	return transfer, nil
+}
+
+func (r *memoryStockTransferTxRepository) FindAll(ctx context.Context) ([]*entity.StockTransfer, error) {
+	r.base.mu.RLock()
+	defer r.base.mu.RUnlock()
+
+	transfers := r.table.scan(func(*entity.StockTransfer) bool { return true })
+	sortNewestFirst(transfers)
+	return transfers, nil
+}
@@ -0,0 +1,112 @@
+package persistence
+
+import (
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// txTable buffers one transaction's view of a single in-memory table.
+//
+// Reads record the version of every base row they observe and writes are kept
+// in a private write set, so nothing is visible to other callers until commit.
+// At commit time validate rejects the transaction if any row it read has been
+// changed since (optimistic concurrency), and apply publishes the write set.
+//
+// The caller must hold the owning repository's read lock while calling get,
+// scan, put and remove, and its write lock while calling validate and apply.
+// A txTable belongs to a single transaction and is not safe for concurrent use.
+type txTable[T any] struct {
+	rows     map[string]*T
+	versions map[string]uint64
+	clone    func(*T) *T
+
+	reads  map[string]uint64
+	writes map[string]*T // a nil value marks a deleted row
+}
+
+func newTxTable[T any](rows map[string]*T, versions map[string]uint64, clone func(*T) *T) *txTable[T] {
+	return &txTable[T]{
+		rows:     rows,
+		versions: versions,
+		clone:    clone,
+		reads:    make(map[string]uint64),
+		writes:   make(map[string]*T),
+	}
+}
+
+// observe records the current base version of a row the first time the transaction depends on it
+func (t *txTable[T]) observe(id string) {
+	if _, seen := t.reads[id]; !seen {
+		t.reads[id] = t.versions[id]
+	}
+}
+
+// get returns a copy of the row as seen by this transaction
+func (t *txTable[T]) get(id string) (*T, bool) {
+	if row, written := t.writes[id]; written {
+		if row == nil {
+			return nil, false
+		}
+		return t.clone(row), true
+	}
+
+	t.observe(id)
+	row, exists := t.rows[id]
+	if !exists {
+		return nil, false
+	}
+	return t.clone(row), true
+}
+
+// scan returns copies of every row matching the predicate as seen by this transaction
+func (t *txTable[T]) scan(match func(*T) bool) []*T {
+	var result []*T
+	for id, row := range t.rows {
+		if _, written := t.writes[id]; written {
+			continue
+		}
+		if match(row) {
+			t.observe(id)
+			result = append(result, t.clone(row))
+		}
+	}
+	for _, row := range t.writes {
+		if row != nil && match(row) {
+			result = append(result, t.clone(row))
+		}
+	}
+	return result
+}
+
+// put stages an insert or update of a row
+func (t *txTable[T]) put(id string, row *T) {
+	t.observe(id)
+	t.writes[id] = t.clone(row)
+}
+
+// remove stages the deletion of a row
+func (t *txTable[T]) remove(id string) {
+	t.observe(id)
+	t.writes[id] = nil
+}
+
+// validate reports a conflict if any row read by the transaction has changed since
+func (t *txTable[T]) validate() error {
+	for id, version := range t.reads {
+		if t.versions[id] != version {
+			return repository.ErrTransactionConflict
+		}
+	}
+	return nil
+}
+
+// apply publishes the write set to the base table
+func (t *txTable[T]) apply() {
+	for id, row := range t.writes {
+		if row == nil {
+			delete(t.rows, id)
+		} else {
+			t.rows[id] = row
+		}
+		t.versions[id]++
+	}
+}
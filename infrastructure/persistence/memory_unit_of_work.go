@@ -0,0 +1,125 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// MemoryUnitOfWork is an in-memory implementation of UnitOfWork spanning the memory stock
+// (with its movement ledger), stock reservation, coupon and order repositories
+type MemoryUnitOfWork struct {
+	stockRepo       *MemoryStockRepository
+	reservationRepo *MemoryStockReservationRepository
+	couponRepo      *MemoryCouponRepository
+	orderRepo       *MemoryOrderRepository
+}
+
+// NewMemoryUnitOfWork creates a new in-memory unit of work
+func NewMemoryUnitOfWork(
+	stockRepo *MemoryStockRepository,
+	reservationRepo *MemoryStockReservationRepository,
+	couponRepo *MemoryCouponRepository,
+	orderRepo *MemoryOrderRepository,
+) *MemoryUnitOfWork {
+	return &MemoryUnitOfWork{
+		stockRepo:       stockRepo,
+		reservationRepo: reservationRepo,
+		couponRepo:      couponRepo,
+		orderRepo:       orderRepo,
+	}
+}
+
+// Begin starts a new transaction
+func (u *MemoryUnitOfWork) Begin(ctx context.Context) (repository.UnitOfWorkTransaction, error) {
+	return &memoryUnitOfWorkTransaction{
+		uow:         u,
+		stock:       newMemoryStockTxRepository(u.stockRepo),
+		ledger:      newMemoryStockMovementTxRepository(u.stockRepo.ledger),
+		reservation: newMemoryStockReservationTxRepository(u.reservationRepo),
+		coupon:      newMemoryCouponTxRepository(u.couponRepo),
+		order:       newMemoryOrderTxRepository(u.orderRepo),
+	}, nil
+}
+
+// memoryUnitOfWorkTransaction buffers writes to all of its repositories and
+// publishes them together on Commit
+type memoryUnitOfWorkTransaction struct {
+	uow         *MemoryUnitOfWork
+	stock       *memoryStockTxRepository
+	ledger      *memoryStockMovementTxRepository
+	reservation *memoryStockReservationTxRepository
+	coupon      *memoryCouponTxRepository
+	order       *memoryOrderTxRepository
+	done        bool
+}
+
+func (t *memoryUnitOfWorkTransaction) GetStockRepository() repository.StockRepository {
+	return t.stock
+}
+
+func (t *memoryUnitOfWorkTransaction) GetStockMovementRepository() repository.StockMovementRepository {
+	return t.ledger
+}
+
+func (t *memoryUnitOfWorkTransaction) GetStockReservationRepository() repository.StockReservationRepository {
+	return t.reservation
+}
+
+func (t *memoryUnitOfWorkTransaction) GetCouponRepository() repository.CouponRepository {
+	return t.coupon
+}
+
+func (t *memoryUnitOfWorkTransaction) GetOrderRepository() repository.OrderRepository {
+	return t.order
+}
+
+// Commit validates every row the transaction read and applies all writes atomically
+func (t *memoryUnitOfWorkTransaction) Commit() error {
+	if t.done {
+		return errors.New("transaction already finished")
+	}
+	t.done = true
+
+	// Always lock in the same order so concurrent commits cannot deadlock
+	t.uow.stockRepo.mu.Lock()
+	defer t.uow.stockRepo.mu.Unlock()
+	t.uow.stockRepo.ledger.mu.Lock()
+	defer t.uow.stockRepo.ledger.mu.Unlock()
+	t.uow.reservationRepo.mu.Lock()
+	defer t.uow.reservationRepo.mu.Unlock()
+	t.uow.couponRepo.mu.Lock()
+	defer t.uow.couponRepo.mu.Unlock()
+	t.uow.orderRepo.mu.Lock()
+	defer t.uow.orderRepo.mu.Unlock()
+
+	if err := t.stock.table.validate(); err != nil {
+		return err
+	}
+	if err := t.reservation.table.validate(); err != nil {
+		return err
+	}
+	if err := t.coupon.table.validate(); err != nil {
+		return err
+	}
+	if err := t.order.table.validate(); err != nil {
+		return err
+	}
+
+	t.stock.table.apply()
+	t.ledger.apply()
+	t.reservation.table.apply()
+	t.coupon.table.apply()
+	t.order.table.apply()
+	return nil
+}
+
+// Rollback discards all buffered writes
+func (t *memoryUnitOfWorkTransaction) Rollback() error {
+	if t.done {
+		return errors.New("transaction already finished")
+	}
+	t.done = true
+	return nil
+}
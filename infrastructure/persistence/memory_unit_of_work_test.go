@@ -0,0 +1,132 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+func newTestUnitOfWork(t *testing.T) (*MemoryUnitOfWork, *MemoryStockRepository, *MemoryCouponRepository, *MemoryOrderRepository) {
+	t.Helper()
+	ctx := context.Background()
+	stockRepo := NewMemoryStockRepository()
+	couponRepo := NewMemoryCouponRepository()
+	orderRepo := NewMemoryOrderRepository()
+
+	stock, _ := entity.NewStock("STK-001", "P001", "WH-001", 10)
+	coupon, _ := entity.NewCoupon("CPN-001", "SAVE10", "10% off", entity.CouponTypePercentage, 10)
+	order, _ := entity.NewOrder("ORD-001", "USER-001")
+	order.AddItem("P001", "Product 1", 3, 1000)
+	if err := stockRepo.Create(ctx, stock); err != nil {
+		t.Fatalf("Failed to seed stock: %v", err)
+	}
+	if err := couponRepo.Create(ctx, coupon); err != nil {
+		t.Fatalf("Failed to seed coupon: %v", err)
+	}
+	if err := orderRepo.Create(ctx, order); err != nil {
+		t.Fatalf("Failed to seed order: %v", err)
+	}
+
+	return NewMemoryUnitOfWork(stockRepo, NewMemoryStockReservationRepository(), couponRepo, orderRepo), stockRepo, couponRepo, orderRepo
+}
+
+// changeAll reduces stock, uses the coupon and marks the order paid inside tx
+func changeAll(t *testing.T, tx repository.UnitOfWorkTransaction) {
+	t.Helper()
+	ctx := context.Background()
+
+	stock, _ := tx.GetStockRepository().FindByID(ctx, "STK-001")
+	stock.Reduce(3)
+	coupon, _ := tx.GetCouponRepository().FindByCode(ctx, "SAVE10")
+	coupon.IncrementUsage()
+	order, _ := tx.GetOrderRepository().FindByID(ctx, "ORD-001")
+	order.MarkPaid()
+
+	if err := tx.GetStockRepository().Update(ctx, stock); err != nil {
+		t.Fatalf("Failed to update stock: %v", err)
+	}
+	if err := tx.GetCouponRepository().Update(ctx, coupon); err != nil {
+		t.Fatalf("Failed to update coupon: %v", err)
+	}
+	if err := tx.GetOrderRepository().Update(ctx, order); err != nil {
+		t.Fatalf("Failed to update order: %v", err)
+	}
+}
+
+func TestMemoryUnitOfWork_CommitAndRollback(t *testing.T) {
+	tests := []struct {
+		name           string
+		commit         bool
+		expectedStock  int
+		expectedUsage  int
+		expectedStatus entity.OrderStatus
+	}{
+		{"commit applies every repository", true, 7, 1, entity.OrderStatusPaid},
+		{"rollback discards every repository", false, 10, 0, entity.OrderStatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			uow, stockRepo, couponRepo, orderRepo := newTestUnitOfWork(t)
+
+			tx, _ := uow.Begin(ctx)
+			changeAll(t, tx)
+
+			// Nothing is visible outside the transaction before it finishes
+			if stock, _ := stockRepo.FindByID(ctx, "STK-001"); stock.Quantity != 10 {
+				t.Errorf("Expected uncommitted stock to stay 10, got %d", stock.Quantity)
+			}
+
+			var err error
+			if tt.commit {
+				err = tx.Commit()
+			} else {
+				err = tx.Rollback()
+			}
+			if err != nil {
+				t.Fatalf("Failed to finish transaction: %v", err)
+			}
+
+			stock, _ := stockRepo.FindByID(ctx, "STK-001")
+			coupon, _ := couponRepo.FindByCode(ctx, "SAVE10")
+			order, _ := orderRepo.FindByID(ctx, "ORD-001")
+			if stock.Quantity != tt.expectedStock {
+				t.Errorf("Expected stock %d, got %d", tt.expectedStock, stock.Quantity)
+			}
+			if coupon.UsageCount != tt.expectedUsage {
+				t.Errorf("Expected coupon usage %d, got %d", tt.expectedUsage, coupon.UsageCount)
+			}
+			if order.Status != tt.expectedStatus {
+				t.Errorf("Expected order status %s, got %s", tt.expectedStatus, order.Status)
+			}
+		})
+	}
+}
+
+func TestMemoryUnitOfWork_ConflictingCommitIsRejected(t *testing.T) {
+	ctx := context.Background()
+	uow, stockRepo, couponRepo, _ := newTestUnitOfWork(t)
+
+	first, _ := uow.Begin(ctx)
+	second, _ := uow.Begin(ctx)
+	changeAll(t, first)
+	changeAll(t, second)
+
+	if err := first.Commit(); err != nil {
+		t.Fatalf("First commit failed: %v", err)
+	}
+	if err := second.Commit(); !errors.Is(err, repository.ErrTransactionConflict) {
+		t.Fatalf("Expected ErrTransactionConflict, got %v", err)
+	}
+
+	// The losing transaction must not have applied any of its writes
+	stock, _ := stockRepo.FindByID(ctx, "STK-001")
+	coupon, _ := couponRepo.FindByCode(ctx, "SAVE10")
+	if stock.Quantity != 7 || coupon.UsageCount != 1 {
+		t.Errorf("Expected stock 7 and usage 1, got stock %d and usage %d", stock.Quantity, coupon.UsageCount)
+	}
+}
@@ -3,6 +3,7 @@ package persistence
 import (
 	"context"
 	"errors"
+	"sort"
 	"sync"
 
 	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
@@ -82,5 +83,6 @@ func (r *MemoryWarehouseRepository) FindAll(ctx context.Context) ([]*entity.Ware
 		result = append(result, &copy)
 	}
 
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
 	return result, nil
 }
\ No newline at end of file
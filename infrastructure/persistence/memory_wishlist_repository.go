@@ -111,6 +111,37 @@ func (r *MemoryWishlistRepository) FindByProduct(ctx context.Context, productID
 	return productWishlists, nil
 }
 
+// DeleteByProduct removes every wishlist entry for a product and returns how many there were
+func (r *MemoryWishlistRepository) DeleteByProduct(ctx context.Context, productID string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := 0
+	for id, w := range r.wishlists {
+		if w.ProductID == productID {
+			delete(r.wishlists, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// FindProductIDs gets the IDs of the products in any wishlist, each once
+func (r *MemoryWishlistRepository) FindProductIDs(ctx context.Context) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var productIDs []string
+	for _, w := range r.wishlists {
+		if !seen[w.ProductID] {
+			seen[w.ProductID] = true
+			productIDs = append(productIDs, w.ProductID)
+		}
+	}
+	return productIDs, nil
+}
+
 // CountByUser gets the count of wishlist items for a user
 func (r *MemoryWishlistRepository) CountByUser(ctx context.Context, userID string) (int, error) {
 	r.mu.RLock()
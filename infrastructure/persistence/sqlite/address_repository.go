@@ -0,0 +1,112 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// AddressRepository is a SQLite implementation of repository.AddressRepository
+type AddressRepository struct {
+	db *sql.DB
+}
+
+// NewAddressRepository creates a new SQLite address repository
+func NewAddressRepository(db *sql.DB) repository.AddressRepository {
+	return &AddressRepository{db: db}
+}
+
+const addressColumns = `id, user_id, recipient, postal_code, prefecture, city, line1, line2, phone, is_default, created_at, updated_at`
+
+// Create creates a new address
+func (r *AddressRepository) Create(ctx context.Context, address *entity.Address) error {
+	if exists, err := rowExists(ctx, r.db, `SELECT 1 FROM addresses WHERE id = ?`, address.ID); err != nil {
+		return err
+	} else if exists {
+		return errors.New("address already exists")
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO addresses (`+addressColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		address.ID, address.UserID, address.Recipient, address.PostalCode, address.Prefecture, address.City,
+		address.Line1, address.Line2, address.Phone, address.IsDefault, address.CreatedAt.UTC(), address.UpdatedAt.UTC())
+	return err
+}
+
+// FindByID finds an address by its ID
+func (r *AddressRepository) FindByID(ctx context.Context, id string) (*entity.Address, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+addressColumns+` FROM addresses WHERE id = ?`, id)
+	address, err := scanAddress(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("address not found")
+	}
+	return address, err
+}
+
+// FindByUserID returns a user's addresses, oldest first
+func (r *AddressRepository) FindByUserID(ctx context.Context, userID string) ([]*entity.Address, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+addressColumns+` FROM addresses WHERE user_id = ? ORDER BY created_at, id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*entity.Address
+	for rows.Next() {
+		address, err := scanAddress(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, address)
+	}
+	return result, rows.Err()
+}
+
+// Update updates an address; its default flag is changed only through SetDefault
+func (r *AddressRepository) Update(ctx context.Context, address *entity.Address) error {
+	result, err := r.db.ExecContext(ctx, `
+UPDATE addresses SET recipient = ?, postal_code = ?, prefecture = ?, city = ?, line1 = ?, line2 = ?, phone = ?, updated_at = ?
+WHERE id = ?`,
+		address.Recipient, address.PostalCode, address.Prefecture, address.City, address.Line1, address.Line2,
+		address.Phone, address.UpdatedAt.UTC(), address.ID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, "address not found")
+}
+
+// Delete deletes an address
+func (r *AddressRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM addresses WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, "address not found")
+}
+
+// SetDefault makes an address its user's default and clears the flag on the user's other addresses
+func (r *AddressRepository) SetDefault(ctx context.Context, userID, addressID string) error {
+	return inTx(ctx, r.db, r.db, func(q queryer) error {
+		if exists, err := rowExists(ctx, q, `SELECT 1 FROM addresses WHERE id = ? AND user_id = ?`, addressID, userID); err != nil {
+			return err
+		} else if !exists {
+			return errors.New("address not found")
+		}
+		_, err := q.ExecContext(ctx, `UPDATE addresses SET is_default = (id = ?) WHERE user_id = ?`, addressID, userID)
+		return err
+	})
+}
+
+func scanAddress(row rowScanner) (*entity.Address, error) {
+	var address entity.Address
+	err := row.Scan(&address.ID, &address.UserID, &address.Recipient, &address.PostalCode, &address.Prefecture, &address.City,
+		&address.Line1, &address.Line2, &address.Phone, &address.IsDefault, &address.CreatedAt, &address.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
@@ -0,0 +1,84 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// CartRepository is a SQLite implementation of repository.CartRepository
+type CartRepository struct {
+	db *sql.DB
+}
+
+// NewCartRepository creates a new SQLite cart repository
+func NewCartRepository(db *sql.DB) repository.CartRepository {
+	return &CartRepository{db: db}
+}
+
+// FindByUserID finds the cart of a user
+func (r *CartRepository) FindByUserID(ctx context.Context, userID string) (*entity.Cart, error) {
+	cart := entity.Cart{UserID: userID, Items: []entity.CartItem{}}
+	err := r.db.QueryRowContext(ctx, `SELECT coupon_code, updated_at FROM carts WHERE user_id = ?`, userID).
+		Scan(&cart.CouponCode, &cart.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("cart not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+SELECT product_id, quantity, added_at FROM cart_items WHERE user_id = ? ORDER BY line_no`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item entity.CartItem
+		if err := rows.Scan(&item.ProductID, &item.Quantity, &item.AddedAt); err != nil {
+			return nil, err
+		}
+		cart.Items = append(cart.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// Save creates or replaces the cart of its user
+func (r *CartRepository) Save(ctx context.Context, cart *entity.Cart) error {
+	return inTx(ctx, r.db, r.db, func(q queryer) error {
+		_, err := q.ExecContext(ctx, `
+INSERT INTO carts (user_id, coupon_code, updated_at) VALUES (?, ?, ?)
+ON CONFLICT (user_id) DO UPDATE SET coupon_code = excluded.coupon_code, updated_at = excluded.updated_at`,
+			cart.UserID, cart.CouponCode, cart.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		if _, err := q.ExecContext(ctx, `DELETE FROM cart_items WHERE user_id = ?`, cart.UserID); err != nil {
+			return err
+		}
+		for i, item := range cart.Items {
+			_, err := q.ExecContext(ctx,
+				`INSERT INTO cart_items (user_id, line_no, product_id, quantity, added_at) VALUES (?, ?, ?, ?, ?)`,
+				cart.UserID, i, item.ProductID, item.Quantity, item.AddedAt)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete removes the cart of a user
+func (r *CartRepository) Delete(ctx context.Context, userID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM carts WHERE user_id = ?`, userID)
+	return err
+}
@@ -0,0 +1,114 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// CouponRepository is a SQLite implementation of repository.CouponRepository
+type CouponRepository struct {
+	db queryer
+}
+
+// NewCouponRepository creates a new SQLite coupon repository
+func NewCouponRepository(db *sql.DB) repository.CouponRepository {
+	return &CouponRepository{db: db}
+}
+
+const couponColumns = `id, code, description, type, value, is_active, valid_from, valid_until,
+	usage_limit, usage_count, minimum_order, created_at, updated_at`
+
+func (r *CouponRepository) Create(ctx context.Context, coupon *entity.Coupon) error {
+	if exists, err := rowExists(ctx, r.db, `SELECT 1 FROM coupons WHERE id = ?`, coupon.ID); err != nil {
+		return err
+	} else if exists {
+		return errors.New("coupon already exists")
+	}
+	if exists, err := rowExists(ctx, r.db, `SELECT 1 FROM coupons WHERE code = ?`, coupon.Code); err != nil {
+		return err
+	} else if exists {
+		return errors.New("coupon code already exists")
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO coupons (`+couponColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		coupon.ID, coupon.Code, coupon.Description, string(coupon.Type), coupon.Value, coupon.IsActive,
+		coupon.ValidFrom, coupon.ValidUntil, coupon.UsageLimit, coupon.UsageCount, coupon.MinimumOrder,
+		coupon.CreatedAt, coupon.UpdatedAt)
+	return err
+}
+
+func (r *CouponRepository) FindByCode(ctx context.Context, code string) (*entity.Coupon, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+couponColumns+` FROM coupons WHERE code = ?`, code)
+	coupon, err := scanCoupon(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("coupon not found")
+	}
+	return coupon, err
+}
+
+func (r *CouponRepository) FindByID(ctx context.Context, id string) (*entity.Coupon, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+couponColumns+` FROM coupons WHERE id = ?`, id)
+	coupon, err := scanCoupon(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("coupon not found")
+	}
+	return coupon, err
+}
+
+func (r *CouponRepository) FindAll(ctx context.Context) ([]*entity.Coupon, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+couponColumns+` FROM coupons ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*entity.Coupon
+	for rows.Next() {
+		coupon, err := scanCoupon(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, coupon)
+	}
+	return result, rows.Err()
+}
+
+func (r *CouponRepository) Update(ctx context.Context, coupon *entity.Coupon) error {
+	result, err := r.db.ExecContext(ctx, `
+UPDATE coupons SET code = ?, description = ?, type = ?, value = ?, is_active = ?, valid_from = ?, valid_until = ?,
+	usage_limit = ?, usage_count = ?, minimum_order = ?, updated_at = ?
+WHERE id = ?`,
+		coupon.Code, coupon.Description, string(coupon.Type), coupon.Value, coupon.IsActive,
+		coupon.ValidFrom, coupon.ValidUntil, coupon.UsageLimit, coupon.UsageCount, coupon.MinimumOrder,
+		coupon.UpdatedAt, coupon.ID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, "coupon not found")
+}
+
+func (r *CouponRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM coupons WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, "coupon not found")
+}
+
+func scanCoupon(row rowScanner) (*entity.Coupon, error) {
+	var coupon entity.Coupon
+	var couponType string
+	err := row.Scan(&coupon.ID, &coupon.Code, &coupon.Description, &couponType, &coupon.Value, &coupon.IsActive,
+		&coupon.ValidFrom, &coupon.ValidUntil, &coupon.UsageLimit, &coupon.UsageCount, &coupon.MinimumOrder,
+		&coupon.CreatedAt, &coupon.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	coupon.Type = entity.CouponType(couponType)
+	return &coupon, nil
+}
@@ -0,0 +1,90 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/port"
+)
+
+// IdempotencyStore is a SQLite implementation of port.IdempotencyStore
+type IdempotencyStore struct {
+	db queryer
+}
+
+// NewIdempotencyStore creates a new SQLite idempotency store
+func NewIdempotencyStore(db *sql.DB) port.IdempotencyStore {
+	return &IdempotencyStore{db: db}
+}
+
+const idempotencyColumns = `user_id, idempotency_key, request_hash, status_code, content_type, body, created_at, expires_at`
+
+// Begin claims the key with a single conditional insert, so concurrent requests cannot both claim it.
+// Expiry times are compared as stored text, so both sides are kept in UTC.
+func (s *IdempotencyStore) Begin(ctx context.Context, record *port.IdempotencyRecord) (*port.IdempotencyRecord, bool, error) {
+	now := record.CreatedAt.UTC()
+	_, err := s.db.ExecContext(ctx,
+		`DELETE FROM idempotency_keys WHERE user_id = ? AND idempotency_key = ? AND expires_at <= ?`,
+		record.UserID, record.Key, now)
+	if err != nil {
+		return nil, false, err
+	}
+
+	result, err := s.db.ExecContext(ctx,
+		`INSERT INTO idempotency_keys (`+idempotencyColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (user_id, idempotency_key) DO NOTHING`,
+		record.UserID, record.Key, record.RequestHash, record.StatusCode, record.ContentType, record.Body,
+		record.CreatedAt.UTC(), record.ExpiresAt.UTC())
+	if err != nil {
+		return nil, false, err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return nil, false, err
+	} else if affected == 1 {
+		return nil, true, nil
+	}
+
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+idempotencyColumns+` FROM idempotency_keys WHERE user_id = ? AND idempotency_key = ?`,
+		record.UserID, record.Key)
+	existing, err := scanIdempotencyRecord(row)
+	if err != nil {
+		return nil, false, err
+	}
+	return existing, false, nil
+}
+
+func (s *IdempotencyStore) Complete(ctx context.Context, userID, key string, statusCode int, contentType string, body []byte) error {
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE idempotency_keys SET status_code = ?, content_type = ?, body = ? WHERE user_id = ? AND idempotency_key = ?`,
+		statusCode, contentType, body, userID, key)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, "idempotency key not found")
+}
+
+func (s *IdempotencyStore) Release(ctx context.Context, userID, key string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE user_id = ? AND idempotency_key = ?`, userID, key)
+	return err
+}
+
+func (s *IdempotencyStore) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= ?`, now.UTC())
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := result.RowsAffected()
+	return int(deleted), err
+}
+
+func scanIdempotencyRecord(row rowScanner) (*port.IdempotencyRecord, error) {
+	var record port.IdempotencyRecord
+	err := row.Scan(&record.UserID, &record.Key, &record.RequestHash, &record.StatusCode, &record.ContentType,
+		&record.Body, &record.CreatedAt, &record.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
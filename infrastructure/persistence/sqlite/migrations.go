@@ -0,0 +1,495 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migration is a single forward-only schema change
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// migrations lists every schema change in the order it must be applied.
+// Never edit an existing entry; append a new one instead.
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "initial schema",
+		sql: `
+CREATE TABLE users (
+	id            TEXT PRIMARY KEY,
+	username      TEXT NOT NULL UNIQUE,
+	password_hash TEXT NOT NULL,
+	is_admin      INTEGER NOT NULL DEFAULT 0,
+	created_at    TIMESTAMP NOT NULL,
+	updated_at    TIMESTAMP NOT NULL
+);
+
+CREATE TABLE products (
+	id         TEXT PRIMARY KEY,
+	name       TEXT NOT NULL,
+	price      INTEGER NOT NULL,
+	category   TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
+CREATE INDEX idx_products_category ON products (category);
+
+CREATE TABLE warehouses (
+	id         TEXT PRIMARY KEY,
+	name       TEXT NOT NULL,
+	location   TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE stocks (
+	id           TEXT PRIMARY KEY,
+	product_id   TEXT NOT NULL,
+	warehouse_id TEXT NOT NULL,
+	quantity     INTEGER NOT NULL,
+	reserved     INTEGER NOT NULL DEFAULT 0,
+	created_at   TIMESTAMP NOT NULL,
+	updated_at   TIMESTAMP NOT NULL
+);
+CREATE INDEX idx_stocks_product ON stocks (product_id);
+CREATE INDEX idx_stocks_warehouse ON stocks (warehouse_id);
+
+CREATE TABLE coupons (
+	id            TEXT PRIMARY KEY,
+	code          TEXT NOT NULL UNIQUE,
+	description   TEXT NOT NULL DEFAULT '',
+	type          TEXT NOT NULL,
+	value         INTEGER NOT NULL,
+	is_active     INTEGER NOT NULL,
+	valid_from    TIMESTAMP NOT NULL,
+	valid_until   TIMESTAMP NOT NULL,
+	usage_limit   INTEGER NOT NULL DEFAULT 0,
+	usage_count   INTEGER NOT NULL DEFAULT 0,
+	minimum_order INTEGER NOT NULL DEFAULT 0,
+	created_at    TIMESTAMP NOT NULL,
+	updated_at    TIMESTAMP NOT NULL
+);
+
+CREATE TABLE orders (
+	id              TEXT PRIMARY KEY,
+	user_id         TEXT NOT NULL,
+	total_price     INTEGER NOT NULL,
+	shipping_fee    INTEGER NOT NULL,
+	discount_amount INTEGER NOT NULL DEFAULT 0,
+	applied_coupon  TEXT NOT NULL DEFAULT '',
+	status          TEXT NOT NULL,
+	created_at      TIMESTAMP NOT NULL,
+	updated_at      TIMESTAMP NOT NULL
+);
+CREATE INDEX idx_orders_user ON orders (user_id);
+
+CREATE TABLE order_items (
+	order_id     TEXT NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+	line_no      INTEGER NOT NULL,
+	product_id   TEXT NOT NULL,
+	product_name TEXT NOT NULL,
+	quantity     INTEGER NOT NULL,
+	price        INTEGER NOT NULL,
+	subtotal     INTEGER NOT NULL,
+	PRIMARY KEY (order_id, line_no)
+);
+
+CREATE TABLE wishlists (
+	id         TEXT PRIMARY KEY,
+	user_id    TEXT NOT NULL,
+	product_id TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	UNIQUE (user_id, product_id)
+);
+CREATE INDEX idx_wishlists_product ON wishlists (product_id);
+`,
+	},
+	{
+		version: 2,
+		name:    "stock reservations",
+		sql: `
+CREATE TABLE stock_reservations (
+	id           TEXT PRIMARY KEY,
+	order_id     TEXT NOT NULL,
+	product_id   TEXT NOT NULL,
+	warehouse_id TEXT NOT NULL,
+	quantity     INTEGER NOT NULL,
+	expires_at   TIMESTAMP NOT NULL,
+	created_at   TIMESTAMP NOT NULL
+);
+CREATE INDEX idx_stock_reservations_order ON stock_reservations (order_id);
+CREATE INDEX idx_stock_reservations_expires ON stock_reservations (expires_at);
+`,
+	},
+	{
+		version: 3,
+		name:    "order item allocations",
+		sql: `
+CREATE TABLE order_item_allocations (
+	order_id     TEXT NOT NULL,
+	line_no      INTEGER NOT NULL,
+	warehouse_id TEXT NOT NULL,
+	quantity     INTEGER NOT NULL,
+	PRIMARY KEY (order_id, line_no, warehouse_id),
+	FOREIGN KEY (order_id, line_no) REFERENCES order_items (order_id, line_no) ON DELETE CASCADE
+);
+`,
+	},
+	{
+		version: 4,
+		name:    "order item allocation warehouse names",
+		sql: `
+ALTER TABLE order_item_allocations ADD COLUMN warehouse_name TEXT NOT NULL DEFAULT '';
+`,
+	},
+	{
+		version: 5,
+		name:    "order lifecycle",
+		sql: `
+UPDATE orders SET status = 'paid' WHERE status IN ('confirmed', 'completed');
+ALTER TABLE orders ADD COLUMN tracking_number TEXT NOT NULL DEFAULT '';
+CREATE TABLE order_status_history (
+	order_id    TEXT NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+	seq         INTEGER NOT NULL,
+	from_status TEXT NOT NULL,
+	to_status   TEXT NOT NULL,
+	note        TEXT NOT NULL,
+	changed_at  TIMESTAMP NOT NULL,
+	PRIMARY KEY (order_id, seq)
+);
+INSERT INTO order_status_history (order_id, seq, from_status, to_status, note, changed_at)
+SELECT id, 0, '', status, 'status before lifecycle tracking', updated_at FROM orders;
+`,
+	},
+	{
+		version: 6,
+		name:    "idempotency keys",
+		sql: `
+CREATE TABLE idempotency_keys (
+	user_id         TEXT NOT NULL,
+	idempotency_key TEXT NOT NULL,
+	request_hash    TEXT NOT NULL,
+	status_code     INTEGER NOT NULL DEFAULT 0,
+	content_type    TEXT NOT NULL DEFAULT '',
+	body            BLOB,
+	created_at      TIMESTAMP NOT NULL,
+	expires_at      TIMESTAMP NOT NULL,
+	PRIMARY KEY (user_id, idempotency_key)
+);
+CREATE INDEX idx_idempotency_keys_expires ON idempotency_keys (expires_at);
+`,
+	},
+	{
+		version: 7,
+		name:    "carts",
+		sql: `
+CREATE TABLE carts (
+	user_id     TEXT PRIMARY KEY,
+	coupon_code TEXT NOT NULL DEFAULT '',
+	updated_at  TIMESTAMP NOT NULL
+);
+CREATE TABLE cart_items (
+	user_id    TEXT NOT NULL REFERENCES carts (user_id) ON DELETE CASCADE,
+	line_no    INTEGER NOT NULL,
+	product_id TEXT NOT NULL,
+	quantity   INTEGER NOT NULL,
+	added_at   TIMESTAMP NOT NULL,
+	PRIMARY KEY (user_id, line_no),
+	UNIQUE (user_id, product_id)
+);
+`,
+	},
+	{
+		version: 8,
+		name:    "tax rates",
+		sql: `
+ALTER TABLE orders ADD COLUMN tax_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE order_items ADD COLUMN tax_class TEXT NOT NULL DEFAULT 'standard';
+ALTER TABLE order_items ADD COLUMN tax_rate INTEGER NOT NULL DEFAULT 10;
+CREATE TABLE order_tax_lines (
+	order_id TEXT NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+	rate     INTEGER NOT NULL,
+	taxable  INTEGER NOT NULL,
+	tax      INTEGER NOT NULL,
+	PRIMARY KEY (order_id, rate)
+);
+-- Orders placed so far were taxed at a flat 10%, rounded down on the whole subtotal
+INSERT INTO order_tax_lines (order_id, rate, taxable, tax)
+SELECT order_id, 10, SUM(subtotal), SUM(subtotal) / 10 FROM order_items GROUP BY order_id;
+UPDATE orders SET tax_amount = COALESCE((SELECT tax FROM order_tax_lines WHERE order_id = orders.id), 0);
+`,
+	},
+	{
+		version: 9,
+		name:    "shipping rules",
+		sql: `
+ALTER TABLE products ADD COLUMN weight INTEGER NOT NULL DEFAULT 0;
+CREATE TABLE shipping_rules (
+	id                      TEXT PRIMARY KEY,
+	name                    TEXT NOT NULL,
+	priority                INTEGER NOT NULL,
+	active                  BOOLEAN NOT NULL,
+	prefectures             TEXT NOT NULL DEFAULT '',
+	warehouse_ids           TEXT NOT NULL DEFAULT '',
+	min_items               INTEGER NOT NULL DEFAULT 0,
+	max_items               INTEGER NOT NULL DEFAULT 0,
+	min_weight              INTEGER NOT NULL DEFAULT 0,
+	max_weight              INTEGER NOT NULL DEFAULT 0,
+	fee                     INTEGER NOT NULL,
+	free_shipping_threshold INTEGER NOT NULL DEFAULT 0,
+	starts_at               TIMESTAMP,
+	ends_at                 TIMESTAMP,
+	created_at              TIMESTAMP NOT NULL,
+	updated_at              TIMESTAMP NOT NULL
+);
+CREATE TABLE order_shipping_charges (
+	order_id     TEXT NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+	seq          INTEGER NOT NULL,
+	warehouse_id TEXT NOT NULL,
+	rule_id      TEXT NOT NULL,
+	fee          INTEGER NOT NULL,
+	PRIMARY KEY (order_id, seq)
+);
+`,
+	},
+	{
+		version: 10,
+		name:    "addresses",
+		sql: `
+CREATE TABLE addresses (
+	id          TEXT PRIMARY KEY,
+	user_id     TEXT NOT NULL,
+	recipient   TEXT NOT NULL,
+	postal_code TEXT NOT NULL,
+	prefecture  TEXT NOT NULL,
+	city        TEXT NOT NULL,
+	line1       TEXT NOT NULL,
+	line2       TEXT NOT NULL DEFAULT '',
+	phone       TEXT NOT NULL,
+	is_default  BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at  TIMESTAMP NOT NULL,
+	updated_at  TIMESTAMP NOT NULL
+);
+CREATE INDEX idx_addresses_user ON addresses (user_id);
+-- The address an order ships to, copied at checkout so editing the address book does not change it
+CREATE TABLE order_shipping_addresses (
+	order_id    TEXT PRIMARY KEY REFERENCES orders (id) ON DELETE CASCADE,
+	address_id  TEXT NOT NULL,
+	recipient   TEXT NOT NULL,
+	postal_code TEXT NOT NULL,
+	prefecture  TEXT NOT NULL,
+	city        TEXT NOT NULL,
+	line1       TEXT NOT NULL,
+	line2       TEXT NOT NULL DEFAULT '',
+	phone       TEXT NOT NULL
+);
+`,
+	},
+	{
+		version: 11,
+		name:    "stock_movements",
+		sql: `
+-- Append-only ledger of every change to stocks.quantity; seq keeps the order movements happened in
+CREATE TABLE stock_movements (
+	seq            INTEGER PRIMARY KEY AUTOINCREMENT,
+	id             TEXT NOT NULL UNIQUE,
+	product_id     TEXT NOT NULL,
+	warehouse_id   TEXT NOT NULL,
+	type           TEXT NOT NULL,
+	quantity       INTEGER NOT NULL,
+	quantity_after INTEGER NOT NULL,
+	order_id       TEXT NOT NULL DEFAULT '',
+	user_id        TEXT NOT NULL DEFAULT '',
+	note           TEXT NOT NULL DEFAULT '',
+	created_at     TIMESTAMP NOT NULL
+);
+CREATE INDEX idx_stock_movements_stock ON stock_movements (product_id, warehouse_id);
+CREATE INDEX idx_stock_movements_order ON stock_movements (order_id);
+-- Open the ledger with the quantities already on hand so replaying it matches them
+INSERT INTO stock_movements (id, product_id, warehouse_id, type, quantity, quantity_after, note, created_at)
+SELECT 'MOV-OPEN-' || id, product_id, warehouse_id, 'stocktake', quantity, quantity, 'opening balance', updated_at
+FROM stocks WHERE quantity <> 0 ORDER BY id;
+`,
+	},
+	{
+		version: 12,
+		name:    "stock transfers",
+		sql: `
+CREATE TABLE stock_transfers (
+	id                TEXT PRIMARY KEY,
+	product_id        TEXT NOT NULL,
+	from_warehouse_id TEXT NOT NULL,
+	to_warehouse_id   TEXT NOT NULL,
+	quantity          INTEGER NOT NULL,
+	status            TEXT NOT NULL,
+	requested_by      TEXT NOT NULL DEFAULT '',
+	note              TEXT NOT NULL DEFAULT '',
+	created_at        TIMESTAMP NOT NULL,
+	updated_at        TIMESTAMP NOT NULL,
+	shipped_at        TIMESTAMP,
+	received_at       TIMESTAMP
+);
+CREATE INDEX idx_stock_transfers_status ON stock_transfers (status);
+`,
+	},
+	{
+		version: 13,
+		name:    "reorder points",
+		sql: `
+-- warehouse_id is empty for a product's default reorder point
+CREATE TABLE reorder_points (
+	product_id   TEXT NOT NULL,
+	warehouse_id TEXT NOT NULL DEFAULT '',
+	threshold    INTEGER NOT NULL,
+	updated_at   TIMESTAMP NOT NULL,
+	PRIMARY KEY (product_id, warehouse_id)
+);
+`,
+	},
+	{
+		version: 14,
+		name:    "backorders",
+		sql: `
+ALTER TABLE products ADD COLUMN backorder TEXT NOT NULL DEFAULT '';
+ALTER TABLE products ADD COLUMN expected_available_at TIMESTAMP;
+ALTER TABLE order_items ADD COLUMN status TEXT NOT NULL DEFAULT 'in_stock';
+ALTER TABLE order_items ADD COLUMN backordered INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE order_items ADD COLUMN expected_available_at TIMESTAMP;
+CREATE INDEX idx_order_items_backordered ON order_items (product_id) WHERE backordered > 0;
+`,
+	},
+	{
+		version: 15,
+		name:    "product skus",
+		sql: `
+ALTER TABLE products ADD COLUMN sku TEXT NOT NULL DEFAULT '';
+CREATE UNIQUE INDEX idx_products_sku ON products (sku) WHERE sku <> '';
+`,
+	},
+	{
+		version: 16,
+		name:    "archived products",
+		sql: `
+ALTER TABLE products ADD COLUMN archived_at TIMESTAMP;
+`,
+	},
+	{
+		version: 17,
+		name:    "price history and sales",
+		sql: `
+-- Append-only history of regular price changes; seq keeps the order they happened in
+CREATE TABLE price_changes (
+	seq            INTEGER PRIMARY KEY AUTOINCREMENT,
+	id             TEXT NOT NULL UNIQUE,
+	product_id     TEXT NOT NULL,
+	previous_price INTEGER NOT NULL,
+	price          INTEGER NOT NULL,
+	user_id        TEXT NOT NULL DEFAULT '',
+	changed_at     TIMESTAMP NOT NULL
+);
+CREATE INDEX idx_price_changes_product ON price_changes (product_id);
+CREATE TABLE sales (
+	id           TEXT PRIMARY KEY,
+	product_id   TEXT NOT NULL,
+	price        INTEGER NOT NULL,
+	starts_at    TIMESTAMP NOT NULL,
+	ends_at      TIMESTAMP NOT NULL,
+	created_by   TEXT NOT NULL DEFAULT '',
+	created_at   TIMESTAMP NOT NULL,
+	cancelled_at TIMESTAMP
+);
+CREATE INDEX idx_sales_product ON sales (product_id);
+`,
+	},
+	{
+		version: 18,
+		name:    "product variants",
+		sql: `
+-- A variant is a product of its own with parent_id set; options is a JSON object such as {"colour":"red"}
+ALTER TABLE products ADD COLUMN parent_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE products ADD COLUMN options TEXT NOT NULL DEFAULT '';
+ALTER TABLE products ADD COLUMN price_override INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX idx_products_parent ON products (parent_id) WHERE parent_id <> '';
+ALTER TABLE order_items ADD COLUMN sku TEXT NOT NULL DEFAULT '';
+`,
+	},
+	{
+		version: 19,
+		name:    "product descriptions",
+		sql: `
+ALTER TABLE products ADD COLUMN description TEXT NOT NULL DEFAULT '';
+`,
+	},
+}
+
+// Migrate applies every migration that has not been recorded in schema_migrations yet
+func Migrate(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+	version    INTEGER PRIMARY KEY,
+	name       TEXT NOT NULL,
+	applied_at TIMESTAMP NOT NULL
+)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	applied := make(map[int]bool)
+	rows, err := db.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to read applied migrations: %w", err)
+		}
+		applied[version] = true
+	}
+	if err := rows.Close(); err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+		if err := applyMigration(ctx, db, m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyMigration runs a single migration and records it in the same transaction
+func applyMigration(ctx context.Context, db *sql.DB, m migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %d: %w", m.version, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.sql); err != nil {
+		return fmt.Errorf("failed to apply migration %d (%s): %w", m.version, m.name, err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+		m.version, m.name, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", m.version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", m.version, err)
+	}
+	return nil
+}
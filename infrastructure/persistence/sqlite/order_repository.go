@@ -0,0 +1,471 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// OrderRepository is a SQLite implementation of repository.OrderRepository
+type OrderRepository struct {
+	db   *sql.DB
+	conn queryer
+}
+
+// NewOrderRepository creates a new SQLite order repository
+func NewOrderRepository(db *sql.DB) repository.OrderRepository {
+	return &OrderRepository{db: db, conn: db}
+}
+
+// orderSortColumns maps the sortable fields to their columns
+var orderSortColumns = map[repository.OrderSortField]string{
+	repository.OrderSortByCreatedAt:  "created_at",
+	repository.OrderSortByUpdatedAt:  "updated_at",
+	repository.OrderSortByTotalPrice: "total_price",
+}
+
+const orderColumns = `id, user_id, total_price, shipping_fee, tax_amount, discount_amount, applied_coupon, status, tracking_number, created_at, updated_at`
+
+// Create creates a new order
+func (r *OrderRepository) Create(ctx context.Context, order *entity.Order) error {
+	return inTx(ctx, r.db, r.conn, func(q queryer) error {
+		if exists, err := rowExists(ctx, q, `SELECT 1 FROM orders WHERE id = ?`, order.ID); err != nil {
+			return err
+		} else if exists {
+			return errors.New("order already exists")
+		}
+
+		_, err := q.ExecContext(ctx,
+			`INSERT INTO orders (`+orderColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			order.ID, order.UserID, order.TotalPrice, order.ShippingFee, order.TaxAmount, order.DiscountAmount,
+			order.AppliedCoupon, string(order.Status), order.TrackingNumber, order.CreatedAt.UTC(), order.UpdatedAt.UTC())
+		if err != nil {
+			return err
+		}
+		if err := insertOrderItems(ctx, q, order); err != nil {
+			return err
+		}
+		if err := insertTaxLines(ctx, q, order); err != nil {
+			return err
+		}
+		if err := insertShippingCharges(ctx, q, order); err != nil {
+			return err
+		}
+		if err := insertShippingAddress(ctx, q, order); err != nil {
+			return err
+		}
+		return insertStatusHistory(ctx, q, order)
+	})
+}
+
+// FindByID finds an order by its ID
+func (r *OrderRepository) FindByID(ctx context.Context, id string) (*entity.Order, error) {
+	row := r.conn.QueryRowContext(ctx, `SELECT `+orderColumns+` FROM orders WHERE id = ?`, id)
+	order, err := scanOrder(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("order not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadDetails(ctx, order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// FindByUserID finds orders by user ID
+func (r *OrderRepository) FindByUserID(ctx context.Context, userID string) ([]*entity.Order, error) {
+	return r.findMany(ctx, `SELECT `+orderColumns+` FROM orders WHERE user_id = ? ORDER BY created_at, id`, userID)
+}
+
+// Update updates an order; its shipping address is fixed when the order is created
+func (r *OrderRepository) Update(ctx context.Context, order *entity.Order) error {
+	return inTx(ctx, r.db, r.conn, func(q queryer) error {
+		result, err := q.ExecContext(ctx, `
+UPDATE orders SET user_id = ?, total_price = ?, shipping_fee = ?, tax_amount = ?, discount_amount = ?, applied_coupon = ?,
+	status = ?, tracking_number = ?, updated_at = ?
+WHERE id = ?`,
+			order.UserID, order.TotalPrice, order.ShippingFee, order.TaxAmount, order.DiscountAmount, order.AppliedCoupon,
+			string(order.Status), order.TrackingNumber, order.UpdatedAt.UTC(), order.ID)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result, "order not found"); err != nil {
+			return err
+		}
+
+		if _, err := q.ExecContext(ctx, `DELETE FROM order_items WHERE order_id = ?`, order.ID); err != nil {
+			return err
+		}
+		if err := insertOrderItems(ctx, q, order); err != nil {
+			return err
+		}
+
+		if _, err := q.ExecContext(ctx, `DELETE FROM order_tax_lines WHERE order_id = ?`, order.ID); err != nil {
+			return err
+		}
+		if err := insertTaxLines(ctx, q, order); err != nil {
+			return err
+		}
+
+		if _, err := q.ExecContext(ctx, `DELETE FROM order_shipping_charges WHERE order_id = ?`, order.ID); err != nil {
+			return err
+		}
+		if err := insertShippingCharges(ctx, q, order); err != nil {
+			return err
+		}
+
+		if _, err := q.ExecContext(ctx, `DELETE FROM order_status_history WHERE order_id = ?`, order.ID); err != nil {
+			return err
+		}
+		return insertStatusHistory(ctx, q, order)
+	})
+}
+
+// FindAll finds all orders
+func (r *OrderRepository) FindAll(ctx context.Context) ([]*entity.Order, error) {
+	return r.findMany(ctx, `SELECT `+orderColumns+` FROM orders ORDER BY created_at, id`)
+}
+
+// Search finds one page of the orders matching filter, together with the total number of matches.
+// Timestamps are stored in UTC so the date range can be compared as stored text.
+func (r *OrderRepository) Search(ctx context.Context, filter repository.OrderFilter) ([]*entity.Order, int, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.Status != "" {
+		conditions = append(conditions, `status = ?`)
+		args = append(args, string(filter.Status))
+	}
+	if filter.UserID != "" {
+		conditions = append(conditions, `user_id = ?`)
+		args = append(args, filter.UserID)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		conditions = append(conditions, `created_at >= ?`)
+		args = append(args, filter.CreatedFrom.UTC())
+	}
+	if !filter.CreatedBefore.IsZero() {
+		conditions = append(conditions, `created_at < ?`)
+		args = append(args, filter.CreatedBefore.UTC())
+	}
+	if filter.CouponCode != "" {
+		conditions = append(conditions, `applied_coupon = ?`)
+		args = append(args, filter.CouponCode)
+	}
+	if filter.WarehouseID != "" {
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM order_item_allocations a WHERE a.order_id = orders.id AND a.warehouse_id = ?)`)
+		args = append(args, filter.WarehouseID)
+	}
+	if filter.BackorderedProductID != "" {
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM order_items i WHERE i.order_id = orders.id AND i.product_id = ? AND i.backordered > 0)`)
+		args = append(args, filter.BackorderedProductID)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = ` WHERE ` + strings.Join(conditions, ` AND `)
+	}
+
+	var total int
+	if err := r.conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM orders`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	column, ok := orderSortColumns[filter.SortBy]
+	if !ok {
+		column = orderSortColumns[repository.OrderSortByCreatedAt]
+	}
+	direction := `ASC`
+	if filter.Descending {
+		direction = `DESC`
+	}
+	query := `SELECT ` + orderColumns + ` FROM orders` + where + ` ORDER BY ` + column + ` ` + direction + `, id ` + direction
+
+	// SQLite only accepts OFFSET after LIMIT; -1 means no limit
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	query += ` LIMIT ? OFFSET ?`
+	args = append(args, limit, max(filter.Offset, 0))
+
+	orders, err := r.findMany(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return orders, total, nil
+}
+
+func (r *OrderRepository) findMany(ctx context.Context, query string, args ...interface{}) ([]*entity.Order, error) {
+	rows, err := r.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*entity.Order
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		result = append(result, order)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	// Details are loaded after the order cursor is closed so the queries don't interleave on one connection
+	for _, order := range result {
+		if err := r.loadDetails(ctx, order); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// loadDetails loads the order's lines, their allocations, its tax totals, its shipping charges,
+// its shipping address and its status history
+func (r *OrderRepository) loadDetails(ctx context.Context, order *entity.Order) error {
+	if err := r.loadItems(ctx, order); err != nil {
+		return err
+	}
+	if err := r.loadTaxLines(ctx, order); err != nil {
+		return err
+	}
+	if err := r.loadShippingCharges(ctx, order); err != nil {
+		return err
+	}
+	if err := r.loadShippingAddress(ctx, order); err != nil {
+		return err
+	}
+	return r.loadStatusHistory(ctx, order)
+}
+
+func (r *OrderRepository) loadItems(ctx context.Context, order *entity.Order) error {
+	rows, err := r.conn.QueryContext(ctx, `
+SELECT product_id, sku, product_name, quantity, price, subtotal, tax_class, tax_rate, status, backordered, expected_available_at
+FROM order_items WHERE order_id = ? ORDER BY line_no`, order.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	order.Items = []entity.OrderItem{}
+	for rows.Next() {
+		var item entity.OrderItem
+		var taxClass, status string
+		var expectedAt sql.NullTime
+		if err := rows.Scan(&item.ProductID, &item.SKU, &item.ProductName, &item.Quantity, &item.Price, &item.Subtotal, &taxClass, &item.TaxRate,
+			&status, &item.Backordered, &expectedAt); err != nil {
+			return err
+		}
+		item.TaxClass = entity.TaxClass(taxClass)
+		item.Status = entity.OrderItemStatus(status)
+		if expectedAt.Valid {
+			item.ExpectedAt = &expectedAt.Time
+		}
+		order.Items = append(order.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Close the item cursor before querying allocations on the same connection
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	return r.loadAllocations(ctx, order)
+}
+
+func (r *OrderRepository) loadAllocations(ctx context.Context, order *entity.Order) error {
+	rows, err := r.conn.QueryContext(ctx, `
+SELECT line_no, warehouse_id, warehouse_name, quantity
+FROM order_item_allocations WHERE order_id = ? ORDER BY line_no, warehouse_id`, order.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var lineNo int
+		var allocation entity.ItemAllocation
+		if err := rows.Scan(&lineNo, &allocation.WarehouseID, &allocation.WarehouseName, &allocation.Quantity); err != nil {
+			return err
+		}
+		if lineNo < 0 || lineNo >= len(order.Items) {
+			return fmt.Errorf("allocation for unknown line %d of order %s", lineNo, order.ID)
+		}
+		order.Items[lineNo].Allocations = append(order.Items[lineNo].Allocations, allocation)
+	}
+	return rows.Err()
+}
+
+func (r *OrderRepository) loadTaxLines(ctx context.Context, order *entity.Order) error {
+	rows, err := r.conn.QueryContext(ctx, `
+SELECT rate, taxable, tax FROM order_tax_lines WHERE order_id = ? ORDER BY rate`, order.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	order.TaxLines = []entity.TaxLine{}
+	for rows.Next() {
+		var line entity.TaxLine
+		if err := rows.Scan(&line.Rate, &line.Taxable, &line.Tax); err != nil {
+			return err
+		}
+		order.TaxLines = append(order.TaxLines, line)
+	}
+	return rows.Err()
+}
+
+// loadShippingCharges leaves the charges nil for orders that paid the standard fee before
+// shipping rules existed
+func (r *OrderRepository) loadShippingCharges(ctx context.Context, order *entity.Order) error {
+	rows, err := r.conn.QueryContext(ctx, `
+SELECT warehouse_id, rule_id, fee FROM order_shipping_charges WHERE order_id = ? ORDER BY seq`, order.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var charge entity.ShippingCharge
+		if err := rows.Scan(&charge.WarehouseID, &charge.RuleID, &charge.Fee); err != nil {
+			return err
+		}
+		order.ShippingCharges = append(order.ShippingCharges, charge)
+	}
+	return rows.Err()
+}
+
+// loadShippingAddress leaves the address nil for orders placed before addresses were recorded
+func (r *OrderRepository) loadShippingAddress(ctx context.Context, order *entity.Order) error {
+	var address entity.ShippingAddress
+	err := r.conn.QueryRowContext(ctx, `
+SELECT address_id, recipient, postal_code, prefecture, city, line1, line2, phone
+FROM order_shipping_addresses WHERE order_id = ?`, order.ID).
+		Scan(&address.AddressID, &address.Recipient, &address.PostalCode, &address.Prefecture, &address.City,
+			&address.Line1, &address.Line2, &address.Phone)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	order.ShippingAddress = &address
+	return nil
+}
+
+func (r *OrderRepository) loadStatusHistory(ctx context.Context, order *entity.Order) error {
+	rows, err := r.conn.QueryContext(ctx, `
+SELECT from_status, to_status, note, changed_at
+FROM order_status_history WHERE order_id = ? ORDER BY seq`, order.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	order.StatusHistory = []entity.StatusChange{}
+	for rows.Next() {
+		var change entity.StatusChange
+		var from, to string
+		if err := rows.Scan(&from, &to, &change.Note, &change.ChangedAt); err != nil {
+			return err
+		}
+		change.From = entity.OrderStatus(from)
+		change.To = entity.OrderStatus(to)
+		order.StatusHistory = append(order.StatusHistory, change)
+	}
+	return rows.Err()
+}
+
+func insertOrderItems(ctx context.Context, q queryer, order *entity.Order) error {
+	for i, item := range order.Items {
+		_, err := q.ExecContext(ctx, `
+INSERT INTO order_items (order_id, line_no, product_id, sku, product_name, quantity, price, subtotal, tax_class, tax_rate, status, backordered, expected_available_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			order.ID, i, item.ProductID, item.SKU, item.ProductName, item.Quantity, item.Price, item.Subtotal, string(item.TaxClass), item.TaxRate,
+			string(item.Status), item.Backordered, nullTime(item.ExpectedAt))
+		if err != nil {
+			return err
+		}
+
+		for _, allocation := range item.Allocations {
+			_, err := q.ExecContext(ctx, `
+INSERT INTO order_item_allocations (order_id, line_no, warehouse_id, warehouse_name, quantity) VALUES (?, ?, ?, ?, ?)`,
+				order.ID, i, allocation.WarehouseID, allocation.WarehouseName, allocation.Quantity)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func insertTaxLines(ctx context.Context, q queryer, order *entity.Order) error {
+	for _, line := range order.TaxLines {
+		_, err := q.ExecContext(ctx, `INSERT INTO order_tax_lines (order_id, rate, taxable, tax) VALUES (?, ?, ?, ?)`,
+			order.ID, line.Rate, line.Taxable, line.Tax)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertShippingCharges(ctx context.Context, q queryer, order *entity.Order) error {
+	for i, charge := range order.ShippingCharges {
+		_, err := q.ExecContext(ctx, `
+INSERT INTO order_shipping_charges (order_id, seq, warehouse_id, rule_id, fee) VALUES (?, ?, ?, ?, ?)`,
+			order.ID, i, charge.WarehouseID, charge.RuleID, charge.Fee)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertShippingAddress(ctx context.Context, q queryer, order *entity.Order) error {
+	address := order.ShippingAddress
+	if address == nil {
+		return nil
+	}
+	_, err := q.ExecContext(ctx, `
+INSERT INTO order_shipping_addresses (order_id, address_id, recipient, postal_code, prefecture, city, line1, line2, phone)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		order.ID, address.AddressID, address.Recipient, address.PostalCode, address.Prefecture, address.City,
+		address.Line1, address.Line2, address.Phone)
+	return err
+}
+
+func insertStatusHistory(ctx context.Context, q queryer, order *entity.Order) error {
+	for i, change := range order.StatusHistory {
+		_, err := q.ExecContext(ctx, `
+INSERT INTO order_status_history (order_id, seq, from_status, to_status, note, changed_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			order.ID, i, string(change.From), string(change.To), change.Note, change.ChangedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scanOrder(row rowScanner) (*entity.Order, error) {
+	var order entity.Order
+	var status string
+	err := row.Scan(&order.ID, &order.UserID, &order.TotalPrice, &order.ShippingFee, &order.TaxAmount, &order.DiscountAmount,
+		&order.AppliedCoupon, &status, &order.TrackingNumber, &order.CreatedAt, &order.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	order.Status = entity.OrderStatus(status)
+	return &order, nil
+}
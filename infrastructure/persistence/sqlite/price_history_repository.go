@@ -0,0 +1,48 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// PriceHistoryRepository is a SQLite implementation of repository.PriceHistoryRepository
+type PriceHistoryRepository struct {
+	db queryer
+}
+
+// NewPriceHistoryRepository creates a new SQLite price history
+func NewPriceHistoryRepository(db *sql.DB) repository.PriceHistoryRepository {
+	return &PriceHistoryRepository{db: db}
+}
+
+const priceChangeColumns = `id, product_id, previous_price, price, user_id, changed_at`
+
+func (r *PriceHistoryRepository) Append(ctx context.Context, change *entity.PriceChange) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO price_changes (`+priceChangeColumns+`) VALUES (?, ?, ?, ?, ?, ?)`,
+		change.ID, change.ProductID, change.PreviousPrice, change.Price, change.UserID, change.ChangedAt)
+	return err
+}
+
+func (r *PriceHistoryRepository) FindByProduct(ctx context.Context, productID string) ([]*entity.PriceChange, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+priceChangeColumns+` FROM price_changes WHERE product_id = ? ORDER BY seq`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*entity.PriceChange
+	for rows.Next() {
+		var change entity.PriceChange
+		err := rows.Scan(&change.ID, &change.ProductID, &change.PreviousPrice, &change.Price, &change.UserID, &change.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &change)
+	}
+	return result, rows.Err()
+}
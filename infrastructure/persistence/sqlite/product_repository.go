@@ -0,0 +1,213 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// ProductRepository is a SQLite implementation of repository.ProductRepository
+type ProductRepository struct {
+	db   *sql.DB
+	conn queryer
+}
+
+// NewProductRepository creates a new SQLite product repository
+func NewProductRepository(db *sql.DB) repository.ProductRepository {
+	return &ProductRepository{db: db, conn: db}
+}
+
+const productColumns = `id, sku, parent_id, options, price_override, name, description, price, category, weight, backorder,
+	expected_available_at, archived_at, created_at, updated_at`
+
+// Create creates a new product
+func (r *ProductRepository) Create(ctx context.Context, product *entity.Product) error {
+	if exists, err := rowExists(ctx, r.conn, `SELECT 1 FROM products WHERE id = ?`, product.ID); err != nil {
+		return err
+	} else if exists {
+		return errors.New("product already exists")
+	}
+	if err := r.checkSKU(ctx, product); err != nil {
+		return err
+	}
+
+	_, err := r.conn.ExecContext(ctx,
+		`INSERT INTO products (`+productColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		product.ID, product.SKU, product.ParentID, joinOptions(product.Options), product.PriceOverride, product.Name, product.Description, product.Price, product.Category, product.Weight,
+		string(product.Backorder), nullTime(product.ExpectedAvailableAt), nullTime(product.ArchivedAt), product.CreatedAt, product.UpdatedAt)
+	return err
+}
+
+// FindByID finds a product by its ID
+func (r *ProductRepository) FindByID(ctx context.Context, id string) (*entity.Product, error) {
+	row := r.conn.QueryRowContext(ctx, `SELECT `+productColumns+` FROM products WHERE id = ?`, id)
+	product, err := scanProduct(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("product not found")
+	}
+	return product, err
+}
+
+// FindBySKU finds a product by its SKU
+func (r *ProductRepository) FindBySKU(ctx context.Context, sku string) (*entity.Product, error) {
+	if sku == "" {
+		return nil, errors.New("product not found")
+	}
+	row := r.conn.QueryRowContext(ctx, `SELECT `+productColumns+` FROM products WHERE sku = ?`, sku)
+	product, err := scanProduct(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("product not found")
+	}
+	return product, err
+}
+
+// FindAll finds all products with optional category filter
+func (r *ProductRepository) FindAll(ctx context.Context, category string) ([]*entity.Product, error) {
+	query := `SELECT ` + productColumns + ` FROM products`
+	var args []interface{}
+	if category != "" {
+		query += ` WHERE category = ?`
+		args = append(args, category)
+	}
+	return r.query(ctx, query+` ORDER BY created_at, id`, args...)
+}
+
+// FindVariants finds the variants of a parent product ordered by SKU
+func (r *ProductRepository) FindVariants(ctx context.Context, parentID string) ([]*entity.Product, error) {
+	if parentID == "" {
+		return nil, nil
+	}
+	return r.query(ctx, `SELECT `+productColumns+` FROM products WHERE parent_id = ? ORDER BY sku, id`, parentID)
+}
+
+func (r *ProductRepository) query(ctx context.Context, query string, args ...interface{}) ([]*entity.Product, error) {
+	rows, err := r.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*entity.Product
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, product)
+	}
+	return result, rows.Err()
+}
+
+// Update updates a product
+func (r *ProductRepository) Update(ctx context.Context, product *entity.Product) error {
+	if err := r.checkSKU(ctx, product); err != nil {
+		return err
+	}
+	result, err := r.conn.ExecContext(ctx,
+		`UPDATE products SET sku = ?, parent_id = ?, options = ?, price_override = ?, name = ?, description = ?, price = ?, category = ?,
+	weight = ?, backorder = ?, expected_available_at = ?, archived_at = ?, updated_at = ? WHERE id = ?`,
+		product.SKU, product.ParentID, joinOptions(product.Options), product.PriceOverride, product.Name, product.Description, product.Price, product.Category, product.Weight,
+		string(product.Backorder), nullTime(product.ExpectedAvailableAt), nullTime(product.ArchivedAt), product.UpdatedAt, product.ID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, "product not found")
+}
+
+// checkSKU rejects a SKU another product already uses
+func (r *ProductRepository) checkSKU(ctx context.Context, product *entity.Product) error {
+	if product.SKU == "" {
+		return nil
+	}
+	if exists, err := rowExists(ctx, r.conn,
+		`SELECT 1 FROM products WHERE sku = ? AND id <> ?`, product.SKU, product.ID); err != nil {
+		return err
+	} else if exists {
+		return repository.ErrSKUTaken
+	}
+	return nil
+}
+
+// BeginTransaction starts a new transaction
+func (r *ProductRepository) BeginTransaction(ctx context.Context) (repository.Transaction, error) {
+	if r.db == nil {
+		return nil, errors.New("nested transactions are not supported")
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return &productTransaction{
+		tx:   tx,
+		repo: &ProductRepository{conn: tx},
+	}, nil
+}
+
+// productTransaction is a repository.Transaction backed by a SQL transaction
+type productTransaction struct {
+	tx   *sql.Tx
+	repo *ProductRepository
+}
+
+// Commit commits the transaction
+func (t *productTransaction) Commit() error {
+	return t.tx.Commit()
+}
+
+// Rollback rolls back the transaction
+func (t *productTransaction) Rollback() error {
+	return t.tx.Rollback()
+}
+
+// GetProductRepository returns the product repository for this transaction
+func (t *productTransaction) GetProductRepository() repository.ProductRepository {
+	return t.repo
+}
+
+func scanProduct(row rowScanner) (*entity.Product, error) {
+	var product entity.Product
+	var backorder, options string
+	var expectedAt, archivedAt sql.NullTime
+	err := row.Scan(&product.ID, &product.SKU, &product.ParentID, &options, &product.PriceOverride, &product.Name, &product.Description, &product.Price, &product.Category, &product.Weight,
+		&backorder, &expectedAt, &archivedAt, &product.CreatedAt, &product.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	product.Backorder = entity.BackorderMode(backorder)
+	if product.Options, err = splitOptions(options); err != nil {
+		return nil, err
+	}
+	if expectedAt.Valid {
+		product.ExpectedAvailableAt = &expectedAt.Time
+	}
+	if archivedAt.Valid {
+		product.ArchivedAt = &archivedAt.Time
+	}
+	product.Stocks = []entity.StockInfo{}
+	return &product, nil
+}
+
+// joinOptions stores the options of a variant in one column as a JSON object
+func joinOptions(options map[string]string) string {
+	if len(options) == 0 {
+		return ""
+	}
+	encoded, _ := json.Marshal(options)
+	return string(encoded)
+}
+
+func splitOptions(value string) (map[string]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var options map[string]string
+	if err := json.Unmarshal([]byte(value), &options); err != nil {
+		return nil, fmt.Errorf("invalid options column %q: %w", value, err)
+	}
+	return options, nil
+}
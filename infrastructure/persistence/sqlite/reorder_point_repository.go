@@ -0,0 +1,53 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// ReorderPointRepository is a SQLite implementation of repository.ReorderPointRepository
+type ReorderPointRepository struct {
+	db queryer
+}
+
+// NewReorderPointRepository creates a new SQLite reorder point repository
+func NewReorderPointRepository(db *sql.DB) repository.ReorderPointRepository {
+	return &ReorderPointRepository{db: db}
+}
+
+func (r *ReorderPointRepository) Save(ctx context.Context, reorderPoint *entity.ReorderPoint) error {
+	_, err := r.db.ExecContext(ctx, `
+INSERT INTO reorder_points (product_id, warehouse_id, threshold, updated_at) VALUES (?, ?, ?, ?)
+ON CONFLICT (product_id, warehouse_id) DO UPDATE SET threshold = excluded.threshold, updated_at = excluded.updated_at`,
+		reorderPoint.ProductID, reorderPoint.WarehouseID, reorderPoint.Threshold, reorderPoint.UpdatedAt)
+	return err
+}
+
+func (r *ReorderPointRepository) Delete(ctx context.Context, productID, warehouseID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM reorder_points WHERE product_id = ? AND warehouse_id = ?`, productID, warehouseID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, "reorder point not found")
+}
+
+func (r *ReorderPointRepository) FindAll(ctx context.Context) ([]*entity.ReorderPoint, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT product_id, warehouse_id, threshold, updated_at FROM reorder_points ORDER BY product_id, warehouse_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*entity.ReorderPoint
+	for rows.Next() {
+		var reorderPoint entity.ReorderPoint
+		if err := rows.Scan(&reorderPoint.ProductID, &reorderPoint.WarehouseID, &reorderPoint.Threshold, &reorderPoint.UpdatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, &reorderPoint)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,723 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/port"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := Open(context.Background(), filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestMigrate_IsIdempotent(t *testing.T) {
+	db := openTestDB(t)
+
+	// Open already migrated once; running again must be a no-op
+	if err := Migrate(context.Background(), db); err != nil {
+		t.Fatalf("Second migration run failed: %v", err)
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&count); err != nil {
+		t.Fatalf("Failed to count migrations: %v", err)
+	}
+	if count != len(migrations) {
+		t.Errorf("Expected %d recorded migrations, got %d", len(migrations), count)
+	}
+}
+
+func TestProductRepository_SKU(t *testing.T) {
+	ctx := context.Background()
+	repo := NewProductRepository(openTestDB(t))
+
+	desk, _ := entity.NewProduct("P001", "Desk", 300, "Furniture")
+	desk.SetSKU("DESK-OAK")
+	chair, _ := entity.NewProduct("P002", "Chair", 100, "Furniture")
+	for _, product := range []*entity.Product{desk, chair} {
+		if err := repo.Create(ctx, product); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	found, err := repo.FindBySKU(ctx, "DESK-OAK")
+	if err != nil || found.ID != "P001" {
+		t.Fatalf("Expected to find the desk by SKU, got %+v, %v", found, err)
+	}
+	if _, err := repo.FindBySKU(ctx, ""); err == nil {
+		t.Error("Expected an empty SKU to match no product")
+	}
+
+	chair.SetSKU("DESK-OAK")
+	if err := repo.Update(ctx, chair); !errors.Is(err, repository.ErrSKUTaken) {
+		t.Errorf("Expected a taken SKU to be rejected, got %v", err)
+	}
+	desk.SetSKU("")
+	if err := repo.Update(ctx, desk); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if err := repo.Update(ctx, chair); err != nil {
+		t.Errorf("Expected the freed SKU to be usable, got %v", err)
+	}
+}
+
+func TestProductRepository_Archive(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	repo := NewProductRepository(db)
+	wishlists := NewWishlistRepository(db)
+
+	desk, _ := entity.NewProduct("P001", "Desk", 300, "Furniture")
+	repo.Create(ctx, desk)
+	desk.Archive()
+	if err := repo.Update(ctx, desk); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	found, err := repo.FindByID(ctx, "P001")
+	if err != nil || !found.IsArchived() {
+		t.Fatalf("Expected the desk to come back archived, got %+v, %v", found, err)
+	}
+
+	for _, productID := range []string{"P001", "P404"} {
+		entry, _ := entity.NewWishlist("WL-"+productID, "USER-001", productID)
+		wishlists.Create(ctx, entry)
+	}
+	productIDs, _ := wishlists.FindProductIDs(ctx)
+	if len(productIDs) != 2 {
+		t.Errorf("Expected 2 wishlisted products, got %v", productIDs)
+	}
+	if removed, err := wishlists.DeleteByProduct(ctx, "P404"); err != nil || removed != 1 {
+		t.Errorf("Expected 1 entry removed, got %d, %v", removed, err)
+	}
+}
+
+func TestProductRepository_Variants(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	repo := NewProductRepository(db)
+
+	shirt, _ := entity.NewProduct("P010", "T-Shirt", 2000, "Apparel")
+	shirt.SetDescription("綿100%のTシャツ")
+	repo.Create(ctx, shirt)
+	price := 2500
+	variant, _ := entity.NewVariant("P011", shirt, "TS-XL-RED", map[string]string{"size": "XL", "colour": "Red"}, &price)
+	if err := repo.Create(ctx, variant); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// Variants are listed by SKU, not in the order they were added
+	blue, _ := entity.NewVariant("P012", shirt, "TS-M-BLUE", map[string]string{"size": "M", "colour": "Blue"}, nil)
+	repo.Create(ctx, blue)
+
+	variants, err := repo.FindVariants(ctx, "P010")
+	if err != nil || len(variants) != 2 || variants[0].ID != "P012" {
+		t.Fatalf("Expected 2 variants in SKU order, got %+v, %v", variants, err)
+	}
+	found := variants[1]
+	if found.ParentID != "P010" || !found.HasOptions(variant.Options) || !found.PriceOverride || found.Price != 2500 ||
+		found.Description != "綿100%のTシャツ" {
+		t.Errorf("Expected the variant to round trip, got %+v", found)
+	}
+	if parents, _ := repo.FindVariants(ctx, "P011"); len(parents) != 0 {
+		t.Errorf("Expected a variant to have no variants, got %+v", parents)
+	}
+
+	// Order lines keep the SKU they were ordered by
+	orders := NewOrderRepository(db)
+	order, _ := entity.NewOrder("ORD-001", "USER-001")
+	order.AddItem("P011", found.Name, 1, found.Price)
+	order.Items[0].SKU = found.SKU
+	orders.Create(ctx, order)
+	if loaded, err := orders.FindByID(ctx, "ORD-001"); err != nil || loaded.Items[0].SKU != "TS-XL-RED" {
+		t.Errorf("Expected the order line to keep its SKU, got %+v, %v", loaded, err)
+	}
+}
+
+func TestSaleRepository_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	repo := NewSaleRepository(openTestDB(t))
+
+	now := time.Now()
+	running, _ := entity.NewSale("SALE-1", "P001", 250, now.Add(-time.Hour), now.Add(time.Hour), "USR-ADMIN")
+	upcoming, _ := entity.NewSale("SALE-2", "P001", 200, now.Add(time.Hour), now.Add(2*time.Hour), "USR-ADMIN")
+	for _, sale := range []*entity.Sale{upcoming, running} {
+		if err := repo.Create(ctx, sale); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	effective, err := repo.FindEffective(ctx, now)
+	if err != nil || len(effective) != 1 || effective[0].ID != "SALE-1" {
+		t.Fatalf("Expected only SALE-1 to be running, got %+v, %v", effective, err)
+	}
+	running.Cancel(now)
+	if err := repo.Update(ctx, running); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if effective, _ := repo.FindEffective(ctx, now); len(effective) != 0 {
+		t.Errorf("Expected no sale running after the cancellation, got %+v", effective)
+	}
+	sales, _ := repo.FindByProduct(ctx, "P001")
+	if len(sales) != 2 || sales[0].ID != "SALE-1" || sales[0].CancelledAt == nil {
+		t.Errorf("Expected both sales ordered by start with SALE-1 cancelled, got %+v", sales)
+	}
+}
+
+func TestOrderRepository_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	repo := NewOrderRepository(openTestDB(t))
+
+	order := newTestOrder(t)
+	order.SetShippingAddress(entity.ShippingAddress{
+		AddressID: "ADDR-1", Recipient: "山田 太郎", PostalCode: "100-0001", Prefecture: "東京都",
+		City: "千代田区", Line1: "千代田1-1", Phone: "0312345678",
+	})
+	if err := repo.Create(ctx, order); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := repo.Create(ctx, order); err == nil {
+		t.Error("Expected duplicate create to fail")
+	}
+
+	order.SetShippingCharges([]entity.ShippingCharge{{WarehouseID: "WH-001", RuleID: "R1", Fee: 300}, {Fee: 500}})
+	order.ApplyCouponDiscount("SAVE10", 100)
+	order.Items[0].Allocations = []entity.ItemAllocation{
+		{WarehouseID: "WH-001", WarehouseName: "Tokyo", Quantity: 1},
+		{WarehouseID: "WH-002", WarehouseName: "Osaka", Quantity: 1},
+	}
+	order.MarkPaid()
+	order.TransitionTo(entity.OrderStatusPicking, "")
+	order.Ship("TRK-001", "handed to carrier")
+	if err := repo.Update(ctx, order); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	found, err := repo.FindByID(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if len(found.Items) != 2 || found.Items[1].ProductID != "P002" {
+		t.Errorf("Expected items to round-trip in order, got %+v", found.Items)
+	}
+	if len(found.Items[0].Allocations) != 2 || found.Items[0].Allocations[1].WarehouseName != "Osaka" || len(found.Items[1].Allocations) != 0 {
+		t.Errorf("Expected allocations to round-trip per line, got %+v", found.Items)
+	}
+	if found.TotalPrice != order.TotalPrice || found.AppliedCoupon != "SAVE10" {
+		t.Errorf("Expected total %d with coupon SAVE10, got %d with %q", order.TotalPrice, found.TotalPrice, found.AppliedCoupon)
+	}
+	if found.TaxAmount != 240 || len(found.TaxLines) != 2 || found.TaxLines[0] != (entity.TaxLine{Rate: 8, Taxable: 500, Tax: 40}) {
+		t.Errorf("Expected 240 yen of tax split by rate, got %d %+v", found.TaxAmount, found.TaxLines)
+	}
+	if found.Items[1].TaxClass != entity.TaxClassReduced || found.Items[1].TaxRate != 8 {
+		t.Errorf("Expected the second line to be reduced rated, got %+v", found.Items[1])
+	}
+	if found.ShippingFee != 800 || len(found.ShippingCharges) != 2 || found.ShippingCharges[0].RuleID != "R1" {
+		t.Errorf("Expected two shipping charges totalling 800, got %d %+v", found.ShippingFee, found.ShippingCharges)
+	}
+	if found.ShippingAddress == nil || *found.ShippingAddress != *order.ShippingAddress {
+		t.Errorf("Expected the shipping address to round-trip, got %+v", found.ShippingAddress)
+	}
+	if !found.CreatedAt.Equal(order.CreatedAt) {
+		t.Errorf("Expected created_at %v, got %v", order.CreatedAt, found.CreatedAt)
+	}
+	if found.Status != entity.OrderStatusShipped || found.TrackingNumber != "TRK-001" {
+		t.Errorf("Expected shipped order with tracking TRK-001, got %s with %q", found.Status, found.TrackingNumber)
+	}
+	if len(found.StatusHistory) != 4 || found.StatusHistory[3].From != entity.OrderStatusPicking || found.StatusHistory[3].Note != "handed to carrier" {
+		t.Errorf("Expected status history to round-trip, got %+v", found.StatusHistory)
+	}
+}
+
+func TestOrderRepository_Backorders(t *testing.T) {
+	ctx := context.Background()
+	repo := NewOrderRepository(openTestDB(t))
+
+	waiting := newTestOrder(t)
+	expectedAt := time.Date(2030, 4, 1, 0, 0, 0, 0, time.UTC)
+	if err := waiting.BackorderProduct(waiting.Items[0].ProductID, 1, &expectedAt); err != nil {
+		t.Fatalf("BackorderProduct failed: %v", err)
+	}
+	if err := repo.Create(ctx, waiting); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	found, err := repo.FindByID(ctx, waiting.ID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	item := found.Items[0]
+	if item.Status != entity.OrderItemBackordered || item.Backordered != 1 || item.ExpectedAt == nil || !item.ExpectedAt.Equal(expectedAt) {
+		t.Errorf("Expected the backorder to round-trip, got %+v", item)
+	}
+	if found.Items[1].Status != entity.OrderItemInStock || found.Items[1].ExpectedAt != nil {
+		t.Errorf("Expected the other line in stock, got %+v", found.Items[1])
+	}
+
+	orders, total, err := repo.Search(ctx, repository.OrderFilter{BackorderedProductID: item.ProductID})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if total != 1 || orders[0].ID != waiting.ID {
+		t.Errorf("Expected only the waiting order, got %d orders", total)
+	}
+	if _, total, _ := repo.Search(ctx, repository.OrderFilter{BackorderedProductID: found.Items[1].ProductID}); total != 0 {
+		t.Errorf("Expected no order waiting for a product in stock, got %d", total)
+	}
+}
+
+func TestOrderRepository_Search(t *testing.T) {
+	ctx := context.Background()
+	repo := NewOrderRepository(openTestDB(t))
+
+	// Orders are created a day apart in a non-UTC zone; odd ones ship from WH-002
+	jst := time.FixedZone("JST", 9*60*60)
+	base := time.Date(2026, 1, 1, 8, 0, 0, 0, jst)
+	for i, price := range []int{1000, 3000, 2000, 500} {
+		order, _ := entity.NewOrder(fmt.Sprintf("ORD-%d", i+1), "USER-001")
+		order.AddItem("P001", "Product 1", 1, price)
+		order.CreatedAt = base.AddDate(0, 0, i)
+		order.Items[0].Allocations = []entity.ItemAllocation{{WarehouseID: fmt.Sprintf("WH-00%d", i%2+1), Quantity: 1}}
+		if err := repo.Create(ctx, order); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		filter        repository.OrderFilter
+		expectedIDs   []string
+		expectedTotal int
+	}{
+		{"no filter sorts by creation", repository.OrderFilter{}, []string{"ORD-1", "ORD-2", "ORD-3", "ORD-4"}, 4},
+		{"date range in another zone", repository.OrderFilter{
+			CreatedFrom:   time.Date(2026, 1, 1, 23, 0, 0, 0, time.UTC),
+			CreatedBefore: time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC),
+		}, []string{"ORD-2", "ORD-3"}, 2},
+		{"warehouse", repository.OrderFilter{WarehouseID: "WH-002"}, []string{"ORD-2", "ORD-4"}, 2},
+		{"sort by price descending", repository.OrderFilter{SortBy: repository.OrderSortByTotalPrice, Descending: true}, []string{"ORD-2", "ORD-3", "ORD-1", "ORD-4"}, 4},
+		{"page", repository.OrderFilter{Offset: 1, Limit: 2}, []string{"ORD-2", "ORD-3"}, 4},
+		{"offset without limit", repository.OrderFilter{Offset: 3}, []string{"ORD-4"}, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, total, err := repo.Search(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if total != tt.expectedTotal {
+				t.Errorf("Expected total %d, got %d", tt.expectedTotal, total)
+			}
+			if len(found) != len(tt.expectedIDs) {
+				t.Fatalf("Expected %d orders, got %d", len(tt.expectedIDs), len(found))
+			}
+			for i, id := range tt.expectedIDs {
+				if found[i].ID != id {
+					t.Errorf("Expected order %d to be %s, got %s", i, id, found[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestStockRepository_TransactionRollback(t *testing.T) {
+	ctx := context.Background()
+	repo := NewStockRepository(openTestDB(t))
+
+	stock, _ := entity.NewStock("STK-001", "P001", "WH-001", 10)
+	if err := repo.Create(ctx, stock); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	tx, err := repo.BeginTransaction(ctx)
+	if err != nil {
+		t.Fatalf("BeginTransaction failed: %v", err)
+	}
+	txStock, _ := tx.GetStockRepository().FindByID(ctx, "STK-001")
+	txStock.Reduce(4)
+	if err := tx.GetStockRepository().Update(ctx, txStock); err != nil {
+		t.Fatalf("Update in transaction failed: %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+
+	found, _ := repo.FindByID(ctx, "STK-001")
+	if found.Quantity != 10 {
+		t.Errorf("Expected quantity 10 after rollback, got %d", found.Quantity)
+	}
+}
+
+func TestStockMovementRepository_AppendInTransaction(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	stockRepo := NewStockRepository(db)
+	ledger := NewStockMovementRepository(db)
+
+	stock, _ := entity.NewStock("STK-001", "P001", "WH-001", 10)
+	stockRepo.Create(ctx, stock)
+	opening, _ := entity.NewStockMovement("MOV-001", stock, entity.StockMovementRestock, 10, "", "ADMIN-001", "")
+	if err := ledger.Append(ctx, opening); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	// A rolled back transaction leaves no movement behind
+	for _, commit := range []bool{false, true} {
+		tx, err := stockRepo.BeginTransaction(ctx)
+		if err != nil {
+			t.Fatalf("BeginTransaction failed: %v", err)
+		}
+		stock.Reduce(3)
+		tx.GetStockRepository().Update(ctx, stock)
+		sale, _ := entity.NewStockMovement(fmt.Sprintf("MOV-SALE-%v", commit), stock, entity.StockMovementSale, -3, "ORD-001", "USER-001", "")
+		if err := tx.GetStockMovementRepository().Append(ctx, sale); err != nil {
+			t.Fatalf("Append in transaction failed: %v", err)
+		}
+		if commit {
+			err = tx.Commit()
+		} else {
+			err = tx.Rollback()
+			stock.Quantity += 3
+		}
+		if err != nil {
+			t.Fatalf("Ending transaction failed: %v", err)
+		}
+	}
+
+	all, err := ledger.Find(ctx, repository.StockMovementFilter{ProductID: "P001", WarehouseID: "WH-001"})
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if len(all) != 2 || all[0].ID != "MOV-001" || all[1].ID != "MOV-SALE-true" {
+		t.Fatalf("Expected the opening and committed sale in order, got %+v", all)
+	}
+	if sale := all[1]; sale.Type != entity.StockMovementSale || sale.Quantity != -3 || sale.QuantityAfter != 7 || sale.OrderID != "ORD-001" || sale.UserID != "USER-001" {
+		t.Errorf("Expected the sale to round-trip, got %+v", sale)
+	}
+	if byOrder, _ := ledger.Find(ctx, repository.StockMovementFilter{OrderID: "ORD-001"}); len(byOrder) != 1 {
+		t.Errorf("Expected 1 movement for ORD-001, got %d", len(byOrder))
+	}
+}
+
+func TestStockTransferRepository_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	repo := NewStockTransferRepository(openTestDB(t))
+
+	transfer, _ := entity.NewStockTransfer("TRF-001", "P001", "WH-001", "WH-002", 3, "ADMIN-001", "rebalance")
+	if err := repo.Create(ctx, transfer); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	transfer.Ship()
+	if err := repo.Update(ctx, transfer); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	found, err := repo.FindByID(ctx, "TRF-001")
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if found.Status != entity.StockTransferInTransit || found.ShippedAt == nil || found.ReceivedAt != nil ||
+		found.Quantity != 3 || found.ToWarehouseID != "WH-002" || found.Note != "rebalance" {
+		t.Errorf("Expected the shipped transfer to round-trip, got %+v", found)
+	}
+	if all, _ := repo.FindAll(ctx); len(all) != 1 {
+		t.Errorf("Expected 1 transfer, got %d", len(all))
+	}
+	if _, err := repo.FindByID(ctx, "TRF-404"); err == nil {
+		t.Error("Expected an unknown transfer to be reported")
+	}
+}
+
+// newTestOrder builds a pending order with a standard rated and a reduced rated line
+func newTestOrder(t *testing.T) *entity.Order {
+	t.Helper()
+	order, err := entity.NewOrder("ORD-001", "USER-001")
+	if err != nil {
+		t.Fatalf("Failed to create order: %v", err)
+	}
+	order.AddItem("P001", "Product 1", 2, 1000)
+	order.AddItemWithTaxClass("P002", "Product 2", 1, 500, entity.TaxClassReduced)
+	return order
+}
+
+func TestUnitOfWork_RollbackSpansRepositories(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	stockRepo := NewStockRepository(db)
+	orderRepo := NewOrderRepository(db)
+
+	stock, _ := entity.NewStock("STK-001", "P001", "WH-001", 10)
+	stockRepo.Create(ctx, stock)
+	order := newTestOrder(t)
+	orderRepo.Create(ctx, order)
+
+	tx, err := NewUnitOfWork(db).Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+	stock.Reduce(2)
+	if err := tx.GetStockRepository().Update(ctx, stock); err != nil {
+		t.Fatalf("Stock update failed: %v", err)
+	}
+	order.MarkPaid()
+	if err := tx.GetOrderRepository().Update(ctx, order); err != nil {
+		t.Fatalf("Order update failed: %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+
+	foundStock, _ := stockRepo.FindByID(ctx, "STK-001")
+	foundOrder, _ := orderRepo.FindByID(ctx, order.ID)
+	if foundStock.Quantity != 10 || foundOrder.Status != entity.OrderStatusPending {
+		t.Errorf("Expected stock 10 and pending order after rollback, got %d and %s", foundStock.Quantity, foundOrder.Status)
+	}
+}
+
+func TestStockReservationRepository_FindExpired(t *testing.T) {
+	ctx := context.Background()
+	repo := NewStockReservationRepository(openTestDB(t))
+
+	now := time.Now()
+	soon, _ := entity.NewStockReservation("RSV-1", "ORD-001", "P001", "WH-001", 2, now.Add(time.Minute))
+	past, _ := entity.NewStockReservation("RSV-2", "ORD-002", "P001", "WH-002", 1, now.Add(-time.Minute))
+	for _, r := range []*entity.StockReservation{soon, past} {
+		if err := repo.Create(ctx, r); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	expired, err := repo.FindExpired(ctx, now)
+	if err != nil {
+		t.Fatalf("FindExpired failed: %v", err)
+	}
+	if len(expired) != 1 || expired[0].ID != "RSV-2" {
+		t.Errorf("Expected only RSV-2 to be expired, got %+v", expired)
+	}
+
+	if err := repo.Delete(ctx, "RSV-1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if remaining, _ := repo.FindByOrderID(ctx, "ORD-001"); len(remaining) != 0 {
+		t.Errorf("Expected RSV-1 to be deleted, got %+v", remaining)
+	}
+}
+
+func TestIdempotencyStore_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	store := NewIdempotencyStore(openTestDB(t))
+	now := time.Now()
+	record := func(at time.Time, hash string) *port.IdempotencyRecord {
+		return &port.IdempotencyRecord{UserID: "USER-001", Key: "key-1", RequestHash: hash, CreatedAt: at, ExpiresAt: at.Add(time.Hour)}
+	}
+
+	if _, claimed, err := store.Begin(ctx, record(now, "h1")); err != nil || !claimed {
+		t.Fatalf("Expected the first request to claim the key, got claimed=%v err=%v", claimed, err)
+	}
+	existing, claimed, err := store.Begin(ctx, record(now, "h1"))
+	if err != nil || claimed || existing.IsCompleted() {
+		t.Fatalf("Expected an in-progress record, got claimed=%v %+v err=%v", claimed, existing, err)
+	}
+
+	if err := store.Complete(ctx, "USER-001", "key-1", 201, "application/json", []byte(`{}`)); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	existing, _, _ = store.Begin(ctx, record(now, "h2"))
+	if existing.StatusCode != 201 || string(existing.Body) != `{}` || existing.RequestHash != "h1" {
+		t.Errorf("Expected the stored response of the first request, got %+v", existing)
+	}
+
+	// Once expired the key can be claimed again
+	if _, claimed, _ := store.Begin(ctx, record(now.Add(2*time.Hour), "h2")); !claimed {
+		t.Error("Expected an expired key to be claimable")
+	}
+	if deleted, _ := store.DeleteExpired(ctx, now.Add(4*time.Hour)); deleted != 1 {
+		t.Errorf("Expected 1 expired key to be deleted, got %d", deleted)
+	}
+}
+
+func TestCartRepository_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	repo := NewCartRepository(openTestDB(t))
+
+	if _, err := repo.FindByUserID(ctx, "USER-001"); err == nil {
+		t.Error("Expected a missing cart to be reported")
+	}
+
+	cart, _ := entity.NewCart("USER-001")
+	cart.AddItem("P002", 1)
+	cart.AddItem("P001", 2)
+	cart.SetCoupon("SAVE10")
+	if err := repo.Save(ctx, cart); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	cart.RemoveItem("P002")
+	cart.AddItem("P003", 4)
+	if err := repo.Save(ctx, cart); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	got, err := repo.FindByUserID(ctx, "USER-001")
+	if err != nil {
+		t.Fatalf("FindByUserID failed: %v", err)
+	}
+	if got.CouponCode != "SAVE10" || len(got.Items) != 2 {
+		t.Fatalf("Expected 2 lines with coupon SAVE10, got %+v", got)
+	}
+	if got.Items[0].ProductID != "P001" || got.Items[0].Quantity != 2 || got.Items[1].ProductID != "P003" {
+		t.Errorf("Expected lines to keep their order, got %+v", got.Items)
+	}
+
+	if err := repo.Delete(ctx, "USER-001"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := repo.FindByUserID(ctx, "USER-001"); err == nil {
+		t.Error("Expected the cart to be deleted")
+	}
+}
+
+func TestShippingRuleRepository_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	repo := NewShippingRuleRepository(openTestDB(t))
+	now := time.Now()
+	end := now.Add(24 * time.Hour)
+
+	campaign := &entity.ShippingRule{
+		ID: "R2", Name: "Campaign", Priority: 1, Active: true, Prefectures: []string{"北海道", "沖縄県"},
+		Fee: 800, FreeShippingThreshold: 3000, StartsAt: &now, EndsAt: &end, CreatedAt: now, UpdatedAt: now,
+	}
+	standard := &entity.ShippingRule{ID: "R1", Name: "Standard", Priority: 1, Active: true, Fee: 500, CreatedAt: now, UpdatedAt: now}
+	for _, rule := range []*entity.ShippingRule{campaign, standard} {
+		if err := repo.Create(ctx, rule); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	rules, err := repo.FindAll(ctx)
+	if err != nil {
+		t.Fatalf("FindAll failed: %v", err)
+	}
+	if len(rules) != 2 || rules[0].ID != "R1" {
+		t.Fatalf("Expected rules ordered by priority then ID, got %+v", rules)
+	}
+	got := rules[1]
+	if len(got.Prefectures) != 2 || got.Prefectures[1] != "沖縄県" || got.WarehouseIDs != nil {
+		t.Errorf("Expected the condition lists to round-trip, got %+v", got)
+	}
+	if got.StartsAt == nil || !got.StartsAt.Equal(now) || !got.EndsAt.Equal(end) || rules[0].StartsAt != nil {
+		t.Errorf("Expected the campaign period to round-trip, got %v - %v", got.StartsAt, got.EndsAt)
+	}
+
+	standard.Active = false
+	if err := repo.Update(ctx, standard); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if found, _ := repo.FindByID(ctx, "R1"); found.Active {
+		t.Error("Expected the rule to be deactivated")
+	}
+	if err := repo.Delete(ctx, "R1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := repo.FindByID(ctx, "R1"); err == nil {
+		t.Error("Expected the rule to be deleted")
+	}
+}
+
+func TestAddressRepository_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	repo := NewAddressRepository(openTestDB(t))
+
+	fields := entity.AddressFields{
+		Recipient: "山田 太郎", PostalCode: "1000001", Prefecture: "東京都", City: "千代田区", Line1: "千代田1-1", Phone: "0312345678",
+	}
+	home, _ := entity.NewAddress("ADDR-1", "USER-001", fields)
+	office, _ := entity.NewAddress("ADDR-2", "USER-001", fields)
+	office.CreatedAt = home.CreatedAt.Add(time.Second)
+	other, _ := entity.NewAddress("ADDR-3", "USER-002", fields)
+	for _, address := range []*entity.Address{office, home, other} {
+		if err := repo.Create(ctx, address); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	if err := repo.SetDefault(ctx, "USER-001", "ADDR-1"); err != nil {
+		t.Fatalf("SetDefault failed: %v", err)
+	}
+	if err := repo.SetDefault(ctx, "USER-001", "ADDR-2"); err != nil {
+		t.Fatalf("SetDefault failed: %v", err)
+	}
+	if err := repo.SetDefault(ctx, "USER-001", "ADDR-3"); err == nil {
+		t.Error("Expected another user's address to be rejected")
+	}
+
+	home.Line2 = "101号室"
+	home.IsDefault = true
+	if err := repo.Update(ctx, home); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	addresses, err := repo.FindByUserID(ctx, "USER-001")
+	if err != nil {
+		t.Fatalf("FindByUserID failed: %v", err)
+	}
+	if len(addresses) != 2 || addresses[0].ID != "ADDR-1" || addresses[0].PostalCode != "100-0001" {
+		t.Fatalf("Expected the user's two addresses oldest first, got %+v", addresses)
+	}
+	if addresses[0].IsDefault || !addresses[1].IsDefault || addresses[0].Line2 != "101号室" {
+		t.Errorf("Expected only ADDR-2 to be the default and the update to be kept, got %+v", addresses)
+	}
+
+	if err := repo.Delete(ctx, "ADDR-1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := repo.FindByID(ctx, "ADDR-1"); err == nil {
+		t.Error("Expected the address to be deleted")
+	}
+}
+
+func TestReorderPointRepository_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	repo := NewReorderPointRepository(openTestDB(t))
+
+	productDefault, _ := entity.NewReorderPoint("P001", "", 5)
+	warehouse, _ := entity.NewReorderPoint("P001", "WH-001", 2)
+	for _, reorderPoint := range []*entity.ReorderPoint{warehouse, productDefault} {
+		if err := repo.Save(ctx, reorderPoint); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	warehouse.Threshold = 3
+	if err := repo.Save(ctx, warehouse); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	reorderPoints, err := repo.FindAll(ctx)
+	if err != nil {
+		t.Fatalf("FindAll failed: %v", err)
+	}
+	if len(reorderPoints) != 2 || reorderPoints[0].WarehouseID != "" || reorderPoints[1].Threshold != 3 {
+		t.Fatalf("Expected the default then the updated warehouse reorder point, got %+v", reorderPoints)
+	}
+
+	if err := repo.Delete(ctx, "P001", "WH-001"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := repo.Delete(ctx, "P001", "WH-001"); err == nil {
+		t.Error("Expected deleting a missing reorder point to fail")
+	}
+}
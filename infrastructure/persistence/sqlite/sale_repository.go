@@ -0,0 +1,110 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// SaleRepository is a SQLite implementation of repository.SaleRepository
+type SaleRepository struct {
+	db queryer
+}
+
+// NewSaleRepository creates a new SQLite sale repository
+func NewSaleRepository(db *sql.DB) repository.SaleRepository {
+	return &SaleRepository{db: db}
+}
+
+const saleColumns = `id, product_id, price, starts_at, ends_at, created_by, created_at, cancelled_at`
+
+// Create creates a new sale
+func (r *SaleRepository) Create(ctx context.Context, sale *entity.Sale) error {
+	if exists, err := rowExists(ctx, r.db, `SELECT 1 FROM sales WHERE id = ?`, sale.ID); err != nil {
+		return err
+	} else if exists {
+		return errors.New("sale already exists")
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO sales (`+saleColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		sale.ID, sale.ProductID, sale.Price, sale.StartsAt, sale.EndsAt, sale.CreatedBy, sale.CreatedAt, nullTime(sale.CancelledAt))
+	return err
+}
+
+// Update updates a sale
+func (r *SaleRepository) Update(ctx context.Context, sale *entity.Sale) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE sales SET price = ?, starts_at = ?, ends_at = ?, cancelled_at = ? WHERE id = ?`,
+		sale.Price, sale.StartsAt, sale.EndsAt, nullTime(sale.CancelledAt), sale.ID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, "sale not found")
+}
+
+// FindByID finds a sale by its ID
+func (r *SaleRepository) FindByID(ctx context.Context, id string) (*entity.Sale, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+saleColumns+` FROM sales WHERE id = ?`, id)
+	sale, err := scanSale(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("sale not found")
+	}
+	return sale, err
+}
+
+// FindByProduct returns every sale of a product ordered by start
+func (r *SaleRepository) FindByProduct(ctx context.Context, productID string) ([]*entity.Sale, error) {
+	return r.query(ctx, `SELECT `+saleColumns+` FROM sales WHERE product_id = ? ORDER BY starts_at, id`, productID)
+}
+
+// FindEffective returns the sales running at the given time. Times are compared in Go since
+// SQLite compares stored timestamps as text.
+func (r *SaleRepository) FindEffective(ctx context.Context, at time.Time) ([]*entity.Sale, error) {
+	sales, err := r.query(ctx, `SELECT `+saleColumns+` FROM sales WHERE cancelled_at IS NULL ORDER BY starts_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	var result []*entity.Sale
+	for _, sale := range sales {
+		if sale.IsEffective(at) {
+			result = append(result, sale)
+		}
+	}
+	return result, nil
+}
+
+func (r *SaleRepository) query(ctx context.Context, query string, args ...interface{}) ([]*entity.Sale, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*entity.Sale
+	for rows.Next() {
+		sale, err := scanSale(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, sale)
+	}
+	return result, rows.Err()
+}
+
+func scanSale(row rowScanner) (*entity.Sale, error) {
+	var sale entity.Sale
+	var cancelledAt sql.NullTime
+	err := row.Scan(&sale.ID, &sale.ProductID, &sale.Price, &sale.StartsAt, &sale.EndsAt, &sale.CreatedBy, &sale.CreatedAt, &cancelledAt)
+	if err != nil {
+		return nil, err
+	}
+	if cancelledAt.Valid {
+		sale.CancelledAt = &cancelledAt.Time
+	}
+	return &sale, nil
+}
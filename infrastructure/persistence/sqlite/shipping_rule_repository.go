@@ -0,0 +1,149 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// ShippingRuleRepository is a SQLite implementation of repository.ShippingRuleRepository
+type ShippingRuleRepository struct {
+	db queryer
+}
+
+// NewShippingRuleRepository creates a new SQLite shipping rule repository
+func NewShippingRuleRepository(db *sql.DB) repository.ShippingRuleRepository {
+	return &ShippingRuleRepository{db: db}
+}
+
+const shippingRuleColumns = `id, name, priority, active, prefectures, warehouse_ids, min_items, max_items, min_weight, max_weight,
+	fee, free_shipping_threshold, starts_at, ends_at, created_at, updated_at`
+
+// Create creates a new shipping rule
+func (r *ShippingRuleRepository) Create(ctx context.Context, rule *entity.ShippingRule) error {
+	if exists, err := rowExists(ctx, r.db, `SELECT 1 FROM shipping_rules WHERE id = ?`, rule.ID); err != nil {
+		return err
+	} else if exists {
+		return errors.New("shipping rule already exists")
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO shipping_rules (`+shippingRuleColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rule.ID, rule.Name, rule.Priority, rule.Active, joinList(rule.Prefectures), joinList(rule.WarehouseIDs),
+		rule.MinItems, rule.MaxItems, rule.MinWeight, rule.MaxWeight, rule.Fee, rule.FreeShippingThreshold,
+		nullTime(rule.StartsAt), nullTime(rule.EndsAt), rule.CreatedAt, rule.UpdatedAt)
+	return err
+}
+
+// FindByID finds a shipping rule by its ID
+func (r *ShippingRuleRepository) FindByID(ctx context.Context, id string) (*entity.ShippingRule, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+shippingRuleColumns+` FROM shipping_rules WHERE id = ?`, id)
+	rule, err := scanShippingRule(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("shipping rule not found")
+	}
+	return rule, err
+}
+
+// FindAll returns all shipping rules ordered by priority, then ID
+func (r *ShippingRuleRepository) FindAll(ctx context.Context) ([]*entity.ShippingRule, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+shippingRuleColumns+` FROM shipping_rules ORDER BY priority, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*entity.ShippingRule
+	for rows.Next() {
+		rule, err := scanShippingRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, rule)
+	}
+	return result, rows.Err()
+}
+
+// Update updates a shipping rule
+func (r *ShippingRuleRepository) Update(ctx context.Context, rule *entity.ShippingRule) error {
+	result, err := r.db.ExecContext(ctx, `
+UPDATE shipping_rules SET name = ?, priority = ?, active = ?, prefectures = ?, warehouse_ids = ?,
+	min_items = ?, max_items = ?, min_weight = ?, max_weight = ?, fee = ?, free_shipping_threshold = ?,
+	starts_at = ?, ends_at = ?, updated_at = ?
+WHERE id = ?`,
+		rule.Name, rule.Priority, rule.Active, joinList(rule.Prefectures), joinList(rule.WarehouseIDs),
+		rule.MinItems, rule.MaxItems, rule.MinWeight, rule.MaxWeight, rule.Fee, rule.FreeShippingThreshold,
+		nullTime(rule.StartsAt), nullTime(rule.EndsAt), rule.UpdatedAt, rule.ID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, "shipping rule not found")
+}
+
+// Delete deletes a shipping rule
+func (r *ShippingRuleRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM shipping_rules WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, "shipping rule not found")
+}
+
+func scanShippingRule(row rowScanner) (*entity.ShippingRule, error) {
+	var rule entity.ShippingRule
+	var prefectures, warehouseIDs string
+	var startsAt, endsAt sql.NullTime
+	err := row.Scan(&rule.ID, &rule.Name, &rule.Priority, &rule.Active, &prefectures, &warehouseIDs,
+		&rule.MinItems, &rule.MaxItems, &rule.MinWeight, &rule.MaxWeight, &rule.Fee, &rule.FreeShippingThreshold,
+		&startsAt, &endsAt, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if rule.Prefectures, err = splitList(prefectures); err != nil {
+		return nil, err
+	}
+	if rule.WarehouseIDs, err = splitList(warehouseIDs); err != nil {
+		return nil, err
+	}
+	if startsAt.Valid {
+		rule.StartsAt = &startsAt.Time
+	}
+	if endsAt.Valid {
+		rule.EndsAt = &endsAt.Time
+	}
+	return &rule, nil
+}
+
+// joinList stores a list of names or IDs in one column as a JSON array
+func joinList(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	encoded, _ := json.Marshal(values)
+	return string(encoded)
+}
+
+func splitList(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var values []string
+	if err := json.Unmarshal([]byte(value), &values); err != nil {
+		return nil, fmt.Errorf("invalid list column %q: %w", value, err)
+	}
+	return values, nil
+}
+
+// nullTime stores a nil time as NULL
+func nullTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
@@ -0,0 +1,71 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// StockMovementRepository is a SQLite implementation of repository.StockMovementRepository
+type StockMovementRepository struct {
+	db queryer
+}
+
+// NewStockMovementRepository creates a new SQLite stock movement ledger
+func NewStockMovementRepository(db *sql.DB) repository.StockMovementRepository {
+	return &StockMovementRepository{db: db}
+}
+
+const stockMovementColumns = `id, product_id, warehouse_id, type, quantity, quantity_after, order_id, user_id, note, created_at`
+
+func (r *StockMovementRepository) Append(ctx context.Context, movement *entity.StockMovement) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO stock_movements (`+stockMovementColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		movement.ID, movement.ProductID, movement.WarehouseID, string(movement.Type), movement.Quantity,
+		movement.QuantityAfter, movement.OrderID, movement.UserID, movement.Note, movement.CreatedAt)
+	return err
+}
+
+func (r *StockMovementRepository) Find(ctx context.Context, filter repository.StockMovementFilter) ([]*entity.StockMovement, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.ProductID != "" {
+		conditions = append(conditions, "product_id = ?")
+		args = append(args, filter.ProductID)
+	}
+	if filter.WarehouseID != "" {
+		conditions = append(conditions, "warehouse_id = ?")
+		args = append(args, filter.WarehouseID)
+	}
+	if filter.OrderID != "" {
+		conditions = append(conditions, "order_id = ?")
+		args = append(args, filter.OrderID)
+	}
+
+	query := `SELECT ` + stockMovementColumns + ` FROM stock_movements`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	rows, err := r.db.QueryContext(ctx, query+` ORDER BY seq`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*entity.StockMovement
+	for rows.Next() {
+		var movement entity.StockMovement
+		var movementType string
+		err := rows.Scan(&movement.ID, &movement.ProductID, &movement.WarehouseID, &movementType, &movement.Quantity,
+			&movement.QuantityAfter, &movement.OrderID, &movement.UserID, &movement.Note, &movement.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		movement.Type = entity.StockMovementType(movementType)
+		result = append(result, &movement)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,150 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// StockRepository is a SQLite implementation of repository.StockRepository
+type StockRepository struct {
+	db   *sql.DB
+	conn queryer
+}
+
+// NewStockRepository creates a new SQLite stock repository
+func NewStockRepository(db *sql.DB) repository.StockRepository {
+	return &StockRepository{db: db, conn: db}
+}
+
+const stockColumns = `id, product_id, warehouse_id, quantity, reserved, created_at, updated_at`
+
+func (r *StockRepository) Create(ctx context.Context, stock *entity.Stock) error {
+	if exists, err := rowExists(ctx, r.conn, `SELECT 1 FROM stocks WHERE id = ?`, stock.ID); err != nil {
+		return err
+	} else if exists {
+		return errors.New("stock already exists")
+	}
+
+	_, err := r.conn.ExecContext(ctx,
+		`INSERT INTO stocks (`+stockColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		stock.ID, stock.ProductID, stock.WarehouseID, stock.Quantity, stock.Reserved, stock.CreatedAt, stock.UpdatedAt)
+	return err
+}
+
+func (r *StockRepository) Update(ctx context.Context, stock *entity.Stock) error {
+	result, err := r.conn.ExecContext(ctx,
+		`UPDATE stocks SET product_id = ?, warehouse_id = ?, quantity = ?, reserved = ?, updated_at = ? WHERE id = ?`,
+		stock.ProductID, stock.WarehouseID, stock.Quantity, stock.Reserved, stock.UpdatedAt, stock.ID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, "stock not found")
+}
+
+func (r *StockRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.conn.ExecContext(ctx, `DELETE FROM stocks WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, "stock not found")
+}
+
+func (r *StockRepository) FindByID(ctx context.Context, id string) (*entity.Stock, error) {
+	row := r.conn.QueryRowContext(ctx, `SELECT `+stockColumns+` FROM stocks WHERE id = ?`, id)
+	stock, err := scanStock(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("stock not found")
+	}
+	return stock, err
+}
+
+func (r *StockRepository) FindByProductID(ctx context.Context, productID string) ([]*entity.Stock, error) {
+	return r.findMany(ctx, `SELECT `+stockColumns+` FROM stocks WHERE product_id = ? ORDER BY warehouse_id`, productID)
+}
+
+func (r *StockRepository) FindByWarehouseID(ctx context.Context, warehouseID string) ([]*entity.Stock, error) {
+	return r.findMany(ctx, `SELECT `+stockColumns+` FROM stocks WHERE warehouse_id = ? ORDER BY product_id`, warehouseID)
+}
+
+func (r *StockRepository) FindByProductAndWarehouse(ctx context.Context, productID, warehouseID string) (*entity.Stock, error) {
+	row := r.conn.QueryRowContext(ctx,
+		`SELECT `+stockColumns+` FROM stocks WHERE product_id = ? AND warehouse_id = ?`, productID, warehouseID)
+	stock, err := scanStock(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("stock not found for product %s in warehouse %s", productID, warehouseID)
+	}
+	return stock, err
+}
+
+// BeginTransaction begins a new transaction
+func (r *StockRepository) BeginTransaction(ctx context.Context) (repository.StockTransaction, error) {
+	if r.db == nil {
+		return nil, errors.New("nested transactions are not supported")
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return &stockTransaction{
+		tx:   tx,
+		repo: &StockRepository{conn: tx},
+	}, nil
+}
+
+func (r *StockRepository) findMany(ctx context.Context, query string, args ...interface{}) ([]*entity.Stock, error) {
+	rows, err := r.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*entity.Stock
+	for rows.Next() {
+		stock, err := scanStock(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, stock)
+	}
+	return result, rows.Err()
+}
+
+// stockTransaction is a repository.StockTransaction backed by a SQL transaction
+type stockTransaction struct {
+	tx   *sql.Tx
+	repo *StockRepository
+}
+
+func (t *stockTransaction) GetStockRepository() repository.StockRepository {
+	return t.repo
+}
+
+func (t *stockTransaction) GetStockMovementRepository() repository.StockMovementRepository {
+	return &StockMovementRepository{db: t.tx}
+}
+
+func (t *stockTransaction) GetStockTransferRepository() repository.StockTransferRepository {
+	return &StockTransferRepository{db: t.tx}
+}
+
+func (t *stockTransaction) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *stockTransaction) Rollback() error {
+	return t.tx.Rollback()
+}
+
+func scanStock(row rowScanner) (*entity.Stock, error) {
+	var stock entity.Stock
+	err := row.Scan(&stock.ID, &stock.ProductID, &stock.WarehouseID, &stock.Quantity, &stock.Reserved, &stock.CreatedAt, &stock.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &stock, nil
+}
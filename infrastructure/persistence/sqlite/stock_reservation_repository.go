@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// StockReservationRepository is a SQLite implementation of repository.StockReservationRepository
+type StockReservationRepository struct {
+	db queryer
+}
+
+// NewStockReservationRepository creates a new SQLite stock reservation repository
+func NewStockReservationRepository(db *sql.DB) repository.StockReservationRepository {
+	return &StockReservationRepository{db: db}
+}
+
+const stockReservationColumns = `id, order_id, product_id, warehouse_id, quantity, expires_at, created_at`
+
+func (r *StockReservationRepository) Create(ctx context.Context, reservation *entity.StockReservation) error {
+	if exists, err := rowExists(ctx, r.db, `SELECT 1 FROM stock_reservations WHERE id = ?`, reservation.ID); err != nil {
+		return err
+	} else if exists {
+		return errors.New("reservation already exists")
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO stock_reservations (`+stockReservationColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		reservation.ID, reservation.OrderID, reservation.ProductID, reservation.WarehouseID,
+		reservation.Quantity, reservation.ExpiresAt.UTC(), reservation.CreatedAt)
+	return err
+}
+
+func (r *StockReservationRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM stock_reservations WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, "reservation not found")
+}
+
+func (r *StockReservationRepository) FindByOrderID(ctx context.Context, orderID string) ([]*entity.StockReservation, error) {
+	return r.findMany(ctx, `SELECT `+stockReservationColumns+` FROM stock_reservations WHERE order_id = ? ORDER BY id`, orderID)
+}
+
+// FindExpired compares expiry times as stored text, so both sides are kept in UTC
+func (r *StockReservationRepository) FindExpired(ctx context.Context, now time.Time) ([]*entity.StockReservation, error) {
+	return r.findMany(ctx, `SELECT `+stockReservationColumns+` FROM stock_reservations WHERE expires_at <= ? ORDER BY expires_at, id`, now.UTC())
+}
+
+func (r *StockReservationRepository) findMany(ctx context.Context, query string, args ...interface{}) ([]*entity.StockReservation, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*entity.StockReservation
+	for rows.Next() {
+		reservation, err := scanStockReservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, reservation)
+	}
+	return result, rows.Err()
+}
+
+func scanStockReservation(row rowScanner) (*entity.StockReservation, error) {
+	var reservation entity.StockReservation
+	err := row.Scan(&reservation.ID, &reservation.OrderID, &reservation.ProductID, &reservation.WarehouseID,
+		&reservation.Quantity, &reservation.ExpiresAt, &reservation.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
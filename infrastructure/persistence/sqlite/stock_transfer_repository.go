@@ -0,0 +1,93 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// StockTransferRepository is a SQLite implementation of repository.StockTransferRepository
+type StockTransferRepository struct {
+	db queryer
+}
+
+// NewStockTransferRepository creates a new SQLite stock transfer repository
+func NewStockTransferRepository(db *sql.DB) repository.StockTransferRepository {
+	return &StockTransferRepository{db: db}
+}
+
+const stockTransferColumns = `id, product_id, from_warehouse_id, to_warehouse_id, quantity, status, requested_by, note, created_at, updated_at, shipped_at, received_at`
+
+func (r *StockTransferRepository) Create(ctx context.Context, transfer *entity.StockTransfer) error {
+	if exists, err := rowExists(ctx, r.db, `SELECT 1 FROM stock_transfers WHERE id = ?`, transfer.ID); err != nil {
+		return err
+	} else if exists {
+		return errors.New("stock transfer already exists")
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO stock_transfers (`+stockTransferColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		transfer.ID, transfer.ProductID, transfer.FromWarehouseID, transfer.ToWarehouseID, transfer.Quantity,
+		string(transfer.Status), transfer.RequestedBy, transfer.Note, transfer.CreatedAt, transfer.UpdatedAt,
+		nullTime(transfer.ShippedAt), nullTime(transfer.ReceivedAt))
+	return err
+}
+
+func (r *StockTransferRepository) Update(ctx context.Context, transfer *entity.StockTransfer) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE stock_transfers SET status = ?, note = ?, updated_at = ?, shipped_at = ?, received_at = ? WHERE id = ?`,
+		string(transfer.Status), transfer.Note, transfer.UpdatedAt, nullTime(transfer.ShippedAt), nullTime(transfer.ReceivedAt), transfer.ID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, "stock transfer not found")
+}
+
+func (r *StockTransferRepository) FindByID(ctx context.Context, id string) (*entity.StockTransfer, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+stockTransferColumns+` FROM stock_transfers WHERE id = ?`, id)
+	transfer, err := scanStockTransfer(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("stock transfer not found")
+	}
+	return transfer, err
+}
+
+func (r *StockTransferRepository) FindAll(ctx context.Context) ([]*entity.StockTransfer, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+stockTransferColumns+` FROM stock_transfers ORDER BY created_at DESC, id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*entity.StockTransfer
+	for rows.Next() {
+		transfer, err := scanStockTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, transfer)
+	}
+	return result, rows.Err()
+}
+
+func scanStockTransfer(row rowScanner) (*entity.StockTransfer, error) {
+	var transfer entity.StockTransfer
+	var status string
+	var shippedAt, receivedAt sql.NullTime
+	err := row.Scan(&transfer.ID, &transfer.ProductID, &transfer.FromWarehouseID, &transfer.ToWarehouseID, &transfer.Quantity,
+		&status, &transfer.RequestedBy, &transfer.Note, &transfer.CreatedAt, &transfer.UpdatedAt, &shippedAt, &receivedAt)
+	if err != nil {
+		return nil, err
+	}
+	transfer.Status = entity.StockTransferStatus(status)
+	if shippedAt.Valid {
+		transfer.ShippedAt = &shippedAt.Time
+	}
+	if receivedAt.Valid {
+		transfer.ReceivedAt = &receivedAt.Time
+	}
+	return &transfer, nil
+}
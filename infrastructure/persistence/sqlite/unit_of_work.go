@@ -0,0 +1,61 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// UnitOfWork is a SQLite implementation of repository.UnitOfWork backed by a single SQL transaction
+type UnitOfWork struct {
+	db *sql.DB
+}
+
+// NewUnitOfWork creates a new SQLite unit of work
+func NewUnitOfWork(db *sql.DB) repository.UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// Begin starts a new transaction
+func (u *UnitOfWork) Begin(ctx context.Context) (repository.UnitOfWorkTransaction, error) {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return &unitOfWorkTransaction{tx: tx}, nil
+}
+
+// unitOfWorkTransaction hands out repositories that all run on the same *sql.Tx
+type unitOfWorkTransaction struct {
+	tx *sql.Tx
+}
+
+func (t *unitOfWorkTransaction) GetStockRepository() repository.StockRepository {
+	return &StockRepository{conn: t.tx}
+}
+
+func (t *unitOfWorkTransaction) GetStockMovementRepository() repository.StockMovementRepository {
+	return &StockMovementRepository{db: t.tx}
+}
+
+func (t *unitOfWorkTransaction) GetStockReservationRepository() repository.StockReservationRepository {
+	return &StockReservationRepository{db: t.tx}
+}
+
+func (t *unitOfWorkTransaction) GetCouponRepository() repository.CouponRepository {
+	return &CouponRepository{db: t.tx}
+}
+
+func (t *unitOfWorkTransaction) GetOrderRepository() repository.OrderRepository {
+	return &OrderRepository{conn: t.tx}
+}
+
+func (t *unitOfWorkTransaction) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *unitOfWorkTransaction) Rollback() error {
+	return t.tx.Rollback()
+}
@@ -0,0 +1,88 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// UserRepository is a SQLite implementation of repository.UserRepository
+type UserRepository struct {
+	db queryer
+}
+
+// NewUserRepository creates a new SQLite user repository
+func NewUserRepository(db *sql.DB) repository.UserRepository {
+	return &UserRepository{db: db}
+}
+
+const userColumns = `id, username, password_hash, is_admin, created_at, updated_at`
+
+// Create creates a new user
+func (r *UserRepository) Create(ctx context.Context, user *entity.User) error {
+	if exists, err := rowExists(ctx, r.db, `SELECT 1 FROM users WHERE id = ?`, user.ID); err != nil {
+		return err
+	} else if exists {
+		return errors.New("user already exists")
+	}
+	if exists, err := rowExists(ctx, r.db, `SELECT 1 FROM users WHERE username = ?`, user.Username); err != nil {
+		return err
+	} else if exists {
+		return errors.New("username already taken")
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO users (`+userColumns+`) VALUES (?, ?, ?, ?, ?, ?)`,
+		user.ID, user.Username, user.PasswordHash, user.IsAdmin, user.CreatedAt, user.UpdatedAt)
+	return err
+}
+
+// FindByID finds a user by its ID
+func (r *UserRepository) FindByID(ctx context.Context, id string) (*entity.User, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = ?`, id)
+	user, err := scanUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("user not found")
+	}
+	return user, err
+}
+
+// FindByUsername finds a user by username
+func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*entity.User, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE username = ?`, username)
+	user, err := scanUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("user not found")
+	}
+	return user, err
+}
+
+// Update updates a user
+func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
+	if exists, err := rowExists(ctx, r.db,
+		`SELECT 1 FROM users WHERE username = ? AND id <> ?`, user.Username, user.ID); err != nil {
+		return err
+	} else if exists {
+		return errors.New("username already taken")
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE users SET username = ?, password_hash = ?, is_admin = ?, updated_at = ? WHERE id = ?`,
+		user.Username, user.PasswordHash, user.IsAdmin, user.UpdatedAt, user.ID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, "user not found")
+}
+
+func scanUser(row rowScanner) (*entity.User, error) {
+	var user entity.User
+	err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.IsAdmin, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
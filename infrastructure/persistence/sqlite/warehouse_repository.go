@@ -0,0 +1,89 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// WarehouseRepository is a SQLite implementation of repository.WarehouseRepository
+type WarehouseRepository struct {
+	db queryer
+}
+
+// NewWarehouseRepository creates a new SQLite warehouse repository
+func NewWarehouseRepository(db *sql.DB) repository.WarehouseRepository {
+	return &WarehouseRepository{db: db}
+}
+
+const warehouseColumns = `id, name, location, created_at, updated_at`
+
+func (r *WarehouseRepository) Create(ctx context.Context, warehouse *entity.Warehouse) error {
+	if exists, err := rowExists(ctx, r.db, `SELECT 1 FROM warehouses WHERE id = ?`, warehouse.ID); err != nil {
+		return err
+	} else if exists {
+		return errors.New("warehouse already exists")
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO warehouses (`+warehouseColumns+`) VALUES (?, ?, ?, ?, ?)`,
+		warehouse.ID, warehouse.Name, warehouse.Location, warehouse.CreatedAt, warehouse.UpdatedAt)
+	return err
+}
+
+func (r *WarehouseRepository) Update(ctx context.Context, warehouse *entity.Warehouse) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE warehouses SET name = ?, location = ?, updated_at = ? WHERE id = ?`,
+		warehouse.Name, warehouse.Location, warehouse.UpdatedAt, warehouse.ID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, "warehouse not found")
+}
+
+func (r *WarehouseRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM warehouses WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, "warehouse not found")
+}
+
+func (r *WarehouseRepository) FindByID(ctx context.Context, id string) (*entity.Warehouse, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+warehouseColumns+` FROM warehouses WHERE id = ?`, id)
+	warehouse, err := scanWarehouse(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("warehouse not found")
+	}
+	return warehouse, err
+}
+
+func (r *WarehouseRepository) FindAll(ctx context.Context) ([]*entity.Warehouse, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+warehouseColumns+` FROM warehouses ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*entity.Warehouse
+	for rows.Next() {
+		warehouse, err := scanWarehouse(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, warehouse)
+	}
+	return result, rows.Err()
+}
+
+func scanWarehouse(row rowScanner) (*entity.Warehouse, error) {
+	var warehouse entity.Warehouse
+	err := row.Scan(&warehouse.ID, &warehouse.Name, &warehouse.Location, &warehouse.CreatedAt, &warehouse.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &warehouse, nil
+}
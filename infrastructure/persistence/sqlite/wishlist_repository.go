@@ -0,0 +1,135 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// WishlistRepository is a SQLite implementation of repository.WishlistRepository
+type WishlistRepository struct {
+	db queryer
+}
+
+// NewWishlistRepository creates a new SQLite wishlist repository
+func NewWishlistRepository(db *sql.DB) repository.WishlistRepository {
+	return &WishlistRepository{db: db}
+}
+
+const wishlistColumns = `id, user_id, product_id, created_at`
+
+// Create adds a new wishlist entry
+func (r *WishlistRepository) Create(ctx context.Context, wishlist *entity.Wishlist) error {
+	if exists, err := rowExists(ctx, r.db, `SELECT 1 FROM wishlists WHERE id = ?`, wishlist.ID); err != nil {
+		return err
+	} else if exists {
+		return errors.New("wishlist entry already exists")
+	}
+	if exists, err := rowExists(ctx, r.db,
+		`SELECT 1 FROM wishlists WHERE user_id = ? AND product_id = ?`, wishlist.UserID, wishlist.ProductID); err != nil {
+		return err
+	} else if exists {
+		return errors.New("product already in user's wishlist")
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO wishlists (`+wishlistColumns+`) VALUES (?, ?, ?, ?)`,
+		wishlist.ID, wishlist.UserID, wishlist.ProductID, wishlist.CreatedAt)
+	return err
+}
+
+// Delete removes a wishlist entry by user and product ID
+func (r *WishlistRepository) Delete(ctx context.Context, userID, productID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM wishlists WHERE user_id = ? AND product_id = ?`, userID, productID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, "wishlist entry not found")
+}
+
+// FindByUserAndProduct checks if a specific product is in user's wishlist
+func (r *WishlistRepository) FindByUserAndProduct(ctx context.Context, userID, productID string) (*entity.Wishlist, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+wishlistColumns+` FROM wishlists WHERE user_id = ? AND product_id = ?`, userID, productID)
+	wishlist, err := scanWishlist(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("wishlist entry not found")
+	}
+	return wishlist, err
+}
+
+// FindByUser gets all wishlist entries for a user
+func (r *WishlistRepository) FindByUser(ctx context.Context, userID string) ([]*entity.Wishlist, error) {
+	return r.findMany(ctx, `SELECT `+wishlistColumns+` FROM wishlists WHERE user_id = ? ORDER BY created_at, id`, userID)
+}
+
+// FindByProduct gets all wishlist entries for a product
+func (r *WishlistRepository) FindByProduct(ctx context.Context, productID string) ([]*entity.Wishlist, error) {
+	return r.findMany(ctx, `SELECT `+wishlistColumns+` FROM wishlists WHERE product_id = ? ORDER BY created_at, id`, productID)
+}
+
+// DeleteByProduct removes every wishlist entry for a product and returns how many there were
+func (r *WishlistRepository) DeleteByProduct(ctx context.Context, productID string) (int, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM wishlists WHERE product_id = ?`, productID)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := result.RowsAffected()
+	return int(deleted), err
+}
+
+// FindProductIDs gets the IDs of the products in any wishlist, each once
+func (r *WishlistRepository) FindProductIDs(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT product_id FROM wishlists ORDER BY product_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var productIDs []string
+	for rows.Next() {
+		var productID string
+		if err := rows.Scan(&productID); err != nil {
+			return nil, err
+		}
+		productIDs = append(productIDs, productID)
+	}
+	return productIDs, rows.Err()
+}
+
+// CountByUser gets the count of wishlist items for a user
+func (r *WishlistRepository) CountByUser(ctx context.Context, userID string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM wishlists WHERE user_id = ?`, userID).Scan(&count)
+	return count, err
+}
+
+func (r *WishlistRepository) findMany(ctx context.Context, query string, args ...interface{}) ([]*entity.Wishlist, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*entity.Wishlist
+	for rows.Next() {
+		wishlist, err := scanWishlist(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, wishlist)
+	}
+	return result, rows.Err()
+}
+
+func scanWishlist(row rowScanner) (*entity.Wishlist, error) {
+	var wishlist entity.Wishlist
+	err := row.Scan(&wishlist.ID, &wishlist.UserID, &wishlist.ProductID, &wishlist.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
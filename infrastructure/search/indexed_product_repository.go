@@ -0,0 +1,97 @@
+package search
+
+import (
+	"context"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// IndexedProductRepository wraps a product repository and puts every product it saves into a
+// search index, so searches see a product as soon as it is written
+type IndexedProductRepository struct {
+	repository.ProductRepository
+	index repository.ProductIndex
+}
+
+// NewIndexedProductRepository creates a product repository that keeps index in sync with repo
+func NewIndexedProductRepository(repo repository.ProductRepository, index repository.ProductIndex) *IndexedProductRepository {
+	return &IndexedProductRepository{ProductRepository: repo, index: index}
+}
+
+// Create creates a new product and indexes it
+func (r *IndexedProductRepository) Create(ctx context.Context, product *entity.Product) error {
+	if err := r.ProductRepository.Create(ctx, product); err != nil {
+		return err
+	}
+	r.index.Put(product)
+	return nil
+}
+
+// Update updates a product and indexes the new version
+func (r *IndexedProductRepository) Update(ctx context.Context, product *entity.Product) error {
+	if err := r.ProductRepository.Update(ctx, product); err != nil {
+		return err
+	}
+	r.index.Put(product)
+	return nil
+}
+
+// BeginTransaction starts a new transaction; the products it saves are indexed when it commits
+func (r *IndexedProductRepository) BeginTransaction(ctx context.Context) (repository.Transaction, error) {
+	tx, err := r.ProductRepository.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &indexedTransaction{Transaction: tx, index: r.index}, nil
+}
+
+// indexedTransaction indexes the products saved in a transaction once it commits
+type indexedTransaction struct {
+	repository.Transaction
+	index   repository.ProductIndex
+	pending []*entity.Product
+}
+
+// Commit commits the transaction and indexes the products it saved
+func (t *indexedTransaction) Commit() error {
+	if err := t.Transaction.Commit(); err != nil {
+		return err
+	}
+	for _, product := range t.pending {
+		t.index.Put(product)
+	}
+	t.pending = nil
+	return nil
+}
+
+// GetProductRepository returns the product repository for this transaction
+func (t *indexedTransaction) GetProductRepository() repository.ProductRepository {
+	return &transactionProductRepository{ProductRepository: t.Transaction.GetProductRepository(), tx: t}
+}
+
+// transactionProductRepository remembers the products saved in a transaction until it commits
+type transactionProductRepository struct {
+	repository.ProductRepository
+	tx *indexedTransaction
+}
+
+// Create creates a new product in the transaction
+func (r *transactionProductRepository) Create(ctx context.Context, product *entity.Product) error {
+	if err := r.ProductRepository.Create(ctx, product); err != nil {
+		return err
+	}
+	saved := *product
+	r.tx.pending = append(r.tx.pending, &saved)
+	return nil
+}
+
+// Update updates a product in the transaction
+func (r *transactionProductRepository) Update(ctx context.Context, product *entity.Product) error {
+	if err := r.ProductRepository.Update(ctx, product); err != nil {
+		return err
+	}
+	saved := *product
+	r.tx.pending = append(r.tx.pending, &saved)
+	return nil
+}
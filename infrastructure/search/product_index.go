@@ -0,0 +1,221 @@
+package search
+
+import (
+	"strings"
+	"sync"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+)
+
+// Keyword matches in the name count for more than matches in the description
+const (
+	nameMatchScore        = 2
+	descriptionMatchScore = 1
+)
+
+// ProductIndex is an in-memory implementation of repository.ProductIndex. Names and descriptions
+// are split into overlapping one and two character grams, so words are found inside longer text
+// without a dictionary, as Japanese is written without spaces.
+type ProductIndex struct {
+	mu       sync.RWMutex
+	docs     map[string]*indexedDoc
+	postings map[string]map[string]struct{} // Gram to the IDs of the products containing it
+}
+
+type indexedDoc struct {
+	product     entity.Product
+	name        string // Normalised for matching
+	description string
+	grams       []string
+	available   int
+	sold        int
+}
+
+// NewProductIndex creates a new, empty product index
+func NewProductIndex() *ProductIndex {
+	return &ProductIndex{
+		docs:     make(map[string]*indexedDoc),
+		postings: make(map[string]map[string]struct{}),
+	}
+}
+
+// Put adds a product or replaces the indexed copy of it, keeping its stock figures
+func (i *ProductIndex) Put(product *entity.Product) {
+	doc := &indexedDoc{
+		product:     *product,
+		name:        normalize(product.Name),
+		description: normalize(product.Description),
+	}
+	// Keep only what is stored; stock, sales and variants are filled in for each response
+	doc.product.Stocks = nil
+	doc.product.TotalStock = 0
+	doc.product.IsFavorite = false
+	doc.product.SalePrice = nil
+	doc.product.SaleEndsAt = nil
+	doc.product.Variants = nil
+	doc.grams = grams(doc.name + " " + doc.description)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if old, ok := i.docs[product.ID]; ok {
+		doc.available, doc.sold = old.available, old.sold
+		for _, gram := range old.grams {
+			delete(i.postings[gram], product.ID)
+			if len(i.postings[gram]) == 0 {
+				delete(i.postings, gram)
+			}
+		}
+	}
+	for _, gram := range doc.grams {
+		ids, ok := i.postings[gram]
+		if !ok {
+			ids = make(map[string]struct{})
+			i.postings[gram] = ids
+		}
+		ids[product.ID] = struct{}{}
+	}
+	i.docs[product.ID] = doc
+}
+
+// SetStock sets the units of an indexed product available to order and sold
+func (i *ProductIndex) SetStock(productID string, available, sold int) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if doc, ok := i.docs[productID]; ok {
+		doc.available, doc.sold = available, sold
+	}
+}
+
+// Products returns every indexed product
+func (i *ProductIndex) Products() []repository.IndexedProduct {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	products := make([]repository.IndexedProduct, 0, len(i.docs))
+	for _, doc := range i.docs {
+		product := doc.product
+		products = append(products, repository.IndexedProduct{
+			Product:   &product,
+			Available: doc.available,
+			Sold:      doc.sold,
+		})
+	}
+	return products
+}
+
+// Match scores the indexed products whose name or description contains every word of the
+// keyword. Each word scores more when it is found in the name than in the description.
+func (i *ProductIndex) Match(keyword string) map[string]int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var scores map[string]int
+	for _, word := range strings.Fields(normalize(keyword)) {
+		wordScores := make(map[string]int)
+		for id := range i.candidates(word) {
+			doc := i.docs[id]
+			switch {
+			case strings.Contains(doc.name, word):
+				wordScores[id] = nameMatchScore
+			case strings.Contains(doc.description, word):
+				wordScores[id] = descriptionMatchScore
+			}
+		}
+
+		if scores == nil {
+			scores = wordScores
+			continue
+		}
+		for id, score := range scores {
+			if wordScore, ok := wordScores[id]; ok {
+				scores[id] = score + wordScore
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+	if scores == nil {
+		scores = map[string]int{}
+	}
+	return scores
+}
+
+// candidates returns the IDs of the products containing every gram of the word. Grams can be
+// found apart from each other, so candidates still need to be checked for the whole word.
+func (i *ProductIndex) candidates(word string) map[string]struct{} {
+	var result map[string]struct{}
+	for _, gram := range searchGrams([]rune(word)) {
+		ids := i.postings[gram]
+		if result == nil {
+			result = make(map[string]struct{}, len(ids))
+			for id := range ids {
+				result[id] = struct{}{}
+			}
+			continue
+		}
+		for id := range result {
+			if _, ok := ids[id]; !ok {
+				delete(result, id)
+			}
+		}
+	}
+	return result
+}
+
+// normalize folds text so that equivalent spellings match: full-width letters and digits become
+// half-width, half-width katakana becomes full-width, letters become lower case and katakana
+// becomes hiragana
+func normalize(text string) string {
+	text = strings.ToLower(norm.NFKC.String(text))
+	return strings.Map(func(r rune) rune {
+		if r >= 'ァ' && r <= 'ヶ' {
+			return r - 'ァ' + 'ぁ'
+		}
+		return r
+	}, text)
+}
+
+// grams returns the distinct grams of every word of normalised text
+func grams(text string) []string {
+	seen := make(map[string]struct{})
+	var result []string
+	for _, word := range strings.Fields(text) {
+		for _, gram := range wordGrams([]rune(word)) {
+			if _, ok := seen[gram]; !ok {
+				seen[gram] = struct{}{}
+				result = append(result, gram)
+			}
+		}
+	}
+	return result
+}
+
+// wordGrams returns the single characters and the pairs of adjacent characters of an indexed word
+func wordGrams(word []rune) []string {
+	result := make([]string, 0, 2*len(word))
+	for i := range word {
+		result = append(result, string(word[i]))
+		if i+1 < len(word) {
+			result = append(result, string(word[i:i+2]))
+		}
+	}
+	return result
+}
+
+// searchGrams returns the grams a search word is looked up by: the word itself when it is a
+// single character, otherwise its pairs of adjacent characters
+func searchGrams(word []rune) []string {
+	if len(word) == 1 {
+		return []string{string(word)}
+	}
+	result := make([]string, 0, len(word)-1)
+	for i := 0; i+1 < len(word); i++ {
+		result = append(result, string(word[i:i+2]))
+	}
+	return result
+}
@@ -0,0 +1,66 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+)
+
+func TestProductIndex_Match(t *testing.T) {
+	index := NewProductIndex()
+	tea, _ := entity.NewProduct("P001", "オーガニック緑茶", 1200, "Food")
+	tea.SetDescription("静岡県産の一番茶を使ったお茶")
+	coffee, _ := entity.NewProduct("P002", "Coffee Beans", 900, "Food")
+	coffee.SetDescription("Dark roast, 緑 packaging")
+	index.Put(tea)
+	index.Put(coffee)
+
+	tests := []struct {
+		name    string
+		keyword string
+		want    map[string]int
+	}{
+		{"word inside Japanese text", "緑茶", map[string]int{"P001": 2}},
+		{"hiragana finds katakana", "おーがにっく", map[string]int{"P001": 2}},
+		{"full-width letters", "ＣＯＦＦＥＥ", map[string]int{"P002": 2}},
+		{"single character", "緑", map[string]int{"P001": 2, "P002": 1}},
+		{"every word must match", "静岡 お茶", map[string]int{"P001": 2}},
+		{"grams apart do not match", "茶緑", map[string]int{}},
+		{"no match", "紅茶", map[string]int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := index.Match(tt.keyword)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, got)
+			}
+			for id, score := range tt.want {
+				if got[id] != score {
+					t.Errorf("Expected %s to score %d, got %d", id, score, got[id])
+				}
+			}
+		})
+	}
+}
+
+func TestProductIndex_PutReplaces(t *testing.T) {
+	index := NewProductIndex()
+	tea, _ := entity.NewProduct("P001", "緑茶", 1200, "Food")
+	index.Put(tea)
+	index.SetStock("P001", 5, 2)
+
+	tea.UpdateDetails("ほうじ茶", 1000, "Food")
+	index.Put(tea)
+	if got := index.Match("緑茶"); len(got) != 0 {
+		t.Errorf("Expected the old name not to match, got %v", got)
+	}
+	if got := index.Match("ほうじ"); got["P001"] == 0 {
+		t.Errorf("Expected the new name to match, got %v", got)
+	}
+
+	products := index.Products()
+	if len(products) != 1 || products[0].Product.Price != 1000 || products[0].Available != 5 || products[0].Sold != 2 {
+		t.Errorf("Expected the new version with its stock figures kept, got %+v", products)
+	}
+}
@@ -0,0 +1,130 @@
+// Package cli implements the command line tools that run next to the API server
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gal1996/vibe_coding_with_architecture/di"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/auth"
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/interactor"
+)
+
+const cliUsage = `Usage: %[1]s <command> [flags] [file]
+
+Commands:
+  import-products [-dry-run] [-as admin] file.csv  Create or update products from a CSV file
+  import-stock [-dry-run] [-as admin] file.csv     Set warehouse stock from a CSV file
+  export-products [-as admin] [file.csv]           Write every product as CSV (stdout by default)
+  export-stock [-as admin] [file.csv]              Write the stock of every warehouse as CSV
+
+Commands use the storage configured by the environment, as the server does.
+Without a command the API server is started.
+`
+
+// Run runs the command line tool named by args[0] with the rest of args and returns the
+// process exit code
+func Run(args []string) int {
+	name := args[0]
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "validate the file without changing anything")
+	username := flags.String("as", "admin", "administrator the command runs as")
+	flags.Usage = func() { fmt.Fprintf(os.Stderr, cliUsage, os.Args[0]) }
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	var run func(ctx context.Context, catalog *interactor.CatalogUseCase) (int, error)
+	switch name {
+	case "import-products", "import-stock":
+		if flags.NArg() != 1 {
+			flags.Usage()
+			return 2
+		}
+		importCSV := (*interactor.CatalogUseCase).ImportProductsCSV
+		if name == "import-stock" {
+			importCSV = (*interactor.CatalogUseCase).ImportStockCSV
+		}
+		run = func(ctx context.Context, catalog *interactor.CatalogUseCase) (int, error) {
+			f, err := os.Open(flags.Arg(0))
+			if err != nil {
+				return 1, err
+			}
+			defer f.Close()
+			report, err := importCSV(catalog, ctx, f, *dryRun)
+			if err != nil {
+				return 1, err
+			}
+			out, _ := json.MarshalIndent(report, "", "  ")
+			fmt.Println(string(out))
+			if len(report.Errors) > 0 {
+				return 1, nil
+			}
+			return 0, nil
+		}
+	case "export-products", "export-stock":
+		if flags.NArg() > 1 {
+			flags.Usage()
+			return 2
+		}
+		exportCSV := (*interactor.CatalogUseCase).ExportProductsCSV
+		if name == "export-stock" {
+			exportCSV = (*interactor.CatalogUseCase).ExportStockCSV
+		}
+		run = func(ctx context.Context, catalog *interactor.CatalogUseCase) (int, error) {
+			var w io.Writer = os.Stdout
+			if flags.NArg() == 1 {
+				f, err := os.Create(flags.Arg(0))
+				if err != nil {
+					return 1, err
+				}
+				defer f.Close()
+				w = f
+			}
+			if err := exportCSV(catalog, ctx, w); err != nil {
+				return 1, err
+			}
+			return 0, nil
+		}
+	default:
+		flags.Usage()
+		return 2
+	}
+
+	code, err := runAsAdmin(*username, run)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+	}
+	return code
+}
+
+// runAsAdmin opens the configured storage and runs fn on behalf of the named administrator
+func runAsAdmin(username string, fn func(ctx context.Context, catalog *interactor.CatalogUseCase) (int, error)) (int, error) {
+	cfg, err := di.LoadConfigFromEnv()
+	if err != nil {
+		return 1, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if cfg.StorageDriver == di.StorageMemory {
+		return 1, errors.New("the memory storage keeps nothing once the command exits; set STORAGE_DRIVER=sqlite")
+	}
+	container, err := di.NewContainer(cfg)
+	if err != nil {
+		return 1, fmt.Errorf("failed to initialize container: %w", err)
+	}
+	defer container.Close()
+
+	ctx := context.Background()
+	user, err := container.UserRepository.FindByUsername(ctx, username)
+	if err != nil {
+		return 1, fmt.Errorf("user not found: %s", username)
+	}
+	if !user.IsAdmin {
+		return 1, fmt.Errorf("%s is not an administrator", username)
+	}
+	return fn(auth.SetUserInContext(ctx, user), container.CatalogUseCase)
+}
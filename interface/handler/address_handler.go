@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/interactor"
+	"github.com/gin-gonic/gin"
+)
+
+// AddressHandler handles HTTP requests for the user's address book
+type AddressHandler struct {
+	addressUseCase *interactor.AddressUseCase
+}
+
+// NewAddressHandler creates a new address handler
+func NewAddressHandler(addressUseCase *interactor.AddressUseCase) *AddressHandler {
+	return &AddressHandler{
+		addressUseCase: addressUseCase,
+	}
+}
+
+// AddressRequest represents the request body for adding or replacing an address
+type AddressRequest struct {
+	Recipient  string `json:"recipient" binding:"required"`
+	PostalCode string `json:"postal_code" binding:"required"` // 1234567 or 123-4567
+	Prefecture string `json:"prefecture" binding:"required"`  // e.g. 東京都
+	City       string `json:"city" binding:"required"`
+	Line1      string `json:"line1" binding:"required"`
+	Line2      string `json:"line2"`
+	Phone      string `json:"phone" binding:"required"`
+}
+
+// toFields converts the request to the address fields
+func (req AddressRequest) toFields() entity.AddressFields {
+	return entity.AddressFields{
+		Recipient:  req.Recipient,
+		PostalCode: req.PostalCode,
+		Prefecture: req.Prefecture,
+		City:       req.City,
+		Line1:      req.Line1,
+		Line2:      req.Line2,
+		Phone:      req.Phone,
+	}
+}
+
+// ListAddresses handles GET /users/me/addresses
+func (h *AddressHandler) ListAddresses(c *gin.Context) {
+	addresses, err := h.addressUseCase.ListAddresses(c.Request.Context())
+	if err != nil {
+		respondAddressError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, addresses)
+}
+
+// AddAddress handles POST /users/me/addresses
+func (h *AddressHandler) AddAddress(c *gin.Context) {
+	var req AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	address, err := h.addressUseCase.AddAddress(c.Request.Context(), req.toFields())
+	if err != nil {
+		respondAddressError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, address)
+}
+
+// UpdateAddress handles PUT /users/me/addresses/:id
+func (h *AddressHandler) UpdateAddress(c *gin.Context) {
+	var req AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	address, err := h.addressUseCase.UpdateAddress(c.Request.Context(), c.Param("id"), req.toFields())
+	if err != nil {
+		respondAddressError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, address)
+}
+
+// DeleteAddress handles DELETE /users/me/addresses/:id
+func (h *AddressHandler) DeleteAddress(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.addressUseCase.DeleteAddress(c.Request.Context(), id); err != nil {
+		respondAddressError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Address deleted",
+		"id":      id,
+	})
+}
+
+// SetDefaultAddress handles POST /users/me/addresses/:id/default
+func (h *AddressHandler) SetDefaultAddress(c *gin.Context) {
+	address, err := h.addressUseCase.SetDefaultAddress(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondAddressError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, address)
+}
+
+// respondAddressError maps address use case errors to HTTP responses
+func respondAddressError(c *gin.Context, err error) {
+	switch {
+	case strings.HasPrefix(err.Error(), "authentication required"):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+	case strings.HasPrefix(err.Error(), "address not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
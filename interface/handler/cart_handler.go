@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/interactor"
+	"github.com/gin-gonic/gin"
+)
+
+// CartHandler handles HTTP requests for the shopping cart
+type CartHandler struct {
+	cartUseCase *interactor.CartUseCase
+}
+
+// NewCartHandler creates a new cart handler
+func NewCartHandler(cartUseCase *interactor.CartUseCase) *CartHandler {
+	return &CartHandler{
+		cartUseCase: cartUseCase,
+	}
+}
+
+// AddCartItemRequest represents the request body for adding a product to the cart
+type AddCartItemRequest struct {
+	ProductID string `json:"product_id" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required,min=1"`
+}
+
+// UpdateCartItemRequest represents the request body for changing a line's quantity
+type UpdateCartItemRequest struct {
+	Quantity int `json:"quantity" binding:"required,min=1"`
+}
+
+// ApplyCouponRequest represents the request body for applying a coupon to the cart
+type ApplyCouponRequest struct {
+	CouponCode string `json:"coupon_code" binding:"required"`
+}
+
+// GetCart handles GET /cart
+func (h *CartHandler) GetCart(c *gin.Context) {
+	cart, err := h.cartUseCase.GetCart(c.Request.Context())
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cart)
+}
+
+// AddItem handles POST /cart/items
+func (h *CartHandler) AddItem(c *gin.Context) {
+	var req AddCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cart, err := h.cartUseCase.AddItem(c.Request.Context(), req.ProductID, req.Quantity)
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cart)
+}
+
+// UpdateItem handles PUT /cart/items/:product_id
+func (h *CartHandler) UpdateItem(c *gin.Context) {
+	var req UpdateCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cart, err := h.cartUseCase.UpdateItem(c.Request.Context(), c.Param("product_id"), req.Quantity)
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cart)
+}
+
+// RemoveItem handles DELETE /cart/items/:product_id
+func (h *CartHandler) RemoveItem(c *gin.Context) {
+	cart, err := h.cartUseCase.RemoveItem(c.Request.Context(), c.Param("product_id"))
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cart)
+}
+
+// ApplyCoupon handles PUT /cart/coupon
+func (h *CartHandler) ApplyCoupon(c *gin.Context) {
+	var req ApplyCouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cart, err := h.cartUseCase.ApplyCoupon(c.Request.Context(), req.CouponCode)
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cart)
+}
+
+// RemoveCoupon handles DELETE /cart/coupon
+func (h *CartHandler) RemoveCoupon(c *gin.Context) {
+	cart, err := h.cartUseCase.RemoveCoupon(c.Request.Context())
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cart)
+}
+
+// CheckoutRequest represents the optional request body for checking out the cart
+type CheckoutRequest struct {
+	AddressID string `json:"address_id"` // The default address when omitted
+}
+
+// Checkout handles POST /cart/checkout
+func (h *CartHandler) Checkout(c *gin.Context) {
+	var req CheckoutRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	order, err := h.cartUseCase.Checkout(c.Request.Context(), req.AddressID)
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, order)
+}
+
+// respondCartError maps cart use case errors to HTTP responses
+func respondCartError(c *gin.Context, err error) {
+	switch {
+	case strings.HasPrefix(err.Error(), "authentication required"):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+	case errors.Is(err, entity.ErrCartItemNotFound), strings.HasPrefix(err.Error(), "product not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		respondOrderError(c, err)
+	}
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/interactor"
+	"github.com/gin-gonic/gin"
+)
+
+// maxCSVUploadSize caps the size of an imported CSV file
+const maxCSVUploadSize = 10 << 20
+
+// CatalogHandler handles HTTP requests for importing and exporting products and stock as CSV
+type CatalogHandler struct {
+	catalogUseCase *interactor.CatalogUseCase
+}
+
+// NewCatalogHandler creates a new catalog handler
+func NewCatalogHandler(catalogUseCase *interactor.CatalogUseCase) *CatalogHandler {
+	return &CatalogHandler{
+		catalogUseCase: catalogUseCase,
+	}
+}
+
+// ExportProducts handles GET /admin/products/export
+func (h *CatalogHandler) ExportProducts(c *gin.Context) {
+	h.export(c, "products.csv", h.catalogUseCase.ExportProductsCSV)
+}
+
+// ImportProducts handles POST /admin/products/import?dry_run=true
+func (h *CatalogHandler) ImportProducts(c *gin.Context) {
+	h.importCSV(c, h.catalogUseCase.ImportProductsCSV)
+}
+
+// ExportStock handles GET /admin/stocks/export
+func (h *CatalogHandler) ExportStock(c *gin.Context) {
+	h.export(c, "stock.csv", h.catalogUseCase.ExportStockCSV)
+}
+
+// ImportStock handles POST /admin/stocks/import?dry_run=true
+func (h *CatalogHandler) ImportStock(c *gin.Context) {
+	h.importCSV(c, h.catalogUseCase.ImportStockCSV)
+}
+
+// export responds with the CSV file written by write as an attachment
+func (h *CatalogHandler) export(c *gin.Context, filename string, write func(ctx context.Context, w io.Writer) error) {
+	var buf bytes.Buffer
+	if err := write(c.Request.Context(), &buf); err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// importCSV runs an import of the uploaded CSV file and responds with its report. The file is
+// either the request body or the "file" field of a multipart form. A report listing row errors
+// is returned with 422.
+func (h *CatalogHandler) importCSV(c *gin.Context, run func(ctx context.Context, r io.Reader, dryRun bool) (*interactor.ImportReport, error)) {
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be true or false"})
+			return
+		}
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxCSVUploadSize)
+	var body io.Reader = c.Request.Body
+	if c.ContentType() == "multipart/form-data" {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "multipart upload needs a file field"})
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+
+	report, err := run(c.Request.Context(), body, dryRun)
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	if len(report.Errors) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// respondCatalogError maps catalog use case errors to HTTP responses
+func respondCatalogError(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.Is(err, interactor.ErrAdminRequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.As(err, &tooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "CSV file is too large"})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -1,8 +1,16 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+	"github.com/gal1996/vibe_coding_with_architecture/interface/middleware"
 	"github.com/gal1996/vibe_coding_with_architecture/usecase/interactor"
 	"github.com/gin-gonic/gin"
 )
@@ -23,45 +31,94 @@ func NewOrderHandler(orderUseCase *interactor.OrderUseCase) *OrderHandler {
 type CreateOrderRequest struct {
 	Items      []OrderItemRequest `json:"items" binding:"required,min=1"`
 	CouponCode string            `json:"coupon_code,omitempty"` // Optional coupon code
+	AddressID  string            `json:"address_id,omitempty"`  // Optional; the default address when omitted
 }
 
-// OrderItemRequest represents an item in an order request
+// OrderItemRequest represents an item in an order request; a variant is ordered by its SKU
 type OrderItemRequest struct {
-	ProductID string `json:"product_id" binding:"required"`
+	ProductID string `json:"product_id" binding:"required_without=SKU"`
+	SKU       string `json:"sku,omitempty"`
 	Quantity  int    `json:"quantity" binding:"required,min=1"`
 }
 
-// CreateOrder handles POST /orders
-func (h *OrderHandler) CreateOrder(c *gin.Context) {
-	var req CreateOrderRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Convert request to use case input
+// toInput converts the request to use case input
+func (req CreateOrderRequest) toInput() interactor.CreateOrderInput {
 	items := make([]interactor.OrderItemInput, len(req.Items))
 	for i, item := range req.Items {
 		items[i] = interactor.OrderItemInput{
 			ProductID: item.ProductID,
+			SKU:       item.SKU,
 			Quantity:  item.Quantity,
 		}
 	}
 
-	input := interactor.CreateOrderInput{
+	return interactor.CreateOrderInput{
 		Items:      items,
 		CouponCode: req.CouponCode,
+		AddressID:  req.AddressID,
 	}
+}
 
-	order, err := h.orderUseCase.CreateOrder(c.Request.Context(), input)
-	if err != nil {
+// CreateOrder handles POST /orders
+func (h *OrderHandler) CreateOrder(c *gin.Context) {
+	var req CreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	order, err := h.orderUseCase.CreateOrder(c.Request.Context(), req.toInput())
+	if err != nil {
+		respondOrderError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusCreated, order)
 }
 
+// respondOrderError maps errors of placing an order to HTTP responses. Failures of the payment
+// gateway or the storage and lost concurrent updates are server errors, so an idempotent
+// request that hit one can be retried with the same key. A charge that could not be refunded
+// is a server error too, but it keeps the key.
+func respondOrderError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, interactor.ErrRefundFailed):
+		// Checked first: the customer was charged whatever else went wrong, so a retry with
+		// the same key must be answered with this response rather than charge again
+		middleware.KeepIdempotencyKey(c)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	case errors.Is(err, interactor.ErrPaymentUnavailable), errors.Is(err, repository.ErrTransactionConflict):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrStorage):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+// QuoteOrder handles POST /orders/quote
+func (h *OrderHandler) QuoteOrder(c *gin.Context) {
+	var req CreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quote, err := h.orderUseCase.QuoteOrder(c.Request.Context(), req.toInput())
+	if err != nil {
+		respondOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
+
+// OrderDetailResponse is an order together with the shipments it is split into
+type OrderDetailResponse struct {
+	*entity.Order
+	Shipments []entity.Shipment `json:"shipments"`
+}
+
 // GetOrder handles GET /orders/:id
 func (h *OrderHandler) GetOrder(c *gin.Context) {
 	orderID := c.Param("id")
@@ -72,7 +129,11 @@ func (h *OrderHandler) GetOrder(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, order)
+	shipments := order.Shipments()
+	if shipments == nil {
+		shipments = []entity.Shipment{}
+	}
+	c.JSON(http.StatusOK, OrderDetailResponse{Order: order, Shipments: shipments})
 }
 
 // ListUserOrders handles GET /orders
@@ -88,3 +149,169 @@ func (h *OrderHandler) ListUserOrders(c *gin.Context) {
 		"count":  len(orders),
 	})
 }
+
+// CancelOrder handles POST /orders/:id/cancel and POST /admin/orders/:id/cancel
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	orderID := c.Param("id")
+
+	order, err := h.orderUseCase.CancelOrder(c.Request.Context(), orderID)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "order not found"):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, interactor.ErrOrderAccessDenied):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, entity.ErrOrderNotCancellable):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// UpdateOrderStatusRequest represents the request body for moving an order to a new status
+type UpdateOrderStatusRequest struct {
+	Status         string `json:"status" binding:"required"`
+	TrackingNumber string `json:"tracking_number,omitempty"` // Required when shipping
+	Note           string `json:"note,omitempty"`
+}
+
+// UpdateOrderStatus handles POST /admin/orders/:id/status
+func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
+	orderID := c.Param("id")
+
+	var req UpdateOrderStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	status, err := entity.ParseOrderStatus(req.Status)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input := interactor.UpdateOrderStatusInput{
+		Status:         status,
+		TrackingNumber: req.TrackingNumber,
+		Note:           req.Note,
+	}
+
+	order, err := h.orderUseCase.UpdateOrderStatus(c.Request.Context(), orderID, input)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "order not found"):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, interactor.ErrOrderAccessDenied):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, entity.ErrInvalidTransition), errors.Is(err, entity.ErrOrderNotCancellable):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// SearchOrders handles GET /admin/orders
+func (h *OrderHandler) SearchOrders(c *gin.Context) {
+	input, err := parseSearchOrdersQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.orderUseCase.SearchOrders(c.Request.Context(), input)
+	if err != nil {
+		if errors.Is(err, interactor.ErrOrderAccessDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// parseSearchOrdersQuery reads the order search filters from the query string.
+// Dates are RFC 3339 timestamps or YYYY-MM-DD days; a day passed as "to" includes the whole day.
+func parseSearchOrdersQuery(c *gin.Context) (interactor.SearchOrdersInput, error) {
+	input := interactor.SearchOrdersInput{
+		UserID:      c.Query("user_id"),
+		CouponCode:  c.Query("coupon"),
+		WarehouseID: c.Query("warehouse_id"),
+		SortBy:      c.Query("sort"),
+	}
+
+	if status := c.Query("status"); status != "" {
+		parsed, err := entity.ParseOrderStatus(status)
+		if err != nil {
+			return input, err
+		}
+		input.Status = parsed
+	}
+
+	switch order := c.Query("order"); order {
+	case "", "asc":
+	case "desc":
+		input.Descending = true
+	default:
+		return input, fmt.Errorf("invalid sort order: %s", order)
+	}
+
+	var err error
+	if input.CreatedFrom, _, err = parseDateQuery(c, "from"); err != nil {
+		return input, err
+	}
+	var dayOnly bool
+	if input.CreatedBefore, dayOnly, err = parseDateQuery(c, "to"); err != nil {
+		return input, err
+	}
+	if dayOnly {
+		input.CreatedBefore = input.CreatedBefore.AddDate(0, 0, 1)
+	}
+
+	if input.Page, err = parseIntQuery(c, "page"); err != nil {
+		return input, err
+	}
+	if input.PageSize, err = parseIntQuery(c, "page_size"); err != nil {
+		return input, err
+	}
+	return input, nil
+}
+
+// parseDateQuery parses an optional date query parameter and reports whether it was a bare day
+func parseDateQuery(c *gin.Context, key string) (time.Time, bool, error) {
+	value := c.Query(key)
+	if value == "" {
+		return time.Time{}, false, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid %s date: %s", key, value)
+	}
+	return t, true, nil
+}
+
+// parseIntQuery parses an optional non-negative integer query parameter
+func parseIntQuery(c *gin.Context, key string) (int, error) {
+	value := c.Query(key)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s: %s", key, value)
+	}
+	return n, nil
+}
@@ -1,9 +1,16 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
 	"github.com/gal1996/vibe_coding_with_architecture/usecase/interactor"
 )
 
@@ -21,9 +28,15 @@ func NewProductHandler(productUseCase *interactor.ProductUseCase) *ProductHandle
 
 // CreateProductRequest represents the request body for creating a product
 type CreateProductRequest struct {
-	Name     string `json:"name" binding:"required"`
-	Price    int    `json:"price" binding:"required,min=0"`
+	SKU         string `json:"sku"` // Optional, unique stock keeping unit
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"` // Optional
+	Price       int    `json:"price" binding:"required,min=0"`
 	Category string `json:"category" binding:"required"`
+	Weight   int    `json:"weight" binding:"min=0"` // Optional, grams per unit
+	// Optional: "backorder" or "preorder" to accept orders beyond stock; pre-orders need expected_available_at
+	Backorder           string     `json:"backorder"`
+	ExpectedAvailableAt *time.Time `json:"expected_available_at"`
 	// Stock is now managed through warehouse-specific allocations after product creation
 }
 
@@ -35,13 +48,28 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 		return
 	}
 
+	backorder, err := entity.ParseBackorderMode(req.Backorder)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	input := interactor.CreateProductInput{
-		Name:     req.Name,
-		Price:    req.Price,
-		Category: req.Category,
+		SKU:                 req.SKU,
+		Name:                req.Name,
+		Description:         req.Description,
+		Price:               req.Price,
+		Category:            req.Category,
+		Weight:              req.Weight,
+		Backorder:           backorder,
+		ExpectedAvailableAt: req.ExpectedAvailableAt,
 	}
 
 	product, err := h.productUseCase.CreateProduct(c.Request.Context(), input)
+	if errors.Is(err, repository.ErrSKUTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -77,4 +105,289 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 		"products": products,
 		"count":    len(products),
 	})
-}
\ No newline at end of file
+}
+
+// SearchProducts handles GET /products/search
+func (h *ProductHandler) SearchProducts(c *gin.Context) {
+	input, err := parseSearchProductsQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.productUseCase.SearchProducts(c.Request.Context(), input)
+	if err != nil {
+		respondProductError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// parseSearchProductsQuery reads the catalog search from the query string
+func parseSearchProductsQuery(c *gin.Context) (interactor.SearchProductsInput, error) {
+	input := interactor.SearchProductsInput{
+		Keyword:  c.Query("q"),
+		Category: c.Query("category"),
+		Cursor:   c.Query("cursor"),
+	}
+
+	var err error
+	if input.Sort, err = service.ParseProductSort(c.Query("sort")); err != nil {
+		return input, err
+	}
+	switch inStock := c.Query("in_stock"); inStock {
+	case "", "false":
+	case "true":
+		input.InStock = true
+	default:
+		return input, fmt.Errorf("invalid in_stock: %s", inStock)
+	}
+	if input.MinPrice, err = parseOptionalIntQuery(c, "min_price"); err != nil {
+		return input, err
+	}
+	if input.MaxPrice, err = parseOptionalIntQuery(c, "max_price"); err != nil {
+		return input, err
+	}
+	if input.Limit, err = parseIntQuery(c, "limit"); err != nil {
+		return input, err
+	}
+	return input, nil
+}
+
+// parseOptionalIntQuery parses a non-negative integer query parameter that is nil when omitted
+func parseOptionalIntQuery(c *gin.Context, key string) (*int, error) {
+	if c.Query(key) == "" {
+		return nil, nil
+	}
+	n, err := parseIntQuery(c, key)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// SetBackorderRequest represents the request body for changing a product's backorder mode
+type SetBackorderRequest struct {
+	Mode                string     `json:"mode"` // "none", "backorder" or "preorder"
+	ExpectedAvailableAt *time.Time `json:"expected_available_at"`
+}
+
+// SetBackorder handles PUT /admin/products/:id/backorder
+func (h *ProductHandler) SetBackorder(c *gin.Context) {
+	var req SetBackorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	mode, err := entity.ParseBackorderMode(req.Mode)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	product, err := h.productUseCase.SetBackorder(c.Request.Context(), c.Param("id"), mode, req.ExpectedAvailableAt)
+	if err != nil {
+		respondProductError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// UpdateProductRequest represents the request body for editing a product; omitted fields are left as they are
+type UpdateProductRequest struct {
+	SKU         *string `json:"sku"` // "" removes the SKU
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Price       *int    `json:"price" binding:"omitempty,min=0"`
+	Category    *string `json:"category"`
+	Weight      *int    `json:"weight" binding:"omitempty,min=0"` // Grams per unit
+}
+
+// UpdateProduct handles PATCH /admin/products/:id
+func (h *ProductHandler) UpdateProduct(c *gin.Context) {
+	var req UpdateProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input := interactor.UpdateProductInput{
+		SKU:         req.SKU,
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+		Category:    req.Category,
+		Weight:      req.Weight,
+	}
+	product, err := h.productUseCase.UpdateProduct(c.Request.Context(), c.Param("id"), input)
+	if err != nil {
+		respondProductError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// CreateVariantRequest represents the request body for creating a product variant
+type CreateVariantRequest struct {
+	SKU     string            `json:"sku" binding:"required"`
+	Options map[string]string `json:"options" binding:"required"`
+	Price   *int              `json:"price"` // Optional, defaults to the parent's price
+}
+
+// CreateVariant handles POST /admin/products/:id/variants
+func (h *ProductHandler) CreateVariant(c *gin.Context) {
+	var req CreateVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input := interactor.CreateVariantInput{
+		SKU:     req.SKU,
+		Options: req.Options,
+		Price:   req.Price,
+	}
+	variant, err := h.productUseCase.CreateVariant(c.Request.Context(), c.Param("id"), input)
+	if err != nil {
+		respondProductError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, variant)
+}
+
+// ScheduleSaleRequest represents the request body for scheduling a sale
+type ScheduleSaleRequest struct {
+	Price    int        `json:"price" binding:"min=0"`
+	StartsAt *time.Time `json:"starts_at"` // Optional, defaults to now
+	EndsAt   time.Time  `json:"ends_at" binding:"required"`
+}
+
+// ScheduleSale handles POST /admin/products/:id/sales
+func (h *ProductHandler) ScheduleSale(c *gin.Context) {
+	var req ScheduleSaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input := interactor.ScheduleSaleInput{
+		Price:    req.Price,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+	}
+	sale, err := h.productUseCase.ScheduleSale(c.Request.Context(), c.Param("id"), input)
+	if err != nil {
+		respondProductError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, sale)
+}
+
+// ListSales handles GET /admin/products/:id/sales
+func (h *ProductHandler) ListSales(c *gin.Context) {
+	sales, err := h.productUseCase.ListSales(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondProductError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sales": sales,
+		"count": len(sales),
+	})
+}
+
+// CancelSale handles POST /admin/products/:id/sales/:sale_id/cancel
+func (h *ProductHandler) CancelSale(c *gin.Context) {
+	sale, err := h.productUseCase.CancelSale(c.Request.Context(), c.Param("id"), c.Param("sale_id"))
+	if err != nil {
+		respondProductError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sale)
+}
+
+// GetPriceHistory handles GET /admin/products/:id/price-history
+func (h *ProductHandler) GetPriceHistory(c *gin.Context) {
+	changes, err := h.productUseCase.GetPriceHistory(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondProductError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"price_changes": changes,
+		"count":         len(changes),
+	})
+}
+
+// ArchiveProduct handles DELETE /admin/products/:id
+func (h *ProductHandler) ArchiveProduct(c *gin.Context) {
+	product, err := h.productUseCase.ArchiveProduct(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondProductError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// RestoreProduct handles POST /admin/products/:id/restore
+func (h *ProductHandler) RestoreProduct(c *gin.Context) {
+	product, err := h.productUseCase.RestoreProduct(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondProductError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// ListArchivedProducts handles GET /admin/products/archived
+func (h *ProductHandler) ListArchivedProducts(c *gin.Context) {
+	products, err := h.productUseCase.ListArchivedProducts(c.Request.Context())
+	if err != nil {
+		respondProductError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"products": products,
+		"count":    len(products),
+	})
+}
+
+// CleanupCatalog handles POST /admin/products/cleanup. It repairs records of products removed
+// outside the API; archived products keep their wishlist entries.
+func (h *ProductHandler) CleanupCatalog(c *gin.Context) {
+	result, err := h.productUseCase.CleanupCatalog(c.Request.Context())
+	if err != nil {
+		respondProductError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// respondProductError maps product use case errors to HTTP responses
+func respondProductError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, interactor.ErrAdminRequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrProductArchived), errors.Is(err, entity.ErrProductNotArchived),
+		errors.Is(err, service.ErrSaleOverlaps):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrSKUTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), "product not found"), strings.HasPrefix(err.Error(), "sale not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/interactor"
+	"github.com/gin-gonic/gin"
+)
+
+// ShippingHandler handles HTTP requests for shipping rule administration
+type ShippingHandler struct {
+	shippingUseCase *interactor.ShippingUseCase
+}
+
+// NewShippingHandler creates a new shipping handler
+func NewShippingHandler(shippingUseCase *interactor.ShippingUseCase) *ShippingHandler {
+	return &ShippingHandler{
+		shippingUseCase: shippingUseCase,
+	}
+}
+
+// ShippingRuleRequest represents the request body for creating or replacing a shipping rule
+type ShippingRuleRequest struct {
+	Name                  string     `json:"name" binding:"required"`
+	Priority              int        `json:"priority"`
+	Active                *bool      `json:"active"` // Defaults to true
+	Prefectures           []string   `json:"prefectures"`
+	WarehouseIDs          []string   `json:"warehouse_ids"`
+	MinItems              int        `json:"min_items" binding:"min=0"`
+	MaxItems              int        `json:"max_items" binding:"min=0"`
+	MinWeight             int        `json:"min_weight" binding:"min=0"`
+	MaxWeight             int        `json:"max_weight" binding:"min=0"`
+	Fee                   int        `json:"fee" binding:"min=0"`
+	FreeShippingThreshold int        `json:"free_shipping_threshold" binding:"min=0"`
+	StartsAt              *time.Time `json:"starts_at"`
+	EndsAt                *time.Time `json:"ends_at"`
+}
+
+// toInput converts the request to use case input
+func (req ShippingRuleRequest) toInput() interactor.ShippingRuleInput {
+	return interactor.ShippingRuleInput{
+		Name:                  req.Name,
+		Priority:              req.Priority,
+		Active:                req.Active == nil || *req.Active,
+		Prefectures:           req.Prefectures,
+		WarehouseIDs:          req.WarehouseIDs,
+		MinItems:              req.MinItems,
+		MaxItems:              req.MaxItems,
+		MinWeight:             req.MinWeight,
+		MaxWeight:             req.MaxWeight,
+		Fee:                   req.Fee,
+		FreeShippingThreshold: req.FreeShippingThreshold,
+		StartsAt:              req.StartsAt,
+		EndsAt:                req.EndsAt,
+	}
+}
+
+// ListShippingRules handles GET /admin/shipping-rules
+func (h *ShippingHandler) ListShippingRules(c *gin.Context) {
+	rules, err := h.shippingUseCase.ListShippingRules(c.Request.Context())
+	if err != nil {
+		respondShippingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"shipping_rules": rules,
+		"count":          len(rules),
+	})
+}
+
+// CreateShippingRule handles POST /admin/shipping-rules
+func (h *ShippingHandler) CreateShippingRule(c *gin.Context) {
+	var req ShippingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.shippingUseCase.CreateShippingRule(c.Request.Context(), req.toInput())
+	if err != nil {
+		respondShippingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateShippingRule handles PUT /admin/shipping-rules/:id
+func (h *ShippingHandler) UpdateShippingRule(c *gin.Context) {
+	var req ShippingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.shippingUseCase.UpdateShippingRule(c.Request.Context(), c.Param("id"), req.toInput())
+	if err != nil {
+		respondShippingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteShippingRule handles DELETE /admin/shipping-rules/:id
+func (h *ShippingHandler) DeleteShippingRule(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.shippingUseCase.DeleteShippingRule(c.Request.Context(), id); err != nil {
+		respondShippingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Shipping rule deleted",
+		"id":      id,
+	})
+}
+
+// respondShippingError maps shipping use case errors to HTTP responses
+func respondShippingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, interactor.ErrAdminRequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), "shipping rule not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
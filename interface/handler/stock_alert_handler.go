@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/interactor"
+	"github.com/gin-gonic/gin"
+)
+
+// StockAlertHandler handles HTTP requests for reorder points and low-stock alerts
+type StockAlertHandler struct {
+	stockAlertUseCase *interactor.StockAlertUseCase
+}
+
+// NewStockAlertHandler creates a new stock alert handler
+func NewStockAlertHandler(stockAlertUseCase *interactor.StockAlertUseCase) *StockAlertHandler {
+	return &StockAlertHandler{
+		stockAlertUseCase: stockAlertUseCase,
+	}
+}
+
+// SetReorderPointRequest represents the request body for setting a reorder point.
+// An empty warehouse ID sets the product's default for every warehouse.
+type SetReorderPointRequest struct {
+	ProductID   string `json:"product_id" binding:"required"`
+	WarehouseID string `json:"warehouse_id"`
+	Threshold   *int   `json:"threshold" binding:"required,min=0"`
+}
+
+// ListReorderPoints handles GET /admin/reorder-points
+func (h *StockAlertHandler) ListReorderPoints(c *gin.Context) {
+	reorderPoints, err := h.stockAlertUseCase.ListReorderPoints(c.Request.Context())
+	if err != nil {
+		respondStockAlertError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reorder_points": reorderPoints,
+		"count":          len(reorderPoints),
+	})
+}
+
+// SetReorderPoint handles PUT /admin/reorder-points
+func (h *StockAlertHandler) SetReorderPoint(c *gin.Context) {
+	var req SetReorderPointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reorderPoint, err := h.stockAlertUseCase.SetReorderPoint(c.Request.Context(), req.ProductID, req.WarehouseID, *req.Threshold)
+	if err != nil {
+		respondStockAlertError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reorderPoint)
+}
+
+// DeleteReorderPoint handles DELETE /admin/reorder-points/:product_id?warehouse_id=
+func (h *StockAlertHandler) DeleteReorderPoint(c *gin.Context) {
+	productID := c.Param("product_id")
+	warehouseID := c.Query("warehouse_id")
+	if err := h.stockAlertUseCase.DeleteReorderPoint(c.Request.Context(), productID, warehouseID); err != nil {
+		respondStockAlertError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Reorder point deleted",
+		"product_id":   productID,
+		"warehouse_id": warehouseID,
+	})
+}
+
+// ListLowStock handles GET /admin/low-stock
+func (h *StockAlertHandler) ListLowStock(c *gin.Context) {
+	alerts, err := h.stockAlertUseCase.ListLowStock(c.Request.Context())
+	if err != nil {
+		respondStockAlertError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": alerts,
+		"count": len(alerts),
+	})
+}
+
+// respondStockAlertError maps stock alert use case errors to HTTP responses
+func respondStockAlertError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, interactor.ErrAdminRequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), "reorder point not found"),
+		strings.HasPrefix(err.Error(), "warehouse not found"),
+		strings.HasPrefix(err.Error(), "product not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/interactor"
+	"github.com/gin-gonic/gin"
+)
+
+// StockTransferHandler handles HTTP requests for transfers between warehouses
+type StockTransferHandler struct {
+	transferUseCase *interactor.StockTransferUseCase
+}
+
+// NewStockTransferHandler creates a new stock transfer handler
+func NewStockTransferHandler(transferUseCase *interactor.StockTransferUseCase) *StockTransferHandler {
+	return &StockTransferHandler{
+		transferUseCase: transferUseCase,
+	}
+}
+
+// RequestTransferRequest represents the request body for requesting a stock transfer
+type RequestTransferRequest struct {
+	ProductID       string `json:"product_id" binding:"required"`
+	FromWarehouseID string `json:"from_warehouse_id" binding:"required"`
+	ToWarehouseID   string `json:"to_warehouse_id" binding:"required"`
+	Quantity        int    `json:"quantity" binding:"required,min=1"`
+	Note            string `json:"note"`
+}
+
+// ListTransfers handles GET /admin/stock-transfers?status=
+func (h *StockTransferHandler) ListTransfers(c *gin.Context) {
+	transfers, err := h.transferUseCase.ListTransfers(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		respondStockTransferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"transfers": transfers,
+		"count":     len(transfers),
+	})
+}
+
+// RequestTransfer handles POST /admin/stock-transfers
+func (h *StockTransferHandler) RequestTransfer(c *gin.Context) {
+	var req RequestTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	transfer, err := h.transferUseCase.RequestTransfer(c.Request.Context(), interactor.RequestTransferInput{
+		ProductID:       req.ProductID,
+		FromWarehouseID: req.FromWarehouseID,
+		ToWarehouseID:   req.ToWarehouseID,
+		Quantity:        req.Quantity,
+		Note:            req.Note,
+	})
+	if err != nil {
+		respondStockTransferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, transfer)
+}
+
+// GetTransfer handles GET /admin/stock-transfers/:id
+func (h *StockTransferHandler) GetTransfer(c *gin.Context) {
+	transfer, err := h.transferUseCase.GetTransfer(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondStockTransferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// ShipTransfer handles POST /admin/stock-transfers/:id/ship
+func (h *StockTransferHandler) ShipTransfer(c *gin.Context) {
+	transfer, err := h.transferUseCase.ShipTransfer(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondStockTransferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// ReceiveTransfer handles POST /admin/stock-transfers/:id/receive
+func (h *StockTransferHandler) ReceiveTransfer(c *gin.Context) {
+	transfer, err := h.transferUseCase.ReceiveTransfer(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondStockTransferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// CancelTransfer handles POST /admin/stock-transfers/:id/cancel
+func (h *StockTransferHandler) CancelTransfer(c *gin.Context) {
+	transfer, err := h.transferUseCase.CancelTransfer(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondStockTransferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// respondStockTransferError maps stock transfer use case errors to HTTP responses
+func respondStockTransferError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, interactor.ErrAdminRequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrInvalidTransferTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), "stock transfer not found"),
+		strings.HasPrefix(err.Error(), "warehouse not found"),
+		strings.HasPrefix(err.Error(), "product not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
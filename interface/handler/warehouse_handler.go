@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/interactor"
+	"github.com/gin-gonic/gin"
+)
+
+// WarehouseHandler handles HTTP requests for warehouse and stock administration
+type WarehouseHandler struct {
+	warehouseUseCase *interactor.WarehouseUseCase
+}
+
+// NewWarehouseHandler creates a new warehouse handler
+func NewWarehouseHandler(warehouseUseCase *interactor.WarehouseUseCase) *WarehouseHandler {
+	return &WarehouseHandler{
+		warehouseUseCase: warehouseUseCase,
+	}
+}
+
+// CreateWarehouseRequest represents the request body for creating a warehouse
+type CreateWarehouseRequest struct {
+	ID       string `json:"id" binding:"required"` // e.g. WH-004
+	Name     string `json:"name" binding:"required"`
+	Location string `json:"location"` // e.g. 北海道札幌市; the leading prefecture is used for nearest-warehouse allocation
+}
+
+// UpdateWarehouseRequest represents the request body for replacing a warehouse's details
+type UpdateWarehouseRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Location string `json:"location"`
+}
+
+// SetStockRequest represents the request body for setting a stock level
+type SetStockRequest struct {
+	Quantity *int   `json:"quantity" binding:"required,min=0"`
+	Note     string `json:"note"`
+}
+
+// AdjustStockRequest represents the request body for adjusting a stock level
+type AdjustStockRequest struct {
+	Delta int                      `json:"delta" binding:"required"` // Positive to add units, negative to remove them
+	Type  entity.StockMovementType `json:"type"`                     // restock or adjustment (default)
+	Note  string                   `json:"note"`
+}
+
+// ListWarehouses handles GET /admin/warehouses
+func (h *WarehouseHandler) ListWarehouses(c *gin.Context) {
+	warehouses, err := h.warehouseUseCase.ListWarehouses(c.Request.Context())
+	if err != nil {
+		respondWarehouseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"warehouses": warehouses,
+		"count":      len(warehouses),
+	})
+}
+
+// CreateWarehouse handles POST /admin/warehouses
+func (h *WarehouseHandler) CreateWarehouse(c *gin.Context) {
+	var req CreateWarehouseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	warehouse, err := h.warehouseUseCase.CreateWarehouse(c.Request.Context(), req.ID, req.Name, req.Location)
+	if err != nil {
+		respondWarehouseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, warehouse)
+}
+
+// UpdateWarehouse handles PUT /admin/warehouses/:id
+func (h *WarehouseHandler) UpdateWarehouse(c *gin.Context) {
+	var req UpdateWarehouseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	warehouse, err := h.warehouseUseCase.UpdateWarehouse(c.Request.Context(), c.Param("id"), req.Name, req.Location)
+	if err != nil {
+		respondWarehouseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, warehouse)
+}
+
+// DeleteWarehouse handles DELETE /admin/warehouses/:id
+func (h *WarehouseHandler) DeleteWarehouse(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.warehouseUseCase.DeleteWarehouse(c.Request.Context(), id); err != nil {
+		respondWarehouseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Warehouse deleted",
+		"id":      id,
+	})
+}
+
+// ListWarehouseStock handles GET /admin/warehouses/:id/stocks
+func (h *WarehouseHandler) ListWarehouseStock(c *gin.Context) {
+	lines, err := h.warehouseUseCase.ListWarehouseStock(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondWarehouseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"warehouse_id": c.Param("id"),
+		"stocks":       lines,
+		"count":        len(lines),
+	})
+}
+
+// SetStock handles PUT /admin/warehouses/:id/stocks/:product_id
+func (h *WarehouseHandler) SetStock(c *gin.Context) {
+	var req SetStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	line, err := h.warehouseUseCase.SetStock(c.Request.Context(), c.Param("id"), c.Param("product_id"), *req.Quantity, req.Note)
+	if err != nil {
+		respondWarehouseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, line)
+}
+
+// AdjustStock handles POST /admin/warehouses/:id/stocks/:product_id/adjust
+func (h *WarehouseHandler) AdjustStock(c *gin.Context) {
+	var req AdjustStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Type == "" {
+		req.Type = entity.StockMovementAdjustment
+	}
+
+	line, err := h.warehouseUseCase.AdjustStock(c.Request.Context(), c.Param("id"), c.Param("product_id"), req.Delta, req.Type, req.Note)
+	if err != nil {
+		respondWarehouseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, line)
+}
+
+// ListStockMovements handles GET /admin/stock-movements?product_id=&warehouse_id=&order_id=
+func (h *WarehouseHandler) ListStockMovements(c *gin.Context) {
+	filter := repository.StockMovementFilter{
+		ProductID:   c.Query("product_id"),
+		WarehouseID: c.Query("warehouse_id"),
+		OrderID:     c.Query("order_id"),
+	}
+	movements, err := h.warehouseUseCase.ListStockMovements(c.Request.Context(), filter)
+	if err != nil {
+		respondWarehouseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"movements": movements,
+		"count":     len(movements),
+	})
+}
+
+// VerifyStockLedger handles GET /admin/stock-movements/verify
+func (h *WarehouseHandler) VerifyStockLedger(c *gin.Context) {
+	discrepancies, err := h.warehouseUseCase.VerifyStockLedger(c.Request.Context())
+	if err != nil {
+		respondWarehouseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"consistent":    len(discrepancies) == 0,
+		"discrepancies": discrepancies,
+	})
+}
+
+// respondWarehouseError maps warehouse use case errors to HTTP responses
+func respondWarehouseError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, interactor.ErrAdminRequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrWarehouseHasStock), errors.Is(err, interactor.ErrStockOnVariants):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), "warehouse not found"), strings.HasPrefix(err.Error(), "product not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/port"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header carrying the client's idempotency key
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed from an earlier request
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+
+	// keepIdempotencyKey is the gin context key set by KeepIdempotencyKey
+	keepIdempotencyKey = "idempotency.keep"
+)
+
+// KeepIdempotencyKey tells the idempotency middleware that the request had an effect that a
+// retry must not repeat, such as a payment that was charged and could not be refunded. Its
+// response is then stored even if it is a server error.
+func KeepIdempotencyKey(c *gin.Context) {
+	c.Set(keepIdempotencyKey, true)
+}
+
+// IdempotencyMiddleware answers requests repeated with the same Idempotency-Key with the first response
+type IdempotencyMiddleware struct {
+	store       port.IdempotencyStore
+	authService port.AuthService
+	ttl         time.Duration
+}
+
+// NewIdempotencyMiddleware creates a new idempotency middleware that remembers keys for ttl
+func NewIdempotencyMiddleware(store port.IdempotencyStore, authService port.AuthService, ttl time.Duration) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		store:       store,
+		authService: authService,
+		ttl:         ttl,
+	}
+}
+
+// Handle makes a route idempotent per user and must run after authentication.
+// Requests without the header pass through. A repeated request is answered with the stored
+// response; reusing a key for a different request, or while the first one is still running,
+// is rejected. Server errors are not stored, so such requests can be retried with the same key,
+// unless the handler called KeepIdempotencyKey because a retry would charge the customer again.
+func (m *IdempotencyMiddleware) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key is too long"})
+			c.Abort()
+			return
+		}
+
+		ctx := c.Request.Context()
+		user, err := m.authService.GetCurrentUser(ctx)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		now := time.Now()
+		hash := requestHash(c.Request.Method, c.Request.URL.Path, body)
+		existing, claimed, err := m.store.Begin(ctx, &port.IdempotencyRecord{
+			UserID:      user.ID,
+			Key:         key,
+			RequestHash: hash,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(m.ttl),
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check Idempotency-Key"})
+			c.Abort()
+			return
+		}
+
+		if !claimed {
+			switch {
+			case existing.RequestHash != hash:
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used for a different request"})
+			case !existing.IsCompleted():
+				c.JSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is still being processed"})
+			default:
+				c.Header(IdempotentReplayedHeader, "true")
+				c.Data(existing.StatusCode, existing.ContentType, existing.Body)
+			}
+			c.Abort()
+			return
+		}
+
+		// The outcome is stored even if the client has gone away in the meantime
+		storeCtx := context.WithoutCancel(ctx)
+		stored := false
+		defer func() {
+			// Free the key if the handler panicked or failed, so it can be retried
+			if !stored {
+				if err := m.store.Release(storeCtx, user.ID, key); err != nil {
+					log.Printf("Failed to release idempotency key %q: %v", key, err)
+				}
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError && !c.GetBool(keepIdempotencyKey) {
+			return
+		}
+		err = m.store.Complete(storeCtx, user.ID, key, status, recorder.Header().Get("Content-Type"), recorder.body.Bytes())
+		if err != nil {
+			log.Printf("Failed to store response for idempotency key %q: %v", key, err)
+			return
+		}
+		stored = true
+	}
+}
+
+// requestHash fingerprints a request so a reused key can be matched against the original request
+func requestHash(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder copies everything written to the response so it can be stored
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/persistence"
+	"github.com/gin-gonic/gin"
+)
+
+// stubAuthService treats the X-User header as the authenticated user
+type stubAuthService struct{}
+
+type stubUserKey struct{}
+
+func (stubAuthService) GenerateToken(user *entity.User) (string, error) { return "", nil }
+func (stubAuthService) ValidateToken(token string) (string, error)      { return "", nil }
+func (stubAuthService) GetCurrentUser(ctx context.Context) (*entity.User, error) {
+	id, _ := ctx.Value(stubUserKey{}).(string)
+	if id == "" {
+		return nil, errors.New("user not found in context")
+	}
+	return &entity.User{ID: id}, nil
+}
+
+// newIdempotentRouter serves order placement and cart checkout with a handler that counts its
+// calls as charges and answers with status, keeping the key when unrefunded is set
+func newIdempotentRouter(status *int, calls *int, unrefunded *bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	m := NewIdempotencyMiddleware(persistence.NewMemoryIdempotencyStore(), stubAuthService{}, time.Hour)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), stubUserKey{}, c.GetHeader("X-User"))
+		c.Request = c.Request.WithContext(ctx)
+	})
+	placeOrder := func(c *gin.Context) {
+		*calls++
+		if *unrefunded {
+			KeepIdempotencyKey(c)
+		}
+		c.JSON(*status, gin.H{"call": *calls})
+	}
+	r.POST("/orders", m.Handle(), placeOrder)
+	r.POST("/cart/checkout", m.Handle(), placeOrder)
+	return r
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	type request struct {
+		user           string
+		key            string
+		body           string
+		expectedStatus int
+		expectedBody   string
+		replayed       bool
+	}
+
+	tests := []struct {
+		name          string
+		handlerStatus int
+		requests      []request
+		expectedCalls int
+	}{
+		{
+			name:          "repeated request replays the first response",
+			handlerStatus: http.StatusCreated,
+			requests: []request{
+				{"USER-001", "key-1", `{"a":1}`, http.StatusCreated, `{"call":1}`, false},
+				{"USER-001", "key-1", `{"a":1}`, http.StatusCreated, `{"call":1}`, true},
+			},
+			expectedCalls: 1,
+		},
+		{
+			name:          "different payload with the same key is rejected",
+			handlerStatus: http.StatusCreated,
+			requests: []request{
+				{"USER-001", "key-1", `{"a":1}`, http.StatusCreated, `{"call":1}`, false},
+				{"USER-001", "key-1", `{"a":2}`, http.StatusUnprocessableEntity, "", false},
+			},
+			expectedCalls: 1,
+		},
+		{
+			name:          "keys are scoped per user",
+			handlerStatus: http.StatusCreated,
+			requests: []request{
+				{"USER-001", "key-1", `{"a":1}`, http.StatusCreated, `{"call":1}`, false},
+				{"USER-002", "key-1", `{"a":1}`, http.StatusCreated, `{"call":2}`, false},
+			},
+			expectedCalls: 2,
+		},
+		{
+			name:          "requests without a key are not deduplicated",
+			handlerStatus: http.StatusCreated,
+			requests: []request{
+				{"USER-001", "", `{"a":1}`, http.StatusCreated, `{"call":1}`, false},
+				{"USER-001", "", `{"a":1}`, http.StatusCreated, `{"call":2}`, false},
+			},
+			expectedCalls: 2,
+		},
+		{
+			name:          "client errors are replayed",
+			handlerStatus: http.StatusBadRequest,
+			requests: []request{
+				{"USER-001", "key-1", `{"a":1}`, http.StatusBadRequest, `{"call":1}`, false},
+				{"USER-001", "key-1", `{"a":1}`, http.StatusBadRequest, `{"call":1}`, true},
+			},
+			expectedCalls: 1,
+		},
+		{
+			name:          "server errors can be retried with the same key",
+			handlerStatus: http.StatusInternalServerError,
+			requests: []request{
+				{"USER-001", "key-1", `{"a":1}`, http.StatusInternalServerError, `{"call":1}`, false},
+				{"USER-001", "key-1", `{"a":1}`, http.StatusInternalServerError, `{"call":2}`, false},
+			},
+			expectedCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, calls, unrefunded := tt.handlerStatus, 0, false
+			r := newIdempotentRouter(&status, &calls, &unrefunded)
+
+			for i, req := range tt.requests {
+				httpReq := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(req.body))
+				httpReq.Header.Set("X-User", req.user)
+				if req.key != "" {
+					httpReq.Header.Set(IdempotencyKeyHeader, req.key)
+				}
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, httpReq)
+
+				if w.Code != req.expectedStatus {
+					t.Errorf("Request %d: expected status %d, got %d", i, req.expectedStatus, w.Code)
+				}
+				if req.expectedBody != "" && w.Body.String() != req.expectedBody {
+					t.Errorf("Request %d: expected body %s, got %s", i, req.expectedBody, w.Body.String())
+				}
+				if replayed := w.Header().Get(IdempotentReplayedHeader) == "true"; replayed != req.replayed {
+					t.Errorf("Request %d: expected replayed=%v, got %v", i, req.replayed, replayed)
+				}
+			}
+
+			if calls != tt.expectedCalls {
+				t.Errorf("Expected the handler to run %d times, got %d", tt.expectedCalls, calls)
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddleware_ServerErrorFreesKey(t *testing.T) {
+	status, calls, unrefunded := http.StatusServiceUnavailable, 0, false
+	r := newIdempotentRouter(&status, &calls, &unrefunded)
+	sendTo := func(path, key string) *httptest.ResponseRecorder {
+		httpReq := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"a":1}`))
+		httpReq.Header.Set("X-User", "USER-001")
+		httpReq.Header.Set(IdempotencyKeyHeader, key)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httpReq)
+		return w
+	}
+	send := func() *httptest.ResponseRecorder { return sendTo("/orders", "key-1") }
+
+	// The payment gateway is down, so the order fails without the key being stored
+	if w := send(); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	// Once it is back the retry places the order, and that response is what gets replayed
+	status = http.StatusCreated
+	if w := send(); w.Code != http.StatusCreated || w.Body.String() != `{"call":2}` {
+		t.Fatalf("Expected the retry to run the handler again, got %d %s", w.Code, w.Body.String())
+	}
+	w := send()
+	if w.Code != http.StatusCreated || w.Body.String() != `{"call":2}` || w.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("Expected the successful response to be replayed, got %d %s", w.Code, w.Body.String())
+	}
+	if calls != 2 {
+		t.Errorf("Expected the handler to run 2 times, got %d", calls)
+	}
+
+	// A charge that could not be refunded keeps the key, so a retry is not charged again
+	status, unrefunded = http.StatusInternalServerError, true
+	for i, path := range []string{"/orders", "/cart/checkout"} {
+		key := "unrefunded-" + path
+		first := sendTo(path, key)
+		if first.Code != http.StatusInternalServerError {
+			t.Fatalf("%s: expected status %d, got %d", path, http.StatusInternalServerError, first.Code)
+		}
+		replay := sendTo(path, key)
+		if replay.Code != http.StatusInternalServerError || replay.Body.String() != first.Body.String() || replay.Header().Get(IdempotentReplayedHeader) != "true" {
+			t.Errorf("%s: expected the failed response to be replayed, got %d %s", path, replay.Code, replay.Body.String())
+		}
+		if want := 3 + i; calls != want {
+			t.Errorf("%s: expected %d charges, got %d", path, want, calls)
+		}
+	}
+}
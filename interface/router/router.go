@@ -26,6 +26,7 @@ func NewRouter(container *di.Container) *gin.Engine {
 
 			// Product routes (read-only for public, with optional authentication)
 			public.GET("/products", container.AuthMiddleware.OptionalAuthenticate(), container.ProductHandler.ListProducts)
+			public.GET("/products/search", container.AuthMiddleware.OptionalAuthenticate(), container.ProductHandler.SearchProducts)
 			public.GET("/products/:id", container.AuthMiddleware.OptionalAuthenticate(), container.ProductHandler.GetProduct)
 		}
 
@@ -37,18 +38,36 @@ func NewRouter(container *di.Container) *gin.Engine {
 			protected.GET("/users/:id", container.UserHandler.GetProfile)
 
 			// Order routes
-			protected.POST("/orders", container.OrderHandler.CreateOrder)
+			protected.POST("/orders", container.IdempotencyMiddleware.Handle(), container.OrderHandler.CreateOrder)
+			protected.POST("/orders/quote", container.OrderHandler.QuoteOrder)
 			protected.GET("/orders", container.OrderHandler.ListUserOrders)
 			protected.GET("/orders/:id", container.OrderHandler.GetOrder)
+			protected.POST("/orders/:id/cancel", container.OrderHandler.CancelOrder)
 
 			// Wishlist routes
 			protected.POST("/wishlist/:product_id", container.WishlistHandler.AddToWishlist)
 			protected.DELETE("/wishlist/:product_id", container.WishlistHandler.RemoveFromWishlist)
 			protected.GET("/wishlist", container.WishlistHandler.GetMyWishlist)
 
+			// Cart routes
+			protected.GET("/cart", container.CartHandler.GetCart)
+			protected.POST("/cart/items", container.CartHandler.AddItem)
+			protected.PUT("/cart/items/:product_id", container.CartHandler.UpdateItem)
+			protected.DELETE("/cart/items/:product_id", container.CartHandler.RemoveItem)
+			protected.PUT("/cart/coupon", container.CartHandler.ApplyCoupon)
+			protected.DELETE("/cart/coupon", container.CartHandler.RemoveCoupon)
+			protected.POST("/cart/checkout", container.IdempotencyMiddleware.Handle(), container.CartHandler.Checkout)
+
 			// Recommendations
 			protected.GET("/users/me/recommendations", container.WishlistHandler.GetRecommendations)
 
+			// Address book
+			protected.GET("/users/me/addresses", container.AddressHandler.ListAddresses)
+			protected.POST("/users/me/addresses", container.AddressHandler.AddAddress)
+			protected.PUT("/users/me/addresses/:id", container.AddressHandler.UpdateAddress)
+			protected.DELETE("/users/me/addresses/:id", container.AddressHandler.DeleteAddress)
+			protected.POST("/users/me/addresses/:id/default", container.AddressHandler.SetDefaultAddress)
+
 			// Admin-only routes
 			admin := protected.Group("")
 			admin.Use(container.AuthMiddleware.RequireAdmin())
@@ -65,6 +84,56 @@ func NewRouter(container *di.Container) *gin.Engine {
 		{
 			// Reports
 			admin.GET("/reports/sales", container.AdminHandler.GetSalesReport)
+
+			// Product management
+			admin.GET("/products/archived", container.ProductHandler.ListArchivedProducts)
+			admin.POST("/products/cleanup", container.ProductHandler.CleanupCatalog)
+			admin.PATCH("/products/:id", container.ProductHandler.UpdateProduct)
+			admin.DELETE("/products/:id", container.ProductHandler.ArchiveProduct)
+			admin.POST("/products/:id/restore", container.ProductHandler.RestoreProduct)
+			admin.POST("/products/:id/variants", container.ProductHandler.CreateVariant)
+			admin.PUT("/products/:id/backorder", container.ProductHandler.SetBackorder)
+			admin.GET("/products/:id/price-history", container.ProductHandler.GetPriceHistory)
+			admin.GET("/products/:id/sales", container.ProductHandler.ListSales)
+			admin.POST("/products/:id/sales", container.ProductHandler.ScheduleSale)
+			admin.POST("/products/:id/sales/:sale_id/cancel", container.ProductHandler.CancelSale)
+			admin.GET("/products/export", container.CatalogHandler.ExportProducts)
+			admin.POST("/products/import", container.CatalogHandler.ImportProducts)
+			admin.GET("/stocks/export", container.CatalogHandler.ExportStock)
+			admin.POST("/stocks/import", container.CatalogHandler.ImportStock)
+
+			// Order management
+			admin.GET("/orders", container.OrderHandler.SearchOrders)
+			admin.GET("/orders/:id", container.OrderHandler.GetOrder)
+			admin.POST("/orders/:id/cancel", container.OrderHandler.CancelOrder)
+			admin.POST("/orders/:id/status", container.OrderHandler.UpdateOrderStatus)
+
+			// Shipping rules
+			admin.GET("/shipping-rules", container.ShippingHandler.ListShippingRules)
+			admin.POST("/shipping-rules", container.ShippingHandler.CreateShippingRule)
+			admin.PUT("/shipping-rules/:id", container.ShippingHandler.UpdateShippingRule)
+			admin.DELETE("/shipping-rules/:id", container.ShippingHandler.DeleteShippingRule)
+
+			// Warehouses and stock
+			admin.GET("/warehouses", container.WarehouseHandler.ListWarehouses)
+			admin.POST("/warehouses", container.WarehouseHandler.CreateWarehouse)
+			admin.PUT("/warehouses/:id", container.WarehouseHandler.UpdateWarehouse)
+			admin.DELETE("/warehouses/:id", container.WarehouseHandler.DeleteWarehouse)
+			admin.GET("/warehouses/:id/stocks", container.WarehouseHandler.ListWarehouseStock)
+			admin.PUT("/warehouses/:id/stocks/:product_id", container.WarehouseHandler.SetStock)
+			admin.POST("/warehouses/:id/stocks/:product_id/adjust", container.WarehouseHandler.AdjustStock)
+			admin.GET("/stock-movements", container.WarehouseHandler.ListStockMovements)
+			admin.GET("/stock-movements/verify", container.WarehouseHandler.VerifyStockLedger)
+			admin.GET("/stock-transfers", container.StockTransferHandler.ListTransfers)
+			admin.POST("/stock-transfers", container.StockTransferHandler.RequestTransfer)
+			admin.GET("/stock-transfers/:id", container.StockTransferHandler.GetTransfer)
+			admin.POST("/stock-transfers/:id/ship", container.StockTransferHandler.ShipTransfer)
+			admin.POST("/stock-transfers/:id/receive", container.StockTransferHandler.ReceiveTransfer)
+			admin.POST("/stock-transfers/:id/cancel", container.StockTransferHandler.CancelTransfer)
+			admin.GET("/reorder-points", container.StockAlertHandler.ListReorderPoints)
+			admin.PUT("/reorder-points", container.StockAlertHandler.SetReorderPoint)
+			admin.DELETE("/reorder-points/:product_id", container.StockAlertHandler.DeleteReorderPoint)
+			admin.GET("/low-stock", container.StockAlertHandler.ListLowStock)
 		}
 	}
 
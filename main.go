@@ -1,16 +1,32 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 
 	"github.com/gal1996/vibe_coding_with_architecture/di"
+	"github.com/gal1996/vibe_coding_with_architecture/interface/cli"
 	"github.com/gal1996/vibe_coding_with_architecture/interface/router"
 )
 
 func main() {
+	// Run a command line tool instead of the server when one is named
+	if len(os.Args) > 1 {
+		os.Exit(cli.Run(os.Args[1:]))
+	}
+
 	// Initialize dependency injection container
-	container := di.NewContainer()
+	cfg, err := di.LoadConfigFromEnv()
+	if err != nil {
+		log.Fatal("Invalid configuration:", err)
+	}
+	container, err := di.NewContainer(cfg)
+	if err != nil {
+		log.Fatal("Failed to initialize container:", err)
+	}
+	defer container.Close()
+	log.Printf("Using %s storage", cfg.StorageDriver)
 
 	// Seed test data
 	if err := container.SeedTestData(); err != nil {
@@ -22,6 +38,14 @@ func main() {
 		log.Println("  User:  username=user, password=user123")
 	}
 
+	// Release stock held by orders that were never paid, forget expired idempotency keys
+	// and alert on stock that runs low
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	container.StartReservationSweeper(ctx)
+	container.StartIdempotencyKeySweeper(ctx)
+	container.StartStockAlertChecker(ctx)
+
 	// Create router
 	r := router.NewRouter(container)
 
@@ -44,4 +68,4 @@ func main() {
 	if err := r.Run(":" + port); err != nil {
 		log.Fatal("Failed to start server:", err)
 	}
-}
\ No newline at end of file
+}
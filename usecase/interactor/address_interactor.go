@@ -0,0 +1,80 @@
+package interactor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/port"
+)
+
+// AddressUseCase handles the current user's address book
+type AddressUseCase struct {
+	addressService *service.AddressService
+	authService    port.AuthService
+}
+
+// NewAddressUseCase creates a new address use case
+func NewAddressUseCase(
+	addressService *service.AddressService,
+	authService port.AuthService,
+) *AddressUseCase {
+	return &AddressUseCase{
+		addressService: addressService,
+		authService:    authService,
+	}
+}
+
+// ListAddresses returns the current user's addresses
+func (uc *AddressUseCase) ListAddresses(ctx context.Context) ([]*entity.Address, error) {
+	userID, err := uc.currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return uc.addressService.ListAddresses(ctx, userID)
+}
+
+// AddAddress adds an address to the current user's address book
+func (uc *AddressUseCase) AddAddress(ctx context.Context, input entity.AddressFields) (*entity.Address, error) {
+	userID, err := uc.currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return uc.addressService.AddAddress(ctx, userID, input)
+}
+
+// UpdateAddress replaces one of the current user's addresses
+func (uc *AddressUseCase) UpdateAddress(ctx context.Context, addressID string, input entity.AddressFields) (*entity.Address, error) {
+	userID, err := uc.currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return uc.addressService.UpdateAddress(ctx, userID, addressID, input)
+}
+
+// DeleteAddress removes one of the current user's addresses
+func (uc *AddressUseCase) DeleteAddress(ctx context.Context, addressID string) error {
+	userID, err := uc.currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+	return uc.addressService.DeleteAddress(ctx, userID, addressID)
+}
+
+// SetDefaultAddress makes one of the current user's addresses the default
+func (uc *AddressUseCase) SetDefaultAddress(ctx context.Context, addressID string) (*entity.Address, error) {
+	userID, err := uc.currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return uc.addressService.SetDefaultAddress(ctx, userID, addressID)
+}
+
+func (uc *AddressUseCase) currentUserID(ctx context.Context) (string, error) {
+	currentUser, err := uc.authService.GetCurrentUser(ctx)
+	if err != nil {
+		return "", fmt.Errorf("authentication required: %w", err)
+	}
+	return currentUser.ID, nil
+}
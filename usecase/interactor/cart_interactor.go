@@ -0,0 +1,153 @@
+package interactor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/port"
+)
+
+// ErrCartEmpty is returned when checking out a cart without lines
+var ErrCartEmpty = errors.New("cart is empty")
+
+// ErrCartUnavailable is returned when checking out a cart whose products are all no longer sold
+var ErrCartUnavailable = errors.New("no product in the cart is sold any more")
+
+// CartUseCase handles shopping cart business logic
+type CartUseCase struct {
+	cartService  *service.CartService
+	orderUseCase *OrderUseCase
+	authService  port.AuthService
+}
+
+// NewCartUseCase creates a new cart use case
+func NewCartUseCase(
+	cartService *service.CartService,
+	orderUseCase *OrderUseCase,
+	authService port.AuthService,
+) *CartUseCase {
+	return &CartUseCase{
+		cartService:  cartService,
+		orderUseCase: orderUseCase,
+		authService:  authService,
+	}
+}
+
+// CartOutput is a cart together with its price at the current product prices and stock.
+// Quote is nil for an empty cart.
+type CartOutput struct {
+	*entity.Cart
+	Quote *service.OrderQuote `json:"quote"`
+}
+
+// GetCart gets the current user's cart
+func (uc *CartUseCase) GetCart(ctx context.Context) (*CartOutput, error) {
+	return uc.withCart(ctx, func(userID string) (*entity.Cart, error) {
+		return uc.cartService.GetCart(ctx, userID)
+	})
+}
+
+// AddItem adds a product to the current user's cart
+func (uc *CartUseCase) AddItem(ctx context.Context, productID string, quantity int) (*CartOutput, error) {
+	return uc.withCart(ctx, func(userID string) (*entity.Cart, error) {
+		return uc.cartService.AddItem(ctx, userID, productID, quantity)
+	})
+}
+
+// UpdateItem sets the quantity of a product in the current user's cart
+func (uc *CartUseCase) UpdateItem(ctx context.Context, productID string, quantity int) (*CartOutput, error) {
+	return uc.withCart(ctx, func(userID string) (*entity.Cart, error) {
+		return uc.cartService.UpdateItem(ctx, userID, productID, quantity)
+	})
+}
+
+// RemoveItem removes a product from the current user's cart
+func (uc *CartUseCase) RemoveItem(ctx context.Context, productID string) (*CartOutput, error) {
+	return uc.withCart(ctx, func(userID string) (*entity.Cart, error) {
+		return uc.cartService.RemoveItem(ctx, userID, productID)
+	})
+}
+
+// ApplyCoupon sets the coupon of the current user's cart
+func (uc *CartUseCase) ApplyCoupon(ctx context.Context, code string) (*CartOutput, error) {
+	return uc.withCart(ctx, func(userID string) (*entity.Cart, error) {
+		return uc.cartService.ApplyCoupon(ctx, userID, code)
+	})
+}
+
+// RemoveCoupon removes the coupon from the current user's cart
+func (uc *CartUseCase) RemoveCoupon(ctx context.Context) (*CartOutput, error) {
+	return uc.withCart(ctx, func(userID string) (*entity.Cart, error) {
+		return uc.cartService.RemoveCoupon(ctx, userID)
+	})
+}
+
+// Checkout turns the current user's cart into an order shipped to the chosen address, or to the
+// default address when addressID is empty. The cart is cleared only when the order is created
+// and paid; otherwise it is left as it was so the user can retry. Lines whose product is no
+// longer sold are left out of the order and cleared with the rest.
+func (uc *CartUseCase) Checkout(ctx context.Context, addressID string) (*entity.Order, error) {
+	// Get current user
+	currentUser, err := uc.authService.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("authentication required: %w", err)
+	}
+
+	cart, err := uc.cartService.GetCart(ctx, currentUser.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+	if cart.IsEmpty() {
+		return nil, ErrCartEmpty
+	}
+
+	// Lines quoted as unavailable are not ordered, so the order matches the cart's quote
+	items, err := uc.cartService.OrderableItems(ctx, cart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check cart items: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, ErrCartUnavailable
+	}
+
+	input := CreateOrderInput{
+		Items:      make([]OrderItemInput, len(items)),
+		CouponCode: cart.CouponCode,
+		AddressID:  addressID,
+	}
+	for i, item := range items {
+		input.Items[i] = OrderItemInput{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+
+	order, err := uc.orderUseCase.CreateOrder(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// The order is already paid, so failing to clear the cart must not fail the checkout
+	uc.cartService.ClearCart(ctx, currentUser.ID)
+	return order, nil
+}
+
+// withCart runs fn for the current user and prices the cart it returns
+func (uc *CartUseCase) withCart(ctx context.Context, fn func(userID string) (*entity.Cart, error)) (*CartOutput, error) {
+	// Get current user
+	currentUser, err := uc.authService.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("authentication required: %w", err)
+	}
+
+	cart, err := fn(currentUser.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := uc.cartService.PriceCart(ctx, cart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to price cart: %w", err)
+	}
+	return &CartOutput{Cart: cart, Quote: quote}, nil
+}
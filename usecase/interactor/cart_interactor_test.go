@@ -0,0 +1,78 @@
+package interactor_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/auth"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/persistence"
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/interactor"
+)
+
+func TestCartUseCase_ArchivedProductsAreUnavailable(t *testing.T) {
+	f, ctx := newOrderFixture(t)
+	cartService := service.NewCartService(persistence.NewMemoryCartRepository(), f.productRepo, f.orderService, f.couponService, f.addressService)
+	carts := interactor.NewCartUseCase(cartService, f.orders, auth.NewJWTAuthService(persistence.NewMemoryUserRepository()))
+
+	for _, product := range []struct {
+		id, name string
+		price    int
+	}{{"P002", "Lamp", 80}, {"P003", "Chair", 150}} {
+		p, _ := entity.NewProduct(product.id, product.name, product.price, "Furniture")
+		f.productRepo.Create(ctx, p)
+		f.stockService.AdjustStockLevel(ctx, product.id, "WH-001", 1, service.MovementRef{Type: entity.StockMovementRestock})
+	}
+	for _, id := range []string{"P001", "P002", "P003"} {
+		if _, err := carts.AddItem(ctx, id, 1); err != nil {
+			t.Fatalf("AddItem failed: %v", err)
+		}
+	}
+
+	// The desk and the chair are taken off sale while they sit in the cart
+	products := service.NewProductService(f.productRepo, persistence.NewMemoryWishlistRepository(), f.warehouseRepo, f.stockService)
+	for _, id := range []string{"P001", "P003"} {
+		if _, err := products.Archive(ctx, id); err != nil {
+			t.Fatalf("Archive failed: %v", err)
+		}
+	}
+
+	cart, err := carts.GetCart(ctx)
+	if err != nil {
+		t.Fatalf("GetCart failed: %v", err)
+	}
+	lines := cart.Quote.Lines
+	if len(lines) != 3 || !lines[0].Unavailable || lines[1].Unavailable || !lines[2].Unavailable || lines[0].ProductName != "Desk" {
+		t.Fatalf("Expected the desk and chair to be unavailable, got %+v", lines)
+	}
+	if cart.Quote.Subtotal != 80 {
+		t.Errorf("Expected only the lamp to be priced, got subtotal %d", cart.Quote.Subtotal)
+	}
+
+	cart, err = carts.RemoveItem(ctx, "P003")
+	if err != nil {
+		t.Fatalf("RemoveItem failed: %v", err)
+	}
+	if len(cart.Items) != 2 {
+		t.Errorf("Expected 2 lines left, got %+v", cart.Items)
+	}
+
+	order, err := carts.Checkout(ctx, "")
+	if err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	if len(order.Items) != 1 || order.Items[0].ProductID != "P002" {
+		t.Errorf("Expected only the lamp to be ordered, got %+v", order.Items)
+	}
+	if cart, _ := carts.GetCart(ctx); !cart.IsEmpty() {
+		t.Errorf("Expected the cart to be cleared, got %+v", cart.Items)
+	}
+
+	// A cart with nothing left on sale cannot be checked out
+	carts.AddItem(ctx, "P002", 1)
+	products.Archive(ctx, "P002")
+	if _, err := carts.Checkout(ctx, ""); !errors.Is(err, interactor.ErrCartUnavailable) {
+		t.Errorf("Expected ErrCartUnavailable, got %v", err)
+	}
+}
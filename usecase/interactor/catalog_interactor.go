@@ -0,0 +1,633 @@
+package interactor
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/port"
+)
+
+// Columns of the product and stock CSV files, in the order they are exported
+var (
+	productCSVColumns = []string{"id", "sku", "name", "price", "category", "weight", "backorder", "expected_available_at"}
+	stockCSVColumns   = []string{"product_id", "sku", "warehouse_id", "quantity"}
+)
+
+// utf8BOM is the byte order mark Excel writes at the start of UTF-8 CSV files
+const utf8BOM = "\ufeff"
+
+// CatalogUseCase imports and exports products and warehouse stock as CSV files
+type CatalogUseCase struct {
+	productRepo      repository.ProductRepository
+	stockService     *service.StockService
+	warehouseService *service.WarehouseService
+	pricingService   *service.PricingService
+	authService      port.AuthService
+}
+
+// NewCatalogUseCase creates a new catalog use case
+func NewCatalogUseCase(
+	productRepo repository.ProductRepository,
+	stockService *service.StockService,
+	warehouseService *service.WarehouseService,
+	pricingService *service.PricingService,
+	authService port.AuthService,
+) *CatalogUseCase {
+	return &CatalogUseCase{
+		productRepo:      productRepo,
+		stockService:     stockService,
+		warehouseService: warehouseService,
+		pricingService:   pricingService,
+		authService:      authService,
+	}
+}
+
+// ImportRowError is a problem with one row of an imported CSV file
+type ImportRowError struct {
+	Line    int    `json:"line"`             // Line the row starts on; the header is line 1
+	Column  string `json:"column,omitempty"` // Column at fault, if a single one is
+	Message string `json:"message"`
+}
+
+// ImportReport describes what an import changed, or would change on a dry run
+type ImportReport struct {
+	DryRun    bool             `json:"dry_run"`
+	Rows      int              `json:"rows"` // Data rows read, not counting the header
+	Created   int              `json:"created"`
+	Updated   int              `json:"updated"`
+	Unchanged int              `json:"unchanged"`
+	Errors    []ImportRowError `json:"errors"`
+}
+
+// importAction is what importing a valid row does
+type importAction int
+
+const (
+	importCreate importAction = iota
+	importUpdate
+	importUnchanged
+)
+
+// count adds a row with the given action to the report's totals
+func (r *ImportReport) count(action importAction) {
+	switch action {
+	case importCreate:
+		r.Created++
+	case importUpdate:
+		r.Updated++
+	default:
+		r.Unchanged++
+	}
+}
+
+// ImportProductsCSV creates or updates products from a CSV file (admin only).
+// Rows are matched to products by id, or by sku when id is empty; unmatched rows create
+// products, which need name, price and category. Columns missing from the file keep their
+// current values and new prices are recorded in the price history. Nothing is changed on a dry
+// run or when any row is invalid. Valid rows are saved one at a time, so when saving a row fails
+// the rows saved before it stay saved; the failed rows are reported with the others.
+func (uc *CatalogUseCase) ImportProductsCSV(ctx context.Context, r io.Reader, dryRun bool) (*ImportReport, error) {
+	admin, err := currentAdmin(ctx, uc.authService)
+	if err != nil {
+		return nil, err
+	}
+	table, err := readCSV(r, productCSVColumns)
+	if err != nil {
+		return nil, err
+	}
+	if !table.has("id") && !table.has("sku") {
+		return nil, errors.New("CSV needs an id or sku column")
+	}
+
+	type change struct {
+		line    int
+		product *entity.Product
+		action  importAction
+	}
+	var changes []change
+	report := &ImportReport{DryRun: dryRun, Rows: len(table.rows), Errors: []ImportRowError{}}
+	lineOfID := make(map[string]int)
+	lineOfSKU := make(map[string]int)
+	for _, row := range table.rows {
+		rowErr := table.rowError(row)
+		var product *entity.Product
+		var action importAction
+		if rowErr == nil {
+			product, action, rowErr = uc.productFromRow(ctx, table, row)
+		}
+		if rowErr == nil {
+			if line, ok := lineOfID[product.ID]; ok {
+				rowErr = &ImportRowError{Line: row.line, Column: "id", Message: fmt.Sprintf("product %s is already in line %d", product.ID, line)}
+			} else if line, ok := lineOfSKU[product.SKU]; ok && product.SKU != "" {
+				rowErr = &ImportRowError{Line: row.line, Column: "sku", Message: fmt.Sprintf("SKU %s is already in line %d", product.SKU, line)}
+			}
+		}
+		if rowErr != nil {
+			report.Errors = append(report.Errors, *rowErr)
+			continue
+		}
+		lineOfID[product.ID] = row.line
+		if product.SKU != "" {
+			lineOfSKU[product.SKU] = row.line
+		}
+		changes = append(changes, change{line: row.line, product: product, action: action})
+	}
+
+	if dryRun || len(report.Errors) > 0 {
+		for _, c := range changes {
+			report.count(c.action)
+		}
+		return report, nil
+	}
+	for _, c := range changes {
+		switch c.action {
+		case importCreate:
+			err = uc.productRepo.Create(ctx, c.product)
+		case importUpdate:
+			err = uc.updateProduct(ctx, c.product, admin.ID)
+		default:
+			err = nil
+		}
+		if err != nil {
+			report.Errors = append(report.Errors, ImportRowError{Line: c.line, Message: fmt.Sprintf("failed to save product: %v", err)})
+			continue
+		}
+		report.count(c.action)
+	}
+	return report, nil
+}
+
+// updateProduct saves an imported product, records a change of its regular price and updates
+// the variants that follow it
+func (uc *CatalogUseCase) updateProduct(ctx context.Context, product *entity.Product, userID string) error {
+	current, err := uc.productRepo.FindByID(ctx, product.ID)
+	if err != nil {
+		return err
+	}
+	if err := uc.productRepo.Update(ctx, product); err != nil {
+		return err
+	}
+	if err := uc.pricingService.RecordPriceChange(ctx, product.ID, current.Price, product.Price, userID); err != nil {
+		return err
+	}
+	return updateVariants(ctx, uc.productRepo, uc.pricingService, product, userID)
+}
+
+// productFromRow returns the product a row creates, or the product it matches with the row applied
+func (uc *CatalogUseCase) productFromRow(ctx context.Context, table *csvTable, row csvRow) (*entity.Product, importAction, *ImportRowError) {
+	fail := func(column, format string, args ...interface{}) (*entity.Product, importAction, *ImportRowError) {
+		return nil, 0, &ImportRowError{Line: row.line, Column: column, Message: fmt.Sprintf(format, args...)}
+	}
+
+	id, sku := table.value(row, "id"), table.value(row, "sku")
+	var product *entity.Product
+	var err error
+	switch {
+	case id != "":
+		product, err = uc.productRepo.FindByID(ctx, id)
+	case sku != "":
+		product, err = uc.productRepo.FindBySKU(ctx, sku)
+	default:
+		return fail("", "id or sku is required")
+	}
+
+	action := importUpdate
+	var before entity.Product
+	if err != nil {
+		action = importCreate
+		if id == "" {
+			id = generateProductID()
+		}
+		product = &entity.Product{ID: id}
+	} else {
+		before = *product
+	}
+
+	name, category := product.Name, product.Category
+	if table.has("name") {
+		name = table.value(row, "name")
+	}
+	if table.has("category") {
+		category = table.value(row, "category")
+	}
+	price := product.Price
+	if table.has("price") || action == importCreate {
+		if price, err = strconv.Atoi(table.value(row, "price")); err != nil {
+			return fail("price", "price must be a whole number of yen")
+		}
+	}
+	if product.IsVariant() {
+		if name != product.Name || category != product.Category {
+			return fail("", "the name and category of variant %s follow its parent", product.ID)
+		}
+		if price != product.Price {
+			if err := product.SetVariantPrice(price); err != nil {
+				return fail("price", "%v", err)
+			}
+		}
+	} else if err := product.UpdateDetails(name, price, category); err != nil {
+		return fail("", "%v", err)
+	}
+	if table.has("weight") {
+		weight := 0
+		if value := table.value(row, "weight"); value != "" {
+			if weight, err = strconv.Atoi(value); err != nil {
+				return fail("weight", "weight must be a whole number of grams")
+			}
+		}
+		if err := product.SetWeight(weight); err != nil {
+			return fail("weight", "%v", err)
+		}
+	}
+	if table.has("sku") {
+		if err := product.SetSKU(sku); err != nil {
+			return fail("sku", "%v", err)
+		}
+		if sku != "" {
+			if owner, err := uc.productRepo.FindBySKU(ctx, sku); err == nil && owner.ID != product.ID {
+				return fail("sku", "SKU %s is used by product %s", sku, owner.ID)
+			}
+		}
+	}
+	if table.has("backorder") || table.has("expected_available_at") {
+		mode, expectedAt := product.Backorder, product.ExpectedAvailableAt
+		if table.has("backorder") {
+			if mode, err = entity.ParseBackorderMode(table.value(row, "backorder")); err != nil {
+				return fail("backorder", "%v", err)
+			}
+		}
+		if table.has("expected_available_at") {
+			if expectedAt, err = parseCSVTime(table.value(row, "expected_available_at")); err != nil {
+				return fail("expected_available_at", "%v", err)
+			}
+		}
+		if err := product.SetBackorder(mode, expectedAt); err != nil {
+			return fail("backorder", "%v", err)
+		}
+	}
+
+	if product.IsVariant() && (product.Weight != before.Weight || !sameBackorder(&before, product)) {
+		return fail("", "the weight and backorder settings of variant %s follow its parent", product.ID)
+	}
+
+	if action == importCreate {
+		product.CreatedAt = product.UpdatedAt
+		product.Stocks = []entity.StockInfo{}
+	} else if sameCatalogFields(&before, product) {
+		action = importUnchanged
+	}
+	return product, action, nil
+}
+
+// sameCatalogFields checks if two versions of a product agree on every column of the product CSV
+func sameCatalogFields(a, b *entity.Product) bool {
+	return a.SKU == b.SKU && a.Name == b.Name && a.Price == b.Price && a.Category == b.Category &&
+		a.Weight == b.Weight && sameBackorder(a, b)
+}
+
+// ExportProductsCSV writes every product as a CSV file that ImportProductsCSV reads back (admin only)
+func (uc *CatalogUseCase) ExportProductsCSV(ctx context.Context, w io.Writer) error {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return err
+	}
+	products, err := uc.productRepo.FindAll(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list products: %w", err)
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+
+	return writeCSV(w, productCSVColumns, func(write func(record []string) error) error {
+		for _, product := range products {
+			expectedAt := ""
+			if product.ExpectedAvailableAt != nil {
+				expectedAt = product.ExpectedAvailableAt.Format(time.RFC3339)
+			}
+			err := write([]string{
+				product.ID, product.SKU, product.Name, strconv.Itoa(product.Price), product.Category,
+				strconv.Itoa(product.Weight), string(product.Backorder), expectedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ImportStockCSV sets the quantity of products held in warehouses from a CSV file (admin only).
+// Rows name the product by product_id or sku and are recorded in the stock ledger as stocktakes.
+// Nothing is changed on a dry run or when any row is invalid. Valid rows are saved one at a time,
+// so when saving a row fails the rows saved before it stay saved; the failed rows are reported.
+func (uc *CatalogUseCase) ImportStockCSV(ctx context.Context, r io.Reader, dryRun bool) (*ImportReport, error) {
+	admin, err := currentAdmin(ctx, uc.authService)
+	if err != nil {
+		return nil, err
+	}
+	table, err := readCSV(r, stockCSVColumns)
+	if err != nil {
+		return nil, err
+	}
+	if !table.has("product_id") && !table.has("sku") {
+		return nil, errors.New("CSV needs a product_id or sku column")
+	}
+	for _, column := range []string{"warehouse_id", "quantity"} {
+		if !table.has(column) {
+			return nil, fmt.Errorf("CSV needs a %s column", column)
+		}
+	}
+
+	// Current stock of each warehouse by product ID; a warehouse that does not exist maps to nil
+	stocks := make(map[string]map[string]*entity.Stock)
+	warehouseStock := func(warehouseID string) map[string]*entity.Stock {
+		if held, ok := stocks[warehouseID]; ok {
+			return held
+		}
+		list, err := uc.stockService.ListWarehouseStock(ctx, warehouseID)
+		var held map[string]*entity.Stock
+		if err == nil {
+			held = make(map[string]*entity.Stock)
+			for _, stock := range list {
+				held[stock.ProductID] = stock
+			}
+		}
+		stocks[warehouseID] = held
+		return held
+	}
+
+	type change struct {
+		line        int
+		productID   string
+		warehouseID string
+		quantity    int
+		action      importAction
+	}
+	var changes []change
+	report := &ImportReport{DryRun: dryRun, Rows: len(table.rows), Errors: []ImportRowError{}}
+	lineOf := make(map[[2]string]int)
+	for _, row := range table.rows {
+		fail := func(column, format string, args ...interface{}) {
+			report.Errors = append(report.Errors, ImportRowError{Line: row.line, Column: column, Message: fmt.Sprintf(format, args...)})
+		}
+		if rowErr := table.rowError(row); rowErr != nil {
+			report.Errors = append(report.Errors, *rowErr)
+			continue
+		}
+
+		productID, sku := table.value(row, "product_id"), table.value(row, "sku")
+		switch {
+		case productID != "":
+			product, err := uc.productRepo.FindByID(ctx, productID)
+			if err != nil {
+				fail("product_id", "product not found: %s", productID)
+				continue
+			}
+			if sku != "" && product.SKU != sku {
+				fail("sku", "SKU %s does not belong to product %s", sku, productID)
+				continue
+			}
+		case sku != "":
+			product, err := uc.productRepo.FindBySKU(ctx, sku)
+			if err != nil {
+				fail("sku", "no product has SKU %s", sku)
+				continue
+			}
+			productID = product.ID
+		default:
+			fail("", "product_id or sku is required")
+			continue
+		}
+		if err := requireNoVariants(ctx, uc.productRepo, productID); err != nil {
+			fail("", "%v", err)
+			continue
+		}
+
+		warehouseID := table.value(row, "warehouse_id")
+		held := warehouseStock(warehouseID)
+		if held == nil {
+			fail("warehouse_id", "warehouse not found: %s", warehouseID)
+			continue
+		}
+		quantity, err := strconv.Atoi(table.value(row, "quantity"))
+		if err != nil || quantity < 0 {
+			fail("quantity", "quantity must be a whole number of units, zero or more")
+			continue
+		}
+		action := importCreate
+		if stock, ok := held[productID]; ok {
+			if quantity < stock.Reserved {
+				fail("quantity", "quantity cannot go below the %d units reserved for orders", stock.Reserved)
+				continue
+			}
+			action = importUpdate
+			if quantity == stock.Quantity {
+				action = importUnchanged
+			}
+		}
+
+		key := [2]string{productID, warehouseID}
+		if line, ok := lineOf[key]; ok {
+			fail("", "stock of %s in %s is already in line %d", productID, warehouseID, line)
+			continue
+		}
+		lineOf[key] = row.line
+		changes = append(changes, change{line: row.line, productID: productID, warehouseID: warehouseID, quantity: quantity, action: action})
+	}
+
+	if dryRun || len(report.Errors) > 0 {
+		for _, c := range changes {
+			report.count(c.action)
+		}
+		return report, nil
+	}
+	ref := service.MovementRef{UserID: admin.ID, Note: "CSV import"}
+	for _, c := range changes {
+		if c.action != importUnchanged {
+			if _, err := uc.stockService.SetStockLevel(ctx, c.productID, c.warehouseID, c.quantity, ref); err != nil {
+				report.Errors = append(report.Errors, ImportRowError{Line: c.line, Message: fmt.Sprintf("failed to set stock: %v", err)})
+				continue
+			}
+		}
+		report.count(c.action)
+	}
+	return report, nil
+}
+
+// ExportStockCSV writes the stock held in every warehouse as a CSV file that ImportStockCSV
+// reads back (admin only)
+func (uc *CatalogUseCase) ExportStockCSV(ctx context.Context, w io.Writer) error {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return err
+	}
+	warehouses, err := uc.warehouseService.ListWarehouses(ctx)
+	if err != nil {
+		return err
+	}
+	sort.Slice(warehouses, func(i, j int) bool { return warehouses[i].ID < warehouses[j].ID })
+
+	skus := make(map[string]string)
+	skuOf := func(productID string) string {
+		sku, ok := skus[productID]
+		if !ok {
+			if product, err := uc.productRepo.FindByID(ctx, productID); err == nil {
+				sku = product.SKU
+			}
+			skus[productID] = sku
+		}
+		return sku
+	}
+	return writeCSV(w, stockCSVColumns, func(write func(record []string) error) error {
+		for _, warehouse := range warehouses {
+			stocks, err := uc.stockService.ListWarehouseStock(ctx, warehouse.ID)
+			if err != nil {
+				return err
+			}
+			for _, stock := range stocks {
+				if err := write([]string{stock.ProductID, skuOf(stock.ProductID), stock.WarehouseID, strconv.Itoa(stock.Quantity)}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// csvTable is a CSV file whose columns are looked up by their header
+type csvTable struct {
+	columns map[string]int
+	rows    []csvRow
+}
+
+// csvRow is a data row of a CSV file
+type csvRow struct {
+	line   int
+	fields []string
+}
+
+// has checks if the file has a column
+func (t *csvTable) has(column string) bool {
+	_, ok := t.columns[column]
+	return ok
+}
+
+// value returns the trimmed cell of a row in a column, or "" when the file has no such column
+func (t *csvTable) value(row csvRow, column string) string {
+	i, ok := t.columns[column]
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(row.fields[i])
+}
+
+// readCSV reads a CSV file whose header names some of the known columns, in any order and case.
+// A leading UTF-8 byte order mark is skipped and blank rows are ignored. Rows with the wrong
+// number of cells or text that is not UTF-8, such as Shift_JIS files, are reported as errors.
+func readCSV(r io.Reader, known []string) (*csvTable, error) {
+	br := bufio.NewReader(r)
+	if prefix, err := br.Peek(len(utf8BOM)); err == nil && string(prefix) == utf8BOM {
+		br.Discard(len(utf8BOM))
+	}
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	table := &csvTable{columns: make(map[string]int)}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !containsString(known, name) {
+			return nil, fmt.Errorf("unknown CSV column %q: columns are %s", name, strings.Join(known, ", "))
+		}
+		if table.has(name) {
+			return nil, fmt.Errorf("CSV column %q appears twice", name)
+		}
+		table.columns[name] = i
+	}
+
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		if strings.TrimSpace(strings.Join(fields, "")) == "" {
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		table.rows = append(table.rows, csvRow{line: line, fields: fields})
+	}
+	return table, nil
+}
+
+// rowError returns the problem with the shape of a row, if any
+func (t *csvTable) rowError(row csvRow) *ImportRowError {
+	if len(row.fields) != len(t.columns) {
+		return &ImportRowError{Line: row.line, Message: fmt.Sprintf("row has %d cells, the header has %d", len(row.fields), len(t.columns))}
+	}
+	for _, field := range row.fields {
+		if !utf8.ValidString(field) {
+			return &ImportRowError{Line: row.line, Message: "row is not UTF-8 text; save the file as CSV UTF-8"}
+		}
+	}
+	return nil
+}
+
+// writeCSV writes a CSV file starting with a UTF-8 byte order mark, so Excel reads Japanese text
+// correctly, and a header row, then the records written by rows
+func writeCSV(w io.Writer, header []string, rows func(write func(record []string) error) error) error {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	if err := rows(writer.Write); err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// parseCSVTime parses an RFC 3339 time or a YYYY-MM-DD date in local time; "" is no time
+func parseCSVTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time %q: use RFC 3339 or YYYY-MM-DD", value)
+	}
+	return &t, nil
+}
+
+// containsString checks if values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package interactor_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/auth"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/persistence"
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/interactor"
+)
+
+type catalogFixture struct {
+	*stockFixture
+	pricing *service.PricingService
+	catalog *interactor.CatalogUseCase
+}
+
+// newCatalogFixture sets up a catalog use case over the desk of newStockFixture and returns
+// it with a context acting as an admin
+func newCatalogFixture(t *testing.T) (*catalogFixture, context.Context) {
+	t.Helper()
+	f := &catalogFixture{stockFixture: newStockFixture(t)}
+	f.pricing = service.NewPricingService(f.productRepo, persistence.NewMemoryPriceHistoryRepository(), persistence.NewMemorySaleRepository())
+	warehouseService := service.NewWarehouseService(f.warehouseRepo, f.stockRepo, f.stockRepo.Transfers())
+	authService := auth.NewJWTAuthService(persistence.NewMemoryUserRepository())
+	f.catalog = interactor.NewCatalogUseCase(f.productRepo, f.stockService, warehouseService, f.pricing, authService)
+
+	admin, _ := entity.NewUser("USR-ADMIN", "admin", "admin123", true)
+	return f, auth.SetUserInContext(context.Background(), admin)
+}
+
+func TestCatalogUseCase_ImportProductsCSV(t *testing.T) {
+	f, ctx := newCatalogFixture(t)
+
+	// Excel writes a byte order mark; rows match by id, then by sku, and create the rest
+	file := "\ufeffID,SKU,Name,Price,Category\r\n" +
+		"P001,DESK-OAK,Oak Desk,350,Furniture\r\n" +
+		",CHAIR-RED,椅子,120,Furniture\r\n"
+	report, err := f.catalog.ImportProductsCSV(ctx, strings.NewReader(file), true)
+	if err != nil {
+		t.Fatalf("ImportProductsCSV failed: %v", err)
+	}
+	if report.Created != 1 || report.Updated != 1 || len(report.Errors) != 0 {
+		t.Fatalf("Expected one product created and one updated, got %+v", report)
+	}
+	if desk, _ := f.productRepo.FindByID(ctx, "P001"); desk.Price != 300 {
+		t.Errorf("Expected a dry run to change nothing, got price %d", desk.Price)
+	}
+
+	if _, err := f.catalog.ImportProductsCSV(ctx, strings.NewReader(file), false); err != nil {
+		t.Fatalf("ImportProductsCSV failed: %v", err)
+	}
+	if desk, _ := f.productRepo.FindByID(ctx, "P001"); desk.Price != 350 || desk.SKU != "DESK-OAK" {
+		t.Errorf("Expected the desk to be updated, got %+v", desk)
+	}
+	if changes, _ := f.pricing.PriceHistory(ctx, "P001"); len(changes) != 1 || changes[0].Price != 350 {
+		t.Errorf("Expected the new price to be recorded in the price history, got %+v", changes)
+	}
+	chair, err := f.productRepo.FindBySKU(ctx, "CHAIR-RED")
+	if err != nil || chair.Name != "椅子" {
+		t.Fatalf("Expected the chair to be created, got %+v, %v", chair, err)
+	}
+
+	// A price-only file updates by SKU and leaves the other columns alone
+	report, _ = f.catalog.ImportProductsCSV(ctx, strings.NewReader("sku,price\nCHAIR-RED,150\nDESK-OAK,350\n"), false)
+	if report.Updated != 1 || report.Unchanged != 1 {
+		t.Errorf("Expected one product updated and one unchanged, got %+v", report)
+	}
+	if chair, _ := f.productRepo.FindBySKU(ctx, "CHAIR-RED"); chair.Price != 150 || chair.Name != "椅子" {
+		t.Errorf("Expected only the chair's price to change, got %+v", chair)
+	}
+
+	// One bad row stops the whole file and every problem is reported with its line
+	file = "id,sku,name,price,category\n" +
+		"P001,,Desk,abc,Furniture\n" +
+		"P009,CHAIR-RED,Stool,50,Furniture\n" +
+		"P010,,Lamp,80,\n" +
+		"P011,,Shelf,90,Furniture\n"
+	report, err = f.catalog.ImportProductsCSV(ctx, strings.NewReader(file), false)
+	if err != nil {
+		t.Fatalf("ImportProductsCSV failed: %v", err)
+	}
+	lines := []int{}
+	for _, rowErr := range report.Errors {
+		lines = append(lines, rowErr.Line)
+	}
+	if len(lines) != 3 || lines[0] != 2 || lines[1] != 3 || lines[2] != 4 {
+		t.Errorf("Expected errors on lines 2, 3 and 4, got %+v", report.Errors)
+	}
+	if _, err := f.productRepo.FindByID(ctx, "P011"); err == nil {
+		t.Error("Expected nothing to be imported from a file with errors")
+	}
+
+	if _, err := f.catalog.ImportProductsCSV(ctx, strings.NewReader("id,colour\nP001,red\n"), false); err == nil {
+		t.Error("Expected an unknown column to be rejected")
+	}
+}
+
+func TestCatalogUseCase_StockCSVRoundTrip(t *testing.T) {
+	f, ctx := newCatalogFixture(t)
+
+	var exported bytes.Buffer
+	if err := f.catalog.ExportStockCSV(ctx, &exported); err != nil {
+		t.Fatalf("ExportStockCSV failed: %v", err)
+	}
+	expected := "\ufeffproduct_id,sku,warehouse_id,quantity\nP001,,WH-001,1\nP001,,WH-002,1\n"
+	if exported.String() != expected {
+		t.Errorf("Expected export %q, got %q", expected, exported.String())
+	}
+
+	file := strings.Replace(exported.String(), "WH-002,1", "WH-002,4", 1)
+	report, err := f.catalog.ImportStockCSV(ctx, strings.NewReader(file), false)
+	if err != nil {
+		t.Fatalf("ImportStockCSV failed: %v", err)
+	}
+	if report.Updated != 1 || report.Unchanged != 1 || len(report.Errors) != 0 {
+		t.Errorf("Expected one stock record updated and one unchanged, got %+v", report)
+	}
+	if f.quantity(t) != 5 {
+		t.Errorf("Expected 5 units in stock, got %d", f.quantity(t))
+	}
+	movements, _ := f.stockService.StockMovements(ctx, repository.StockMovementFilter{WarehouseID: "WH-002"})
+	if last := movements[len(movements)-1]; last.Type != entity.StockMovementStocktake || last.Quantity != 3 {
+		t.Errorf("Expected the import to be recorded as a stocktake of +3, got %+v", last)
+	}
+
+	report, _ = f.catalog.ImportStockCSV(ctx, strings.NewReader("product_id,warehouse_id,quantity\nP001,WH-404,1\nP001,WH-001,-1\n"), false)
+	if len(report.Errors) != 2 {
+		t.Errorf("Expected an unknown warehouse and a negative quantity to be reported, got %+v", report.Errors)
+	}
+}
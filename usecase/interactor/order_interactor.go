@@ -2,7 +2,9 @@ package interactor
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
 	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
@@ -10,11 +12,28 @@ import (
 	"github.com/gal1996/vibe_coding_with_architecture/usecase/port"
 )
 
+// ErrOrderAccessDenied is returned when the current user may not access an order
+var ErrOrderAccessDenied = errors.New("permission denied: cannot access order")
+
+// ErrPaymentUnavailable is returned when the payment gateway could not be reached or failed,
+// so neither a charge nor a refund can be relied on
+var ErrPaymentUnavailable = errors.New("payment processing error")
+
+// ErrRefundFailed is returned when an order failed after its payment was charged and the charge
+// could not be refunded, so the customer stays charged until the refund is retried
+var ErrRefundFailed = errors.New("refund failed")
+
+const (
+	defaultOrderPageSize = 20
+	maxOrderPageSize     = 100
+)
+
 // OrderUseCase implements the order use cases
 type OrderUseCase struct {
 	orderRepo      repository.OrderRepository
 	productRepo    repository.ProductRepository
 	orderService   *service.OrderService
+	addressService *service.AddressService
 	authService    port.AuthService
 	paymentService port.PaymentService
 }
@@ -24,6 +43,7 @@ func NewOrderUseCase(
 	orderRepo repository.OrderRepository,
 	productRepo repository.ProductRepository,
 	orderService *service.OrderService,
+	addressService *service.AddressService,
 	authService port.AuthService,
 	paymentService port.PaymentService,
 ) *OrderUseCase {
@@ -31,6 +51,7 @@ func NewOrderUseCase(
 		orderRepo:      orderRepo,
 		productRepo:    productRepo,
 		orderService:   orderService,
+		addressService: addressService,
 		authService:    authService,
 		paymentService: paymentService,
 	}
@@ -40,11 +61,13 @@ func NewOrderUseCase(
 type CreateOrderInput struct {
 	Items      []OrderItemInput
 	CouponCode string `json:"coupon_code,omitempty"` // Optional coupon code
+	AddressID  string // Address book entry to ship to; the user's default address when empty
 }
 
 // OrderItemInput represents an item in an order input
 type OrderItemInput struct {
 	ProductID string
+	SKU       string // Orders the product or variant with this SKU when ProductID is empty
 	Quantity  int
 }
 
@@ -61,12 +84,19 @@ func (uc *OrderUseCase) CreateOrder(ctx context.Context, input CreateOrderInput)
 	for i, item := range input.Items {
 		requests[i] = service.OrderRequest{
 			ProductID: item.ProductID,
+			SKU:       item.SKU,
 			Quantity:  item.Quantity,
 		}
 	}
 
-	// Create pending order with stock validation and coupon application (but without reducing stock)
-	order, err := uc.orderService.ProcessOrder(ctx, currentUser.ID, requests, input.CouponCode)
+	// Every order ships to an address from the user's address book
+	address, err := uc.addressService.ShippingAddressFor(ctx, currentUser.ID, input.AddressID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create order: %w", err)
+	}
+
+	// Create pending order with stock reservation and coupon application (but without reducing stock)
+	order, err := uc.orderService.ProcessOrder(ctx, currentUser.ID, requests, input.CouponCode, address)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create order: %w", err)
 	}
@@ -74,45 +104,69 @@ func (uc *OrderUseCase) CreateOrder(ctx context.Context, input CreateOrderInput)
 	// Process payment before confirming the order and reducing stock
 	paymentSuccess, err := uc.paymentService.ProcessPayment(ctx, order.TotalPrice, currentUser.ID, order.ID)
 	if err != nil {
-		// If payment processing fails (system error), mark order as payment failed
-		order.FailPayment()
-		uc.orderRepo.Update(ctx, order)
-		return nil, fmt.Errorf("payment processing error: %w", err)
+		// If payment processing fails (system error), mark order as payment failed and release its stock
+		if failErr := uc.orderService.FailPaymentAndReleaseStock(ctx, order); failErr != nil {
+			return nil, fmt.Errorf("%w: %w; failed to update order status: %w", ErrPaymentUnavailable, err, failErr)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrPaymentUnavailable, err)
 	}
 
 	if !paymentSuccess {
-		// If payment is declined, mark order as payment failed
-		order.FailPayment()
-		err = uc.orderRepo.Update(ctx, order)
+		// If payment is declined, mark order as payment failed and release its stock
+		err = uc.orderService.FailPaymentAndReleaseStock(ctx, order)
 		if err != nil {
 			return nil, fmt.Errorf("failed to update order status: %w", err)
 		}
 		return nil, fmt.Errorf("payment declined for order %s", order.ID)
 	}
 
-	// Payment successful, now confirm the order and reduce stock atomically
+	// Payment successful, now confirm the order and turn its reservations into deductions atomically
 	err = uc.orderService.ConfirmOrderAndReduceStock(ctx, order)
 	if err != nil {
-		// If stock reduction fails, mark order as payment failed
+		// If stock reduction fails, mark order as payment failed and refund the charge
 		// (Though payment succeeded, we cannot fulfill the order)
-		order.FailPayment()
-		uc.orderRepo.Update(ctx, order)
+		failErr := uc.orderService.FailPaymentAndReleaseStock(ctx, order)
+		if refundErr := uc.paymentService.RefundPayment(ctx, order.TotalPrice, currentUser.ID, order.ID); refundErr != nil {
+			return nil, fmt.Errorf("%w: order %s could not be confirmed after payment: %w", ErrRefundFailed, order.ID, errors.Join(err, refundErr, failErr))
+		}
+		if failErr != nil {
+			return nil, fmt.Errorf("failed to confirm order after payment: %w; failed to update order status: %w", err, failErr)
+		}
 		return nil, fmt.Errorf("failed to confirm order after payment: %w", err)
 	}
 
-	// Complete the order
-	err = order.Complete()
+	return order, nil
+}
+
+// QuoteOrder prices an order without placing it, so nothing is reserved or charged
+func (uc *OrderUseCase) QuoteOrder(ctx context.Context, input CreateOrderInput) (*service.OrderQuote, error) {
+	// Get current user
+	currentUser, err := uc.authService.GetCurrentUser(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to complete order: %w", err)
+		return nil, fmt.Errorf("authentication required: %w", err)
 	}
 
-	// Update order status in repository
-	err = uc.orderRepo.Update(ctx, order)
-	if err != nil {
-		return nil, fmt.Errorf("failed to update order: %w", err)
+	// Convert input to domain service request
+	requests := make([]service.OrderRequest, len(input.Items))
+	for i, item := range input.Items {
+		requests[i] = service.OrderRequest{
+			ProductID: item.ProductID,
+			SKU:       item.SKU,
+			Quantity:  item.Quantity,
+		}
 	}
 
-	return order, nil
+	// Without an address shipping is priced for an unknown destination
+	address, err := uc.addressService.ShippingAddressFor(ctx, currentUser.ID, input.AddressID)
+	if err != nil && !errors.Is(err, service.ErrShippingAddressRequired) {
+		return nil, fmt.Errorf("failed to quote order: %w", err)
+	}
+
+	quote, err := uc.orderService.QuoteOrder(ctx, currentUser.ID, requests, input.CouponCode, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to quote order: %w", err)
+	}
+	return quote, nil
 }
 
 // GetOrder retrieves an order by ID
@@ -131,12 +185,120 @@ func (uc *OrderUseCase) GetOrder(ctx context.Context, orderID string) (*entity.O
 
 	// Check if user owns the order or is admin
 	if order.UserID != currentUser.ID && !currentUser.IsAdmin {
-		return nil, fmt.Errorf("permission denied: cannot access order")
+		return nil, ErrOrderAccessDenied
 	}
 
 	return order, nil
 }
 
+// CancelOrder cancels an order, returns its stock and refunds it if it was paid.
+// Customers may cancel their own orders; administrators may cancel any order
+// and may additionally close out orders whose payment failed.
+func (uc *OrderUseCase) CancelOrder(ctx context.Context, orderID string) (*entity.Order, error) {
+	// Get current user
+	currentUser, err := uc.authService.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("authentication required: %w", err)
+	}
+
+	// Get order
+	order, err := uc.orderRepo.FindByID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("order not found: %w", err)
+	}
+
+	// Check if user owns the order or is admin
+	if order.UserID != currentUser.ID && !currentUser.IsAdmin {
+		return nil, ErrOrderAccessDenied
+	}
+
+	return uc.cancelOrder(ctx, orderID, currentUser.ID, currentUser.IsAdmin, "")
+}
+
+// UpdateOrderStatusInput represents an administrator's request to move an order to a new status
+type UpdateOrderStatusInput struct {
+	Status         entity.OrderStatus
+	TrackingNumber string // Required when shipping
+	Note           string
+}
+
+// UpdateOrderStatus moves an order along its lifecycle on behalf of an administrator.
+// Fulfilment statuses only change the order; cancelling and returning also give the stock
+// back and refund the payment, and refunding retries a refund that failed earlier.
+func (uc *OrderUseCase) UpdateOrderStatus(ctx context.Context, orderID string, input UpdateOrderStatusInput) (*entity.Order, error) {
+	// Get current user
+	currentUser, err := uc.authService.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("authentication required: %w", err)
+	}
+	if !currentUser.IsAdmin {
+		return nil, ErrOrderAccessDenied
+	}
+
+	// Get order
+	order, err := uc.orderRepo.FindByID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("order not found: %w", err)
+	}
+
+	switch input.Status {
+	case entity.OrderStatusCancelled:
+		return uc.cancelOrder(ctx, orderID, currentUser.ID, true, input.Note)
+
+	case entity.OrderStatusReturned:
+		returned, err := uc.orderService.ReturnOrderAndRestoreStock(ctx, orderID, currentUser.ID, input.Note)
+		if err != nil {
+			return nil, fmt.Errorf("failed to return order: %w", err)
+		}
+		return uc.refund(ctx, returned, "")
+
+	case entity.OrderStatusRefunded:
+		// Check the transition on the loaded copy before any money moves
+		check := *order
+		if err := check.TransitionTo(entity.OrderStatusRefunded, input.Note); err != nil {
+			return nil, fmt.Errorf("failed to refund order: %w", err)
+		}
+		return uc.refund(ctx, order, input.Note)
+	}
+
+	updated, err := uc.orderService.AdvanceFulfilment(ctx, orderID, input.Status, input.TrackingNumber, input.Note)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update order status: %w", err)
+	}
+	return updated, nil
+}
+
+// cancelOrder cancels an order and refunds it if it was paid
+func (uc *OrderUseCase) cancelOrder(ctx context.Context, orderID, actorID string, byAdmin bool, note string) (*entity.Order, error) {
+	// Restore stock and coupon usage and mark the order cancelled atomically
+	cancelled, refundDue, err := uc.orderService.CancelOrderAndRestoreStock(ctx, orderID, actorID, byAdmin, note)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel order: %w", err)
+	}
+
+	if !refundDue {
+		return cancelled, nil
+	}
+	return uc.refund(ctx, cancelled, "")
+}
+
+// refund refunds the payment of a cancelled or returned order and marks it refunded.
+// It runs only after the cancellation or return is committed, so a refund is never issued
+// for an order that stays active. If the refund fails the order keeps its status and the
+// refund can be retried by moving it to refunded.
+func (uc *OrderUseCase) refund(ctx context.Context, order *entity.Order, note string) (*entity.Order, error) {
+	err := uc.paymentService.RefundPayment(ctx, order.TotalPrice, order.UserID, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("order %s was %s but the refund failed: %w", order.ID, order.Status, err)
+	}
+
+	refunded, err := uc.orderService.MarkRefunded(ctx, order.ID, note)
+	if err != nil {
+		return nil, fmt.Errorf("order %s was refunded but its status could not be updated: %w", order.ID, err)
+	}
+	return refunded, nil
+}
+
 // ListUserOrders lists orders for the current user
 func (uc *OrderUseCase) ListUserOrders(ctx context.Context) ([]*entity.Order, error) {
 	// Get current user
@@ -153,3 +315,82 @@ func (uc *OrderUseCase) ListUserOrders(ctx context.Context) ([]*entity.Order, er
 
 	return orders, nil
 }
+
+// SearchOrdersInput represents an administrator's order search. Zero-valued filters match every order.
+type SearchOrdersInput struct {
+	Status        entity.OrderStatus
+	UserID        string
+	CreatedFrom   time.Time // Inclusive
+	CreatedBefore time.Time // Exclusive
+	CouponCode    string
+	WarehouseID   string
+	SortBy        string // created_at (default), updated_at or total_price
+	Descending    bool
+	Page          int // 1-based, defaults to 1
+	PageSize      int // Defaults to 20, at most 100
+}
+
+// SearchOrdersOutput is one page of an order search
+type SearchOrdersOutput struct {
+	Orders   []*entity.Order `json:"orders"`
+	Total    int             `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+}
+
+// SearchOrders lists every user's orders matching the input (admin only)
+func (uc *OrderUseCase) SearchOrders(ctx context.Context, input SearchOrdersInput) (*SearchOrdersOutput, error) {
+	// Check if current user is admin
+	currentUser, err := uc.authService.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("authentication required: %w", err)
+	}
+	if !currentUser.IsAdmin {
+		return nil, ErrOrderAccessDenied
+	}
+
+	sortBy := repository.OrderSortField(input.SortBy)
+	switch sortBy {
+	case "":
+		sortBy = repository.OrderSortByCreatedAt
+	case repository.OrderSortByCreatedAt, repository.OrderSortByUpdatedAt, repository.OrderSortByTotalPrice:
+	default:
+		return nil, fmt.Errorf("invalid sort field: %s", input.SortBy)
+	}
+
+	page := input.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := input.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultOrderPageSize
+	}
+	pageSize = min(pageSize, maxOrderPageSize)
+
+	orders, total, err := uc.orderRepo.Search(ctx, repository.OrderFilter{
+		Status:        input.Status,
+		UserID:        input.UserID,
+		CreatedFrom:   input.CreatedFrom,
+		CreatedBefore: input.CreatedBefore,
+		CouponCode:    input.CouponCode,
+		WarehouseID:   input.WarehouseID,
+		SortBy:        sortBy,
+		Descending:    input.Descending,
+		Offset:        (page - 1) * pageSize,
+		Limit:         pageSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search orders: %w", err)
+	}
+	if orders == nil {
+		orders = []*entity.Order{}
+	}
+
+	return &SearchOrdersOutput{
+		Orders:   orders,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
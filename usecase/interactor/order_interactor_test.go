@@ -0,0 +1,120 @@
+package interactor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/auth"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/persistence"
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/interactor"
+)
+
+// stubPayment charges every payment, running onCharge first, and records the refunds it is asked for
+type stubPayment struct {
+	onCharge  func()
+	refundErr error
+	refunds   []int
+}
+
+func (p *stubPayment) ProcessPayment(ctx context.Context, amount int, userID string, orderID string) (bool, error) {
+	if p.onCharge != nil {
+		p.onCharge()
+	}
+	return true, nil
+}
+
+func (p *stubPayment) RefundPayment(ctx context.Context, amount int, userID string, orderID string) error {
+	if p.refundErr != nil {
+		return p.refundErr
+	}
+	p.refunds = append(p.refunds, amount)
+	return nil
+}
+
+type orderFixture struct {
+	*stockFixture
+	orderService   *service.OrderService
+	orderRepo      *persistence.MemoryOrderRepository
+	couponService  *service.CouponService
+	addressService *service.AddressService
+	payment        *stubPayment
+	orders         *interactor.OrderUseCase
+}
+
+// newOrderFixture sets up an order use case selling the desk of newStockFixture and returns
+// it with a context acting as a customer who has a shipping address
+func newOrderFixture(t *testing.T) (*orderFixture, context.Context) {
+	t.Helper()
+	f := &orderFixture{
+		stockFixture: newStockFixture(t),
+		orderRepo:    persistence.NewMemoryOrderRepository(),
+		payment:      &stubPayment{},
+	}
+	couponRepo := persistence.NewMemoryCouponRepository()
+	pricing := service.NewPricingService(f.productRepo, persistence.NewMemoryPriceHistoryRepository(), persistence.NewMemorySaleRepository())
+	unitOfWork := persistence.NewMemoryUnitOfWork(f.stockRepo, persistence.NewMemoryStockReservationRepository(), couponRepo, f.orderRepo)
+	f.couponService = service.NewCouponService(couponRepo)
+	f.orderService = service.NewOrderService(f.productRepo, f.orderRepo, f.stockService, pricing, f.couponService,
+		unitOfWork, entity.DefaultTaxPolicy(), service.NewRuleShippingCalculator(persistence.NewMemoryShippingRuleRepository()), time.Minute)
+	f.addressService = service.NewAddressService(persistence.NewMemoryAddressRepository())
+	authService := auth.NewJWTAuthService(persistence.NewMemoryUserRepository())
+	f.orders = interactor.NewOrderUseCase(f.orderRepo, f.productRepo, f.orderService, f.addressService, authService, f.payment)
+
+	customer, _ := entity.NewUser("USER-001", "customer", "customer123", false)
+	ctx := auth.SetUserInContext(context.Background(), customer)
+	_, err := f.addressService.AddAddress(ctx, customer.ID, entity.AddressFields{
+		Recipient:  "山田 太郎",
+		PostalCode: "100-0001",
+		Prefecture: "東京都",
+		City:       "千代田区",
+		Line1:      "千代田1-1",
+		Phone:      "0312345678",
+	})
+	if err != nil {
+		t.Fatalf("AddAddress failed: %v", err)
+	}
+	return f, ctx
+}
+
+func TestOrderUseCase_RefundsWhenConfirmationFails(t *testing.T) {
+	for _, refundErr := range []error{nil, errors.New("gateway timeout")} {
+		f, ctx := newOrderFixture(t)
+		f.payment.refundErr = refundErr
+
+		// While the payment is charged the reservations expire and the stock is written off
+		f.payment.onCharge = func() {
+			if _, err := f.orderService.ReleaseExpiredReservations(ctx, time.Now().Add(2*time.Minute)); err != nil {
+				t.Fatalf("ReleaseExpiredReservations failed: %v", err)
+			}
+			for _, id := range []string{"WH-001", "WH-002"} {
+				writeOff := service.MovementRef{Type: entity.StockMovementAdjustment, Note: "damaged"}
+				if _, err := f.stockService.AdjustStockLevel(ctx, "P001", id, -1, writeOff); err != nil {
+					t.Fatalf("AdjustStockLevel failed: %v", err)
+				}
+			}
+		}
+
+		_, err := f.orders.CreateOrder(ctx, interactor.CreateOrderInput{Items: []interactor.OrderItemInput{{ProductID: "P001", Quantity: 2}}})
+		if err == nil {
+			t.Fatal("Expected the order to fail without stock to confirm it")
+		}
+		orders, _ := f.orderRepo.FindByUserID(ctx, "USER-001")
+		if len(orders) != 1 || orders[0].Status != entity.OrderStatusPaymentFailed {
+			t.Fatalf("Expected one order with failed payment, got %+v", orders)
+		}
+
+		if refundErr != nil {
+			if !errors.Is(err, interactor.ErrRefundFailed) || !errors.Is(err, refundErr) || errors.Is(err, interactor.ErrPaymentUnavailable) {
+				t.Errorf("Expected the error to report the failed refund, got %v", err)
+			}
+			continue
+		}
+		if len(f.payment.refunds) != 1 || f.payment.refunds[0] != orders[0].TotalPrice {
+			t.Errorf("Expected a refund of %d, got %v", orders[0].TotalPrice, f.payment.refunds)
+		}
+	}
+}
@@ -20,6 +20,9 @@ type ProductUseCase struct {
 	authService     port.AuthService
 	stockService    *service.StockService
 	wishlistService *service.WishlistService
+	productService  *service.ProductService
+	pricingService  *service.PricingService
+	searchService   *service.ProductSearchService
 }
 
 // NewProductUseCase creates a new product use case
@@ -29,6 +32,9 @@ func NewProductUseCase(
 	authService port.AuthService,
 	stockService *service.StockService,
 	wishlistService *service.WishlistService,
+	productService *service.ProductService,
+	pricingService *service.PricingService,
+	searchService *service.ProductSearchService,
 ) *ProductUseCase {
 	return &ProductUseCase{
 		productRepo:     productRepo,
@@ -36,14 +42,24 @@ func NewProductUseCase(
 		authService:     authService,
 		stockService:    stockService,
 		wishlistService: wishlistService,
+		productService:  productService,
+		pricingService:  pricingService,
+		searchService:   searchService,
 	}
 }
 
 // CreateProductInput represents the input for creating a product
 type CreateProductInput struct {
-	Name     string
-	Price    int
+	SKU         string // Optional, unique stock keeping unit
+	Name        string
+	Description string
+	Price       int
 	Category string
+	Weight   int // Grams per unit
+	// Backorder is whether the product can be ordered beyond its stock; ExpectedAvailableAt is
+	// when missing units are expected and is required for pre-orders
+	Backorder           entity.BackorderMode
+	ExpectedAvailableAt *time.Time
 	// Stock is now managed through warehouse-specific allocations
 	// Use StockService to add stock to specific warehouses after product creation
 }
@@ -69,6 +85,18 @@ func (uc *ProductUseCase) CreateProduct(ctx context.Context, input CreateProduct
 	if err != nil {
 		return nil, fmt.Errorf("invalid product data: %w", err)
 	}
+	if err := product.SetWeight(input.Weight); err != nil {
+		return nil, fmt.Errorf("invalid product data: %w", err)
+	}
+	if err := product.SetDescription(input.Description); err != nil {
+		return nil, fmt.Errorf("invalid product data: %w", err)
+	}
+	if err := product.SetSKU(input.SKU); err != nil {
+		return nil, fmt.Errorf("invalid product data: %w", err)
+	}
+	if err := product.SetBackorder(input.Backorder, input.ExpectedAvailableAt); err != nil {
+		return nil, fmt.Errorf("invalid product data: %w", err)
+	}
 
 	// Save to repository
 	err = uc.productRepo.Create(ctx, product)
@@ -79,6 +107,264 @@ func (uc *ProductUseCase) CreateProduct(ctx context.Context, input CreateProduct
 	return product, nil
 }
 
+// SetBackorder sets whether a product can be ordered beyond its stock and when missing units
+// are expected (admin only). Orders already waiting keep the date they were placed with.
+// Variants follow their parent's settings.
+func (uc *ProductUseCase) SetBackorder(ctx context.Context, productID string, mode entity.BackorderMode, expectedAt *time.Time) (*entity.Product, error) {
+	admin, err := currentAdmin(ctx, uc.authService)
+	if err != nil {
+		return nil, err
+	}
+	product, err := uc.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %s", productID)
+	}
+	if product.IsVariant() {
+		return nil, errors.New("invalid product data: the backorder settings of a variant follow its parent")
+	}
+	if err := product.SetBackorder(mode, expectedAt); err != nil {
+		return nil, err
+	}
+	if err := uc.productRepo.Update(ctx, product); err != nil {
+		return nil, fmt.Errorf("failed to update product: %w", err)
+	}
+	if err := updateVariants(ctx, uc.productRepo, uc.pricingService, product, admin.ID); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// CreateVariantInput represents a variant of a product to create
+type CreateVariantInput struct {
+	SKU     string
+	Options map[string]string // Such as {"size": "M", "colour": "Red"}
+	Price   *int              // nil sells the variant at the parent's price
+}
+
+// CreateVariant adds a variant with its own SKU, stock and optionally price to a product (admin
+// only). The product is then ordered through its variants.
+func (uc *ProductUseCase) CreateVariant(ctx context.Context, parentID string, input CreateVariantInput) (*entity.Product, error) {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return nil, err
+	}
+	return uc.productService.CreateVariant(ctx, generateProductID(), parentID, input.SKU, input.Options, input.Price)
+}
+
+// UpdateProductInput represents the changes to a product; nil fields are left as they are
+type UpdateProductInput struct {
+	SKU         *string // "" removes the SKU
+	Name        *string
+	Description *string
+	Price       *int
+	Category    *string
+	Weight      *int
+}
+
+// UpdateProduct changes the details of a product (admin only). Orders already placed keep the
+// name and price they were placed with; a new regular price is recorded in the price history.
+// Variants follow their parent's name, description, category and weight, and its price unless
+// given their own.
+func (uc *ProductUseCase) UpdateProduct(ctx context.Context, productID string, input UpdateProductInput) (*entity.Product, error) {
+	admin, err := currentAdmin(ctx, uc.authService)
+	if err != nil {
+		return nil, err
+	}
+	product, err := uc.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %s", productID)
+	}
+
+	previousPrice := product.Price
+	if product.IsVariant() {
+		if input.Name != nil || input.Description != nil || input.Category != nil || input.Weight != nil {
+			return nil, errors.New("invalid product data: the name, description, category and weight of a variant follow its parent")
+		}
+		if input.Price != nil {
+			if err := product.SetVariantPrice(*input.Price); err != nil {
+				return nil, fmt.Errorf("invalid product data: %w", err)
+			}
+		}
+	} else {
+		name, price, category := product.Name, product.Price, product.Category
+		if input.Name != nil {
+			name = *input.Name
+		}
+		if input.Price != nil {
+			price = *input.Price
+		}
+		if input.Category != nil {
+			category = *input.Category
+		}
+		if err := product.UpdateDetails(name, price, category); err != nil {
+			return nil, fmt.Errorf("invalid product data: %w", err)
+		}
+		if input.Description != nil {
+			if err := product.SetDescription(*input.Description); err != nil {
+				return nil, fmt.Errorf("invalid product data: %w", err)
+			}
+		}
+	}
+	if input.Weight != nil {
+		if err := product.SetWeight(*input.Weight); err != nil {
+			return nil, fmt.Errorf("invalid product data: %w", err)
+		}
+	}
+	if input.SKU != nil {
+		if err := product.SetSKU(*input.SKU); err != nil {
+			return nil, fmt.Errorf("invalid product data: %w", err)
+		}
+	}
+
+	if err := uc.productRepo.Update(ctx, product); err != nil {
+		return nil, fmt.Errorf("failed to update product: %w", err)
+	}
+	if err := uc.pricingService.RecordPriceChange(ctx, product.ID, previousPrice, product.Price, admin.ID); err != nil {
+		return nil, err
+	}
+	if err := updateVariants(ctx, uc.productRepo, uc.pricingService, product, admin.ID); err != nil {
+		return nil, err
+	}
+	if err := uc.pricingService.ApplySale(ctx, product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// updateVariants copies a parent's details to its variants with FollowParent and records the
+// variants' price changes
+func updateVariants(ctx context.Context, productRepo repository.ProductRepository, pricing *service.PricingService, parent *entity.Product, userID string) error {
+	if parent.IsVariant() {
+		return nil
+	}
+	variants, err := productRepo.FindVariants(ctx, parent.ID)
+	if err != nil {
+		return fmt.Errorf("failed to find variants: %w", err)
+	}
+	for _, variant := range variants {
+		before := *variant
+		variant.FollowParent(parent)
+		if variant.Name == before.Name && variant.Description == before.Description &&
+			variant.Category == before.Category && variant.Price == before.Price &&
+			variant.Weight == before.Weight && sameBackorder(&before, variant) {
+			continue
+		}
+		if err := productRepo.Update(ctx, variant); err != nil {
+			return fmt.Errorf("failed to update variant: %w", err)
+		}
+		if err := pricing.RecordPriceChange(ctx, variant.ID, before.Price, variant.Price, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sameBackorder checks if two versions of a product have the same backorder settings
+func sameBackorder(a, b *entity.Product) bool {
+	sameTime := a.ExpectedAvailableAt == nil && b.ExpectedAvailableAt == nil ||
+		a.ExpectedAvailableAt != nil && b.ExpectedAvailableAt != nil && a.ExpectedAvailableAt.Equal(*b.ExpectedAvailableAt)
+	return a.Backorder == b.Backorder && sameTime
+}
+
+// ScheduleSaleInput represents a sale to schedule
+type ScheduleSaleInput struct {
+	Price    int
+	StartsAt *time.Time // nil starts the sale now
+	EndsAt   time.Time
+}
+
+// ScheduleSale schedules a time-boxed sale price for a product (admin only). While the sale runs
+// the product lists both prices and orders are charged the sale price.
+func (uc *ProductUseCase) ScheduleSale(ctx context.Context, productID string, input ScheduleSaleInput) (*entity.Sale, error) {
+	admin, err := currentAdmin(ctx, uc.authService)
+	if err != nil {
+		return nil, err
+	}
+	startsAt := time.Now()
+	if input.StartsAt != nil {
+		startsAt = *input.StartsAt
+	}
+	return uc.pricingService.ScheduleSale(ctx, productID, input.Price, startsAt, input.EndsAt, admin.ID)
+}
+
+// CancelSale cancels a sale of a product that has not ended yet (admin only)
+func (uc *ProductUseCase) CancelSale(ctx context.Context, productID, saleID string) (*entity.Sale, error) {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return nil, err
+	}
+	return uc.pricingService.CancelSale(ctx, productID, saleID)
+}
+
+// ListSales lists the past, running and scheduled sales of a product (admin only)
+func (uc *ProductUseCase) ListSales(ctx context.Context, productID string) ([]*entity.Sale, error) {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return nil, err
+	}
+	return uc.pricingService.ListSales(ctx, productID)
+}
+
+// GetPriceHistory lists the changes of a product's regular price, oldest first (admin only)
+func (uc *ProductUseCase) GetPriceHistory(ctx context.Context, productID string) ([]*entity.PriceChange, error) {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return nil, err
+	}
+	return uc.pricingService.PriceHistory(ctx, productID)
+}
+
+// ArchiveProduct stops selling a product (admin only). It disappears from the product list
+// but stays readable by ID for past orders and wishlists.
+func (uc *ProductUseCase) ArchiveProduct(ctx context.Context, productID string) (*entity.Product, error) {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return nil, err
+	}
+	return uc.productService.Archive(ctx, productID)
+}
+
+// RestoreProduct puts an archived product back on sale (admin only)
+func (uc *ProductUseCase) RestoreProduct(ctx context.Context, productID string) (*entity.Product, error) {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return nil, err
+	}
+	return uc.productService.Restore(ctx, productID)
+}
+
+// ListArchivedProducts lists the archived products with their stock information (admin only)
+func (uc *ProductUseCase) ListArchivedProducts(ctx context.Context) ([]*entity.Product, error) {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return nil, err
+	}
+	products, err := uc.productRepo.FindAll(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list products: %w", err)
+	}
+
+	archived := make([]*entity.Product, 0)
+	for _, product := range products {
+		if !product.IsArchived() {
+			continue
+		}
+		stockInfos, totalStock, err := uc.stockService.GetProductStockInfo(ctx, product.ID)
+		if err != nil {
+			stockInfos = []entity.StockInfo{}
+			totalStock = 0
+		}
+		product.Stocks = stockInfos
+		product.TotalStock = totalStock
+		archived = append(archived, product)
+	}
+	return archived, nil
+}
+
+// CleanupCatalog removes wishlist entries and stock records left behind by deleted products,
+// and the empty stock records of archived products (admin only). Deleting a product through
+// the API only archives it, so the wishlist part repairs legacy data only.
+func (uc *ProductUseCase) CleanupCatalog(ctx context.Context) (*service.CatalogCleanup, error) {
+	admin, err := currentAdmin(ctx, uc.authService)
+	if err != nil {
+		return nil, err
+	}
+	return uc.productService.Cleanup(ctx, service.MovementRef{UserID: admin.ID, Note: "catalog cleanup"})
+}
+
 // GetProduct retrieves a product by ID with stock information from all warehouses
 func (uc *ProductUseCase) GetProduct(ctx context.Context, productID string) (*entity.Product, error) {
 	product, err := uc.productRepo.FindByID(ctx, productID)
@@ -87,17 +373,30 @@ func (uc *ProductUseCase) GetProduct(ctx context.Context, productID string) (*en
 	}
 
 	// Get stock information from all warehouses
-	stockInfos, totalStock, err := uc.stockService.GetProductStockInfo(ctx, productID)
-	if err != nil {
-		// Log error but don't fail - product exists even if stock info unavailable
-		// In production, you might want to handle this differently
-		stockInfos = []entity.StockInfo{}
-		totalStock = 0
+	uc.loadStock(ctx, product)
+
+	// Show the sale price next to the regular price while a sale is running
+	if err := uc.pricingService.ApplySale(ctx, product); err != nil {
+		return nil, err
 	}
 
-	// Add warehouse stock information to product
-	product.Stocks = stockInfos
-	product.TotalStock = totalStock
+	// Show the variants on sale under their parent
+	if !product.IsVariant() {
+		all, err := uc.productRepo.FindVariants(ctx, product.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find variants: %w", err)
+		}
+		variants := make([]*entity.Product, 0, len(all))
+		for _, variant := range all {
+			if !variant.IsArchived() {
+				variants = append(variants, variant)
+			}
+		}
+		if err := uc.pricingService.ApplySales(ctx, variants); err != nil {
+			return nil, err
+		}
+		uc.attachVariants(ctx, product, variants)
+	}
 
 	// Check if product is in user's wishlist
 	currentUser, _ := uc.authService.GetCurrentUser(ctx)
@@ -109,26 +408,40 @@ func (uc *ProductUseCase) GetProduct(ctx context.Context, productID string) (*en
 	return product, nil
 }
 
-// ListProducts lists all products with optional category filter and stock information
+// ListProducts lists all products on sale with optional category filter and stock information
 func (uc *ProductUseCase) ListProducts(ctx context.Context, category string) ([]*entity.Product, error) {
-	products, err := uc.productRepo.FindAll(ctx, category)
+	all, err := uc.productRepo.FindAll(ctx, category)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list products: %w", err)
 	}
+	onSale := make([]*entity.Product, 0, len(all))
+	for _, product := range all {
+		if !product.IsArchived() {
+			onSale = append(onSale, product)
+		}
+	}
+	if err := uc.pricingService.ApplySales(ctx, onSale); err != nil {
+		return nil, err
+	}
+
+	// Variants are listed under their parent rather than on their own
+	products := make([]*entity.Product, 0, len(onSale))
+	variants := make(map[string][]*entity.Product)
+	for _, product := range onSale {
+		if product.IsVariant() {
+			variants[product.ParentID] = append(variants[product.ParentID], product)
+		} else {
+			products = append(products, product)
+		}
+	}
 
 	// Get current user for wishlist check
 	currentUser, _ := uc.authService.GetCurrentUser(ctx)
 
 	// Add stock information and wishlist status for each product
 	for _, product := range products {
-		stockInfos, totalStock, err := uc.stockService.GetProductStockInfo(ctx, product.ID)
-		if err != nil {
-			// Log error but don't fail - continue with empty stock info
-			stockInfos = []entity.StockInfo{}
-			totalStock = 0
-		}
-		product.Stocks = stockInfos
-		product.TotalStock = totalStock
+		uc.loadStock(ctx, product)
+		uc.attachVariants(ctx, product, variants[product.ID])
 
 		// Check if product is in user's wishlist
 		if currentUser != nil && uc.wishlistService != nil {
@@ -140,6 +453,80 @@ func (uc *ProductUseCase) ListProducts(ctx context.Context, category string) ([]
 	return products, nil
 }
 
+// Search results are returned a page at a time
+const (
+	defaultProductPageSize = 20
+	maxProductPageSize     = 100
+)
+
+// SearchProductsInput represents a catalog search. Zero-valued filters match every product.
+type SearchProductsInput struct {
+	Keyword  string
+	Category string
+	MinPrice *int
+	MaxPrice *int
+	InStock  bool
+	Sort     service.ProductSort // relevance (the default with a keyword), newest (the default without), price_asc, price_desc or popularity
+	Cursor   string
+	Limit    int // Defaults to 20, at most 100
+}
+
+// SearchProducts finds one page of the products on sale by keyword, price and stock, with the
+// number of results in each category. Variants are listed under their parent.
+func (uc *ProductUseCase) SearchProducts(ctx context.Context, input SearchProductsInput) (*service.ProductSearchResult, error) {
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultProductPageSize
+	}
+	limit = min(limit, maxProductPageSize)
+
+	result, err := uc.searchService.Search(ctx, service.ProductSearch{
+		Keyword:  input.Keyword,
+		Category: input.Category,
+		MinPrice: input.MinPrice,
+		MaxPrice: input.MaxPrice,
+		InStock:  input.InStock,
+		Sort:     input.Sort,
+		Cursor:   input.Cursor,
+		Limit:    limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Check which products are in the user's wishlist
+	currentUser, _ := uc.authService.GetCurrentUser(ctx)
+	if currentUser != nil && uc.wishlistService != nil {
+		for _, product := range result.Products {
+			isFavorite, _ := uc.wishlistService.IsInWishlist(ctx, currentUser.ID, product.ID)
+			product.IsFavorite = isFavorite
+		}
+	}
+	return result, nil
+}
+
+// loadStock adds the product's stock in each warehouse
+func (uc *ProductUseCase) loadStock(ctx context.Context, product *entity.Product) {
+	stockInfos, totalStock, err := uc.stockService.GetProductStockInfo(ctx, product.ID)
+	if err != nil {
+		// Log error but don't fail - product exists even if stock info unavailable
+		stockInfos = []entity.StockInfo{}
+		totalStock = 0
+	}
+	product.Stocks = stockInfos
+	product.TotalStock = totalStock
+}
+
+// attachVariants puts a product's variants, with their stock, under it; the product's total
+// stock includes theirs
+func (uc *ProductUseCase) attachVariants(ctx context.Context, product *entity.Product, variants []*entity.Product) {
+	for _, variant := range variants {
+		uc.loadStock(ctx, variant)
+		product.TotalStock += variant.TotalStock
+	}
+	product.Variants = variants
+}
+
 // generateProductID generates a unique product ID
 func generateProductID() string {
 	// In a real implementation, this would use a proper ID generator
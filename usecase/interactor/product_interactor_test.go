@@ -0,0 +1,52 @@
+package interactor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/auth"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/persistence"
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/interactor"
+)
+
+func TestProductUseCase_VariantsFollowParent(t *testing.T) {
+	f, ctx := newCatalogFixture(t)
+	productService := service.NewProductService(f.productRepo, persistence.NewMemoryWishlistRepository(), f.warehouseRepo, f.stockService)
+	products := interactor.NewProductUseCase(f.productRepo, persistence.NewMemoryUserRepository(), auth.NewJWTAuthService(persistence.NewMemoryUserRepository()),
+		f.stockService, nil, productService, f.pricing, nil)
+
+	shirt, _ := entity.NewProduct("P010", "T-Shirt", 2000, "Apparel")
+	shirt.SetWeight(200)
+	f.productRepo.Create(ctx, shirt)
+	medium, err := productService.CreateVariant(ctx, "P011", "P010", "TS-M", map[string]string{"size": "M"}, nil)
+	if err != nil {
+		t.Fatalf("CreateVariant failed: %v", err)
+	}
+	if medium.Weight != 200 {
+		t.Errorf("Expected the variant to start with the parent's weight, got %d", medium.Weight)
+	}
+
+	// Changing the parent's weight and backorder settings carries over to the variant
+	weight := 250
+	if _, err := products.UpdateProduct(ctx, "P010", interactor.UpdateProductInput{Weight: &weight}); err != nil {
+		t.Fatalf("UpdateProduct failed: %v", err)
+	}
+	expectedAt := time.Date(2030, 4, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := products.SetBackorder(ctx, "P010", entity.BackorderPreorder, &expectedAt); err != nil {
+		t.Fatalf("SetBackorder failed: %v", err)
+	}
+	medium, _ = f.productRepo.FindByID(ctx, "P011")
+	if medium.Weight != 250 || medium.Backorder != entity.BackorderPreorder || medium.ExpectedAvailableAt == nil || !medium.ExpectedAvailableAt.Equal(expectedAt) {
+		t.Errorf("Expected the variant to follow the parent's weight and pre-order date, got %+v", medium)
+	}
+
+	// The variant cannot set them on its own
+	if _, err := products.UpdateProduct(ctx, "P011", interactor.UpdateProductInput{Weight: &weight}); err == nil {
+		t.Error("Expected the weight of a variant to be refused")
+	}
+	if _, err := products.SetBackorder(ctx, "P011", entity.BackorderNone, nil); err == nil {
+		t.Error("Expected the backorder settings of a variant to be refused")
+	}
+}
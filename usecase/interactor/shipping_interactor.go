@@ -0,0 +1,158 @@
+package interactor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/port"
+)
+
+// ErrAdminRequired is returned when a non-admin user calls an admin-only use case
+var ErrAdminRequired = errors.New("permission denied: admin access required")
+
+// ShippingUseCase handles the administration of shipping rules
+type ShippingUseCase struct {
+	ruleRepo    repository.ShippingRuleRepository
+	authService port.AuthService
+}
+
+// NewShippingUseCase creates a new shipping use case
+func NewShippingUseCase(ruleRepo repository.ShippingRuleRepository, authService port.AuthService) *ShippingUseCase {
+	return &ShippingUseCase{
+		ruleRepo:    ruleRepo,
+		authService: authService,
+	}
+}
+
+// ShippingRuleInput represents the settings of a shipping rule
+type ShippingRuleInput struct {
+	Name                  string
+	Priority              int
+	Active                bool
+	Prefectures           []string
+	WarehouseIDs          []string
+	MinItems              int
+	MaxItems              int
+	MinWeight             int
+	MaxWeight             int
+	Fee                   int
+	FreeShippingThreshold int
+	StartsAt              *time.Time
+	EndsAt                *time.Time
+}
+
+// apply copies the input's settings onto a rule
+func (input ShippingRuleInput) apply(rule *entity.ShippingRule) {
+	rule.Name = input.Name
+	rule.Priority = input.Priority
+	rule.Active = input.Active
+	rule.Prefectures = input.Prefectures
+	rule.WarehouseIDs = input.WarehouseIDs
+	rule.MinItems = input.MinItems
+	rule.MaxItems = input.MaxItems
+	rule.MinWeight = input.MinWeight
+	rule.MaxWeight = input.MaxWeight
+	rule.Fee = input.Fee
+	rule.FreeShippingThreshold = input.FreeShippingThreshold
+	rule.StartsAt = input.StartsAt
+	rule.EndsAt = input.EndsAt
+}
+
+// ListShippingRules lists all shipping rules in the order they are tried (admin only)
+func (uc *ShippingUseCase) ListShippingRules(ctx context.Context) ([]*entity.ShippingRule, error) {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return nil, err
+	}
+
+	rules, err := uc.ruleRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shipping rules: %w", err)
+	}
+	if rules == nil {
+		rules = []*entity.ShippingRule{}
+	}
+	return rules, nil
+}
+
+// CreateShippingRule creates a shipping rule (admin only)
+func (uc *ShippingUseCase) CreateShippingRule(ctx context.Context, input ShippingRuleInput) (*entity.ShippingRule, error) {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rule := &entity.ShippingRule{
+		ID:        generateShippingRuleID(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	input.apply(rule)
+	if err := rule.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid shipping rule: %w", err)
+	}
+
+	if err := uc.ruleRepo.Create(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to create shipping rule: %w", err)
+	}
+	return rule, nil
+}
+
+// UpdateShippingRule replaces the settings of a shipping rule (admin only)
+func (uc *ShippingUseCase) UpdateShippingRule(ctx context.Context, id string, input ShippingRuleInput) (*entity.ShippingRule, error) {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return nil, err
+	}
+
+	rule, err := uc.ruleRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	input.apply(rule)
+	rule.UpdatedAt = time.Now()
+	if err := rule.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid shipping rule: %w", err)
+	}
+
+	if err := uc.ruleRepo.Update(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to update shipping rule: %w", err)
+	}
+	return rule, nil
+}
+
+// DeleteShippingRule deletes a shipping rule (admin only)
+func (uc *ShippingUseCase) DeleteShippingRule(ctx context.Context, id string) error {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return err
+	}
+
+	return uc.ruleRepo.Delete(ctx, id)
+}
+
+// requireAdmin checks that the current user is an admin
+func requireAdmin(ctx context.Context, authService port.AuthService) error {
+	_, err := currentAdmin(ctx, authService)
+	return err
+}
+
+// currentAdmin returns the current user if they are an administrator
+func currentAdmin(ctx context.Context, authService port.AuthService) (*entity.User, error) {
+	currentUser, err := authService.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("authentication required: %w", err)
+	}
+	if !currentUser.IsAdmin {
+		return nil, ErrAdminRequired
+	}
+	return currentUser, nil
+}
+
+// generateShippingRuleID generates a unique shipping rule ID
+func generateShippingRuleID() string {
+	// In a real implementation, this would use a proper ID generator
+	return fmt.Sprintf("SHIP-%d-%d", time.Now().Unix(), rand.Intn(10000))
+}
@@ -0,0 +1,148 @@
+package interactor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/port"
+)
+
+// stockAlertKey identifies the stock record an alert is about
+type stockAlertKey struct {
+	productID   string
+	warehouseID string
+}
+
+// StockAlertUseCase handles reorder points and the alerts raised when stock runs low
+type StockAlertUseCase struct {
+	reorderService *service.ReorderService
+	productRepo    repository.ProductRepository
+	authService    port.AuthService
+	notifier       port.StockAlertNotifier
+
+	mu       sync.Mutex
+	notified map[stockAlertKey]entity.StockAlertType // Last alert sent for each stock record still low
+}
+
+// NewStockAlertUseCase creates a new stock alert use case
+func NewStockAlertUseCase(
+	reorderService *service.ReorderService,
+	productRepo repository.ProductRepository,
+	authService port.AuthService,
+	notifier port.StockAlertNotifier,
+) *StockAlertUseCase {
+	return &StockAlertUseCase{
+		reorderService: reorderService,
+		productRepo:    productRepo,
+		authService:    authService,
+		notifier:       notifier,
+		notified:       make(map[stockAlertKey]entity.StockAlertType),
+	}
+}
+
+// SetReorderPoint sets the reorder point of a product in a warehouse, or its default when
+// warehouseID is empty (admin only)
+func (uc *StockAlertUseCase) SetReorderPoint(ctx context.Context, productID, warehouseID string, threshold int) (*entity.ReorderPoint, error) {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return nil, err
+	}
+	if _, err := uc.productRepo.FindByID(ctx, productID); err != nil {
+		return nil, fmt.Errorf("product not found: %s", productID)
+	}
+	return uc.reorderService.SetReorderPoint(ctx, productID, warehouseID, threshold)
+}
+
+// DeleteReorderPoint removes the reorder point of a product in a warehouse, or its default (admin only)
+func (uc *StockAlertUseCase) DeleteReorderPoint(ctx context.Context, productID, warehouseID string) error {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return err
+	}
+	return uc.reorderService.DeleteReorderPoint(ctx, productID, warehouseID)
+}
+
+// ListReorderPoints lists every reorder point (admin only)
+func (uc *StockAlertUseCase) ListReorderPoints(ctx context.Context) ([]*entity.ReorderPoint, error) {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return nil, err
+	}
+	return uc.reorderService.ListReorderPoints(ctx)
+}
+
+// ListLowStock lists every stock record at or below its reorder point (admin only)
+func (uc *StockAlertUseCase) ListLowStock(ctx context.Context) ([]entity.StockAlert, error) {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return nil, err
+	}
+	alerts, err := uc.reorderService.CheckStock(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	uc.nameProducts(ctx, alerts)
+	if alerts == nil {
+		alerts = []entity.StockAlert{}
+	}
+	return alerts, nil
+}
+
+// CheckStock checks the stock of the given products, or of all products when productIDs is nil,
+// and notifies each alert whose type differs from the last one sent for its stock record.
+// Records that recovered are forgotten so they alert again when they next run low.
+// An alert that fails to send is retried by the next check.
+func (uc *StockAlertUseCase) CheckStock(ctx context.Context, productIDs []string) error {
+	alerts, err := uc.reorderService.CheckStock(ctx, productIDs)
+	if err != nil {
+		return err
+	}
+	uc.nameProducts(ctx, alerts)
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	var only map[string]bool
+	if productIDs != nil {
+		only = make(map[string]bool)
+		for _, productID := range productIDs {
+			only[productID] = true
+		}
+	}
+	current := make(map[stockAlertKey]bool)
+	var errs []error
+	for _, alert := range alerts {
+		key := stockAlertKey{alert.ProductID, alert.WarehouseID}
+		current[key] = true
+		if uc.notified[key] == alert.Type {
+			continue
+		}
+		if err := uc.notifier.NotifyStockAlert(ctx, alert); err != nil {
+			errs = append(errs, fmt.Errorf("failed to notify stock alert for %s in %s: %w", alert.ProductID, alert.WarehouseID, err))
+			continue
+		}
+		uc.notified[key] = alert.Type
+	}
+	for key := range uc.notified {
+		if !current[key] && (only == nil || only[key.productID]) {
+			delete(uc.notified, key)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// nameProducts fills in the product names of the alerts, leaving those of missing products empty
+func (uc *StockAlertUseCase) nameProducts(ctx context.Context, alerts []entity.StockAlert) {
+	names := make(map[string]string)
+	for i := range alerts {
+		name, ok := names[alerts[i].ProductID]
+		if !ok {
+			if product, err := uc.productRepo.FindByID(ctx, alerts[i].ProductID); err == nil {
+				name = product.Name
+			}
+			names[alerts[i].ProductID] = name
+		}
+		alerts[i].ProductName = name
+	}
+}
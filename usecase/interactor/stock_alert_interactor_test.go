@@ -0,0 +1,129 @@
+package interactor_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/persistence"
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/interactor"
+)
+
+type stockFixture struct {
+	productRepo   *persistence.MemoryProductRepository
+	warehouseRepo repository.WarehouseRepository
+	stockRepo     *persistence.MemoryStockRepository
+	stockService  *service.StockService
+}
+
+// newStockFixture sets up one product with a single unit left in each of two warehouses
+func newStockFixture(t *testing.T) *stockFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	productRepo := persistence.NewMemoryProductRepository()
+	warehouseRepo := persistence.NewMemoryWarehouseRepository()
+	stockRepo := persistence.NewMemoryStockRepository()
+
+	product, _ := entity.NewProduct("P001", "Desk", 300, "Furniture")
+	productRepo.Create(ctx, product)
+	stockService := service.NewStockService(stockRepo, warehouseRepo, stockRepo.Ledger(), service.NewPriorityAllocation(nil))
+	for _, id := range []string{"WH-001", "WH-002"} {
+		warehouse, _ := entity.NewWarehouse(id, id, "")
+		warehouseRepo.Create(ctx, warehouse)
+		restock := service.MovementRef{Type: entity.StockMovementRestock}
+		if _, err := stockService.AdjustStockLevel(ctx, "P001", id, 1, restock); err != nil {
+			t.Fatalf("AdjustStockLevel failed: %v", err)
+		}
+	}
+
+	return &stockFixture{
+		productRepo:   productRepo,
+		warehouseRepo: warehouseRepo,
+		stockRepo:     stockRepo,
+		stockService:  stockService,
+	}
+}
+
+// quantity returns the units of the desk held across all warehouses, reserved or not
+func (f *stockFixture) quantity(t *testing.T) int {
+	t.Helper()
+	stocks, _ := f.stockRepo.FindByProductID(context.Background(), "P001")
+	total := 0
+	for _, stock := range stocks {
+		total += stock.Quantity
+	}
+	return total
+}
+
+// recordingNotifier records the stock alerts it is sent
+type recordingNotifier struct {
+	alerts []entity.StockAlert
+}
+
+func (n *recordingNotifier) NotifyStockAlert(ctx context.Context, alert entity.StockAlert) error {
+	n.alerts = append(n.alerts, alert)
+	return nil
+}
+
+func TestStockAlertUseCase_NotifiesOncePerChange(t *testing.T) {
+	ctx := context.Background()
+	f := newStockFixture(t)
+	reorderService := service.NewReorderService(persistence.NewMemoryReorderPointRepository(), f.stockRepo, f.warehouseRepo)
+	notifier := &recordingNotifier{}
+	alertUseCase := interactor.NewStockAlertUseCase(reorderService, f.productRepo, nil, notifier)
+
+	var changed []string
+	f.stockService.OnStockChanged(func(productIDs []string) {
+		changed = append(changed, productIDs...)
+	})
+	check := func(expected int) {
+		t.Helper()
+		if err := alertUseCase.CheckStock(ctx, changed); err != nil {
+			t.Fatalf("CheckStock failed: %v", err)
+		}
+		changed = nil
+		if len(notifier.alerts) != expected {
+			t.Errorf("Expected %d alerts sent, got %+v", expected, notifier.alerts)
+		}
+	}
+
+	if _, err := reorderService.SetReorderPoint(ctx, "P001", "WH-001", 1); err != nil {
+		t.Fatalf("SetReorderPoint failed: %v", err)
+	}
+	if err := alertUseCase.CheckStock(ctx, nil); err != nil {
+		t.Fatalf("CheckStock failed: %v", err)
+	}
+	if len(notifier.alerts) != 1 || notifier.alerts[0].ProductName != "Desk" || notifier.alerts[0].Type != entity.StockAlertLow {
+		t.Fatalf("Expected a low-stock alert for the desk, got %+v", notifier.alerts)
+	}
+
+	// A stock change that leaves the alert as it was sends nothing
+	restock := service.MovementRef{Type: entity.StockMovementRestock}
+	if _, err := f.stockService.AdjustStockLevel(ctx, "P001", "WH-002", 1, restock); err != nil {
+		t.Fatalf("AdjustStockLevel failed: %v", err)
+	}
+	check(1)
+
+	// Running out escalates the alert
+	adjustment := service.MovementRef{Type: entity.StockMovementAdjustment}
+	if _, err := f.stockService.AdjustStockLevel(ctx, "P001", "WH-001", -1, adjustment); err != nil {
+		t.Fatalf("AdjustStockLevel failed: %v", err)
+	}
+	check(2)
+	if notifier.alerts[1].Type != entity.StockAlertOutOfStock {
+		t.Errorf("Expected an out-of-stock alert, got %+v", notifier.alerts[1])
+	}
+
+	// Recovering clears the alert so running low again is notified
+	if _, err := f.stockService.AdjustStockLevel(ctx, "P001", "WH-001", 5, restock); err != nil {
+		t.Fatalf("AdjustStockLevel failed: %v", err)
+	}
+	check(2)
+	if _, err := f.stockService.AdjustStockLevel(ctx, "P001", "WH-001", -4, adjustment); err != nil {
+		t.Fatalf("AdjustStockLevel failed: %v", err)
+	}
+	check(3)
+}
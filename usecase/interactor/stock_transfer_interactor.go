@@ -0,0 +1,103 @@
+package interactor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/port"
+)
+
+// StockTransferUseCase handles moving stock between warehouses
+type StockTransferUseCase struct {
+	transferService *service.StockTransferService
+	productRepo     repository.ProductRepository
+	authService     port.AuthService
+}
+
+// NewStockTransferUseCase creates a new stock transfer use case
+func NewStockTransferUseCase(
+	transferService *service.StockTransferService,
+	productRepo repository.ProductRepository,
+	authService port.AuthService,
+) *StockTransferUseCase {
+	return &StockTransferUseCase{
+		transferService: transferService,
+		productRepo:     productRepo,
+		authService:     authService,
+	}
+}
+
+// RequestTransferInput represents a request to move stock between warehouses
+type RequestTransferInput struct {
+	ProductID       string
+	FromWarehouseID string
+	ToWarehouseID   string
+	Quantity        int
+	Note            string
+}
+
+// RequestTransfer requests a transfer and holds its units in the source warehouse (admin only)
+func (uc *StockTransferUseCase) RequestTransfer(ctx context.Context, input RequestTransferInput) (*entity.StockTransfer, error) {
+	admin, err := currentAdmin(ctx, uc.authService)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uc.productRepo.FindByID(ctx, input.ProductID); err != nil {
+		return nil, fmt.Errorf("product not found: %s", input.ProductID)
+	}
+	return uc.transferService.RequestTransfer(ctx, input.ProductID, input.FromWarehouseID, input.ToWarehouseID, input.Quantity, admin.ID, input.Note)
+}
+
+// ShipTransfer takes a requested transfer's units out of the source warehouse (admin only)
+func (uc *StockTransferUseCase) ShipTransfer(ctx context.Context, id string) (*entity.StockTransfer, error) {
+	admin, err := currentAdmin(ctx, uc.authService)
+	if err != nil {
+		return nil, err
+	}
+	return uc.transferService.ShipTransfer(ctx, id, admin.ID)
+}
+
+// ReceiveTransfer adds an in-transit transfer's units to the destination warehouse (admin only)
+func (uc *StockTransferUseCase) ReceiveTransfer(ctx context.Context, id string) (*entity.StockTransfer, error) {
+	admin, err := currentAdmin(ctx, uc.authService)
+	if err != nil {
+		return nil, err
+	}
+	return uc.transferService.ReceiveTransfer(ctx, id, admin.ID)
+}
+
+// CancelTransfer cancels a transfer that has not shipped (admin only)
+func (uc *StockTransferUseCase) CancelTransfer(ctx context.Context, id string) (*entity.StockTransfer, error) {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return nil, err
+	}
+	return uc.transferService.CancelTransfer(ctx, id)
+}
+
+// GetTransfer returns a transfer (admin only)
+func (uc *StockTransferUseCase) GetTransfer(ctx context.Context, id string) (*entity.StockTransfer, error) {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return nil, err
+	}
+	return uc.transferService.GetTransfer(ctx, id)
+}
+
+// ListTransfers lists transfers newest first, optionally only those with one status (admin only)
+func (uc *StockTransferUseCase) ListTransfers(ctx context.Context, status string) ([]*entity.StockTransfer, error) {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return nil, err
+	}
+
+	var filter entity.StockTransferStatus
+	if status != "" {
+		parsed, err := entity.ParseStockTransferStatus(status)
+		if err != nil {
+			return nil, err
+		}
+		filter = parsed
+	}
+	return uc.transferService.ListTransfers(ctx, filter)
+}
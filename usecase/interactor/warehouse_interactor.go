@@ -0,0 +1,199 @@
+package interactor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/repository"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/port"
+)
+
+// ErrStockOnVariants is returned when stock is written for a product that is sold through its variants
+var ErrStockOnVariants = errors.New("product has variants: set the stock of each variant instead")
+
+// WarehouseUseCase handles the administration of warehouses and the stock they hold
+type WarehouseUseCase struct {
+	warehouseService *service.WarehouseService
+	stockService     *service.StockService
+	transferService  *service.StockTransferService
+	productRepo      repository.ProductRepository
+	authService      port.AuthService
+}
+
+// NewWarehouseUseCase creates a new warehouse use case
+func NewWarehouseUseCase(
+	warehouseService *service.WarehouseService,
+	stockService *service.StockService,
+	transferService *service.StockTransferService,
+	productRepo repository.ProductRepository,
+	authService port.AuthService,
+) *WarehouseUseCase {
+	return &WarehouseUseCase{
+		warehouseService: warehouseService,
+		stockService:     stockService,
+		transferService:  transferService,
+		productRepo:      productRepo,
+		authService:      authService,
+	}
+}
+
+// WarehouseStockLine is the stock of one product in a warehouse
+type WarehouseStockLine struct {
+	ProductID   string `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Quantity    int    `json:"quantity"`   // Units on hand, including reserved units
+	Reserved    int    `json:"reserved"`   // Units held for pending orders
+	Available   int    `json:"available"`  // Units that can still be ordered
+	InTransit   int    `json:"in_transit"` // Units on their way from other warehouses; not sellable until received
+}
+
+// ListWarehouses lists all warehouses (admin only)
+func (uc *WarehouseUseCase) ListWarehouses(ctx context.Context) ([]*entity.Warehouse, error) {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return nil, err
+	}
+	return uc.warehouseService.ListWarehouses(ctx)
+}
+
+// CreateWarehouse creates a warehouse (admin only)
+func (uc *WarehouseUseCase) CreateWarehouse(ctx context.Context, id, name, location string) (*entity.Warehouse, error) {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return nil, err
+	}
+	return uc.warehouseService.CreateWarehouse(ctx, id, name, location)
+}
+
+// UpdateWarehouse replaces the name and location of a warehouse (admin only)
+func (uc *WarehouseUseCase) UpdateWarehouse(ctx context.Context, id, name, location string) (*entity.Warehouse, error) {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return nil, err
+	}
+	return uc.warehouseService.UpdateWarehouse(ctx, id, name, location)
+}
+
+// DeleteWarehouse deletes a warehouse that holds no stock (admin only)
+func (uc *WarehouseUseCase) DeleteWarehouse(ctx context.Context, id string) error {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return err
+	}
+	return uc.warehouseService.DeleteWarehouse(ctx, id)
+}
+
+// ListWarehouseStock lists the stock held in a warehouse (admin only)
+func (uc *WarehouseUseCase) ListWarehouseStock(ctx context.Context, warehouseID string) ([]WarehouseStockLine, error) {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return nil, err
+	}
+
+	stocks, err := uc.stockService.ListWarehouseStock(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	inTransit, err := uc.transferService.InTransitTo(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []WarehouseStockLine{}
+	for _, stock := range stocks {
+		lines = append(lines, uc.stockLine(ctx, stock, inTransit[stock.ProductID]))
+		delete(inTransit, stock.ProductID)
+	}
+	// Products the warehouse will hold once a transfer arrives
+	for productID, quantity := range inTransit {
+		lines = append(lines, uc.stockLine(ctx, &entity.Stock{ProductID: productID, WarehouseID: warehouseID}, quantity))
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i].ProductID < lines[j].ProductID })
+	return lines, nil
+}
+
+// SetStock sets the quantity of a product held in a warehouse after a stocktake (admin only).
+// The difference is recorded in the stock ledger with note.
+func (uc *WarehouseUseCase) SetStock(ctx context.Context, warehouseID, productID string, quantity int, note string) (*WarehouseStockLine, error) {
+	return uc.changeStock(ctx, productID, func(actorID string) (*entity.Stock, error) {
+		ref := service.MovementRef{UserID: actorID, Note: note}
+		return uc.stockService.SetStockLevel(ctx, productID, warehouseID, quantity, ref)
+	})
+}
+
+// AdjustStock adds or, with a negative delta, removes units of a product in a warehouse (admin only).
+// movementType is restock or adjustment and is recorded in the stock ledger with note.
+func (uc *WarehouseUseCase) AdjustStock(ctx context.Context, warehouseID, productID string, delta int, movementType entity.StockMovementType, note string) (*WarehouseStockLine, error) {
+	return uc.changeStock(ctx, productID, func(actorID string) (*entity.Stock, error) {
+		ref := service.MovementRef{Type: movementType, UserID: actorID, Note: note}
+		return uc.stockService.AdjustStockLevel(ctx, productID, warehouseID, delta, ref)
+	})
+}
+
+// ListStockMovements lists the stock ledger entries matching the filter, oldest first (admin only)
+func (uc *WarehouseUseCase) ListStockMovements(ctx context.Context, filter repository.StockMovementFilter) ([]*entity.StockMovement, error) {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return nil, err
+	}
+	return uc.stockService.StockMovements(ctx, filter)
+}
+
+// VerifyStockLedger replays the stock ledger and lists the stock records it does not reproduce (admin only)
+func (uc *WarehouseUseCase) VerifyStockLedger(ctx context.Context) ([]service.LedgerDiscrepancy, error) {
+	if err := requireAdmin(ctx, uc.authService); err != nil {
+		return nil, err
+	}
+	return uc.stockService.VerifyLedger(ctx)
+}
+
+// changeStock checks the caller and the product before running change on the caller's behalf
+func (uc *WarehouseUseCase) changeStock(ctx context.Context, productID string, change func(actorID string) (*entity.Stock, error)) (*WarehouseStockLine, error) {
+	admin, err := currentAdmin(ctx, uc.authService)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uc.productRepo.FindByID(ctx, productID); err != nil {
+		return nil, fmt.Errorf("product not found: %s", productID)
+	}
+	if err := requireNoVariants(ctx, uc.productRepo, productID); err != nil {
+		return nil, err
+	}
+
+	stock, err := change(admin.ID)
+	if err != nil {
+		return nil, err
+	}
+	inTransit, err := uc.transferService.InTransitTo(ctx, stock.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+	line := uc.stockLine(ctx, stock, inTransit[productID])
+	return &line, nil
+}
+
+// requireNoVariants fails with ErrStockOnVariants if the product has variants, whose own stock is
+// what they are sold from
+func requireNoVariants(ctx context.Context, productRepo repository.ProductRepository, productID string) error {
+	variants, err := productRepo.FindVariants(ctx, productID)
+	if err != nil {
+		return fmt.Errorf("failed to find variants: %w", err)
+	}
+	if len(variants) > 0 {
+		return fmt.Errorf("%w: %s", ErrStockOnVariants, productID)
+	}
+	return nil
+}
+
+// stockLine describes a stock record with the product's current name and the units in transit to it
+func (uc *WarehouseUseCase) stockLine(ctx context.Context, stock *entity.Stock, inTransit int) WarehouseStockLine {
+	line := WarehouseStockLine{
+		ProductID: stock.ProductID,
+		Quantity:  stock.Quantity,
+		Reserved:  stock.Reserved,
+		Available: stock.Available(),
+		InTransit: inTransit,
+	}
+	if product, err := uc.productRepo.FindByID(ctx, stock.ProductID); err == nil {
+		line.ProductName = product.Name
+	}
+	return line
+}
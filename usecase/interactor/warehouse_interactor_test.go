@@ -0,0 +1,45 @@
+package interactor_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+	"github.com/gal1996/vibe_coding_with_architecture/domain/service"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/auth"
+	"github.com/gal1996/vibe_coding_with_architecture/infrastructure/persistence"
+	"github.com/gal1996/vibe_coding_with_architecture/usecase/interactor"
+)
+
+func TestWarehouseUseCase_StockOfProductsWithVariants(t *testing.T) {
+	f, ctx := newCatalogFixture(t)
+	warehouses := interactor.NewWarehouseUseCase(service.NewWarehouseService(f.warehouseRepo, f.stockRepo, f.stockRepo.Transfers()), f.stockService,
+		service.NewStockTransferService(f.stockService, f.warehouseRepo, f.stockRepo.Transfers()), f.productRepo, auth.NewJWTAuthService(persistence.NewMemoryUserRepository()))
+
+	shirt, _ := entity.NewProduct("P010", "T-Shirt", 2000, "Apparel")
+	f.productRepo.Create(ctx, shirt)
+	productService := service.NewProductService(f.productRepo, persistence.NewMemoryWishlistRepository(), f.warehouseRepo, f.stockService)
+	if _, err := productService.CreateVariant(ctx, "P011", "P010", "TS-M", map[string]string{"size": "M"}, nil); err != nil {
+		t.Fatalf("CreateVariant failed: %v", err)
+	}
+
+	// Stock is kept per variant, never on the parent
+	if _, err := warehouses.SetStock(ctx, "WH-001", "P010", 5, ""); !errors.Is(err, interactor.ErrStockOnVariants) {
+		t.Errorf("Expected ErrStockOnVariants, got %v", err)
+	}
+	if _, err := warehouses.AdjustStock(ctx, "WH-001", "P010", 5, entity.StockMovementRestock, ""); !errors.Is(err, interactor.ErrStockOnVariants) {
+		t.Errorf("Expected ErrStockOnVariants, got %v", err)
+	}
+	if line, err := warehouses.SetStock(ctx, "WH-001", "P011", 5, ""); err != nil || line.Quantity != 5 {
+		t.Errorf("Expected the variant's stock to be set, got %+v, %v", line, err)
+	}
+
+	report, err := f.catalog.ImportStockCSV(ctx, strings.NewReader("product_id,warehouse_id,quantity\nP010,WH-001,5\n"), false)
+	if err != nil {
+		t.Fatalf("ImportStockCSV failed: %v", err)
+	}
+	if len(report.Errors) != 1 {
+		t.Errorf("Expected the parent's row to be rejected, got %+v", report)
+	}
+}
@@ -0,0 +1,41 @@
+package port
+
+import (
+	"context"
+	"time"
+)
+
+// IdempotencyRecord is the stored outcome of a request made with an idempotency key.
+// A record without a status code belongs to a request that is still being processed.
+type IdempotencyRecord struct {
+	UserID      string
+	Key         string
+	RequestHash string // Fingerprint of the request the key was first used with
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// IsCompleted checks if the response of the original request has been recorded
+func (r *IdempotencyRecord) IsCompleted() bool {
+	return r.StatusCode != 0
+}
+
+// IdempotencyStore keeps idempotency keys per user so repeated requests can be answered from the first response
+type IdempotencyStore interface {
+	// Begin claims the record's key for a new request. If a record for the key that has not expired
+	// by record.CreatedAt already exists, it is returned with claimed false and left untouched;
+	// otherwise record is stored and claimed is true.
+	Begin(ctx context.Context, record *IdempotencyRecord) (existing *IdempotencyRecord, claimed bool, err error)
+
+	// Complete stores the response of the request that claimed the key
+	Complete(ctx context.Context, userID, key string, statusCode int, contentType string, body []byte) error
+
+	// Release forgets a claimed key so the request can be retried with it
+	Release(ctx context.Context, userID, key string) error
+
+	// DeleteExpired removes records that expired at or before now and returns how many were removed
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+}
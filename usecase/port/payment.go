@@ -9,6 +9,9 @@ type PaymentService interface {
 	// ProcessPayment processes the payment for the given amount
 	// Returns true if payment is successful, false otherwise
 	ProcessPayment(ctx context.Context, amount int, userID string, orderID string) (bool, error)
+
+	// RefundPayment returns the given amount of an order's payment to the user
+	RefundPayment(ctx context.Context, amount int, userID string, orderID string) error
 }
 
 // PaymentRequest represents a payment request
@@ -0,0 +1,13 @@
+package port
+
+import (
+	"context"
+
+	"github.com/gal1996/vibe_coding_with_architecture/domain/entity"
+)
+
+// StockAlertNotifier delivers low-stock and out-of-stock alerts to whoever restocks
+type StockAlertNotifier interface {
+	// NotifyStockAlert sends one alert
+	NotifyStockAlert(ctx context.Context, alert entity.StockAlert) error
+}